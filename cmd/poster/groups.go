@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/group"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// FileConfig is the content of the -config file
+//
+//	{
+//	  "groups": [
+//	    {"name": "kitchen", "printers": ["Cocina-1", "Cocina-2"], "strategy": "failover", "banner": true}
+//	  ]
+//	}
+type FileConfig struct {
+	Groups []group.Config `json:"groups"`
+}
+
+func loadFileConfig(path string) (*FileConfig, error) {
+	fileConfig := &FileConfig{}
+	if path == "" {
+		return fileConfig, nil
+	}
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if err := json.Unmarshal(data, fileConfig); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+	return fileConfig, nil
+}
+
+// newGroupRegistry loads the groups of the config file and the group given
+// by -backup, which puts the backups behind the selected printer
+func newGroupRegistry(config *Config) (*group.Registry, error) {
+	fileConfig, err := loadFileConfig(config.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	dial := func(name string) (connection.Connector, error) {
+		memberConfig := *config
+		memberConfig.PrinterName = name
+		if name != config.PrinterName {
+			// Other members are dialed by name (host:port for network printers)
+			memberConfig.NetworkAddr = ""
+		}
+		return createConnection(&memberConfig)
+	}
+
+	reg, err := group.NewRegistry(dial, healthCheck(), fileConfig.Groups...)
+	if err != nil {
+		return nil, err
+	}
+
+	if backups := splitList(config.Backup); len(backups) > 0 {
+		if config.PrinterName == "" {
+			return nil, fmt.Errorf("-backup requires a printer")
+		}
+		strategy := group.Failover
+		if config.RoundRobin {
+			strategy = group.RoundRobin
+		}
+		err := reg.Add(group.Config{
+			Name:     config.PrinterName,
+			Printers: append([]string{config.PrinterName}, backups...),
+			Strategy: strategy,
+			Banner:   config.Banner,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return reg, nil
+}
+
+// openPrinter opens a printer or printer group by name with the given profile
+func openPrinter(reg *group.Registry, config *Config, name string, prof *profile.Escpos) (*service.Printer, error) {
+	printer, _, err := reg.OpenPrinter(name, func(conn connection.Connector) (*service.Printer, error) {
+		return service.NewPrinter(composer.NewEscpos(), prof, conn)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	printer.Transmit = service.TransmitOptions{
+		ChunkSize:  config.ChunkSize,
+		ChunkDelay: config.ChunkDelay,
+	}
+	return printer, nil
+}
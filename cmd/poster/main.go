@@ -1,5 +1,7 @@
 // Package main es el ejecutor principal de la librería poster
 // Uso: poster [options] <json_file> [printer_name]
+//
+//	poster serve [options]
 package main
 
 import (
@@ -9,11 +11,14 @@ import (
 	"log"
 	"os"
 	"runtime"
+	"strings"
+	"time"
 
 	"github.com/adcondev/poster/pkg/composer"
 	"github.com/adcondev/poster/pkg/connection"
 	"github.com/adcondev/poster/pkg/document/executor"
 	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
 	"github.com/adcondev/poster/pkg/service"
 )
 
@@ -40,9 +45,30 @@ type Config struct {
 	SerialPort     string
 	BaudRate       int
 	OutputFile     string
+	Buffered       bool
+	ChunkSize      int
+	ChunkDelay     time.Duration
+	ConfigFile     string
+	ProfilesDir    string
+	Backup         string
+	RoundRobin     bool
+	Banner         bool
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := runServe(os.Args[2:]); err != nil {
+			log.Fatalf("Server failed: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "profiles" {
+		if err := runProfiles(os.Args[2:]); err != nil {
+			log.Fatalf("Profiles failed: %v", err)
+		}
+		return
+	}
+
 	config := parseArgs()
 
 	if config.Version {
@@ -96,6 +122,16 @@ func parseArgs() *Config {
 	flag.IntVar(&config.BaudRate, "baud", 9600, "Serial baud rate")
 	flag.StringVar(&config.OutputFile, "output", "output.prn", "Output file for file type")
 
+	flag.BoolVar(&config.Buffered, "buffered", false, "Compile the whole job before sending it to the printer")
+	flag.IntVar(&config.ChunkSize, "chunk-size", 0, "Bytes per write for buffered jobs (0 = single write)")
+	flag.DurationVar(&config.ChunkDelay, "chunk-delay", 0, "Pause between chunks for slow links (e.g., 20ms)")
+
+	flag.StringVar(&config.ConfigFile, "config", "", "Config file with printer groups")
+	flag.StringVar(&config.ProfilesDir, "profiles", "", "Directory with printer profile files (JSON/YAML)")
+	flag.StringVar(&config.Backup, "backup", "", "Comma-separated backup printers used when the printer is unavailable")
+	flag.BoolVar(&config.RoundRobin, "round-robin", false, "Share jobs between the printer and its backups")
+	flag.BoolVar(&config.Banner, "banner", false, "Print a banner on jobs redirected to a backup printer")
+
 	flag.BoolVar(&config.DryRun, "dry-run", false, "Validate without printing")
 	flag.BoolVar(&config.Debug, "debug", false, "Enable debug logging")
 	flag.BoolVar(&config.ListPrinters, "list", false, "List all available printers (Windows only)")
@@ -137,6 +173,8 @@ func showHelp() {
 
 USAGE:
   %s [options] <json_file> [printer_name]
+  %s serve [serve options]
+  %s profiles list|import [options]
 
 EXAMPLES:
   %s ticket.json
@@ -144,22 +182,55 @@ EXAMPLES:
   %s -t network -network 192.168.1.100:9100 ticket.json
   %s -t serial -serial COM1 -baud 115200 ticket.json
   %s -t file -output receipt.prn ticket.json
+  %s --buffered -chunk-size 512 -chunk-delay 20ms ticket.json
+  %s -backup "Cocina-2" -banner ticket.json "Cocina-1"
+  %s -config poster.json ticket.json kitchen
+  %s -profiles ./profiles ticket.json
   %s --dry-run ticket.json
   %s --list
   %s --list-thermal
   %s --list-physical
+  %s serve -printers "POS-80=ec-pm-80250" -token secret -cors http://localhost:5173
+  %s profiles import -o profiles/printer-db.json capabilities.json
 
 OPTIONS:
-`, AppName, AppVersion, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName)
+`, AppName, AppVersion, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName, AppName)
 
 	flag.PrintDefaults()
 
 	fmt.Println(`
 CONNECTION TYPES:
   windows  - Windows printer (default)
-  network  - Network printer
+  network  - Network printer (raw TCP, -network host[:port], port 9100 by default)
   serial   - Serial/USB printer
-  file     - Output to file
+  file     - Compile the job and write it to -output
+
+SERVE OPTIONS:
+  -addr      Listen address (default 127.0.0.1:8420)
+  -token     Bearer token required by the API (default $POSTER_TOKEN)
+  -cors      Comma-separated browser origins allowed (* for any)
+  -queue     Job queue file (default spool/jobs.jsonl)
+  -printers  Comma-separated name=profile pairs (names may be groups)
+             Profiles: generic-58mm, generic-80mm, pt-210, gp-58n, ec-pm-80250
+  -config    Config file with printer groups
+  -profiles  Directory with printer profile files (JSON/YAML)
+
+PRINTER GROUPS:
+  A group name can be used wherever a printer name is accepted. Members are
+  tried in order and skipped when they cannot be opened or report offline,
+  cover open or paper out. Groups are defined in the -config file:
+    {"groups": [{"name": "kitchen", "printers": ["Cocina-1", "Cocina-2"],
+                 "strategy": "failover", "banner": true}]}
+  Strategies: failover (default), round_robin
+
+PRINTER PROFILES:
+  profile.model is matched against profile names, aliases and models. Files
+  in the -profiles directory add models or override the built-in ones:
+    {"name": "tm-t20", "extends": "generic-80mm", "model": "Epson TM-T20",
+     "image_mode": "graphics", "cut": {"mode": "full"}}
+  The capabilities.json of escpos-printer-db (python-escpos, escpos-php) is
+  read as is from -profiles, or converted with "profiles import".
+  Use "profiles list" to see the available profiles.
 
 PRINTER LISTING (Windows only):
   --list          List all installed printers
@@ -169,7 +240,12 @@ PRINTER LISTING (Windows only):
 NOTES:
   - If no printer is specified, attempts to auto-detect common models
   - JSON files should follow the poster document format
-  - Use --dry-run to validate JSON without printing`)
+  - Use --dry-run to validate JSON without printing
+  - Use --buffered to send the ticket only after it fully compiles
+  - $POSTER_FALLBACK_FONT is the TTF/OTF font for text the printer cannot
+    print, on profiles without "fallback_font"
+  - Documents with "printers" are split by command target and printed on
+    every destination at once (file output writes <output>-<name>.prn)`)
 }
 
 func listPrinters(config *Config) {
@@ -256,33 +332,36 @@ func executePrint(config *Config) error {
 		log.Printf("Connection type: %s", config.ConnectionType)
 	}
 
+	// Printer profiles (built-in and -profiles files) resolve profile.model
+	profiles, err := loadProfiles(config.ProfilesDir)
+	if err != nil {
+		return err
+	}
+
 	// Dry run validation
 	if config.DryRun {
 		return validateDocument(&doc)
 	}
 
-	// Create connection
-	conn, err := createConnection(config)
-	if err != nil {
-		return fmt.Errorf("failed to create connection: %w", err)
+	// Documents with "printers" are split and sent to every destination
+	if len(doc.Printers) > 0 {
+		return routeDocument(config, profiles, &doc)
 	}
-	defer func(conn connection.Connector) {
-		err := conn.Close()
-		if err != nil {
-			log.Panicf("failed to close connection: %v", err)
-		}
-	}(conn)
 
-	// Create profile
-	prof := createProfile(&doc)
+	// File output compiles the job without a printer connection
+	if strings.ToLower(config.ConnectionType) == "file" {
+		return compileToFile(config, profiles, &doc)
+	}
 
-	// Create protocol
-	proto := composer.NewEscpos()
+	// Printer groups (from -config and -backup) fail over to healthy members
+	groups, err := newGroupRegistry(config)
+	if err != nil {
+		return err
+	}
 
-	// Create printer
-	printerService, err := service.NewPrinter(proto, prof, conn)
+	printerService, err := openPrinter(groups, config, config.PrinterName, createProfile(profiles, &doc))
 	if err != nil {
-		return fmt.Errorf("failed to create printer: %w", err)
+		return fmt.Errorf("failed to open printer: %w", err)
 	}
 	defer func(printerService *service.Printer) {
 		err := printerService.Close()
@@ -299,5 +378,35 @@ func executePrint(config *Config) error {
 		log.Println("Executing document...")
 	}
 
-	return exec.Execute(&doc)
+	var report *executor.Report
+	if config.Buffered || config.ChunkSize > 0 {
+		report, err = exec.ExecuteBuffered(&doc)
+	} else {
+		report, err = exec.Execute(&doc)
+	}
+	if report != nil {
+		log.Printf("Execution report: %s", report)
+	}
+	return err
+}
+
+// compileToFile compila el documento completo y lo guarda como archivo .prn
+func compileToFile(config *Config, profiles *profile.Registry, doc *schema.Document) error {
+	printerService, err := service.NewPrinter(composer.NewEscpos(), createProfile(profiles, doc), connection.NewBufferConnector())
+	if err != nil {
+		return fmt.Errorf("failed to create printer: %w", err)
+	}
+
+	job, err := executor.NewExecutor(printerService).Compile(doc)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(config.OutputFile, job, 0o600); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	log.Printf("Compiled job written to %s (%d bytes, sha256 %s)",
+		config.OutputFile, len(job), service.JobHash(job))
+	return nil
 }
@@ -47,6 +47,21 @@ func validateDocument(doc *schema.Document) error {
 	fmt.Printf("Profile: %s (%dmm)\n", doc.Profile.Model, doc.Profile.PaperWidth)
 	fmt.Printf("Commands: %d\n", len(doc.Commands))
 
+	if len(doc.Printers) > 0 {
+		subs, err := doc.Split()
+		if err != nil {
+			return err
+		}
+		fmt.Println("\nDestinations:")
+		for _, name := range doc.Destinations() {
+			if sub, ok := subs[name]; ok {
+				fmt.Printf(" %s → %s: %d commands\n", name, sub.Profile.Model, len(sub.Commands))
+			} else {
+				fmt.Printf(" %s: nothing to print\n", name)
+			}
+		}
+	}
+
 	// Validate commands
 	commandCounts := make(map[string]int)
 	for i, cmd := range doc.Commands {
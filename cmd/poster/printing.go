@@ -10,8 +10,7 @@ import (
 	"strings"
 
 	"github.com/adcondev/poster/pkg/connection"
-	"github.com/adcondev/poster/pkg/document/schema"
-	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/group"
 )
 
 func detectPrinter() string {
@@ -82,11 +81,14 @@ func createConnection(config *Config) (connection.Connector, error) {
 		return connection.NewWindowsPrintConnector(config.PrinterName)
 
 	case "network":
-		if config.NetworkAddr == "" {
+		addr := config.NetworkAddr
+		if addr == "" {
+			addr = config.PrinterName
+		}
+		if addr == "" {
 			return nil, fmt.Errorf("network address required")
 		}
-		// TODO: Implement when available in library
-		return nil, fmt.Errorf("network connection not yet implemented")
+		return connection.NewNetworkConnector(addr)
 
 	case "serial":
 		if config.SerialPort == "" {
@@ -95,51 +97,35 @@ func createConnection(config *Config) (connection.Connector, error) {
 		// TODO: Implement when available in library
 		return nil, fmt.Errorf("serial connection not yet implemented")
 
-	case "file":
-		// TODO: Implement file output connector
-		return nil, fmt.Errorf("file output not yet implemented")
-
 	default:
 		return nil, fmt.Errorf("unknown connection type: %s", config.ConnectionType)
 	}
 }
 
-func createProfile(doc *schema.Document) *profile.Escpos {
-	var prof *profile.Escpos
-
-	// Select profile based on paper width or model
-	if doc.Profile.Model != "" {
-		switch strings.ToLower(doc.Profile.Model) {
-		case "80mm ec-pm-80250", "ec-pm-80250":
-			prof = profile.CreateECPM80250()
-		case "58mm pt-210", "pt-210":
-			prof = profile.CreatePt210()
-		case "58mm gp-58n", "gp-58n":
-			prof = profile.CreateGP58N()
-		default:
-			if doc.Profile.PaperWidth >= 80 {
-				prof = profile.CreateProfile80mm()
-			} else {
-				prof = profile.CreateProfile58mm()
-			}
+// healthCheck adds the spooler state to the default status query, since the
+// Windows spooler connector cannot read DLE EOT answers
+func healthCheck() group.HealthCheck {
+	return func(name string, conn connection.Connector) error {
+		if err := group.CheckStatus(name, conn); err != nil {
+			return err
 		}
-	} else {
-		// Default based on paper width
-		if doc.Profile.PaperWidth >= 80 {
-			prof = profile.CreateProfile80mm()
-		} else {
-			prof = profile.CreateProfile58mm()
+		if _, ok := conn.(*connection.WindowsPrintConnector); !ok {
+			return nil
 		}
-	}
 
-	// Apply JSON overrides
-	if doc.Profile.Model != "" {
-		prof.Model = doc.Profile.Model
-	}
-	if doc.Profile.DPI > 0 {
-		prof.DPI = doc.Profile.DPI
+		printers, err := connection.ListAvailablePrinters()
+		if err != nil {
+			return nil
+		}
+		for _, p := range printers {
+			if !strings.EqualFold(p.Name, name) {
+				continue
+			}
+			switch p.Status {
+			case connection.StateOffline, connection.StatePaused, connection.StateError:
+				return fmt.Errorf("spooler reports %s", p.Status)
+			}
+		}
+		return nil
 	}
-	prof.HasQR = doc.Profile.HasQR
-
-	return prof
 }
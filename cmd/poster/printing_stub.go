@@ -6,8 +6,7 @@ import (
 	"fmt"
 
 	"github.com/adcondev/poster/pkg/connection"
-	"github.com/adcondev/poster/pkg/document/schema"
-	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/group"
 )
 
 func detectPrinter() string {
@@ -18,7 +17,6 @@ func createConnection(_ *Config) (connection.Connector, error) {
 	return nil, fmt.Errorf("printing is only supported on Windows")
 }
 
-func createProfile(_ *schema.Document) *profile.Escpos {
-	// Retornar un perfil dummy o nil para que compile
-	return &profile.Escpos{}
+func healthCheck() group.HealthCheck {
+	return nil
 }
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+// loadProfiles returns the built-in profiles plus the profile files found
+// in dir, which may add models or override the built-in ones
+func loadProfiles(dir string) (*profile.Registry, error) {
+	reg := profile.Builtin()
+	if dir != "" {
+		if err := reg.LoadDir(dir); err != nil {
+			return nil, err
+		}
+	}
+	if err := reg.Validate(); err != nil {
+		return nil, err
+	}
+	return reg, nil
+}
+
+// serverProfiles returns every registered profile by name, plus the profile
+// names used by the configured printers (aliases or models)
+func serverProfiles(reg *profile.Registry, refs ...string) (map[string]*profile.Escpos, error) {
+	profiles := make(map[string]*profile.Escpos)
+	for _, name := range reg.Names() {
+		p, err := reg.Get(name)
+		if err != nil {
+			return nil, err
+		}
+		profiles[name] = withFallbackFont(p)
+	}
+	for _, ref := range refs {
+		if _, ok := profiles[ref]; ok {
+			continue
+		}
+		p, err := reg.Resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+		profiles[ref] = withFallbackFont(p)
+	}
+	return profiles, nil
+}
+
+// withFallbackFont sets the fallback font of p from $POSTER_FALLBACK_FONT
+// unless its profile file declares one
+func withFallbackFont(p *profile.Escpos) *profile.Escpos {
+	if p.FallbackFont == "" {
+		p.FallbackFont = os.Getenv(constants.FallbackFontEnv)
+	}
+	return p
+}
+
+// createProfile resolves doc.Profile.Model against the registry. Unknown
+// models use the generic profile for the paper width.
+func createProfile(reg *profile.Registry, doc *schema.Document) *profile.Escpos {
+	prof, err := reg.Resolve(doc.Profile.Model)
+	if err != nil {
+		name := profile.Generic58mm
+		if doc.Profile.PaperWidth >= 80 {
+			name = profile.Generic80mm
+		}
+		prof, _ = reg.Get(name) // Built-in names are always registered
+	}
+
+	// Apply JSON overrides
+	if doc.Profile.Model != "" {
+		prof.Model = doc.Profile.Model
+	}
+	if doc.Profile.DPI > 0 {
+		prof.DPI = doc.Profile.DPI
+	}
+	prof.HasQR = doc.Profile.HasQR
+
+	return withFallbackFont(prof)
+}
+
+// runProfiles implements `poster profiles list` and `poster profiles import`
+func runProfiles(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s profiles list [-profiles dir] | import [-o file] capabilities.json", AppName)
+	}
+
+	fs := flag.NewFlagSet("profiles "+args[0], flag.ContinueOnError)
+	dir := fs.String("profiles", "", "Directory with printer profile files (JSON/YAML)")
+	output := fs.String("o", "", "Profile file to write (default stdout)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "list":
+		reg, err := loadProfiles(*dir)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintln(w, "NAME\tMODEL\tPAPER\tDPI\tDOTS")
+		for _, name := range reg.Names() {
+			p, err := reg.Get(name)
+			if err != nil {
+				return err
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%.0fmm\t%d\t%d\n", name, p.Model, p.PaperWidth, p.DPI, p.DotsPerLine)
+		}
+		return w.Flush()
+
+	case "import":
+		if fs.NArg() != 1 {
+			return fmt.Errorf("usage: %s profiles import [-o file] capabilities.json", AppName)
+		}
+		data, err := os.ReadFile(filepath.Clean(fs.Arg(0)))
+		if err != nil {
+			return fmt.Errorf("failed to read capabilities: %w", err)
+		}
+		imported, err := profile.ImportCapabilities(data)
+		if err != nil {
+			return err
+		}
+		for _, warning := range imported.Warnings {
+			log.Printf("warning: %s", warning)
+		}
+
+		out, err := json.MarshalIndent(imported.Definitions, "", "  ")
+		if err != nil {
+			return err
+		}
+		out = append(out, '\n')
+		if *output == "" {
+			_, err = os.Stdout.Write(out)
+			return err
+		}
+		if err := os.WriteFile(*output, out, 0o600); err != nil {
+			return fmt.Errorf("failed to write profiles: %w", err)
+		}
+		log.Printf("Imported %d profiles into %s", len(imported.Definitions), *output)
+		return nil
+
+	default:
+		return fmt.Errorf("unknown profiles command %q (use list or import)", args[0])
+	}
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/document/router"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// routeDocument prints a document that declares "printers", one sub-document
+// per destination. The model of each destination is its printer or group name.
+func routeDocument(config *Config, profiles *profile.Registry, doc *schema.Document) error {
+	if strings.ToLower(config.ConnectionType) == "file" {
+		return compileRoutesToFiles(config, profiles, doc)
+	}
+
+	groups, err := newGroupRegistry(config)
+	if err != nil {
+		return err
+	}
+
+	open := func(_ string, p schema.ProfileConfig) (*service.Printer, error) {
+		return openPrinter(groups, config, p.Model, createProfile(profiles, &schema.Document{Profile: p}))
+	}
+
+	r, err := router.New(open, router.Options{Buffered: config.Buffered || config.ChunkSize > 0})
+	if err != nil {
+		return err
+	}
+
+	result, err := r.Route(doc)
+	if result != nil {
+		for _, d := range result.Destinations {
+			switch {
+			case d.Error != "":
+				log.Printf("Destination %s (%s): %s", d.Destination, d.Printer, d.Error)
+			case d.Report != nil:
+				log.Printf("Destination %s (%s): %s", d.Destination, d.Printer, d.Report)
+			}
+		}
+	}
+	return err
+}
+
+// compileRoutesToFiles writes one .prn file per destination, named
+// <output>-<destination>.prn
+func compileRoutesToFiles(config *Config, profiles *profile.Registry, doc *schema.Document) error {
+	subs, err := doc.Split()
+	if err != nil {
+		return err
+	}
+
+	ext := filepath.Ext(config.OutputFile)
+	base := strings.TrimSuffix(config.OutputFile, ext)
+
+	for _, name := range doc.Destinations() {
+		sub, ok := subs[name]
+		if !ok {
+			continue
+		}
+
+		printer, err := service.NewPrinter(composer.NewEscpos(), createProfile(profiles, sub), connection.NewBufferConnector())
+		if err != nil {
+			return fmt.Errorf("%s: failed to create printer: %w", name, err)
+		}
+		job, err := executor.NewExecutor(printer).Compile(sub)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+
+		path := fmt.Sprintf("%s-%s%s", base, name, ext)
+		if err := os.WriteFile(path, job, 0o600); err != nil {
+			return fmt.Errorf("%s: failed to write output file: %w", name, err)
+		}
+		log.Printf("Compiled job for %s written to %s (%d bytes, sha256 %s)",
+			name, path, len(job), service.JobHash(job))
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/queue"
+	"github.com/adcondev/poster/pkg/server"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// ServeConfig holds the options of `poster serve`
+type ServeConfig struct {
+	Addr           string
+	Token          string
+	AllowedOrigins string
+	QueuePath      string
+	Printers       string
+	ConnectionType string
+	ConfigFile     string
+	ProfilesDir    string
+	Debug          bool
+}
+
+func parseServeArgs(args []string) (*ServeConfig, error) {
+	config := &ServeConfig{}
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+
+	fs.StringVar(&config.Addr, "addr", constants.DefaultServerAddr, "Listen address")
+	fs.StringVar(&config.Token, "token", os.Getenv("POSTER_TOKEN"), "Bearer token required by the API (default $POSTER_TOKEN)")
+	fs.StringVar(&config.AllowedOrigins, "cors", "", "Comma-separated browser origins allowed to call the API (* for any)")
+	fs.StringVar(&config.QueuePath, "queue", constants.DefaultQueuePath, "Job queue file")
+	fs.StringVar(&config.Printers, "printers", "", "Comma-separated printers or groups as name=profile (e.g., POS-80=ec-pm-80250)")
+	fs.StringVar(&config.ConnectionType, "type", win, "Connection type for printers: windows, network, serial")
+	fs.StringVar(&config.ConfigFile, "config", "", "Config file with printer groups")
+	fs.StringVar(&config.ProfilesDir, "profiles", "", "Directory with printer profile files (JSON/YAML)")
+	fs.BoolVar(&config.Debug, "debug", false, "Enable debug logging")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// parsePrinters parses "name=profile,name2=profile2"
+func parsePrinters(spec string) ([]server.PrinterConfig, error) {
+	var printers []server.PrinterConfig
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, prof, ok := strings.Cut(item, "=")
+		if !ok || name == "" || prof == "" {
+			return nil, fmt.Errorf("invalid printer %q, expected name=profile", item)
+		}
+		printers = append(printers, server.PrinterConfig{
+			Name:    strings.TrimSpace(name),
+			Profile: strings.ToLower(strings.TrimSpace(prof)),
+		})
+	}
+	return printers, nil
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+// runServe starts the local HTTP print server until interrupted
+func runServe(args []string) error {
+	config, err := parseServeArgs(args)
+	if err != nil {
+		return err
+	}
+
+	printers, err := parsePrinters(config.Printers)
+	if err != nil {
+		return err
+	}
+	if len(printers) == 0 {
+		name := detectPrinter()
+		if name == "" {
+			return fmt.Errorf("at least one printer is required, use -printers name=profile")
+		}
+		printers = []server.PrinterConfig{{Name: name, Profile: profile.Generic80mm}}
+	}
+
+	reg, err := loadProfiles(config.ProfilesDir)
+	if err != nil {
+		return err
+	}
+	printerProfiles := make(map[string]string, len(printers))
+	refs := make([]string, 0, len(printers))
+	for _, p := range printers {
+		printerProfiles[p.Name] = p.Profile
+		refs = append(refs, p.Profile)
+	}
+	profiles, err := serverProfiles(reg, refs...)
+	if err != nil {
+		return err
+	}
+
+	groups, err := newGroupRegistry(&Config{ConnectionType: config.ConnectionType, ConfigFile: config.ConfigFile})
+	if err != nil {
+		return err
+	}
+
+	open := func(name string) (*service.Printer, error) {
+		prof, ok := profiles[printerProfiles[name]]
+		if !ok {
+			return nil, fmt.Errorf("unknown printer %q", name)
+		}
+		return openPrinter(groups, &Config{}, name, prof.Clone())
+	}
+
+	q, err := queue.Open(config.QueuePath, queue.ExecutorDispatcher(open), queue.Options{
+		Retention: constants.DefaultQueueRetention,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open queue: %w", err)
+	}
+	defer func() {
+		if err := q.Close(); err != nil {
+			log.Printf("failed to close queue: %v", err)
+		}
+	}()
+
+	srv, err := server.New(server.Config{
+		Token:          config.Token,
+		AllowedOrigins: splitList(config.AllowedOrigins),
+		Printers:       printers,
+		Profiles:       profiles,
+		OpenPrinter:    open,
+	}, q)
+	if err != nil {
+		return err
+	}
+
+	if config.Token == "" {
+		log.Println("Warning: no -token configured, the API accepts unauthenticated requests")
+	}
+	if config.Debug {
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		log.Printf("Profiles: %s", strings.Join(names, ", "))
+		log.Printf("Printers: %+v", printers)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go q.Run(ctx, constants.DefaultQueuePollInterval)
+	go srv.Bridge().WatchStatus(ctx, constants.DefaultBridgeStatusInterval)
+
+	httpServer := &http.Server{
+		Addr:              config.Addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("🖨️  %s serving on http://%s", AppName, config.Addr)
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	log.Println("Shutting down...")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), constants.DefaultServerShutdownTimeout)
+	defer cancel()
+	return httpServer.Shutdown(shutdownCtx)
+}
@@ -132,5 +132,6 @@ func printDocument(doc *schema.Document, printerName string) error {
 	}(printer)
 
 	exec := executor.NewExecutor(printer)
-	return exec.Execute(doc)
+	_, err = exec.Execute(doc)
+	return err
 }
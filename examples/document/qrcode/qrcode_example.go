@@ -73,7 +73,7 @@ func main() {
 		log.Panicf("Failed to read JSON file: %v", err)
 	}
 
-	if err := exec.ExecuteJSON(jsonData); err != nil {
+	if _, err := exec.ExecuteJSON(jsonData); err != nil {
 		log.Panicf("Failed to execute document: %v", err)
 	}
 
@@ -101,7 +101,7 @@ func main() {
 
 	// Execute the document
 	fmt.Printf("Printing table example to %s...\n", printerName)
-	if err := exec.Execute(&doc); err != nil {
+	if _, err := exec.Execute(&doc); err != nil {
 		log.Panicf("Failed to print document: %v", err)
 	}
 
@@ -0,0 +1,292 @@
+package bidi
+
+import (
+	"strings"
+
+	xbidi "golang.org/x/text/unicode/bidi"
+)
+
+// Direction is the base direction of a paragraph
+type Direction int
+
+const (
+	// Auto takes the direction of the first strong character (rules P2, P3)
+	Auto Direction = iota
+	// LeftToRight forces a left-to-right paragraph
+	LeftToRight
+	// RightToLeft forces a right-to-left paragraph
+	RightToLeft
+)
+
+// mirrors maps characters that are not paired brackets to their mirrored
+// glyph (rule L4); brackets are mirrored with xbidi.ReverseString
+var mirrors = map[rune]rune{
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+	'‹': '›', '›': '‹',
+	'≤': '≥', '≥': '≤',
+}
+
+// class returns the bidirectional class of r
+func class(r rune) xbidi.Class {
+	p, _ := xbidi.LookupRune(r)
+	return p.Class()
+}
+
+// ParagraphDirection returns the direction of the first strong character of
+// text, LeftToRight when it has none
+func ParagraphDirection(text string) Direction {
+	for _, r := range text {
+		switch class(r) {
+		case xbidi.L:
+			return LeftToRight
+		case xbidi.R, xbidi.AL:
+			return RightToLeft
+		default:
+		}
+	}
+	return LeftToRight
+}
+
+// HasRTL reports whether text has right-to-left characters
+func HasRTL(text string) bool {
+	for _, r := range text {
+		if c := class(r); c == xbidi.R || c == xbidi.AL {
+			return true
+		}
+	}
+	return false
+}
+
+// Reorder returns text in visual order, line by line, so that a printer
+// that prints left to right shows Hebrew and Arabic correctly. Explicit
+// embedding and isolate controls are removed.
+func Reorder(text string, base Direction) string {
+	if !HasRTL(text) {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = string(reorderLine([]rune(line), base))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Visual shapes Arabic text with the presentation forms accepted by has
+// and reorders it with the direction of its first strong character
+func Visual(text string, has func(rune) bool) string {
+	return Reorder(Shape(text, has), Auto)
+}
+
+// reorderLine applies the implicit rules of the Unicode Bidirectional
+// Algorithm (UAX #9, W1–W7, N1–N2, I1–I2) and L1, L2 and L4 to one line
+func reorderLine(line []rune, base Direction) []rune {
+	runes := make([]rune, 0, len(line))
+	types := make([]xbidi.Class, 0, len(line))
+	for _, r := range line {
+		c := class(r)
+		switch c {
+		case xbidi.BN, xbidi.LRE, xbidi.RLE, xbidi.LRO, xbidi.RLO, xbidi.PDF,
+			xbidi.LRI, xbidi.RLI, xbidi.FSI, xbidi.PDI:
+			// X9: removed, printers cannot print them
+			continue
+		case xbidi.Control:
+			c = xbidi.ON
+		default:
+		}
+		runes = append(runes, r)
+		types = append(types, c)
+	}
+	n := len(runes)
+	original := append([]xbidi.Class(nil), types...)
+
+	if base == Auto {
+		base = ParagraphDirection(string(runes))
+	}
+	baseLevel := 0
+	sos := xbidi.L // Also eos and the embedding direction
+	if base == RightToLeft {
+		baseLevel, sos = 1, xbidi.R
+	}
+
+	// lastStrong returns the closest strong type before i
+	lastStrong := func(i int, strong ...xbidi.Class) xbidi.Class {
+		for j := i - 1; j >= 0; j-- {
+			for _, s := range strong {
+				if types[j] == s {
+					return s
+				}
+			}
+		}
+		return sos
+	}
+
+	// W1: NSM takes the type of the previous character
+	for i := range types {
+		if types[i] == xbidi.NSM {
+			types[i] = sos
+			if i > 0 {
+				types[i] = types[i-1]
+			}
+		}
+	}
+	// W2: EN after AL is AN
+	for i := range types {
+		if types[i] == xbidi.EN && lastStrong(i, xbidi.L, xbidi.R, xbidi.AL) == xbidi.AL {
+			types[i] = xbidi.AN
+		}
+	}
+	// W3: AL is R
+	for i := range types {
+		if types[i] == xbidi.AL {
+			types[i] = xbidi.R
+		}
+	}
+	// W4: a single separator between two numbers of the same type
+	for i := 1; i < n-1; i++ {
+		prev, next := types[i-1], types[i+1]
+		switch {
+		case types[i] == xbidi.ES && prev == xbidi.EN && next == xbidi.EN:
+			types[i] = xbidi.EN
+		case types[i] == xbidi.CS && prev == next && (prev == xbidi.EN || prev == xbidi.AN):
+			types[i] = prev
+		default:
+		}
+	}
+	// W5: terminators next to EN are EN
+	for i := 0; i < n; i++ {
+		if types[i] != xbidi.ET {
+			continue
+		}
+		end := i
+		for end < n && types[end] == xbidi.ET {
+			end++
+		}
+		if (i > 0 && types[i-1] == xbidi.EN) || (end < n && types[end] == xbidi.EN) {
+			for j := i; j < end; j++ {
+				types[j] = xbidi.EN
+			}
+		}
+		i = end - 1
+	}
+	// W6: remaining separators and terminators are neutral
+	for i := range types {
+		switch types[i] {
+		case xbidi.ES, xbidi.ET, xbidi.CS:
+			types[i] = xbidi.ON
+		default:
+		}
+	}
+	// W7: EN after L is L
+	for i := range types {
+		if types[i] == xbidi.EN && lastStrong(i, xbidi.L, xbidi.R) == xbidi.L {
+			types[i] = xbidi.L
+		}
+	}
+
+	// N1, N2: neutrals take the direction of the surrounding text when both
+	// sides agree (numbers count as R), the embedding direction otherwise
+	strongDir := func(c xbidi.Class) xbidi.Class {
+		if c == xbidi.EN || c == xbidi.AN {
+			return xbidi.R
+		}
+		return c
+	}
+	isNeutral := func(c xbidi.Class) bool {
+		return c == xbidi.B || c == xbidi.S || c == xbidi.WS || c == xbidi.ON
+	}
+	for i := 0; i < n; i++ {
+		if !isNeutral(types[i]) {
+			continue
+		}
+		end := i
+		for end < n && isNeutral(types[end]) {
+			end++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before = strongDir(types[i-1])
+		}
+		if end < n {
+			after = strongDir(types[end])
+		}
+		dir := sos
+		if before == after {
+			dir = before
+		}
+		for j := i; j < end; j++ {
+			types[j] = dir
+		}
+		i = end - 1
+	}
+
+	// I1, I2: resolve levels
+	levels := make([]int, n)
+	for i, c := range types {
+		levels[i] = baseLevel
+		switch {
+		case baseLevel == 0 && c == xbidi.R:
+			levels[i] = 1
+		case baseLevel == 0 && (c == xbidi.AN || c == xbidi.EN):
+			levels[i] = 2
+		case baseLevel == 1 && (c == xbidi.L || c == xbidi.AN || c == xbidi.EN):
+			levels[i] = 2
+		default:
+		}
+	}
+
+	// L1: separators and trailing whitespace take the paragraph level
+	trailing := true
+	for i := n - 1; i >= 0; i-- {
+		switch original[i] {
+		case xbidi.S, xbidi.B:
+			levels[i] = baseLevel
+			trailing = true
+		case xbidi.WS:
+			if trailing {
+				levels[i] = baseLevel
+			}
+		default:
+			trailing = false
+		}
+	}
+
+	// L2: reverse every sequence at or above each level, down to the lowest
+	// odd level
+	highest, lowestOdd := 0, 3
+	for _, l := range levels {
+		highest = max(highest, l)
+		if l%2 == 1 {
+			lowestOdd = min(lowestOdd, l)
+		}
+	}
+	for level := highest; level >= lowestOdd; level-- {
+		for i := 0; i < n; i++ {
+			if levels[i] < level {
+				continue
+			}
+			end := i
+			for end < n && levels[end] >= level {
+				end++
+			}
+			for a, b := i, end-1; a < b; a, b = a+1, b-1 {
+				runes[a], runes[b] = runes[b], runes[a]
+				levels[a], levels[b] = levels[b], levels[a]
+			}
+			i = end
+		}
+	}
+
+	// L4: mirror characters in right-to-left runs
+	for i, r := range runes {
+		if levels[i]%2 == 0 {
+			continue
+		}
+		if m, ok := mirrors[r]; ok {
+			runes[i] = m
+		} else if p, _ := xbidi.LookupRune(r); p.IsBracket() {
+			runes[i] = []rune(xbidi.ReverseString(string(r)))[0]
+		}
+	}
+	return runes
+}
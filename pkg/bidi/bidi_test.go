@@ -0,0 +1,93 @@
+package bidi_test
+
+import (
+	"testing"
+
+	"github.com/adcondev/poster/pkg/bidi"
+)
+
+// Naming Convention: Test{Function}_{Scenario}
+
+func TestReorder(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		base bidi.Direction
+		want string
+	}{
+		{"latin only", "Total 12.50", bidi.Auto, "Total 12.50"},
+		{"hebrew", "שלום עולם", bidi.Auto, "םלוע םולש"},
+		{"hebrew with number", "סה\"כ 125.50", bidi.Auto, "125.50 כ\"הס"},
+		{"number after hebrew inside latin", "Item שלום עולם 2", bidi.Auto, "Item 2 םלוע םולש"},
+		{"number between hebrew words", "abc שלום 123 עולם", bidi.Auto, "abc םלוע 123 םולש"},
+		{"latin inside hebrew", "מחיר USD 10", bidi.Auto, "USD 10 ריחמ"},
+		{"mirrored brackets", "שלום (א)", bidi.Auto, "(א) םולש"},
+		{"forced direction", "abc", bidi.RightToLeft, "abc"},
+		{"trailing spaces end the rtl line", "שלום  ", bidi.Auto, "  םולש"},
+		{"lines are reordered separately", "אב\nגד", bidi.Auto, "בא\nדג"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bidi.Reorder(tt.text, tt.base); got != tt.want {
+				t.Errorf("Reorder(%q) = %q; want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParagraphDirection(t *testing.T) {
+	tests := []struct {
+		text string
+		want bidi.Direction
+	}{
+		{"123 שלום", bidi.RightToLeft},
+		{"مرحبا", bidi.RightToLeft},
+		{"Hola שלום", bidi.LeftToRight},
+		{"123", bidi.LeftToRight},
+	}
+	for _, tt := range tests {
+		if got := bidi.ParagraphDirection(tt.text); got != tt.want {
+			t.Errorf("ParagraphDirection(%q) = %d; want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestShape(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		has  func(rune) bool
+		want string
+	}{
+		// beh: initial, medial, final
+		{"dual joining", "ببب", nil, "ﺑﺒﺐ"},
+		// alef joins only on the right: beh after it starts a new form
+		{"right joining", "باب", nil, "ﺑﺎﺏ"},
+		{"isolated letter", "ب ب", nil, "ﺏ ﺏ"},
+		{"lam alef", "لا", nil, "ﻻ"},
+		{"lam alef after a letter", "بلا", nil, "ﺑﻼ"},
+		{"harakat are transparent", "بَب", nil, "ﺑَﺐ"},
+		{"latin untouched", "abc", nil, "abc"},
+		// Medial rejected falls back to initial, then letters to themselves
+		{"partial forms", "ببب", func(r rune) bool { return r != 0xFE92 }, "ﺑﺑﺐ"},
+		{"no forms", "لا", func(r rune) bool { return r < 0xFE70 }, "لا"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bidi.Shape(tt.text, tt.has); got != tt.want {
+				t.Errorf("Shape(%q) = %+q; want %+q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVisual(t *testing.T) {
+	// "سلام 5": seen initial, lam-alef final, meem isolated, reversed
+	got := bidi.Visual("سلام 5", nil)
+	want := "5 ﻡﻼﺳ"
+	if got != want {
+		t.Errorf("Visual() = %+q; want %+q", got, want)
+	}
+}
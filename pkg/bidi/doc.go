@@ -0,0 +1,25 @@
+// Package bidi prepares Hebrew and Arabic text for printers that print
+// every line left to right in the order the bytes arrive.
+//
+// Text is stored in logical order, so a Hebrew or Arabic string sent as is
+// prints reversed, and Arabic letters print in their isolated form. Shape
+// replaces Arabic letters with their contextual presentation forms, which
+// code pages such as PC864 encode, and Reorder applies the Unicode
+// Bidirectional Algorithm to put each line in visual order. Visual does both.
+//
+// Only the implicit rules are implemented: explicit embeddings, overrides
+// and isolates are dropped, which is enough for receipts where each line is
+// a single paragraph mixing RTL words, numbers and Latin text.
+//
+// # Quick Start
+//
+//	line := bidi.Visual("المجموع: 125.50", func(r rune) bool {
+//		_, err := prof.EncodeString(string(r))
+//		return err == nil
+//	})
+//	printer.PrintLine(line)
+//
+// Lines must be wrapped before they are reordered: Reorder works line by
+// line and the first visual character of a right-to-left line is the last
+// one in logical order.
+package bidi
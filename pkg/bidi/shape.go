@@ -0,0 +1,162 @@
+package bidi
+
+import (
+	"unicode"
+)
+
+// Presentation form indexes
+const (
+	isolated = iota
+	final
+	initial
+	medial
+)
+
+// tatweel joins on both sides without changing shape
+const tatweel = 'ـ'
+
+// arabicForms maps Arabic letters to their isolated, final, initial and
+// medial presentation forms (Arabic Presentation Forms-B). Letters that only
+// join on the right have no initial or medial form.
+var arabicForms = map[rune][4]rune{
+	'ء': {0xFE80, 0, 0, 0},
+	'آ': {0xFE81, 0xFE82, 0, 0},
+	'أ': {0xFE83, 0xFE84, 0, 0},
+	'ؤ': {0xFE85, 0xFE86, 0, 0},
+	'إ': {0xFE87, 0xFE88, 0, 0},
+	'ئ': {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+	'ا': {0xFE8D, 0xFE8E, 0, 0},
+	'ب': {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+	'ة': {0xFE93, 0xFE94, 0, 0},
+	'ت': {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+	'ث': {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+	'ج': {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+	'ح': {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+	'خ': {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+	'د': {0xFEA9, 0xFEAA, 0, 0},
+	'ذ': {0xFEAB, 0xFEAC, 0, 0},
+	'ر': {0xFEAD, 0xFEAE, 0, 0},
+	'ز': {0xFEAF, 0xFEB0, 0, 0},
+	'س': {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+	'ش': {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+	'ص': {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+	'ض': {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+	'ط': {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+	'ظ': {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+	'ع': {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+	'غ': {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+	'ف': {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+	'ق': {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+	'ك': {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+	'ل': {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+	'م': {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+	'ن': {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+	'ه': {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+	'و': {0xFEED, 0xFEEE, 0, 0},
+	'ى': {0xFEEF, 0xFEF0, 0, 0},
+	'ي': {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+}
+
+// lamAlef maps the alef that follows a lam to the isolated and final forms
+// of their ligature
+var lamAlef = map[rune][2]rune{
+	'آ': {0xFEF5, 0xFEF6},
+	'أ': {0xFEF7, 0xFEF8},
+	'إ': {0xFEF9, 0xFEFA},
+	'ا': {0xFEFB, 0xFEFC},
+}
+
+// formFallbacks lists the forms tried for each form, in order
+var formFallbacks = [4][]int{
+	isolated: {isolated},
+	final:    {final, isolated},
+	initial:  {initial, isolated},
+	medial:   {medial, initial, isolated},
+}
+
+// joinsNext reports whether r connects to the following letter
+func joinsNext(r rune) bool {
+	return r == tatweel || arabicForms[r][initial] != 0
+}
+
+// joinsPrevious reports whether r connects to the preceding letter
+func joinsPrevious(r rune) bool {
+	return r == tatweel || arabicForms[r][final] != 0
+}
+
+// transparent reports whether r is skipped when joining, like harakat
+func transparent(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+// Shape replaces Arabic letters with their contextual presentation forms
+// (isolated, final, initial, medial) and lam-alef ligatures, in logical
+// order. Forms rejected by has fall back to a simpler form (medial to
+// initial, final to isolated) and then to the letter itself, so code pages
+// with a partial set of forms still print; nil accepts every form.
+func Shape(text string, has func(rune) bool) string {
+	runes := []rune(text)
+	if has == nil {
+		has = func(rune) bool { return true }
+	}
+
+	// neighbor returns the closest non-transparent rune in direction step
+	neighbor := func(i, step int) rune {
+		for j := i + step; j >= 0 && j < len(runes); j += step {
+			if !transparent(runes[j]) {
+				return runes[j]
+			}
+		}
+		return 0
+	}
+
+	out := make([]rune, 0, len(runes))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		forms, ok := arabicForms[r]
+		if !ok {
+			out = append(out, r)
+			continue
+		}
+		prev, next := joinsNext(neighbor(i, -1)), joinsPrevious(neighbor(i, 1))
+
+		// Lam followed by alef becomes a single ligature
+		if r == 'ل' && i+1 < len(runes) {
+			if lig, ok := lamAlef[runes[i+1]]; ok {
+				form := lig[isolated]
+				if prev {
+					form = lig[final]
+				}
+				if has(form) {
+					out = append(out, form)
+					i++
+					continue
+				}
+			}
+		}
+
+		form := isolated
+		switch {
+		case prev && next && forms[medial] != 0:
+			form = medial
+		case prev:
+			form = final
+		case next && forms[initial] != 0:
+			form = initial
+		default:
+		}
+		out = append(out, presentation(r, forms, form, has))
+	}
+	return string(out)
+}
+
+// presentation returns the first form accepted by has, falling back from
+// medial to initial, from final and initial to isolated, then to r
+func presentation(r rune, forms [4]rune, form int, has func(rune) bool) rune {
+	for _, f := range formFallbacks[form] {
+		if forms[f] != 0 && has(forms[f]) {
+			return forms[f]
+		}
+	}
+	return r
+}
@@ -0,0 +1,138 @@
+package cfdi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Regime is an entry of the SAT c_RegimenFiscal catalog
+type Regime struct {
+	Code        string
+	Description string
+	Physical    bool // Applies to personas físicas
+	Moral       bool // Applies to personas morales
+}
+
+// regimes is the c_RegimenFiscal catalog of CFDI 4.0
+var regimes = map[string]Regime{
+	"601": {"601", "General de Ley Personas Morales", false, true},
+	"603": {"603", "Personas Morales con Fines no Lucrativos", false, true},
+	"605": {"605", "Sueldos y Salarios e Ingresos Asimilados a Salarios", true, false},
+	"606": {"606", "Arrendamiento", true, false},
+	"607": {"607", "Régimen de Enajenación o Adquisición de Bienes", true, false},
+	"608": {"608", "Demás ingresos", true, false},
+	"610": {"610", "Residentes en el Extranjero sin Establecimiento Permanente en México", true, true},
+	"611": {"611", "Ingresos por Dividendos (socios y accionistas)", true, false},
+	"612": {"612", "Personas Físicas con Actividades Empresariales y Profesionales", true, false},
+	"614": {"614", "Ingresos por intereses", true, false},
+	"615": {"615", "Régimen de los ingresos por obtención de premios", true, false},
+	"616": {"616", "Sin obligaciones fiscales", true, false},
+	"620": {"620", "Sociedades Cooperativas de Producción que optan por diferir sus ingresos", false, true},
+	"621": {"621", "Incorporación Fiscal", true, false},
+	"622": {"622", "Actividades Agrícolas, Ganaderas, Silvícolas y Pesqueras", false, true},
+	"623": {"623", "Opcional para Grupos de Sociedades", false, true},
+	"624": {"624", "Coordinados", false, true},
+	"625": {"625", "Régimen de las Actividades Empresariales con ingresos a través de Plataformas Tecnológicas", true, false},
+	"626": {"626", "Régimen Simplificado de Confianza", true, true},
+}
+
+// cfdiUses is the c_UsoCFDI catalog of CFDI 4.0
+var cfdiUses = map[string]string{
+	"G01":  "Adquisición de mercancías",
+	"G02":  "Devoluciones, descuentos o bonificaciones",
+	"G03":  "Gastos en general",
+	"I01":  "Construcciones",
+	"I02":  "Mobiliario y equipo de oficina por inversiones",
+	"I03":  "Equipo de transporte",
+	"I04":  "Equipo de computo y accesorios",
+	"I05":  "Dados, troqueles, moldes, matrices y herramental",
+	"I06":  "Comunicaciones telefónicas",
+	"I07":  "Comunicaciones satelitales",
+	"I08":  "Otra maquinaria y equipo",
+	"D01":  "Honorarios médicos, dentales y gastos hospitalarios",
+	"D02":  "Gastos médicos por incapacidad o discapacidad",
+	"D03":  "Gastos funerales",
+	"D04":  "Donativos",
+	"D05":  "Intereses reales efectivamente pagados por créditos hipotecarios (casa habitación)",
+	"D06":  "Aportaciones voluntarias al SAR",
+	"D07":  "Primas por seguros de gastos médicos",
+	"D08":  "Gastos de transportación escolar obligatoria",
+	"D09":  "Depósitos en cuentas para el ahorro, primas que tengan como base planes de pensiones",
+	"D10":  "Pagos por servicios educativos (colegiaturas)",
+	"S01":  "Sin efectos fiscales",
+	"CP01": "Pagos",
+	"CN01": "Nómina",
+}
+
+// paymentForms is the c_FormaPago catalog
+var paymentForms = map[string]string{
+	"01": "Efectivo",
+	"02": "Cheque nominativo",
+	"03": "Transferencia electrónica de fondos",
+	"04": "Tarjeta de crédito",
+	"05": "Monedero electrónico",
+	"06": "Dinero electrónico",
+	"08": "Vales de despensa",
+	"12": "Dación en pago",
+	"13": "Pago por subrogación",
+	"14": "Pago por consignación",
+	"15": "Condonación",
+	"17": "Compensación",
+	"23": "Novación",
+	"24": "Confusión",
+	"25": "Remisión de deuda",
+	"26": "Prescripción o caducidad",
+	"27": "A satisfacción del acreedor",
+	"28": "Tarjeta de débito",
+	"29": "Tarjeta de servicios",
+	"30": "Aplicación de anticipos",
+	"31": "Intermediario pagos",
+	"99": "Por definir",
+}
+
+// paymentMethods is the c_MetodoPago catalog
+var paymentMethods = map[string]string{
+	"PUE": "Pago en una sola exhibición",
+	"PPD": "Pago en parcialidades o diferido",
+}
+
+// LookupRegime returns a fiscal regime by code, e.g. "601"
+func LookupRegime(code string) (Regime, error) {
+	r, ok := regimes[strings.TrimSpace(code)]
+	if !ok {
+		return Regime{}, fmt.Errorf("unknown régimen fiscal %q", code)
+	}
+	return r, nil
+}
+
+// Applies reports whether the regime can be used with an RFC of person
+func (r Regime) Applies(person Person) bool {
+	if person == Moral {
+		return r.Moral
+	}
+	return r.Physical
+}
+
+// CFDIUse returns the description of a uso CFDI code, e.g. "G03"
+func CFDIUse(code string) (string, error) {
+	return describe(cfdiUses, code, "uso CFDI")
+}
+
+// PaymentForm returns the description of a forma de pago code, e.g. "01"
+func PaymentForm(code string) (string, error) {
+	return describe(paymentForms, code, "forma de pago")
+}
+
+// PaymentMethod returns the description of a método de pago code ("PUE"
+// or "PPD")
+func PaymentMethod(code string) (string, error) {
+	return describe(paymentMethods, code, "método de pago")
+}
+
+func describe(catalog map[string]string, code, name string) (string, error) {
+	d, ok := catalog[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return "", fmt.Errorf("unknown %s %q", name, code)
+	}
+	return d, nil
+}
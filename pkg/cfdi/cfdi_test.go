@@ -0,0 +1,210 @@
+package cfdi_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/cfdi"
+	"github.com/adcondev/poster/pkg/document/builder"
+)
+
+// Naming Convention: Test{Function}_{Scenario}
+
+func TestParseRFC(t *testing.T) {
+	tests := []struct {
+		in      string
+		value   string
+		person  cfdi.Person
+		generic bool
+	}{
+		{"EKU9003173C9", "EKU9003173C9", cfdi.Moral, false},
+		{"iia040805dz4", "IIA040805DZ4", cfdi.Moral, false},
+		{"GODE-561231-GR8", "GODE561231GR8", cfdi.Physical, false},
+		{" CACX7605101P8 ", "CACX7605101P8", cfdi.Physical, false},
+		{"ÑAÑ850101AB5", "ÑAÑ850101AB5", cfdi.Moral, false},
+		{"XAXX010101000", cfdi.GenericRFC, cfdi.Physical, true},
+		{"XEXX010101000", cfdi.ForeignRFC, cfdi.Physical, true},
+	}
+
+	for _, tt := range tests {
+		got, err := cfdi.ParseRFC(tt.in)
+		if err != nil {
+			t.Errorf("ParseRFC(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got.Value != tt.value || got.Person != tt.person || got.Generic != tt.generic {
+			t.Errorf("ParseRFC(%q) = %+v; want %s %s generic=%v", tt.in, got, tt.value, tt.person, tt.generic)
+		}
+	}
+}
+
+func TestParseRFC_Errors(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"EKU9003173C8", "check digit 8, expected 9"},
+		{"EKU900317", "9 characters"},
+		{"EK19003173C9", "name part must be letters"},
+		{"EKU9013173C9", "invalid date 901317"},
+		{"EKU9002303C9", "invalid date 900230"},
+		{"EKU900317_C9", "invalid homoclave"},
+	}
+
+	for _, tt := range tests {
+		if _, err := cfdi.ParseRFC(tt.in); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseRFC(%q) error = %v; want %q", tt.in, err, tt.want)
+		}
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	for base, want := range map[string]rune{"EKU9003173C": '9', "GODE561231GR": '8', "AAA010101AA": '1'} {
+		if got, err := cfdi.CheckDigit(base); err != nil || got != want {
+			t.Errorf("CheckDigit(%q) = %c, %v; want %c", base, got, err, want)
+		}
+	}
+	if _, err := cfdi.CheckDigit("ABC"); err == nil {
+		t.Error("CheckDigit(\"ABC\") error = nil; want length error")
+	}
+}
+
+func TestCatalogs(t *testing.T) {
+	regime, err := cfdi.LookupRegime("601")
+	if err != nil || regime.Description != "General de Ley Personas Morales" || !regime.Applies(cfdi.Moral) || regime.Applies(cfdi.Physical) {
+		t.Errorf("LookupRegime(601) = %+v, %v", regime, err)
+	}
+	if r, _ := cfdi.LookupRegime("626"); !r.Applies(cfdi.Moral) || !r.Applies(cfdi.Physical) {
+		t.Errorf("LookupRegime(626) = %+v; want both persons", r)
+	}
+
+	for _, tt := range []struct {
+		fn   func(string) (string, error)
+		code string
+		want string
+	}{
+		{cfdi.CFDIUse, "g03", "Gastos en general"},
+		{cfdi.CFDIUse, "S01", "Sin efectos fiscales"},
+		{cfdi.PaymentForm, "01", "Efectivo"},
+		{cfdi.PaymentForm, "28", "Tarjeta de débito"},
+		{cfdi.PaymentMethod, "PUE", "Pago en una sola exhibición"},
+	} {
+		if got, err := tt.fn(tt.code); err != nil || got != tt.want {
+			t.Errorf("describe(%q) = %q, %v; want %q", tt.code, got, err, tt.want)
+		}
+	}
+
+	if _, err := cfdi.CFDIUse("Z99"); err == nil || !strings.Contains(err.Error(), `unknown uso CFDI "Z99"`) {
+		t.Errorf("CFDIUse(Z99) error = %v", err)
+	}
+	if _, err := cfdi.LookupRegime("600"); err == nil {
+		t.Error("LookupRegime(600) error = nil; want unknown")
+	}
+}
+
+func invoice() cfdi.Invoice {
+	return cfdi.Invoice{
+		UUID:           "5fb2822e-396d-4725-8521-cdc4bdd20ccf",
+		IssuerRFC:      "EKU9003173C9",
+		IssuerRegime:   "601",
+		ReceiverRFC:    "ÑAÑ850101AB5",
+		ReceiverRegime: "626",
+		CFDIUse:        "G03",
+		PaymentMethod:  "PUE",
+		PaymentForm:    "01",
+		Total:          "1234.500000",
+		Seal:           "MIIBIjANBgkqhkiG9w0BAQEFAAOCAQ8A+/3Gq1Ab==",
+	}
+}
+
+func TestInvoice_QRURL(t *testing.T) {
+	got, err := invoice().QRURL()
+	if err != nil {
+		t.Fatalf("QRURL error: %v", err)
+	}
+	want := "https://verificacfdi.facturacion.sat.gob.mx/default.aspx" +
+		"?id=5FB2822E-396D-4725-8521-CDC4BDD20CCF&re=EKU9003173C9&rr=%C3%91A%C3%91850101AB5&tt=1234.5&fe=3Gq1Ab%3D%3D"
+	if got != want {
+		t.Errorf("QRURL() =\n%s\nwant\n%s", got, want)
+	}
+
+	for total, tt := range map[string]string{"234": "tt=234.0&", "0.1234567": "tt=0.123457&", "007.10": "tt=7.1&"} {
+		inv := invoice()
+		inv.Total = total
+		if got, err := inv.QRURL(); err != nil || !strings.Contains(got, tt) {
+			t.Errorf("QRURL() with total %q = %s, %v; want %q", total, got, err, tt)
+		}
+	}
+}
+
+func TestInvoice_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*cfdi.Invoice)
+		want   string
+	}{
+		{"uuid", func(i *cfdi.Invoice) { i.UUID = "5FB2822E" }, "invalid folio fiscal"},
+		{"issuer", func(i *cfdi.Invoice) { i.IssuerRFC = "EKU9003173C8" }, "issuer: invalid RFC"},
+		{"regime person", func(i *cfdi.Invoice) { i.ReceiverRFC = "GODE561231GR8"; i.ReceiverRegime = "601" }, "régimen fiscal 601 does not apply to persona fisica"},
+		{"use", func(i *cfdi.Invoice) { i.CFDIUse = "X01" }, `unknown uso CFDI "X01"`},
+		{"total", func(i *cfdi.Invoice) { i.Total = "-1" }, "must be >= 0"},
+		{"seal", func(i *cfdi.Invoice) { i.Seal = "abc" }, "at least 8 characters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inv := invoice()
+			tt.change(&inv)
+			if err := inv.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Validate() error = %v; want %q", err, tt.want)
+			}
+		})
+	}
+
+	generic := invoice()
+	generic.ReceiverRFC, generic.ReceiverRegime = cfdi.GenericRFC, "616"
+	if err := generic.Validate(); err != nil {
+		t.Errorf("Validate() with the generic RFC error: %v", err)
+	}
+}
+
+func TestInvoice_WriteFooter(t *testing.T) {
+	inv := invoice()
+	inv.SATSeal = "SATSEAL"
+	inv.StampedAt = "2025-07-16T12:18:18"
+
+	b := builder.NewDocument().SetProfile("80mm EC-PM-80250", 80, "")
+	if err := inv.WriteFooter(b, 180); err != nil {
+		t.Fatalf("WriteFooter error: %v", err)
+	}
+	doc := b.Build()
+	data, err := json.Marshal(doc.Commands)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	out := string(data)
+	for _, s := range []string{
+		"representación impresa de un CFDI",
+		`"text":"5FB2822E-396D-4725-8521-CDC4BDD20CCF"`, `"label":{"text":"Folio fiscal"}`,
+		"601 General de Ley Personas Morales", "626 Régimen Simplificado de Confianza",
+		"G03 Gastos en general", "PUE Pago en una sola exhibición", "01 Efectivo",
+		"Fecha de certificación", "Sello digital del SAT:", `"font":"B"`,
+		`"data":"https://verificacfdi.facturacion.sat.gob.mx/default.aspx?id=`, `"pixel_width":180`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("footer commands do not contain %s", s)
+		}
+	}
+	if strings.Contains(out, "certificado del SAT") {
+		t.Error("footer contains the empty SAT certificate")
+	}
+	if last := doc.Commands[len(doc.Commands)-1].Type; last != "qr" {
+		t.Errorf("last command = %q; want qr", last)
+	}
+
+	inv.UUID = ""
+	if err := inv.WriteFooter(builder.NewDocument(), 0); err == nil {
+		t.Error("WriteFooter() error = nil; want invalid folio fiscal")
+	}
+}
@@ -0,0 +1,33 @@
+// Package cfdi helps print Mexican fiscal tickets (CFDI 4.0). Everything is
+// computed offline: nothing here calls the SAT.
+//
+// ParseRFC validates an RFC: length, name letters, registration date,
+// homoclave and check digit. LookupRegime, CFDIUse, PaymentForm and
+// PaymentMethod describe SAT catalog codes, and a Regime tells whether it
+// applies to personas físicas or morales.
+//
+// An Invoice holds the stamped data of a CFDI. QRURL builds the SAT
+// verification URL for its QR code and WriteFooter adds the standard fiscal
+// block to a document.
+//
+// # Quick Start
+//
+//	rfc, err := cfdi.ParseRFC("EKU9003173C9") // rfc.Person == cfdi.Moral
+//	use, _ := cfdi.CFDIUse("G03")            // "Gastos en general"
+//
+//	inv := cfdi.Invoice{
+//	    UUID:        "5FB2822E-396D-4725-8521-CDC4BDD20CCF",
+//	    IssuerRFC:   "EKU9003173C9",
+//	    ReceiverRFC: cfdi.GenericRFC,
+//	    Total:       "234.00",
+//	    Seal:        "...base64...",
+//	}
+//	url, _ := inv.QRURL() // https://verificacfdi.facturacion.sat.gob.mx/default.aspx?id=...&tt=234.0&fe=...
+//
+// With the receipt package, the fiscal block goes after the sale:
+//
+//	b := builder.NewDocument().SetProfile(layout.Model, layout.PaperWidth, "")
+//	_ = r.Write(b, layout)
+//	_ = inv.WriteFooter(b, 200)
+//	doc := b.Cut().Build()
+package cfdi
@@ -0,0 +1,65 @@
+package cfdi
+
+import (
+	"strings"
+
+	"github.com/adcondev/poster/pkg/document/builder"
+)
+
+// WriteFooter adds the fiscal block of a printed CFDI to b: the legend,
+// folio fiscal, regimes, uso CFDI, payment, certificates, stamp date, seals
+// and the verification QR. Empty fields are left out; qrSize is the QR
+// width in pixels, 0 for the builder default.
+func (inv Invoice) WriteFooter(b *builder.DocumentBuilder, qrSize int) error {
+	qr, err := inv.QRURL()
+	if err != nil {
+		return err
+	}
+
+	b.Text("Este documento es una representación impresa de un CFDI").Bold().Center().End()
+	field := func(label, value string) {
+		if value != "" {
+			b.Text(value).WithLabel(label).End()
+		}
+	}
+	// coded writes a catalog code with its description
+	coded := func(label, code string, describe func(string) (string, error)) {
+		if code == "" {
+			return
+		}
+		description, _ := describe(code)
+		field(label, strings.ToUpper(code)+" "+description)
+	}
+	regime := func(code string) (string, error) {
+		r, err := LookupRegime(code)
+		return r.Description, err
+	}
+
+	field("Folio fiscal", strings.ToUpper(strings.TrimSpace(inv.UUID)))
+	coded("Régimen fiscal", inv.IssuerRegime, regime)
+	coded("Régimen fiscal receptor", inv.ReceiverRegime, regime)
+	coded("Uso CFDI", inv.CFDIUse, CFDIUse)
+	coded("Método de pago", inv.PaymentMethod, PaymentMethod)
+	coded("Forma de pago", inv.PaymentForm, PaymentForm)
+	field("No. de serie del certificado del emisor", inv.CertificateNumber)
+	field("No. de serie del certificado del SAT", inv.SATCertificateNumber)
+	field("Fecha de certificación", inv.StampedAt)
+
+	for _, seal := range []struct{ label, value string }{
+		{"Sello digital del CFDI:", inv.Seal},
+		{"Sello digital del SAT:", inv.SATSeal},
+	} {
+		if seal.value == "" {
+			continue
+		}
+		b.Text(seal.label).Bold().End()
+		b.Text(strings.TrimSpace(seal.value)).Font("B").End()
+	}
+
+	q := b.QR(qr).Center()
+	if qrSize > 0 {
+		q.Size(qrSize)
+	}
+	q.End()
+	return nil
+}
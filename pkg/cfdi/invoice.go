@@ -0,0 +1,134 @@
+package cfdi
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/numfmt"
+)
+
+// VerificationURL is the SAT page that verifies a CFDI
+const VerificationURL = "https://verificacfdi.facturacion.sat.gob.mx/default.aspx"
+
+// uuidPattern matches a folio fiscal
+var uuidPattern = regexp.MustCompile(`^[0-9A-F]{8}-[0-9A-F]{4}-[0-9A-F]{4}-[0-9A-F]{4}-[0-9A-F]{12}$`)
+
+// Invoice holds the stamped CFDI data a printed ticket shows
+type Invoice struct {
+	UUID                 string // Folio fiscal
+	IssuerRFC            string
+	IssuerRegime         string // c_RegimenFiscal code
+	ReceiverRFC          string
+	ReceiverRegime       string // c_RegimenFiscal code
+	CFDIUse              string // c_UsoCFDI code
+	PaymentMethod        string // c_MetodoPago code, PUE or PPD
+	PaymentForm          string // c_FormaPago code
+	Total                string // Raw decimal, e.g. "234.00"
+	Seal                 string // Sello digital del CFDI
+	SATSeal              string // Sello digital del SAT
+	CertificateNumber    string // No. de serie del certificado del emisor
+	SATCertificateNumber string // No. de serie del certificado del SAT
+	StampedAt            string // Fecha de certificación, as printed
+}
+
+// Validate checks the folio fiscal, both RFCs, the catalog codes and that
+// each regime applies to its RFC. Empty optional codes are not checked.
+func (inv Invoice) Validate() error {
+	if !uuidPattern.MatchString(strings.ToUpper(strings.TrimSpace(inv.UUID))) {
+		return fmt.Errorf("invalid folio fiscal %q", inv.UUID)
+	}
+	issuer, err := ParseRFC(inv.IssuerRFC)
+	if err != nil {
+		return fmt.Errorf("issuer: %w", err)
+	}
+	receiver, err := ParseRFC(inv.ReceiverRFC)
+	if err != nil {
+		return fmt.Errorf("receiver: %w", err)
+	}
+	if err := checkRegime(inv.IssuerRegime, issuer); err != nil {
+		return fmt.Errorf("issuer: %w", err)
+	}
+	if err := checkRegime(inv.ReceiverRegime, receiver); err != nil {
+		return fmt.Errorf("receiver: %w", err)
+	}
+	for _, check := range []struct {
+		code string
+		fn   func(string) (string, error)
+	}{{inv.CFDIUse, CFDIUse}, {inv.PaymentMethod, PaymentMethod}, {inv.PaymentForm, PaymentForm}} {
+		if check.code == "" {
+			continue
+		}
+		if _, err := check.fn(check.code); err != nil {
+			return err
+		}
+	}
+	if _, err := verificationTotal(inv.Total); err != nil {
+		return err
+	}
+	if len(strings.TrimSpace(inv.Seal)) < 8 {
+		return fmt.Errorf("seal must have at least 8 characters")
+	}
+	return nil
+}
+
+// QRURL builds the SAT verification URL printed as the CFDI QR code: the
+// folio fiscal (id), issuer (re) and receiver (rr) RFCs, total (tt) and
+// the last 8 characters of the seal (fe)
+func (inv Invoice) QRURL() (string, error) {
+	if err := inv.Validate(); err != nil {
+		return "", err
+	}
+	issuer, _ := ParseRFC(inv.IssuerRFC)
+	receiver, _ := ParseRFC(inv.ReceiverRFC)
+	total, _ := verificationTotal(inv.Total)
+	seal := strings.TrimSpace(inv.Seal)
+
+	query := []string{
+		"id=" + strings.ToUpper(strings.TrimSpace(inv.UUID)),
+		"re=" + url.QueryEscape(issuer.Value),
+		"rr=" + url.QueryEscape(receiver.Value),
+		"tt=" + total,
+		"fe=" + url.QueryEscape(seal[len(seal)-8:]),
+	}
+	return VerificationURL + "?" + strings.Join(query, "&"), nil
+}
+
+// verificationTotal writes a total as the verification URL expects: up to
+// 18 integer digits and 6 decimals, without non-significant zeros but with
+// at least one decimal ("234.0")
+func verificationTotal(total string) (string, error) {
+	n, _, err := numfmt.Parse(total)
+	if err != nil {
+		return "", fmt.Errorf("invalid total: %w", err)
+	}
+	if n.Sign() < 0 {
+		return "", fmt.Errorf("invalid total %q (must be >= 0)", total)
+	}
+	s := n.FloatString(6)
+	if whole, _, _ := strings.Cut(s, "."); len(whole) > 18 {
+		return "", fmt.Errorf("invalid total %q (more than 18 integer digits)", total)
+	}
+	s = strings.TrimRight(s, "0")
+	if strings.HasSuffix(s, ".") {
+		s += "0"
+	}
+	return s, nil
+}
+
+// checkRegime checks that a regime code exists and applies to rfc; an empty
+// code is not checked
+func checkRegime(code string, rfc RFC) error {
+	if code == "" {
+		return nil
+	}
+	regime, err := LookupRegime(code)
+	if err != nil {
+		return err
+	}
+	if !rfc.Generic && !regime.Applies(rfc.Person) {
+		return fmt.Errorf("régimen fiscal %s does not apply to persona %s RFC %s", regime.Code, rfc.Person, rfc.Value)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package cfdi
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Person is the kind of taxpayer an RFC belongs to
+type Person string
+
+// Persons
+const (
+	Physical Person = "fisica" // Persona física, 13 characters
+	Moral    Person = "moral"  // Persona moral, 12 characters
+)
+
+// Generic RFCs, which have no check digit
+const (
+	GenericRFC = "XAXX010101000" // Público en general
+	ForeignRFC = "XEXX010101000" // Residente en el extranjero
+)
+
+// rfcChars gives each RFC character its value for the check digit
+const rfcChars = "0123456789ABCDEFGHIJKLMN&OPQRSTUVWXYZ Ñ"
+
+// RFC is a valid Registro Federal de Contribuyentes
+type RFC struct {
+	Value   string // Uppercase, without separators
+	Person  Person
+	Generic bool // GenericRFC or ForeignRFC
+}
+
+// String returns the RFC value
+func (r RFC) String() string {
+	return r.Value
+}
+
+// ParseRFC validates an RFC: its length, name letters, date of
+// registration, homoclave and check digit. Spaces and hyphens are dropped
+// and letters are uppercased.
+func ParseRFC(s string) (RFC, error) {
+	value := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(s)))
+	if value == GenericRFC || value == ForeignRFC {
+		return RFC{Value: value, Person: Physical, Generic: true}, nil
+	}
+
+	runes := []rune(value)
+	var letters int
+	var person Person
+	switch len(runes) {
+	case 12:
+		letters, person = 3, Moral
+	case 13:
+		letters, person = 4, Physical
+	default:
+		return RFC{}, fmt.Errorf("invalid RFC %q: %d characters (use 12 or 13)", s, len(runes))
+	}
+
+	for _, r := range runes[:letters] {
+		if !(r >= 'A' && r <= 'Z') && r != 'Ñ' && r != '&' {
+			return RFC{}, fmt.Errorf("invalid RFC %q: name part must be letters", s)
+		}
+	}
+	date := string(runes[letters : letters+6])
+	if !validDate(date) {
+		return RFC{}, fmt.Errorf("invalid RFC %q: invalid date %s", s, date)
+	}
+	for _, r := range runes[letters+6 : letters+8] {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return RFC{}, fmt.Errorf("invalid RFC %q: invalid homoclave", s)
+		}
+	}
+
+	want, err := CheckDigit(string(runes[:len(runes)-1]))
+	if err != nil {
+		return RFC{}, fmt.Errorf("invalid RFC %q: %w", s, err)
+	}
+	if got := runes[len(runes)-1]; got != want {
+		return RFC{}, fmt.Errorf("invalid RFC %q: check digit %c, expected %c", s, got, want)
+	}
+	return RFC{Value: value, Person: person}, nil
+}
+
+// CheckDigit computes the check digit of an RFC without it: 11 characters
+// for personas morales or 12 for personas físicas
+func CheckDigit(base string) (rune, error) {
+	runes := []rune(base)
+	switch len(runes) {
+	case 11:
+		runes = append([]rune{' '}, runes...)
+	case 12:
+	default:
+		return 0, fmt.Errorf("check digit needs 11 or 12 characters, got %d", len(runes))
+	}
+
+	sum := 0
+	for i, r := range runes {
+		// Every character before Ñ is one byte, so byte index is value
+		value := strings.IndexRune(rfcChars, r)
+		if value < 0 {
+			return 0, fmt.Errorf("invalid character %q", r)
+		}
+		sum += value * (13 - i)
+	}
+
+	switch digit := 11 - sum%11; digit {
+	case 11:
+		return '0', nil
+	case 10:
+		return 'A', nil
+	default:
+		return rune('0' + digit), nil
+	}
+}
+
+// validDate reports whether s is a YYMMDD date of either century
+func validDate(s string) bool {
+	for _, century := range []string{"19", "20"} {
+		if _, err := time.Parse("20060102", century+s); err == nil {
+			return true
+		}
+	}
+	return false
+}
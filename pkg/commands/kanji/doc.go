@@ -0,0 +1,7 @@
+// Package kanji implements ESC/POS commands for double-byte character modes.
+//
+// ESC/POS is the command system used by thermal receipt printers to switch
+// between single-byte text and the double-byte character sets of Asian
+// models (Kanji/Shift-JIS, GB18030, Big5, KS C 5601), and to style the
+// double-byte characters independently of the single-byte ones.
+package kanji
@@ -0,0 +1,99 @@
+package kanji
+
+import (
+	"fmt"
+)
+
+// ============================================================================
+// Context
+// ============================================================================
+// This package implements ESC/POS commands for double-byte character modes.
+// In Kanji mode a byte in the double-byte range starts a two byte character
+// of the character set of the model (JIS/Shift-JIS on Japanese models,
+// GB18030 on Chinese models, Big5 on Traditional Chinese models and
+// KS C 5601 on Korean models). Single-byte text keeps using ESC t.
+
+// ============================================================================
+// Constant and Var Definitions
+// ============================================================================
+
+// PrintMode represents the print mode bits of FS !
+type PrintMode byte
+
+const (
+	// DoubleWidth doubles the width of Kanji characters
+	DoubleWidth PrintMode = 0x04
+	// DoubleHeight doubles the height of Kanji characters
+	DoubleHeight PrintMode = 0x08
+	// Underline underlines Kanji characters
+	Underline PrintMode = 0x80
+)
+
+// CodeSystem represents the Kanji character code system of Japanese models
+type CodeSystem byte
+
+const (
+	// JIS represents the JIS code system
+	JIS CodeSystem = 0
+	// ShiftJIS represents the Shift JIS code system
+	ShiftJIS CodeSystem = 1
+	// ShiftJIS2004 represents the Shift JIS-2004 code system
+	ShiftJIS2004 CodeSystem = 2
+	// JISASCII represents the JIS code system (ASCII form)
+	JISASCII CodeSystem = '0'
+	// ShiftJISASCII represents the Shift JIS code system (ASCII form)
+	ShiftJISASCII CodeSystem = '1'
+	// ShiftJIS2004ASCII represents the Shift JIS-2004 code system (ASCII form)
+	ShiftJIS2004ASCII CodeSystem = '2'
+)
+
+// ============================================================================
+// Error Definitions
+// ============================================================================
+
+var (
+	// ErrCodeSystem represents an invalid Kanji code system
+	ErrCodeSystem = fmt.Errorf("invalid kanji code system(0-2 or '0'-'2')")
+)
+
+// ============================================================================
+// Interface Definitions
+// ============================================================================
+
+// Interface compliance check
+var _ Capability = (*Commands)(nil)
+
+// Capability defines the interface for Kanji commands
+type Capability interface {
+	SelectPrintModes(mode PrintMode) []byte
+	SelectKanjiMode() []byte
+	CancelKanjiMode() []byte
+	SelectCodeSystem(system CodeSystem) ([]byte, error)
+	SetCharacterSpacing(left, right byte) []byte
+}
+
+// ============================================================================
+// Main Implementation
+// ============================================================================
+
+// Commands implements the Capability interface for ESC/POS printers
+type Commands struct{}
+
+// NewCommands creates a new instance of Kanji Commands
+func NewCommands() *Commands {
+	return &Commands{}
+}
+
+// ============================================================================
+// Validation Functions
+// ============================================================================
+
+// ValidateCodeSystem validates if the Kanji code system is valid
+func ValidateCodeSystem(system CodeSystem) error {
+	switch system {
+	case JIS, ShiftJIS, ShiftJIS2004, JISASCII, ShiftJISASCII, ShiftJIS2004ASCII:
+		return nil
+	default:
+		return ErrCodeSystem
+	}
+}
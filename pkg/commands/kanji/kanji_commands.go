@@ -0,0 +1,176 @@
+package kanji
+
+import (
+	"github.com/adcondev/poster/pkg/commands/shared"
+)
+
+// SelectPrintModes selects the print modes of Kanji characters.
+//
+// Format:
+//
+//	ASCII:   FS ! n
+//	Hex:     0x1C 0x21 n
+//	Decimal: 28 33 n
+//
+// Range:
+//
+//	n = 0–255
+//
+// Default:
+//
+//	n = 0
+//
+// Parameters:
+//
+//	n: Bit flags:
+//	   bit 2 (0x04) -> Double-width
+//	   bit 3 (0x08) -> Double-height
+//	   bit 7 (0x80) -> Underline
+//
+// Notes:
+//   - Other bits are ignored
+//   - GS ! overrides the size bits while it is set
+//   - Settings persist until ESC @, printer reset, or power off
+//
+// Errors:
+//
+//	This function is safe and does not return errors.
+func (c *Commands) SelectPrintModes(mode PrintMode) []byte {
+	return []byte{shared.FS, '!', byte(mode)}
+}
+
+// SelectKanjiMode selects Kanji character mode.
+//
+// Format:
+//
+//	ASCII:   FS &
+//	Hex:     0x1C 0x26
+//	Decimal: 28 38
+//
+// Range:
+//
+//	Not applicable
+//
+// Default:
+//
+//	Kanji mode is off on models with code tables; on Japanese and Chinese
+//	models it may be on by default
+//
+// Parameters:
+//
+//	None
+//
+// Notes:
+//   - Bytes in the double-byte range are processed as two byte characters
+//     of the character set of the model
+//   - ASCII bytes keep printing as single-byte characters
+//   - Ignored while UTF-8 is selected with FS ( C
+//
+// Errors:
+//
+//	This function is safe and does not return errors.
+func (c *Commands) SelectKanjiMode() []byte {
+	return []byte{shared.FS, '&'}
+}
+
+// CancelKanjiMode cancels Kanji character mode.
+//
+// Format:
+//
+//	ASCII:   FS .
+//	Hex:     0x1C 0x2E
+//	Decimal: 28 46
+//
+// Range:
+//
+//	Not applicable
+//
+// Default:
+//
+//	Not applicable
+//
+// Parameters:
+//
+//	None
+//
+// Notes:
+//   - Every byte is processed as a single-byte character of the code
+//     table selected with ESC t
+//
+// Errors:
+//
+//	This function is safe and does not return errors.
+func (c *Commands) CancelKanjiMode() []byte {
+	return []byte{shared.FS, '.'}
+}
+
+// SelectCodeSystem selects the Kanji character code system.
+//
+// Format:
+//
+//	ASCII:   FS C n
+//	Hex:     0x1C 0x43 n
+//	Decimal: 28 67 n
+//
+// Range:
+//
+//	n = 0–2, 48–50
+//
+// Default:
+//
+//	Model-dependent (JIS on most Japanese models)
+//
+// Parameters:
+//
+//	n: Code system:
+//	   0 or 48 -> JIS
+//	   1 or 49 -> Shift JIS
+//	   2 or 50 -> Shift JIS-2004
+//
+// Notes:
+//   - Only available on Japanese models
+//   - Settings persist until ESC @, printer reset, or power off
+//
+// Errors:
+//
+//	Returns ErrCodeSystem if n is not a valid code system.
+func (c *Commands) SelectCodeSystem(system CodeSystem) ([]byte, error) {
+	if err := ValidateCodeSystem(system); err != nil {
+		return nil, err
+	}
+	return []byte{shared.FS, 'C', byte(system)}, nil
+}
+
+// SetCharacterSpacing sets the left- and right-side spacing of Kanji characters.
+//
+// Format:
+//
+//	ASCII:   FS S n1 n2
+//	Hex:     0x1C 0x53 n1 n2
+//	Decimal: 28 83 n1 n2
+//
+// Range:
+//
+//	n1 = 0–255
+//	n2 = 0–255
+//
+// Default:
+//
+//	n1 = 0, n2 = 0
+//
+// Parameters:
+//
+//	n1: Left-side spacing in horizontal motion units
+//	n2: Right-side spacing in horizontal motion units
+//
+// Notes:
+//   - The maximum spacing is model-dependent; larger values use the maximum
+//   - Spacing is doubled with double-width characters
+//   - Settings persist until ESC @, printer reset, or power off
+//
+// Errors:
+//
+//	This function is safe and does not return errors.
+func (c *Commands) SetCharacterSpacing(left, right byte) []byte {
+	return []byte{shared.FS, 'S', left, right}
+}
@@ -0,0 +1,81 @@
+package kanji_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/commands/kanji"
+	"github.com/adcondev/poster/pkg/commands/shared"
+)
+
+// Naming Convention: Test{Struct}_{Method}_{Scenario}
+
+func TestKanjiCommands_SelectPrintModes(t *testing.T) {
+	kc := kanji.NewCommands()
+	tests := []struct {
+		name string
+		mode kanji.PrintMode
+		want []byte
+	}{
+		{"normal", 0, []byte{shared.FS, '!', 0}},
+		{"double width and height", kanji.DoubleWidth | kanji.DoubleHeight, []byte{shared.FS, '!', 0x0C}},
+		{"underline", kanji.Underline, []byte{shared.FS, '!', 0x80}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := kc.SelectPrintModes(tt.mode)
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("SelectPrintModes(%#x) = %#v; want %#v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKanjiCommands_KanjiMode(t *testing.T) {
+	kc := kanji.NewCommands()
+
+	if got := kc.SelectKanjiMode(); !bytes.Equal(got, []byte{shared.FS, '&'}) {
+		t.Errorf("SelectKanjiMode() = %#v; want FS &", got)
+	}
+	if got := kc.CancelKanjiMode(); !bytes.Equal(got, []byte{shared.FS, '.'}) {
+		t.Errorf("CancelKanjiMode() = %#v; want FS .", got)
+	}
+}
+
+func TestKanjiCommands_SelectCodeSystem(t *testing.T) {
+	kc := kanji.NewCommands()
+	tests := []struct {
+		name    string
+		system  kanji.CodeSystem
+		want    []byte
+		wantErr error
+	}{
+		{"JIS", kanji.JIS, []byte{shared.FS, 'C', 0}, nil},
+		{"Shift JIS", kanji.ShiftJIS, []byte{shared.FS, 'C', 1}, nil},
+		{"Shift JIS-2004 ASCII form", kanji.ShiftJIS2004ASCII, []byte{shared.FS, 'C', '2'}, nil},
+		{"invalid", 3, nil, kanji.ErrCodeSystem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := kc.SelectCodeSystem(tt.system)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SelectCodeSystem(%d) error = %v; want %v", tt.system, err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("SelectCodeSystem(%d) = %#v; want %#v", tt.system, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKanjiCommands_SetCharacterSpacing(t *testing.T) {
+	kc := kanji.NewCommands()
+	got := kc.SetCharacterSpacing(2, 4)
+	want := []byte{shared.FS, 'S', 2, 4}
+	if !bytes.Equal(got, want) {
+		t.Errorf("SetCharacterSpacing(2, 4) = %#v; want %#v", got, want)
+	}
+}
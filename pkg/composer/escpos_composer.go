@@ -9,6 +9,7 @@ import (
 	"github.com/adcondev/poster/pkg/commands/barcode"
 	"github.com/adcondev/poster/pkg/commands/bitimage"
 	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/commands/kanji"
 	"github.com/adcondev/poster/pkg/commands/linespacing"
 	"github.com/adcondev/poster/pkg/commands/mechanismcontrol"
 	"github.com/adcondev/poster/pkg/commands/print"
@@ -23,6 +24,9 @@ type EscposProtocol struct {
 	Barcode          barcode.Capability
 	BitImage         bitimage.Capability
 	Character        character.Capability
+	CodeConversion   character.CodeConversionCapability
+	UserDefined      character.UserDefinedCapability
+	Kanji            kanji.Capability
 	LineSpacing      linespacing.Capability
 	MechanismControl mechanismcontrol.Capability
 	Print            print.Capability
@@ -34,7 +38,6 @@ type EscposProtocol struct {
 	// PanelButton      panelbutton.Capability
 	// Status           status.Capability
 	// MacroFunctions   macrofunctions.Capability
-	// Kanji 		    kanji.Capability
 	// Miscellaneous 	miscellaneous.Capability
 	// Customize 	    customize.Capability
 	// CounterPrinting  counterprinting.Capability
@@ -52,6 +55,9 @@ func NewEscpos() *EscposProtocol {
 		Barcode:          barcode.NewCommands(),
 		BitImage:         bitimage.NewCommands(),
 		Character:        character.NewCommands(),
+		CodeConversion:   character.NewCodeConversionCommands(),
+		UserDefined:      &character.UserDefinedCommands{},
+		Kanji:            kanji.NewCommands(),
 		LineSpacing:      linespacing.NewCommands(),
 		MechanismControl: mechanismcontrol.NewCommands(),
 		Print:            print.NewCommands(),
@@ -0,0 +1,50 @@
+package connection
+
+import (
+	"bytes"
+	"errors"
+)
+
+var _ Connector = (*BufferConnector)(nil)
+
+// BufferConnector accumulates written data in memory instead of sending it to a device.
+// It is used to compile complete print jobs before transmitting them.
+type BufferConnector struct {
+	buf    bytes.Buffer
+	closed bool
+}
+
+// NewBufferConnector creates an empty in-memory connector.
+func NewBufferConnector() *BufferConnector {
+	return &BufferConnector{}
+}
+
+// Write appends data to the buffer.
+func (c *BufferConnector) Write(data []byte) (int, error) {
+	if c.closed {
+		return 0, errors.New("buffer connector is closed")
+	}
+	return c.buf.Write(data)
+}
+
+// Close marks the connector as closed; the buffered data remains readable.
+func (c *BufferConnector) Close() error {
+	c.closed = true
+	return nil
+}
+
+// Bytes returns a copy of the buffered data.
+func (c *BufferConnector) Bytes() []byte {
+	return bytes.Clone(c.buf.Bytes())
+}
+
+// Len returns the number of buffered bytes.
+func (c *BufferConnector) Len() int {
+	return c.buf.Len()
+}
+
+// Reset discards the buffered data and reopens the connector.
+func (c *BufferConnector) Reset() {
+	c.buf.Reset()
+	c.closed = false
+}
@@ -0,0 +1,46 @@
+package connection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferConnector_WriteAndBytes(t *testing.T) {
+	conn := NewBufferConnector()
+
+	n, err := conn.Write([]byte{0x1B, 0x40})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = conn.Write([]byte("Hello"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, 7, conn.Len())
+	assert.Equal(t, append([]byte{0x1B, 0x40}, "Hello"...), conn.Bytes())
+}
+
+func TestBufferConnector_BytesReturnsCopy(t *testing.T) {
+	conn := NewBufferConnector()
+	_, _ = conn.Write([]byte("abc"))
+
+	data := conn.Bytes()
+	data[0] = 'x'
+
+	assert.Equal(t, []byte("abc"), conn.Bytes())
+}
+
+func TestBufferConnector_WriteAfterClose(t *testing.T) {
+	conn := NewBufferConnector()
+	_, _ = conn.Write([]byte("abc"))
+	assert.NoError(t, conn.Close())
+
+	_, err := conn.Write([]byte("def"))
+	assert.Error(t, err)
+	assert.Equal(t, []byte("abc"), conn.Bytes())
+
+	conn.Reset()
+	assert.Equal(t, 0, conn.Len())
+	_, err = conn.Write([]byte("def"))
+	assert.NoError(t, err)
+}
@@ -7,7 +7,14 @@ type Connector interface {
 	io.WriteCloser // Write([]byte) (int, error) y Close() error
 
 	// TODO: Agregar más métodos si necesitas:
-	// - Read([]byte) (int, error) para status
 	// - IsConnected() bool
 	// - Reset() error
 }
+
+// StatusReader is implemented by bidirectional connectors that return the
+// printer's answers to real-time status requests (DLE EOT). It is separate
+// from io.Reader because the Windows spooler connector exposes a Read that
+// always fails.
+type StatusReader interface {
+	ReadStatus(buf []byte) (int, error)
+}
@@ -0,0 +1,73 @@
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/adcondev/poster/pkg/constants"
+)
+
+var (
+	_ Connector    = (*NetworkConnector)(nil)
+	_ StatusReader = (*NetworkConnector)(nil)
+)
+
+// NetworkConnector sends raw ESC/POS data to a printer over TCP (usually port
+// 9100). The connection is bidirectional, so it also reads the answers to
+// real-time status requests.
+type NetworkConnector struct {
+	addr    string
+	conn    net.Conn
+	timeout time.Duration
+}
+
+// NewNetworkConnector dials the printer at addr. The raw printing port is used
+// when addr has no port.
+func NewNetworkConnector(addr string) (*NetworkConnector, error) {
+	if addr == "" {
+		return nil, errors.New("network address cannot be empty")
+	}
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, constants.DefaultNetworkPort)
+	}
+
+	timeout := constants.DefaultNetworkTimeout
+	conn, err := net.DialTimeout("tcp", addr, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to printer '%s': %w", addr, err)
+	}
+
+	return &NetworkConnector{
+		addr:    addr,
+		conn:    conn,
+		timeout: timeout,
+	}, nil
+}
+
+// Addr returns the address of the printer, including the port.
+func (c *NetworkConnector) Addr() string {
+	return c.addr
+}
+
+// Write sends data to the printer.
+func (c *NetworkConnector) Write(data []byte) (int, error) {
+	if err := c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.conn.Write(data)
+}
+
+// ReadStatus reads the printer's answer to a status request.
+func (c *NetworkConnector) ReadStatus(buf []byte) (int, error) {
+	if err := c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return 0, err
+	}
+	return c.conn.Read(buf)
+}
+
+// Close closes the connection.
+func (c *NetworkConnector) Close() error {
+	return c.conn.Close()
+}
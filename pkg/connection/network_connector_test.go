@@ -0,0 +1,98 @@
+package connection
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeNetworkPrinter accepts one connection, records what it receives and
+// answers every DLE EOT request with answer
+func fakeNetworkPrinter(t *testing.T, answer byte) (string, <-chan []byte) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var data []byte
+		buf := make([]byte, 64)
+		for {
+			n, err := conn.Read(buf)
+			data = append(data, buf[:n]...)
+			for i := 0; i+1 < n; i++ {
+				if buf[i] == 0x10 && buf[i+1] == 0x04 {
+					_, _ = conn.Write([]byte{answer})
+				}
+			}
+			if err != nil {
+				received <- data
+				return
+			}
+		}
+	}()
+	return ln.Addr().String(), received
+}
+
+func TestNetworkConnector_WriteAndReadStatus(t *testing.T) {
+	addr, received := fakeNetworkPrinter(t, 0x12)
+
+	conn, err := NewNetworkConnector(addr)
+	require.NoError(t, err)
+	assert.Equal(t, addr, conn.Addr())
+
+	_, err = conn.Write([]byte{0x1B, 0x40})
+	require.NoError(t, err)
+	_, err = conn.Write([]byte{0x10, 0x04, 0x01})
+	require.NoError(t, err)
+
+	buf := make([]byte, 1)
+	n, err := conn.ReadStatus(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, byte(0x12), buf[0])
+
+	require.NoError(t, conn.Close())
+	assert.Equal(t, []byte{0x1B, 0x40, 0x10, 0x04, 0x01}, <-received)
+}
+
+func TestNetworkConnector_ReadStatusAfterPrinterCloses(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		if conn, err := ln.Accept(); err == nil {
+			_ = conn.Close()
+		}
+	}()
+
+	conn, err := NewNetworkConnector(ln.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = conn.ReadStatus(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestNewNetworkConnector_Errors(t *testing.T) {
+	_, err := NewNetworkConnector("")
+	assert.Error(t, err)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	_, err = NewNetworkConnector(addr)
+	assert.Error(t, err)
+}
@@ -199,6 +199,32 @@ const (
 	FontBHeight = 17
 )
 
+// FallbackFontEnv names the environment variable the poster command reads
+// for the TTF/OTF font used for text the printer fonts cannot print, on
+// profiles without one
+const FallbackFontEnv = "POSTER_FALLBACK_FONT"
+
+// ============================================================================
+// Text Render Constants
+// ============================================================================
+
+// Ensure Render implements fmt.Stringer
+var _ fmt.Stringer = Render("")
+
+// Render options for printing text
+type Render string
+
+func (r Render) String() string {
+	return string(r)
+}
+
+const (
+	// RenderText prints text with the printer fonts
+	RenderText Render = "text"
+	// RenderImage rasterizes text with the fallback font
+	RenderImage Render = "image"
+)
+
 // ============================================================================
 // Cut Constants
 // ============================================================================
@@ -425,3 +451,26 @@ const (
 	// TopMarginMultiplier is used to calculate initial top margin
 	TopMarginMultiplier = 2
 )
+
+// ============================================================================
+// Error Policy Constants
+// ============================================================================
+
+// Ensure ErrorPolicy implements fmt.Stringer
+var _ fmt.Stringer = ErrorPolicy("")
+
+// ErrorPolicy options for handling command failures during execution
+type ErrorPolicy string
+
+func (p ErrorPolicy) String() string {
+	return string(p)
+}
+
+const (
+	// Abort stops the job at the first failing command
+	Abort ErrorPolicy = "abort"
+	// Skip records the failure and continues with the next command
+	Skip ErrorPolicy = "skip"
+	// Placeholder prints a visible marker in place of the failing command
+	Placeholder ErrorPolicy = "placeholder"
+)
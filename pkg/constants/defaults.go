@@ -2,6 +2,8 @@
 // All packages should reference these constants to ensure consistency.
 package constants
 
+import "time"
+
 // TODO: Add MustCompile validation for some constants (Text Size, etc.)
 
 // ============================================================================
@@ -48,6 +50,8 @@ const (
 	DefaultTextNewLine = true
 	// DefaultUnderline is the default underline style
 	DefaultUnderline = NoDot
+	// DefaultTextRender prints text with the printer fonts by default
+	DefaultTextRender = RenderText
 )
 
 // Image defaults for processing
@@ -154,6 +158,66 @@ const (
 	DefaultBeepLapse = 1
 )
 
+// Error handling defaults
+const (
+	// DefaultErrorPolicy is the policy applied when a command fails
+	DefaultErrorPolicy = Abort
+	// DefaultUnknownPolicy is the policy applied to unknown command types
+	DefaultUnknownPolicy = Skip
+	// DefaultPlaceholderFormat is the marker printed for failed commands (receives the command type)
+	DefaultPlaceholderFormat = "[%s unavailable]"
+)
+
+// Queue defaults
+const (
+	// DefaultQueueMaxAttempts is the number of delivery attempts before a job is dead-lettered
+	DefaultQueueMaxAttempts = 8
+	// DefaultQueueBaseDelay is the first retry delay, doubled on every failed attempt
+	DefaultQueueBaseDelay = 2 * time.Second
+	// DefaultQueueMaxDelay caps the exponential backoff between retries
+	DefaultQueueMaxDelay = 5 * time.Minute
+	// DefaultQueuePollInterval is how often Run looks for due jobs
+	DefaultQueuePollInterval = time.Second
+	// DefaultQueueCompactInterval is how often Run compacts the queue file
+	DefaultQueueCompactInterval = time.Hour
+	// DefaultQueueRetention is how long `poster serve` keeps delivered jobs
+	// for idempotency checks
+	DefaultQueueRetention = 7 * 24 * time.Hour
+	// DefaultQueuePath is the job log used by `poster serve`
+	DefaultQueuePath = "spool/jobs.jsonl"
+)
+
+// Server defaults
+const (
+	// DefaultServerAddr only listens on the loopback interface
+	DefaultServerAddr = "127.0.0.1:8420"
+	// DefaultServerMaxBodyBytes limits the size of request bodies
+	DefaultServerMaxBodyBytes = 10 << 20
+	// DefaultServerShutdownTimeout is how long in-flight requests get to finish
+	DefaultServerShutdownTimeout = 5 * time.Second
+	// DefaultBridgeEventBuffer is the number of events buffered per bridge client
+	DefaultBridgeEventBuffer = 64
+	// DefaultBridgeRequestBuffer is the number of messages queued per bridge
+	// client while it handles an earlier one
+	DefaultBridgeRequestBuffer = 16
+	// DefaultBridgeStatusInterval is how often the bridge polls printer status
+	DefaultBridgeStatusInterval = 5 * time.Second
+)
+
+// Network connection defaults
+const (
+	// DefaultNetworkPort is the raw printing port of network printers
+	DefaultNetworkPort = "9100"
+	// DefaultNetworkTimeout bounds connecting, writing and reading status
+	DefaultNetworkTimeout = 5 * time.Second
+)
+
+// Printer group defaults
+const (
+	// DefaultBackupBannerFormat is printed on jobs redirected to a backup printer (receives the primary printer)
+	DefaultBackupBannerFormat = "*** BACKUP PRINTER - %s unavailable ***"
+)
+
 // Raw defaults
 const (
 	// DefaultRawFormat is the default format for raw data
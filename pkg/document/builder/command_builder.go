@@ -13,6 +13,9 @@ type DocumentBuilder struct {
 	version  string
 	profile  schema.ProfileConfig
 	debugLog bool
+	onError  string
+	printers map[string]schema.ProfileConfig
+	glyphs   map[string]schema.Glyph
 	commands []schema.Command
 }
 
@@ -52,12 +55,21 @@ func (b *DocumentBuilder) EnableDebug() *DocumentBuilder {
 	return b
 }
 
+// SetOnError sets the document error policy ("abort", "skip" or "placeholder")
+func (b *DocumentBuilder) SetOnError(policy string) *DocumentBuilder {
+	b.onError = policy
+	return b
+}
+
 // Build creates the final Document
 func (b *DocumentBuilder) Build() *schema.Document {
 	return &schema.Document{
 		Version:  b.version,
 		Profile:  b.profile,
 		DebugLog: b.debugLog,
+		OnError:  b.onError,
+		Printers: b.printers,
+		Glyphs:   b.glyphs,
 		Commands: b.commands,
 	}
 }
@@ -86,6 +86,14 @@ func TestSetHasQR(t *testing.T) {
 	}
 }
 
+func TestSetOnError(t *testing.T) {
+	doc := NewDocument().SetProfile("Test", 80, "WPC1252").SetOnError("placeholder").Build()
+
+	if doc.OnError != "placeholder" {
+		t.Errorf("Expected OnError 'placeholder', got '%s'", doc.OnError)
+	}
+}
+
 func TestEnableDebug(t *testing.T) {
 	doc := NewDocument().EnableDebug()
 
@@ -0,0 +1,25 @@
+package builder
+
+import (
+	"github.com/adcondev/poster/pkg/document/schema"
+)
+
+// AddGlyph defines a user-defined character from pixel rows ('#' is black),
+// referenced in text as :name:
+func (b *DocumentBuilder) AddGlyph(name string, rows ...string) *DocumentBuilder {
+	return b.addGlyph(name, schema.Glyph{Bitmap: rows})
+}
+
+// AddGlyphImage defines a user-defined character from a base64 image, scaled
+// to the font cell and referenced in text as :name:
+func (b *DocumentBuilder) AddGlyphImage(name, base64Data string) *DocumentBuilder {
+	return b.addGlyph(name, schema.Glyph{Code: base64Data})
+}
+
+func (b *DocumentBuilder) addGlyph(name string, glyph schema.Glyph) *DocumentBuilder {
+	if b.glyphs == nil {
+		b.glyphs = make(map[string]schema.Glyph)
+	}
+	b.glyphs[name] = glyph
+	return b
+}
@@ -0,0 +1,24 @@
+package builder
+
+import (
+	"testing"
+)
+
+func TestAddGlyph(t *testing.T) {
+	doc := NewDocument().
+		SetProfile("POS-80", 80, "WPC1252").
+		AddGlyph("leaf", ".##.", "####", ".##.").
+		AddGlyphImage("star", "iVBORw0KGgo=").
+		Text("Salad :leaf:").End().
+		Build()
+
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got := len(doc.Glyphs["leaf"].Bitmap); got != 3 {
+		t.Errorf("Expected 3 leaf rows, got %d", got)
+	}
+	if doc.Glyphs["star"].Code != "iVBORw0KGgo=" {
+		t.Errorf("Expected star image, got %q", doc.Glyphs["star"].Code)
+	}
+}
@@ -0,0 +1,72 @@
+package builder
+
+import "github.com/adcondev/poster/pkg/numfmt"
+
+// KVBuilder constructs key/value lines
+type KVBuilder struct {
+	parent *DocumentBuilder
+	cmd    kvCommand
+}
+
+type kvCommand struct {
+	Key    string     `json:"key"`
+	Value  string     `json:"value"`
+	Leader string     `json:"leader,omitempty"`
+	Style  *textStyle `json:"style,omitempty"`
+	numfmt.Spec
+}
+
+// KV starts a line with the key on the left and the value on the right
+func (b *DocumentBuilder) KV(key, value string) *KVBuilder {
+	return &KVBuilder{parent: b, cmd: kvCommand{Key: key, Value: value}}
+}
+
+// Leader fills the space between key and value with char (e.g., ".")
+func (kb *KVBuilder) Leader(char string) *KVBuilder {
+	kb.cmd.Leader = char
+	return kb
+}
+
+// Format writes a raw number value with spec, e.g. KV("Total", "1234.5")
+// with numfmt.Spec{Kind: numfmt.Currency} prints "$1,234.50"
+func (kb *KVBuilder) Format(spec numfmt.Spec) *KVBuilder {
+	kb.cmd.Spec = spec
+	return kb
+}
+
+// Currency writes a raw number value as an amount of the default locale
+func (kb *KVBuilder) Currency() *KVBuilder {
+	kb.cmd.Kind = numfmt.Currency
+	return kb
+}
+
+// Bold enables bold text
+func (kb *KVBuilder) Bold() *KVBuilder {
+	t := true
+	kb.style().Bold = &t
+	return kb
+}
+
+// Size sets text size (e.g., "2x1"); the line width shrinks accordingly
+func (kb *KVBuilder) Size(size string) *KVBuilder {
+	kb.style().Size = &size
+	return kb
+}
+
+// Font sets the font (A or B)
+func (kb *KVBuilder) Font(font string) *KVBuilder {
+	kb.style().Font = &font
+	return kb
+}
+
+func (kb *KVBuilder) style() *textStyle {
+	if kb.cmd.Style == nil {
+		kb.cmd.Style = &textStyle{}
+	}
+	return kb.cmd.Style
+}
+
+// End finishes the kv command and returns to document builder
+func (kb *KVBuilder) End() *DocumentBuilder {
+	return kb.parent.addCommand("kv", kb.cmd)
+}
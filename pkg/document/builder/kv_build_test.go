@@ -0,0 +1,38 @@
+package builder
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestKVBuilder(t *testing.T) {
+	doc := NewDocument().
+		KV("Subtotal", "$45.00").Leader(".").Bold().Size("2x1").Font("B").End().
+		Build()
+
+	if len(doc.Commands) != 1 || doc.Commands[0].Type != "kv" {
+		t.Fatalf("Expected 1 kv command, got %+v", doc.Commands)
+	}
+
+	var cmd kvCommand
+	if err := json.Unmarshal(doc.Commands[0].Data, &cmd); err != nil {
+		t.Fatalf("Failed to unmarshal kv command: %v", err)
+	}
+	if cmd.Key != "Subtotal" || cmd.Value != "$45.00" || cmd.Leader != "." {
+		t.Errorf("Unexpected command: %+v", cmd)
+	}
+	if cmd.Style == nil || !*cmd.Style.Bold || *cmd.Style.Size != "2x1" || *cmd.Style.Font != "B" {
+		t.Errorf("Unexpected style: %+v", cmd.Style)
+	}
+}
+
+func TestKVBuilder_Currency(t *testing.T) {
+	doc := NewDocument().
+		KV("Total", "1234.5").Currency().End().
+		Build()
+
+	want := `{"key":"Total","value":"1234.5","format":"currency"}`
+	if got := string(doc.Commands[0].Data); got != want {
+		t.Errorf("kv command = %s, want %s", got, want)
+	}
+}
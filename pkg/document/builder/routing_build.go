@@ -0,0 +1,25 @@
+package builder
+
+import (
+	"github.com/adcondev/poster/pkg/document/schema"
+)
+
+// AddPrinter declares a named destination for Group. Empty profile fields
+// are inherited from the document profile.
+func (b *DocumentBuilder) AddPrinter(name string, profile schema.ProfileConfig) *DocumentBuilder {
+	if b.printers == nil {
+		b.printers = make(map[string]schema.ProfileConfig)
+	}
+	b.printers[name] = profile
+	return b
+}
+
+// Group adds the commands built by fn as a group sent to target
+func (b *DocumentBuilder) Group(target string, fn func(*DocumentBuilder)) *DocumentBuilder {
+	inner := NewDocument()
+	fn(inner)
+
+	b.addCommand("group", schema.GroupCommand{Commands: inner.commands})
+	b.commands[len(b.commands)-1].Target = target
+	return b
+}
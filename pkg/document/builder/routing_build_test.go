@@ -0,0 +1,40 @@
+package builder
+
+import (
+	"testing"
+
+	"github.com/adcondev/poster/pkg/document/schema"
+)
+
+func TestGroupAndPrinters(t *testing.T) {
+	doc := NewDocument().
+		SetProfile("POS-80", 80, "WPC1252").
+		AddPrinter("receipt", schema.ProfileConfig{Model: "POS-80"}).
+		AddPrinter("kitchen", schema.ProfileConfig{Model: "Cocina", PaperWidth: 58}).
+		Text("MESA 4").End().
+		Group("kitchen", func(g *DocumentBuilder) {
+			g.Text("2x TACOS").End()
+			g.Feed(1)
+		}).
+		Cut().
+		Build()
+
+	if err := doc.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if doc.Commands[1].Type != "group" || doc.Commands[1].Target != "kitchen" {
+		t.Errorf("Expected group for kitchen, got %s/%s", doc.Commands[1].Type, doc.Commands[1].Target)
+	}
+
+	subs, err := doc.Split()
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if got := len(subs["kitchen"].Commands); got != 4 {
+		t.Errorf("Expected 4 kitchen commands, got %d", got)
+	}
+	// Nothing targets the receipt printer, so it gets the shared commands
+	if got := len(subs["receipt"].Commands); got != 2 {
+		t.Errorf("Expected 2 receipt commands, got %d", got)
+	}
+}
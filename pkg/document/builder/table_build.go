@@ -1,23 +1,68 @@
 package builder
 
 import (
+	"encoding/json"
+
 	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/numfmt"
 )
 
 // TableBuilder constructs table commands
 type TableBuilder struct {
 	parent      *DocumentBuilder
 	columns     []tableColumn
-	rows        [][]string
+	rows        []tableRow
+	totals      *tableTotals
 	paperWidth  int
 	showHeaders bool
 	options     *tableOptions
 }
 
 type tableColumn struct {
-	Name  string `json:"name"`
-	Width int    `json:"width"`
-	Align string `json:"align,omitempty"`
+	Name     string      `json:"name"`
+	Width    any         `json:"width"` // Characters, or "N%", "*", "N*", "auto"
+	MinWidth int         `json:"min_width,omitempty"`
+	MaxWidth int         `json:"max_width,omitempty"`
+	Align    string      `json:"align,omitempty"`
+	Style    *tableStyle `json:"style,omitempty"`
+	Total    bool        `json:"total,omitempty"`
+	numfmt.Spec
+}
+
+type tableStyle struct {
+	Bold      bool   `json:"bold,omitempty"`
+	Underline bool   `json:"underline,omitempty"`
+	Size      string `json:"size,omitempty"`
+	Font      string `json:"font,omitempty"`
+}
+
+// tableRow is written as an array of cells unless it has spans or a style
+type tableRow struct {
+	Cells []string    `json:"cells"`
+	Spans []int       `json:"spans,omitempty"`
+	Style *tableStyle `json:"style,omitempty"`
+}
+
+func (r tableRow) MarshalJSON() ([]byte, error) {
+	if len(r.Spans) == 0 && r.Style == nil {
+		return json.Marshal(r.Cells)
+	}
+	type row tableRow
+	return json.Marshal(row(r))
+}
+
+func (r *tableRow) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		*r = tableRow{}
+		return json.Unmarshal(data, &r.Cells)
+	}
+	type row tableRow
+	return json.Unmarshal(data, (*row)(r))
+}
+
+type tableTotals struct {
+	Label string      `json:"label,omitempty"`
+	Style *tableStyle `json:"style,omitempty"`
 }
 
 type tableDefinition struct {
@@ -30,13 +75,18 @@ type tableOptions struct {
 	WordWrap      bool   `json:"word_wrap,omitempty"`
 	ColumnSpacing int    `json:"column_spacing,omitempty"`
 	Align         string `json:"align,omitempty"`
+	Font          string `json:"font,omitempty"`
 	AutoReduce    *bool  `json:"auto_reduce,omitempty"`
+	HeaderRule    string `json:"header_rule,omitempty"`
+	RowRule       string `json:"row_rule,omitempty"`
+	TotalsRule    string `json:"totals_rule,omitempty"`
 }
 
 type tableCommand struct {
 	Definition  tableDefinition `json:"definition"`
 	ShowHeaders bool            `json:"show_headers,omitempty"`
-	Rows        [][]string      `json:"rows"`
+	Rows        []tableRow      `json:"rows"`
+	Totals      *tableTotals    `json:"totals,omitempty"`
 	Options     *tableOptions   `json:"options,omitempty"`
 }
 
@@ -46,7 +96,7 @@ func newTableBuilder(parent *DocumentBuilder) *TableBuilder {
 	return &TableBuilder{
 		parent:      parent,
 		columns:     []tableColumn{},
-		rows:        [][]string{},
+		rows:        []tableRow{},
 		showHeaders: true,
 		options: &tableOptions{
 			HeaderBold: true,
@@ -68,15 +118,165 @@ func (tb *TableBuilder) Column(header string, width int, align ...constants.Alig
 	return tb
 }
 
+// RelativeColumn adds a column whose width is worked out for the paper:
+// "30%" of the line, "*" or "2*" shares of the space the other columns leave,
+// or "auto" to fit the longest cell
+func (tb *TableBuilder) RelativeColumn(header, width string, align ...constants.Alignment) *TableBuilder {
+	tb.Column(header, 0, align...)
+	tb.columns[len(tb.columns)-1].Width = width
+	return tb
+}
+
+// ColumnLimits bounds the resolved width of the last added column; 0 leaves
+// a bound unset
+func (tb *TableBuilder) ColumnLimits(minWidth, maxWidth int) *TableBuilder {
+	if len(tb.columns) > 0 {
+		tb.columns[len(tb.columns)-1].MinWidth = minWidth
+		tb.columns[len(tb.columns)-1].MaxWidth = maxWidth
+	}
+	return tb
+}
+
+// ColumnFormat writes the raw numbers of the last added column with spec,
+// e.g. numfmt.Spec{Kind: numfmt.Currency}
+func (tb *TableBuilder) ColumnFormat(spec numfmt.Spec) *TableBuilder {
+	if len(tb.columns) > 0 {
+		tb.columns[len(tb.columns)-1].Spec = spec
+	}
+	return tb
+}
+
+// ColumnDecimalAlign lines up the cells of the last added column on their
+// decimal separator
+func (tb *TableBuilder) ColumnDecimalAlign() *TableBuilder {
+	if len(tb.columns) > 0 {
+		tb.columns[len(tb.columns)-1].Align = "decimal"
+	}
+	return tb
+}
+
+// ColumnBold prints the cells of the last added column in bold
+func (tb *TableBuilder) ColumnBold() *TableBuilder {
+	if style := tb.columnStyle(); style != nil {
+		style.Bold = true
+	}
+	return tb
+}
+
+// ColumnSize sets the text size of the last added column (e.g., "2x1"); its
+// width still counts characters of the table font
+func (tb *TableBuilder) ColumnSize(size string) *TableBuilder {
+	if style := tb.columnStyle(); style != nil {
+		style.Size = size
+	}
+	return tb
+}
+
+// ColumnFont sets the font of the last added column (A or B)
+func (tb *TableBuilder) ColumnFont(font string) *TableBuilder {
+	if style := tb.columnStyle(); style != nil {
+		style.Font = font
+	}
+	return tb
+}
+
+// ColumnTotal sums the last added column in the totals row
+func (tb *TableBuilder) ColumnTotal() *TableBuilder {
+	if len(tb.columns) > 0 {
+		tb.columns[len(tb.columns)-1].Total = true
+	}
+	return tb
+}
+
+func (tb *TableBuilder) columnStyle() *tableStyle {
+	if len(tb.columns) == 0 {
+		return nil
+	}
+	col := &tb.columns[len(tb.columns)-1]
+	if col.Style == nil {
+		col.Style = &tableStyle{}
+	}
+	return col.Style
+}
+
 // Row adds a data row
 func (tb *TableBuilder) Row(cells ...string) *TableBuilder {
-	tb.rows = append(tb.rows, cells)
+	tb.rows = append(tb.rows, tableRow{Cells: cells})
 	return tb
 }
 
 // Rows adds multiple rows at once
 func (tb *TableBuilder) Rows(rows [][]string) *TableBuilder {
-	tb.rows = append(tb.rows, rows...)
+	for _, cells := range rows {
+		tb.rows = append(tb.rows, tableRow{Cells: cells})
+	}
+	return tb
+}
+
+// RowSpans sets how many columns each cell of the last added row spans,
+// e.g. RowSpans(3) for a single cell across three columns
+func (tb *TableBuilder) RowSpans(spans ...int) *TableBuilder {
+	if len(tb.rows) > 0 {
+		tb.rows[len(tb.rows)-1].Spans = spans
+	}
+	return tb
+}
+
+// RowBold prints the last added row in bold
+func (tb *TableBuilder) RowBold() *TableBuilder {
+	if style := tb.rowStyle(); style != nil {
+		style.Bold = true
+	}
+	return tb
+}
+
+// RowSize sets the text size of the last added row (e.g., "2x1")
+func (tb *TableBuilder) RowSize(size string) *TableBuilder {
+	if style := tb.rowStyle(); style != nil {
+		style.Size = size
+	}
+	return tb
+}
+
+func (tb *TableBuilder) rowStyle() *tableStyle {
+	if len(tb.rows) == 0 {
+		return nil
+	}
+	row := &tb.rows[len(tb.rows)-1]
+	if row.Style == nil {
+		row.Style = &tableStyle{}
+	}
+	return row.Style
+}
+
+// Totals adds a row with the sums of the ColumnTotal columns, after a label
+// ("TOTAL" when empty)
+func (tb *TableBuilder) Totals(label string) *TableBuilder {
+	tb.totals = &tableTotals{Label: label}
+	return tb
+}
+
+// HeaderRule draws a line of char after the header (e.g., "-")
+func (tb *TableBuilder) HeaderRule(char string) *TableBuilder {
+	tb.options.HeaderRule = char
+	return tb
+}
+
+// RowRule draws a line of char between rows
+func (tb *TableBuilder) RowRule(char string) *TableBuilder {
+	tb.options.RowRule = char
+	return tb
+}
+
+// TotalsRule draws a line of char before the totals row
+func (tb *TableBuilder) TotalsRule(char string) *TableBuilder {
+	tb.options.TotalsRule = char
+	return tb
+}
+
+// Font sets the table font (A or B); column widths count characters of it
+func (tb *TableBuilder) Font(font string) *TableBuilder {
+	tb.options.Font = font
 	return tb
 }
 
@@ -163,6 +363,7 @@ func (tb *TableBuilder) End() *DocumentBuilder {
 		},
 		ShowHeaders: tb.showHeaders,
 		Rows:        tb.rows,
+		Totals:      tb.totals,
 		Options:     tb.options,
 	}
 	return tb.parent.addCommand("table", cmd)
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/numfmt"
 )
 
 func TestTableBuilder(t *testing.T) {
@@ -199,3 +200,79 @@ func TestTableBuilder_AutoReduce_DefaultNil(t *testing.T) {
 		t.Fatalf("expected auto_reduce to be nil (omitted), got %v", *cmd.Options.AutoReduce)
 	}
 }
+
+func TestTableBuilder_StylesAndTotals(t *testing.T) {
+	doc := NewDocument().
+		SetProfile("Test", 80, "WPC1252").
+		Table().
+		Column("Item", 20, constants.Left).
+		Column("Price", 10, constants.Right).ColumnBold().ColumnTotal().
+		Row("Coffee", "$3.50").
+		Row("Thanks!").RowSpans(2).RowSize("2x1").
+		Totals("").
+		HeaderRule("-").
+		TotalsRule("=").
+		Font("B").
+		End().
+		Build()
+
+	want := `{"definition":{"columns":[{"name":"Item","width":20,"align":"left"},` +
+		`{"name":"Price","width":10,"align":"right","style":{"bold":true},"total":true}]},` +
+		`"show_headers":true,"rows":[["Coffee","$3.50"],{"cells":["Thanks!"],"spans":[2],"style":{"size":"2x1"}}],` +
+		`"totals":{},"options":{"header_bold":true,"word_wrap":true,"font":"B","header_rule":"-","totals_rule":"="}}`
+	if got := string(doc.Commands[0].Data); got != want {
+		t.Errorf("table command =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTableBuilder_RelativeColumns(t *testing.T) {
+	doc := NewDocument().
+		SetProfile("Test", 58, "WPC1252").
+		Table().
+		RelativeColumn("Qty", "auto").ColumnLimits(2, 5).
+		RelativeColumn("Item", "*", constants.Left).
+		RelativeColumn("Price", "25%", constants.Right).
+		Row("1", "Coffee", "$3.50").
+		End().
+		Build()
+
+	want := `[{"name":"Qty","width":"auto","min_width":2,"max_width":5},` +
+		`{"name":"Item","width":"*","align":"left"},` +
+		`{"name":"Price","width":"25%","align":"right"}]`
+
+	var cmd struct {
+		Definition struct {
+			Columns json.RawMessage `json:"columns"`
+		} `json:"definition"`
+	}
+	if err := json.Unmarshal(doc.Commands[0].Data, &cmd); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	if got := string(cmd.Definition.Columns); got != want {
+		t.Errorf("columns =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestTableBuilder_ColumnFormat(t *testing.T) {
+	doc := NewDocument().
+		SetProfile("Test", 80, "WPC1252").
+		Table().
+		Column("Item", 20, constants.Left).
+		Column("Amount", 12).ColumnFormat(numfmt.Spec{Kind: numfmt.Currency, Negative: numfmt.Parentheses}).ColumnDecimalAlign().
+		Row("Coffee", "35.5").
+		End().
+		Build()
+
+	var cmd struct {
+		Definition struct {
+			Columns []json.RawMessage `json:"columns"`
+		} `json:"definition"`
+	}
+	if err := json.Unmarshal(doc.Commands[0].Data, &cmd); err != nil {
+		t.Fatalf("Unmarshal error: %v", err)
+	}
+	want := `{"name":"Amount","width":12,"align":"decimal","format":"currency","negative":"parentheses"}`
+	if got := string(cmd.Definition.Columns[1]); got != want {
+		t.Errorf("column = %s, want %s", got, want)
+	}
+}
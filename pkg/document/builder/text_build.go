@@ -6,12 +6,14 @@ type TextBuilder struct {
 	content textContent
 	label   *textLabel
 	newLine *bool
+	render  string
 }
 
 type textContent struct {
-	Text  string     `json:"text"`
-	Style *textStyle `json:"content_style,omitempty"`
-	Align *string    `json:"align,omitempty"`
+	Text   string     `json:"text"`
+	Markup bool       `json:"markup,omitempty"`
+	Style  *textStyle `json:"content_style,omitempty"`
+	Align  *string    `json:"align,omitempty"`
 }
 
 type textLabel struct {
@@ -33,6 +35,7 @@ type textCommand struct {
 	Content textContent `json:"content"`
 	Label   *textLabel  `json:"label,omitempty"`
 	NewLine *bool       `json:"new_line,omitempty"`
+	Render  string      `json:"render,omitempty"`
 }
 
 func newTextBuilder(parent *DocumentBuilder, content string) *TextBuilder {
@@ -91,6 +94,13 @@ func (tb *TextBuilder) Font(font string) *TextBuilder {
 	return tb
 }
 
+// Markup styles parts of the text inline: **bold**, __underline__,
+// [size=2x1]...[/size], [inv]...[/inv] and [font=b]...[/font]
+func (tb *TextBuilder) Markup() *TextBuilder {
+	tb.content.Markup = true
+	return tb
+}
+
 // Left aligns text to the left
 func (tb *TextBuilder) Left() *TextBuilder {
 	align := "left"
@@ -134,12 +144,19 @@ func (tb *TextBuilder) NoNewLine() *TextBuilder {
 	return tb
 }
 
+// AsImage rasterizes the text with the executor fallback font
+func (tb *TextBuilder) AsImage() *TextBuilder {
+	tb.render = "image"
+	return tb
+}
+
 // End finishes the text command and returns to document builder
 func (tb *TextBuilder) End() *DocumentBuilder {
 	cmd := textCommand{
 		Content: tb.content,
 		Label:   tb.label,
 		NewLine: tb.newLine,
+		Render:  tb.render,
 	}
 	return tb.parent.addCommand("text", cmd)
 }
@@ -21,6 +21,7 @@ func TestTextBuilder(t *testing.T) {
 		Inverse().
 		Font("B").
 		Center().
+		AsImage().
 		End().
 		Build()
 
@@ -56,6 +57,10 @@ func TestTextBuilder(t *testing.T) {
 	if cmd.Content.Align == nil || *cmd.Content.Align != "center" {
 		t.Errorf("Expected align 'center', got '%v'", cmd.Content.Align)
 	}
+
+	if cmd.Render != "image" {
+		t.Errorf("Expected render 'image', got '%s'", cmd.Render)
+	}
 }
 
 func TestTextBuilderDefaults(t *testing.T) {
@@ -123,3 +128,17 @@ func TestTextBuilderWithLabel(t *testing.T) {
 		t.Error("Expected NewLine to be false")
 	}
 }
+
+func TestTextBuilder_Markup(t *testing.T) {
+	doc := NewDocument().
+		Text("Total: **$123.45**").Markup().End().
+		Build()
+
+	var cmd textCommand
+	if err := json.Unmarshal(doc.Commands[0].Data, &cmd); err != nil {
+		t.Fatalf("Failed to unmarshal text command: %v", err)
+	}
+	if !cmd.Content.Markup || cmd.Content.Text != "Total: **$123.45**" {
+		t.Errorf("Unexpected content: %+v", cmd.Content)
+	}
+}
@@ -101,9 +101,15 @@ func (e *Executor) handleCut(printer *service.Printer, data json.RawMessage) err
 		return fmt.Errorf("failed to parse cut command: %w", err)
 	}
 
-	// Default feed antes del corte según schema
+	// Default feed antes del corte según perfil o schema
 	if cmd.Feed == 0 {
 		cmd.Feed = constants.DefaultCutFeed
+		if printer.Profile.CutFeed > 0 {
+			cmd.Feed = printer.Profile.CutFeed
+		}
+	}
+	if cmd.Mode == "" {
+		cmd.Mode = string(printer.Profile.CutMode)
 	}
 
 	// Avance antes del corte si se especifica
@@ -1,10 +1,12 @@
 package executor
 
 import (
+	"bytes"
 	"encoding/json"
 	"testing"
 
 	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/profile"
 )
 
 // ============================================================================
@@ -118,6 +120,30 @@ func TestCutCommand_Defaults(t *testing.T) {
 	}
 }
 
+func TestHandleCut_ProfileDefaults(t *testing.T) {
+	e := &Executor{}
+	printer, conn := newBufferPrinter(t)
+	printer.Profile.CutMode = profile.CutFull
+	printer.Profile.CutFeed = 5
+
+	if err := e.handleCut(printer, json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("handleCut error: %v", err)
+	}
+	// ESC d 5, then GS V A 0 (full cut)
+	if want := []byte{0x1B, 'd', 5, 0x1D, 'V', 'A', 0}; !bytes.Equal(conn.Bytes(), want) {
+		t.Errorf("Expected %X, got %X", want, conn.Bytes())
+	}
+
+	// The document still wins
+	conn.Reset()
+	if err := e.handleCut(printer, json.RawMessage(`{"mode":"partial","feed":1}`)); err != nil {
+		t.Fatalf("handleCut error: %v", err)
+	}
+	if want := []byte{0x1B, 'd', 1, 0x1D, 'V', 'B', 0}; !bytes.Equal(conn.Bytes(), want) {
+		t.Errorf("Expected %X, got %X", want, conn.Bytes())
+	}
+}
+
 func TestCutCommand_Validation(t *testing.T) {
 	var cmd CutCommand
 	err := json.Unmarshal([]byte(`{invalid}`), &cmd)
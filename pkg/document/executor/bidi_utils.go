@@ -0,0 +1,139 @@
+package executor
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/adcondev/poster/pkg/bidi"
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// ============================================================================
+// Bidirectional Text Utilities
+// ============================================================================
+
+// visualText shapes and reorders Hebrew and Arabic text when the document
+// enables bidi. Only the presentation forms the printer can encode are used.
+func (e *Executor) visualText(printer *service.Printer, text string, base bidi.Direction) string {
+	if e.bidi == nil || !bidi.HasRTL(text) {
+		return text
+	}
+	shaped := bidi.Shape(text, func(r rune) bool {
+		return printer.CanEncode(string(r))
+	})
+	return bidi.Reorder(shaped, base)
+}
+
+// textAlign returns align, or right for right-to-left text without an
+// alignment when the document sets bidi.align_right
+func (e *Executor) textAlign(align *string, text string) *string {
+	if align != nil || e.bidi == nil || !e.bidi.AlignRight {
+		return align
+	}
+	if bidi.ParagraphDirection(text) == bidi.RightToLeft {
+		return strPtr(constants.Right.String())
+	}
+	return nil
+}
+
+// rtlLine reports whether the label and content of a text command share a
+// right-to-left line, so the content is printed first
+func (e *Executor) rtlLine(cmd *TextCommand) bool {
+	label := cmd.labelText()
+	if e.bidi == nil || label == "" || cmd.Content.Text == "" || !sameOrNil(cmd.Label.Align, cmd.Content.Align) {
+		return false
+	}
+	return bidi.ParagraphDirection(label+cmd.Content.Text) == bidi.RightToLeft
+}
+
+// printRTLLine prints a right-to-left line with its content first and its
+// label last, each one reordered with the direction of the whole line
+func (e *Executor) printRTLLine(printer *service.Printer, cmd *TextCommand) error {
+	if err := e.applyAlign(printer, cmd.Content.Align); err != nil {
+		return err
+	}
+
+	parts := []struct {
+		text  string
+		style *TextStyle
+	}{
+		{cmd.Content.Text, cmd.Content.Style},
+		{cmd.labelText(), cmd.Label.Style},
+	}
+	if cmd.spans != nil {
+		if err := e.printSpans(printer, cmd.spans, false); err != nil {
+			return err
+		}
+		parts = parts[1:]
+	}
+	newLine := cmd.NewLine == nil || *cmd.NewLine
+	for i, part := range parts {
+		if err := e.applyTextStyle(printer, part.style); err != nil {
+			return fmt.Errorf("failed to apply text style: %w", err)
+		}
+		text := e.visualText(printer, part.text, bidi.RightToLeft)
+		if err := e.printText(printer, text, newLine && i == len(parts)-1); err != nil {
+			return err
+		}
+		if err := e.resetTextStyle(printer, part.style); err != nil {
+			return fmt.Errorf("failed to reset text style: %w", err)
+		}
+	}
+	return e.applyAlign(printer, strPtr(constants.Left.String()))
+}
+
+// mirrorTable reverses the column order of a table with right-to-left text,
+// and swaps left and right column alignments, when the document sets
+// bidi.mirror_tables
+func (e *Executor) mirrorTable(cmd *TableCommand) {
+	if e.bidi == nil || !e.bidi.MirrorTables || !tableHasRTL(cmd) {
+		return
+	}
+
+	columns := cmd.Definition.Columns
+	for i, j := 0, len(columns)-1; i < j; i, j = i+1, j-1 {
+		columns[i], columns[j] = columns[j], columns[i]
+	}
+	for i := range columns {
+		switch columns[i].Align {
+		case constants.Left:
+			columns[i].Align = constants.Right
+		case constants.Right:
+			columns[i].Align = constants.Left
+		default:
+		}
+	}
+
+	for _, row := range cmd.Rows {
+		slices.Reverse(row.Cells)
+		slices.Reverse(row.Spans)
+	}
+}
+
+// tableHasRTL reports whether a header or cell has right-to-left text
+func tableHasRTL(cmd *TableCommand) bool {
+	for _, col := range cmd.Definition.Columns {
+		if bidi.HasRTL(col.Name) {
+			return true
+		}
+	}
+	for _, row := range cmd.Rows {
+		for _, cell := range row.Cells {
+			if bidi.HasRTL(cell) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// tableVisual returns the tables.Options.Visual hook for the printer
+func (e *Executor) tableVisual(printer *service.Printer) func(string) string {
+	if e.bidi == nil {
+		return nil
+	}
+	return func(cell string) string {
+		return e.visualText(printer, cell, bidi.Auto)
+	}
+}
@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/document/schema"
+)
+
+// Hebrew letters in Windows-1255
+var (
+	shalom = []byte{0xF9, 0xEC, 0xE5, 0xED} // שלום in logical order
+	molash = []byte{0xED, 0xE5, 0xEC, 0xF9} // שלום in visual order
+)
+
+func newBidiExecutor(t *testing.T, table character.CodeTable, opts *schema.Bidi) (*Executor, *bufferConnector) {
+	t.Helper()
+	printer, conn := newBufferPrinter(t)
+	printer.Profile.CodeTable = table
+	exec := NewExecutor(printer)
+	exec.bidi = opts
+	return exec, conn
+}
+
+func TestHandleText_Bidi(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    *schema.Bidi
+		data    string
+		want    []byte
+		notWant []byte
+	}{
+		{
+			name:    "disabled keeps logical order",
+			data:    `{"content": {"text": "שלום"}}`,
+			want:    shalom,
+			notWant: []byte{0x1B, 'a', 2},
+		},
+		{
+			name:    "visual order",
+			opts:    &schema.Bidi{},
+			data:    `{"content": {"text": "שלום"}}`,
+			want:    molash,
+			notWant: []byte{0x1B, 'a', 2},
+		},
+		{
+			name: "rtl paragraph aligned right",
+			opts: &schema.Bidi{AlignRight: true},
+			data: `{"content": {"text": "שלום"}}`,
+			want: append([]byte{0x1B, 'a', 2}, molash...),
+		},
+		{
+			name:    "explicit alignment wins",
+			opts:    &schema.Bidi{AlignRight: true},
+			data:    `{"content": {"text": "שלום", "align": "center"}}`,
+			want:    append([]byte{0x1B, 'a', 1}, molash...),
+			notWant: []byte{0x1B, 'a', 2},
+		},
+		{
+			name: "label after content on an rtl line",
+			opts: &schema.Bidi{},
+			data: `{"label": {"text": "שלום"}, "content": {"text": "12.50"}}`,
+			want: append([]byte("12.50 :"), molash...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec, conn := newBidiExecutor(t, character.WPC1255, tt.opts)
+			if err := exec.handleText(exec.printer, json.RawMessage(tt.data)); err != nil {
+				t.Fatalf("handleText error: %v", err)
+			}
+			if !bytes.Contains(conn.Bytes(), tt.want) {
+				t.Errorf("Expected % X in output % X", tt.want, conn.Bytes())
+			}
+			if tt.notWant != nil && bytes.Contains(conn.Bytes(), tt.notWant) {
+				t.Errorf("Expected no % X in output % X", tt.notWant, conn.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandleText_BidiArabicShaping(t *testing.T) {
+	exec, conn := newBidiExecutor(t, character.PC864, &schema.Bidi{})
+
+	if err := exec.handleText(exec.printer, json.RawMessage(`{"content": {"text": "سلام"}}`)); err != nil {
+		t.Fatalf("handleText error: %v", err)
+	}
+
+	// Meem isolated, lam-alef final, seen initial in PC864
+	want := []byte{0xEF, 0x9E, 0xD3}
+	if !bytes.Contains(conn.Bytes(), want) {
+		t.Errorf("Expected % X in output % X", want, conn.Bytes())
+	}
+}
+
+func TestHandleTable_BidiMirror(t *testing.T) {
+	data := json.RawMessage(`{
+		"definition": {"columns": [{"name": "Item", "width": 10, "align": "left"}, {"name": "Qty", "width": 3, "align": "right"}]},
+		"rows": [["שלום", "2"]]
+	}`)
+
+	tests := []struct {
+		name string
+		opts *schema.Bidi
+		want []byte
+	}{
+		{"reordered cells", &schema.Bidi{}, append(append([]byte{}, molash...), []byte("         2")...)},
+		{"mirrored columns", &schema.Bidi{MirrorTables: true}, append([]byte("2         "), molash...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exec, conn := newBidiExecutor(t, character.WPC1255, tt.opts)
+			if err := exec.handleTable(exec.printer, data); err != nil {
+				t.Fatalf("handleTable error: %v", err)
+			}
+			if !bytes.Contains(conn.Bytes(), tt.want) {
+				t.Errorf("Expected % X in output % X", tt.want, conn.Bytes())
+			}
+		})
+	}
+}
+
+func TestHandleTable_BidiMirrorTotals(t *testing.T) {
+	data := json.RawMessage(`{
+		"definition": {"columns": [{"name": "Item", "width": 10, "align": "left"}, {"name": "Qty", "width": 3, "align": "right", "total": true}]},
+		"rows": [["שלום", "2"], ["שלום", "3"]],
+		"totals": {}
+	}`)
+
+	exec, conn := newBidiExecutor(t, character.WPC1255, &schema.Bidi{MirrorTables: true})
+	if err := exec.handleTable(exec.printer, data); err != nil {
+		t.Fatalf("handleTable error: %v", err)
+	}
+
+	// The label moves to the right with the item column
+	want := []byte("5  \x1bE\x00 \x1bE\x01     TOTAL")
+	if !bytes.Contains(conn.Bytes(), want) {
+		t.Errorf("Expected %q in output %q", want, conn.Bytes())
+	}
+}
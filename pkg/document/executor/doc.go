@@ -11,7 +11,7 @@
 //	defer printer.Close()
 //
 //	exec := executor.NewExecutor(printer)
-//	err := exec.ExecuteJSON(jsonData)
+//	report, err := exec.ExecuteJSON(jsonData)
 //
 // # Architecture
 //
@@ -20,6 +20,18 @@
 //
 //	Document JSON → ParseDocument() → Execute() → handlers → Printer
 //
+// # Buffered Jobs
+//
+// Compile renders the whole document into memory without writing to the
+// connection, and refuses partial jobs (ErrPartialJob) when a failed command
+// was skipped by the error policy. ExecuteBuffered compiles and then
+// transmits the job with Printer.SendJob, honoring Printer.Transmit chunk
+// size and pacing, and reports failed commands instead:
+//
+//	job, err := exec.Compile(doc)
+//	hash := service.JobHash(job)
+//	err = printer.SendJob(job)
+//
 // # Built-in Commands
 //
 //	text        Styled text with labels
@@ -28,11 +40,13 @@
 //	barcode     1D barcodes (CODE128, EAN13, etc.)
 //	table       Formatted tables
 //	separator   Line separators
+//	kv          Key and value on one line, joined by a leader
 //	feed        Paper advance
 //	cut         Paper cutting (full/partial)
 //	pulse       Cash drawer activation
 //	beep        Buzzer sound
 //	raw         Direct ESC/POS bytes
+//	group       Nested commands sharing target and on_error (run inline)
 //
 // # Error Handling
 //
@@ -40,6 +54,16 @@
 //
 //	command 3 (barcode) failed: barcode data is required
 //
+// The document "on_error" policy (overridable per command) decides what
+// happens next: "abort" (default) stops the job, "skip" continues with the
+// next command and "placeholder" prints a marker such as "[QR unavailable]".
+// Unknown command types follow "on_unknown" (default "skip").
+//
+// Execute returns a Report listing the status of every command:
+//
+//	report, err := exec.Execute(doc)
+//	log.Println(report) // 9 succeeded, 1 failed, 0 skipped
+//
 // # Testing
 //
 // Handler tests follow a standardized three-category structure:
@@ -56,6 +80,7 @@
 //	ProfileConfig   Printer configuration (model, paper width, etc.)
 //	Command         Single command with type and JSON data
 //	Executor        Main executor with handler registry
+//	Report          Per-command execution results
 //	CommandHandler  Function signature:  func(*service.Printer, json.RawMessage) error
 //	HandlerRegistry Registry for custom command handlers
 //
@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandStatus describes the outcome of a single command
+type CommandStatus string
+
+const (
+	// StatusSucceeded indicates the handler completed without error
+	StatusSucceeded CommandStatus = "succeeded"
+	// StatusFailed indicates the handler returned an error
+	StatusFailed CommandStatus = "failed"
+	// StatusSkipped indicates the command was not executed
+	StatusSkipped CommandStatus = "skipped"
+)
+
+// CommandResult records what happened to one command of the document
+type CommandResult struct {
+	Index       int           `json:"index"`
+	Type        string        `json:"type"`
+	Status      CommandStatus `json:"status"`
+	Error       string        `json:"error,omitempty"`
+	Placeholder bool          `json:"placeholder,omitempty"`
+	// Warnings lists characters printed differently under the profile
+	// unencodable policy
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ProgressFunc is called with the result of each command as soon as it
+// has been executed
+type ProgressFunc func(result CommandResult)
+
+// Report summarizes the execution of a document
+type Report struct {
+	Results []CommandResult `json:"results"`
+	Aborted bool            `json:"aborted,omitempty"`
+
+	notify ProgressFunc
+}
+
+func (r *Report) add(result CommandResult) {
+	r.Results = append(r.Results, result)
+	if r.notify != nil {
+		r.notify(result)
+	}
+}
+
+// Count returns the number of commands with the given status
+func (r *Report) Count(status CommandStatus) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the results of the commands that failed
+func (r *Report) Failed() []CommandResult {
+	var failed []CommandResult
+	for _, res := range r.Results {
+		if res.Status == StatusFailed {
+			failed = append(failed, res)
+		}
+	}
+	return failed
+}
+
+// Warnings returns the warnings of every command, prefixed with the command
+func (r *Report) Warnings() []string {
+	var warnings []string
+	for _, res := range r.Results {
+		for _, w := range res.Warnings {
+			warnings = append(warnings, fmt.Sprintf("command %d (%s): %s", res.Index, res.Type, w))
+		}
+	}
+	return warnings
+}
+
+// OK reports whether every executed command succeeded
+func (r *Report) OK() bool {
+	return !r.Aborted && r.Count(StatusFailed) == 0
+}
+
+// String returns a one-line summary of the report
+func (r *Report) String() string {
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "%d succeeded, %d failed, %d skipped",
+		r.Count(StatusSucceeded), r.Count(StatusFailed), r.Count(StatusSkipped))
+	if n := len(r.Warnings()); n > 0 {
+		_, _ = fmt.Fprintf(&b, ", %d warnings", n)
+	}
+	if r.Aborted {
+		b.WriteString(" (aborted)")
+	}
+	return b.String()
+}
@@ -0,0 +1,363 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// bufferConnector captures everything written to the printer
+type bufferConnector struct {
+	bytes.Buffer
+}
+
+func (c *bufferConnector) Close() error { return nil }
+
+func newBufferPrinter(t *testing.T) (*service.Printer, *bufferConnector) {
+	t.Helper()
+	conn := &bufferConnector{}
+	printer, err := service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), conn)
+	if err != nil {
+		t.Fatalf("NewPrinter error: %v", err)
+	}
+	return printer, conn
+}
+
+func policyDocument(onError string, commands ...schema.Command) *schema.Document {
+	return &schema.Document{
+		Version:  "1.0",
+		Profile:  schema.ProfileConfig{Model: "Test"},
+		OnError:  onError,
+		Commands: commands,
+	}
+}
+
+var (
+	okText     = schema.Command{Type: "text", Data: json.RawMessage(`{"content":{"text":"Hello"}}`)}
+	brokenQR   = schema.Command{Type: "qr", Data: json.RawMessage(`{"data":""}`)}
+	unknownCmd = schema.Command{Type: "hologram", Data: json.RawMessage(`{}`)}
+)
+
+// ============================================================================
+// Error Policy Tests
+// ============================================================================
+
+func TestExecute_AbortPolicy_StopsAtFirstFailure(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	report, err := exec.Execute(policyDocument("", okText, brokenQR, okText))
+	if err == nil {
+		t.Fatal("Expected error with abort policy")
+	}
+	if !strings.Contains(err.Error(), "command 1 (qr) failed") {
+		t.Errorf("Unexpected error message: %v", err)
+	}
+	if !report.Aborted {
+		t.Error("Expected report to be marked as aborted")
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(report.Results))
+	}
+	if report.Results[1].Status != StatusFailed {
+		t.Errorf("Expected failed status, got %s", report.Results[1].Status)
+	}
+}
+
+func TestExecute_SkipPolicy_ContinuesAfterFailure(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	report, err := exec.Execute(policyDocument("skip", brokenQR, okText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Count(StatusFailed) != 1 || report.Count(StatusSucceeded) != 1 {
+		t.Errorf("Unexpected report: %s", report)
+	}
+	if report.OK() {
+		t.Error("Expected report with failures not to be OK")
+	}
+	if !bytes.Contains(conn.Bytes(), []byte("Hello")) {
+		t.Error("Expected following command to be printed")
+	}
+	if bytes.Contains(conn.Bytes(), []byte("unavailable")) {
+		t.Error("Skip policy must not print a placeholder")
+	}
+}
+
+func TestExecute_PlaceholderPolicy_PrintsMarker(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	report, err := exec.Execute(policyDocument("placeholder", brokenQR, okText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !report.Results[0].Placeholder {
+		t.Error("Expected placeholder flag on failed command")
+	}
+	if !bytes.Contains(conn.Bytes(), []byte("[QR unavailable]")) {
+		t.Error("Expected placeholder marker in output")
+	}
+}
+
+func TestExecute_CommandOverridesDocumentPolicy(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	optionalQR := brokenQR
+	optionalQR.OnError = "skip"
+
+	report, err := exec.Execute(policyDocument("abort", optionalQR, okText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if report.Count(StatusSucceeded) != 1 {
+		t.Errorf("Expected following command to succeed, got %s", report)
+	}
+}
+
+func TestExecute_UnknownTypes(t *testing.T) {
+	t.Run("skipped by default", func(t *testing.T) {
+		printer, _ := newBufferPrinter(t)
+		report, err := NewExecutor(printer).Execute(policyDocument("", unknownCmd, okText))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if report.Results[0].Status != StatusSkipped {
+			t.Errorf("Expected skipped status, got %s", report.Results[0].Status)
+		}
+	})
+
+	t.Run("abort when configured", func(t *testing.T) {
+		printer, _ := newBufferPrinter(t)
+		doc := policyDocument("", unknownCmd, okText)
+		doc.OnUnknown = "abort"
+		report, err := NewExecutor(printer).Execute(doc)
+		if err == nil {
+			t.Fatal("Expected error for unknown command with abort policy")
+		}
+		if len(report.Results) != 1 {
+			t.Errorf("Expected 1 result, got %d", len(report.Results))
+		}
+	})
+}
+
+// ============================================================================
+// Report Tests
+// ============================================================================
+
+func TestExecute_OnProgress(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	var events []CommandResult
+	exec.OnProgress(func(result CommandResult) {
+		events = append(events, result)
+	})
+
+	report, err := exec.Execute(policyDocument("skip", okText, brokenQR, okText))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 progress events, got %d", len(events))
+	}
+	for i, event := range events {
+		if !reflect.DeepEqual(event, report.Results[i]) {
+			t.Errorf("Event %d = %+v, want %+v", i, event, report.Results[i])
+		}
+	}
+}
+
+func TestExecute_GroupsRunInline(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	group := schema.Command{
+		Type:    "group",
+		OnError: "skip",
+		Target:  "kitchen",
+		Data:    json.RawMessage(`{"commands":[{"type":"qr","data":{"data":""}},{"type":"text","data":{"content":{"text":"Inside"}}}]}`),
+	}
+	report, err := exec.Execute(policyDocument("", okText, group))
+	if err != nil {
+		t.Fatalf("Expected group children to inherit skip policy, got %v", err)
+	}
+	if len(report.Results) != 3 {
+		t.Fatalf("Expected 3 flattened results, got %d", len(report.Results))
+	}
+	if report.Results[1].Status != StatusFailed || report.Results[2].Type != "text" {
+		t.Errorf("Unexpected results: %+v", report.Results)
+	}
+	if !strings.Contains(conn.String(), "Inside") {
+		t.Error("Expected group text in output")
+	}
+}
+
+func TestReport_String(t *testing.T) {
+	report := &Report{Results: []CommandResult{
+		{Index: 0, Type: "text", Status: StatusSucceeded},
+		{Index: 1, Type: "qr", Status: StatusFailed, Error: "boom"},
+		{Index: 2, Type: "x", Status: StatusSkipped},
+	}}
+
+	if got := report.String(); got != "1 succeeded, 1 failed, 1 skipped" {
+		t.Errorf("Unexpected summary: %s", got)
+	}
+	if failed := report.Failed(); len(failed) != 1 || failed[0].Type != "qr" {
+		t.Errorf("Unexpected failed list: %+v", failed)
+	}
+
+	report.Results[0].Warnings = []string{`'€' printed as "EUR"`}
+	if got := report.String(); got != "1 succeeded, 1 failed, 1 skipped, 1 warnings" {
+		t.Errorf("Unexpected summary: %s", got)
+	}
+}
+
+func TestExecute_UnencodableWarnings(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{
+		Type: "text",
+		Data: json.RawMessage(`{"content":{"text":"Total “5€”"}}`),
+	}, okText)
+	doc.Profile.CodeTable = "PC437"
+	doc.Profile.Unencodable = "transliterate"
+
+	report, err := NewExecutor(printer).Execute(doc)
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if !bytes.Contains(conn.Bytes(), []byte(`Total "5EUR"`)) {
+		t.Errorf("transliterated text missing in %q", conn.Bytes())
+	}
+	if n := len(report.Results[0].Warnings); n != 3 {
+		t.Errorf("Expected 3 warnings on the first command, got %v", report.Results[0].Warnings)
+	}
+	if report.Results[1].Warnings != nil {
+		t.Errorf("Expected no warnings on the second command, got %v", report.Results[1].Warnings)
+	}
+	if w := report.Warnings(); len(w) != 3 || !strings.HasPrefix(w[0], "command 0 (text): ") {
+		t.Errorf("Unexpected report warnings: %v", w)
+	}
+}
+
+func TestExecute_UnencodableFromProfile(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	printer.Profile.Unencodable = profile.UnencodableReplace
+	printer.Profile.Replacement = "#"
+	doc := policyDocument("", schema.Command{
+		Type: "text",
+		Data: json.RawMessage(`{"content":{"text":"5€"}}`),
+	})
+	doc.Profile.CodeTable = "PC437"
+
+	report, err := NewExecutor(printer).Execute(doc)
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if !bytes.Contains(conn.Bytes(), []byte("5#")) {
+		t.Errorf("replaced text missing in %q", conn.Bytes())
+	}
+	if len(report.Warnings()) != 1 {
+		t.Errorf("Expected 1 warning, got %v", report.Warnings())
+	}
+
+	// The document overrides the policy but keeps the profile replacement
+	doc.Profile.Unencodable = "transliterate"
+	doc.Commands[0].Data = json.RawMessage(`{"content":{"text":"5€ ő"}}`)
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if !bytes.Contains(conn.Bytes(), []byte("5EUR o")) || printer.Profile.Replacement != "#" {
+		t.Errorf("transliterated text missing in %q", conn.Bytes())
+	}
+}
+
+func TestExecute_DocumentSettingsDoNotLeak(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	unencodable := printer.Profile.Unencodable
+	exec := NewExecutor(printer)
+	text := schema.Command{Type: "text", Data: json.RawMessage(`{"content":{"text":"5€ Да"}}`)}
+
+	doc := policyDocument("", text)
+	doc.Profile.CodeTable = "PC437"
+	doc.Profile.AutoCodeTables = []string{"PC866"}
+	doc.Profile.Unencodable = "transliterate"
+	if _, err := exec.Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if len(printer.Profile.AutoCodeTables) != 0 || printer.Profile.Unencodable != unencodable {
+		t.Errorf("Document settings leaked into the profile: auto=%v unencodable=%q",
+			printer.Profile.AutoCodeTables, printer.Profile.Unencodable)
+	}
+
+	// The next document on the same executor uses the profile defaults
+	conn.Reset()
+	next := policyDocument("", text)
+	next.Profile.CodeTable = "PC437"
+	if _, err := exec.Execute(next); err == nil {
+		t.Errorf("Expected encoding error without the previous document settings, printed %q", conn.Bytes())
+	}
+}
+
+func TestExecute_CodeTableErrorsAbort(t *testing.T) {
+	tests := []struct {
+		name  string
+		table string
+		want  string
+	}{
+		{"unknown", "PC999", `unknown code table "PC999"`},
+		{"not supported by profile", "PC866", "unsupported code table"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			printer, conn := newBufferPrinter(t)
+			printer.Profile.CodeTables = []character.CodeTable{character.WPC1252, character.PC850}
+			exec := NewExecutor(printer)
+
+			doc := policyDocument("", okText)
+			doc.Profile.CodeTable = tt.table
+			report, err := exec.Execute(doc)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Execute error = %v; want %q", err, tt.want)
+			}
+			if !report.Aborted || len(report.Results) != 0 {
+				t.Errorf("Expected an aborted report without results, got %+v", report)
+			}
+			if bytes.Contains(conn.Bytes(), []byte("Hello")) {
+				t.Error("Expected no text printed with the wrong code table")
+			}
+		})
+	}
+}
+
+func TestExecute_BidiDoesNotLeakBetweenJobs(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	doc := policyDocument("", okText)
+	doc.Profile.Bidi = &schema.Bidi{AlignRight: true}
+	if _, err := exec.Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	doc = policyDocument("", okText)
+	doc.Profile.CodeTable = "PC999"
+	if _, err := exec.Execute(doc); err == nil {
+		t.Fatal("Expected an error for PC999")
+	}
+	if exec.bidi != nil {
+		t.Error("Expected the bidi options of the previous job to be cleared")
+	}
+}
@@ -0,0 +1,205 @@
+package executor
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/adcondev/poster/internal/load"
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/graphics"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// ============================================================================
+// User-Defined Glyphs
+// ============================================================================
+
+// glyphRuneBase is the private use rune that stands for glyph code 0 in text
+// being printed, so a glyph stays a single character through bidi
+// reordering and padding
+const glyphRuneBase = '\uE000'
+
+// glyphToken matches :name: references in text
+var glyphToken = regexp.MustCompile(`:([a-z0-9_-]+):`)
+
+// glyphCell is a font cell that glyphs are defined for
+type glyphCell struct {
+	selectFont    func() error
+	width, height int
+}
+
+// newGlyphCell returns the cell of the named font from the profile metrics
+func newGlyphCell(printer *service.Printer, font string, selectFont func() error) glyphCell {
+	width, height := printer.Profile.CellSize(font)
+	return glyphCell{selectFont: selectFont, width: width, height: height}
+}
+
+// defineGlyphs defines the document glyphs (ESC &) on consecutive codes from
+// 32, in name order, once for font B and once for font A, which stays
+// selected. Glyphs are scaled to the cell of each font in the profile.
+// Text references them as :name:.
+func (e *Executor) defineGlyphs(printer *service.Printer, glyphs map[string]schema.Glyph) error {
+	e.glyphs = nil
+	if len(glyphs) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(glyphs))
+	for name := range glyphs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	images := make([]image.Image, len(names))
+	for i, name := range names {
+		img, err := glyphImage(glyphs[name])
+		if err != nil {
+			return fmt.Errorf("glyph %s: %w", name, err)
+		}
+		images[i] = img
+	}
+
+	cells := []glyphCell{
+		newGlyphCell(printer, "B", printer.FontB),
+		newGlyphCell(printer, "A", printer.FontA),
+	}
+	for _, cell := range cells {
+		chars := make([]character.UserDefinedChar, len(names))
+		for i, name := range names {
+			threshold := glyphs[name].Threshold
+			if threshold == 0 {
+				threshold = constants.DefaultImageThreshold
+			}
+			bitmap, err := graphics.FitCell(images[i], cell.width, cell.height, threshold)
+			if err != nil {
+				return fmt.Errorf("glyph %s: %w", name, err)
+			}
+			chars[i] = character.UserDefinedChar{Width: byte(cell.width), Data: bitmap.ColumnData()}
+		}
+		if err := cell.selectFont(); err != nil {
+			return err
+		}
+		height := byte((cell.height + 7) / 8)
+		if err := printer.DefineUserChars(height, character.UserDefinedMinCode, chars); err != nil {
+			return err
+		}
+	}
+
+	e.glyphs = make(map[string]byte, len(names))
+	for i, name := range names {
+		e.glyphs[name] = character.UserDefinedMinCode + byte(i)
+	}
+	log.Printf("Glyphs: defined %d user-defined characters", len(names))
+	return nil
+}
+
+// glyphImage decodes the base64 image of a glyph, or draws its bitmap rows
+func glyphImage(glyph schema.Glyph) (image.Image, error) {
+	if glyph.Code != "" {
+		img, _, err := load.ImgFromBase64(glyph.Code)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load image: %w", err)
+		}
+		return img, nil
+	}
+
+	width := 0
+	for _, row := range glyph.Bitmap {
+		width = max(width, len(row))
+	}
+	if width == 0 {
+		return nil, fmt.Errorf("bitmap is empty")
+	}
+	img := image.NewGray(image.Rect(0, 0, width, len(glyph.Bitmap)))
+	for y, row := range glyph.Bitmap {
+		for x := 0; x < width; x++ {
+			c := color.White
+			if x < len(row) && row[x] == '#' {
+				c = color.Black
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img, nil
+}
+
+// withGlyphs replaces :name: references to defined glyphs with their private
+// use runes; unknown names are left as text
+func (e *Executor) withGlyphs(text string) string {
+	if len(e.glyphs) == 0 || !strings.Contains(text, ":") {
+		return text
+	}
+	return glyphToken.ReplaceAllStringFunc(text, func(token string) string {
+		code, ok := e.glyphs[strings.Trim(token, ":")]
+		if !ok {
+			return token
+		}
+		return string(glyphRuneBase + rune(code))
+	})
+}
+
+// isGlyphRune reports whether r stands for a defined glyph
+func (e *Executor) isGlyphRune(r rune) bool {
+	return len(e.glyphs) > 0 && r >= glyphRuneBase+rune(character.UserDefinedMinCode) &&
+		r <= glyphRuneBase+rune(character.UserDefinedMaxCode)
+}
+
+// printText prints text with Print or PrintLine, switching to the
+// user-defined set (ESC % 1) around the glyphs it references
+func (e *Executor) printText(printer *service.Printer, text string, newLine bool) error {
+	if !strings.ContainsFunc(text, e.isGlyphRune) {
+		if newLine {
+			return printer.PrintLine(text)
+		}
+		return printer.Print(text)
+	}
+
+	var plain strings.Builder
+	var codes []byte
+	flush := func() error {
+		if plain.Len() > 0 {
+			if err := printer.Print(plain.String()); err != nil {
+				return err
+			}
+			plain.Reset()
+		}
+		if len(codes) > 0 {
+			if err := printer.PrintUserChars(codes); err != nil {
+				return err
+			}
+			codes = codes[:0]
+		}
+		return nil
+	}
+	for _, r := range text {
+		if e.isGlyphRune(r) {
+			if plain.Len() > 0 {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			codes = append(codes, byte(r-glyphRuneBase))
+			continue
+		}
+		if len(codes) > 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		plain.WriteRune(r)
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	if newLine {
+		return printer.FeedLines(1)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+func glyphDocument(glyphs map[string]schema.Glyph, text string) *schema.Document {
+	doc := policyDocument("", schema.Command{
+		Type: "text",
+		Data: json.RawMessage(`{"content":{"text":"` + text + `"}}`),
+	})
+	doc.Glyphs = glyphs
+	return doc
+}
+
+func TestExecute_Glyphs(t *testing.T) {
+	glyphs := map[string]schema.Glyph{
+		"star": {Bitmap: []string{"#"}},
+		"leaf": {Bitmap: []string{"#"}},
+	}
+
+	printer, conn := newBufferPrinter(t)
+	if _, err := NewExecutor(printer).Execute(glyphDocument(glyphs, "Salad :leaf: :star::leaf: :pepper:")); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	out := conn.Bytes()
+
+	// Defined for font B (9 dots wide) and then font A (12 dots wide)
+	defineB := []byte{0x1B, 'M', 1, 0x1B, '&', 3, ' ', '!', 9}
+	defineA := []byte{0x1B, 'M', 0, 0x1B, '&', 3, ' ', '!', 12}
+	if i, j := bytes.Index(out, defineB), bytes.Index(out, defineA); i < 0 || j < i {
+		t.Errorf("glyph definitions missing or out of order in %X", out)
+	}
+
+	// Codes follow name order: leaf is ' ', star is '!'
+	want := [][]byte{
+		[]byte("Salad "),
+		{0x1B, '%', 1, ' ', 0x1B, '%', 0},
+		{0x1B, '%', 1, '!', ' ', 0x1B, '%', 0},
+		[]byte(" :pepper:"),
+	}
+	for _, w := range want {
+		if !bytes.Contains(out, w) {
+			t.Errorf("output missing %q", w)
+		}
+	}
+}
+
+func TestExecute_GlyphsUseProfileFontMetrics(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	// Font B declares only its width, so its height stays 17
+	printer.Profile.Fonts = []profile.Font{{Name: "A", Width: 10, Height: 16}, {Name: "B", Width: 8}}
+	glyphs := map[string]schema.Glyph{"star": {Bitmap: []string{"#"}}}
+	if _, err := NewExecutor(printer).Execute(glyphDocument(glyphs, ":star:")); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	out := conn.Bytes()
+
+	defineB := []byte{0x1B, 'M', 1, 0x1B, '&', 3, ' ', ' ', 8}
+	defineA := []byte{0x1B, 'M', 0, 0x1B, '&', 2, ' ', ' ', 10}
+	for _, define := range [][]byte{defineB, defineA} {
+		i := bytes.Index(out, define)
+		if i < 0 {
+			t.Fatalf("glyph definition %X missing in %X", define, out)
+		}
+		// y bytes per column, then the next command
+		if next := i + len(define) + int(define[5])*int(define[8]); next >= len(out) || out[next] != 0x1B {
+			t.Errorf("glyph definition %X has the wrong data length", define)
+		}
+	}
+}
+
+func TestExecute_GlyphsWithoutDefinitions(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	if _, err := NewExecutor(printer).Execute(glyphDocument(nil, "Salad :leaf:")); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+	if !bytes.Contains(conn.Bytes(), []byte("Salad :leaf:\n")) {
+		t.Errorf("token should print as text, got %X", conn.Bytes())
+	}
+	if bytes.Contains(conn.Bytes(), []byte{0x1B, '%'}) {
+		t.Error("user-defined set selected without glyphs")
+	}
+}
+
+func TestExecute_GlyphInvalidImage(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+	glyphs := map[string]schema.Glyph{"leaf": {Code: "not base64"}}
+	if _, err := NewExecutor(printer).Execute(glyphDocument(glyphs, "x")); err == nil {
+		t.Error("expected error for an undecodable glyph image")
+	}
+}
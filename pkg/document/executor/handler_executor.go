@@ -2,12 +2,18 @@ package executor
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/adcondev/poster/internal/calculate"
 	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/constants"
 	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/graphics"
+	"github.com/adcondev/poster/pkg/profile"
 	"github.com/adcondev/poster/pkg/service"
 )
 
@@ -15,8 +21,12 @@ import (
 
 // Executor ejecuta documentos de impresión
 type Executor struct {
-	printer  *service.Printer
-	handlers map[string]CommandHandler
+	printer    *service.Printer
+	handlers   map[string]CommandHandler
+	progress   ProgressFunc
+	textRaster *graphics.TextRasterizer // Fallback font for text, nil if none
+	bidi       *schema.Bidi             // Bidi options of the document, nil if off
+	glyphs     map[string]byte          // User-defined character codes by glyph name
 }
 
 // CommandHandler a command handler function
@@ -38,6 +48,7 @@ func NewExecutor(printer *service.Printer) *Executor {
 	e.registerHandler("feed", e.handleFeed)
 	e.registerHandler("cut", e.handleCut)
 	e.registerHandler("separator", e.handleSeparator)
+	e.registerHandler("kv", e.handleKV)
 
 	// Registrar handlers para hardware
 	e.registerHandler("pulse", e.handlePulse)
@@ -52,6 +63,12 @@ func NewExecutor(printer *service.Printer) *Executor {
 	e.registerHandler("table", e.handleTable)
 	e.registerHandler("raw", e.handleRaw)
 
+	if path := printer.Profile.FallbackFont; path != "" {
+		if err := e.SetFallbackFont(path); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
 	// TODO: Implement other commands
 	if e.printer.Profile.DebugLog {
 		logHandlerRegistration(e.handlers)
@@ -71,69 +88,268 @@ func (e *Executor) registerHandler(cmdType string, handler CommandHandler) {
 	e.handlers[cmdType] = handler
 }
 
-// Execute ejecuta un documento completo
-func (e *Executor) Execute(doc *schema.Document) error {
+// SetFallbackFont loads the TTF/OTF font used to rasterize text the printer
+// cannot encode, and text commands with render "image". NewExecutor loads
+// the profile's FallbackFont when set.
+func (e *Executor) SetFallbackFont(path string) error {
+	f, err := graphics.LoadFont(path)
+	if err != nil {
+		return fmt.Errorf("fallback font: %w", err)
+	}
+	e.textRaster = graphics.NewTextRasterizer(f)
+	return nil
+}
+
+// OnProgress registers fn to be called after every command of Execute,
+// e.g. to stream progress events to a client. Pass nil to remove it.
+func (e *Executor) OnProgress(fn ProgressFunc) {
+	e.progress = fn
+}
+
+// Execute ejecuta un documento completo y retorna un reporte por comando.
+// Group commands are expanded in place, so report indexes refer to the
+// flattened command list.
+//
+// The profile settings of the document are undone when Execute returns.
+//
+// Failing commands are handled according to the effective error policy
+// (command on_error, then document on_error, default abort). Unknown command
+// types follow the document on_unknown policy (default skip).
+func (e *Executor) Execute(doc *schema.Document) (*Report, error) {
+	report := &Report{notify: e.progress}
+
+	// Document settings (code tables, unencodable) apply to this document
+	// only; the printer profile is restored for the next one
+	saved := *e.printer.Profile.Clone()
+	defer func() {
+		e.printer.Profile = saved
+	}()
+
 	// Inicializar impresora
 	if err := e.printer.Initialize(); err != nil {
-		return fmt.Errorf("failed to initialize printer: %w", err)
+		report.Aborted = true
+		return report, fmt.Errorf("failed to initialize printer: %w", err)
 	}
 
-	// Aplicar configuración del profile desde JSON
+	// Aplicar configuración del profile desde JSON; una tabla desconocida o
+	// no soportada aborta en lugar de imprimir con la tabla activa
 	if err := e.applyProfileFromDocument(doc); err != nil {
-		log.Printf("Warning: failed to apply profile settings: %v", err)
+		report.Aborted = true
+		return report, err
+	}
+
+	// Caracteres definidos por el usuario, referenciados como :nombre:
+	if err := e.defineGlyphs(e.printer, doc.Glyphs); err != nil {
+		report.Aborted = true
+		return report, fmt.Errorf("failed to define glyphs: %w", err)
+	}
+
+	// Group commands run inline; targets only matter to the router
+	commands, err := doc.Flatten()
+	if err != nil {
+		report.Aborted = true
+		return report, err
 	}
 
 	// Execute commands
-	for i, cmd := range doc.Commands {
+	for i, cmd := range commands {
+		result := CommandResult{Index: i, Type: cmd.Type}
+
+		var err error
+		policy := doc.ErrorPolicyFor(cmd)
 		handler, exists := e.handlers[cmd.Type]
-		if !exists {
+		if exists {
+			err = handler(e.printer, cmd.Data)
+		} else {
 			log.Printf("[EXECUTOR] unknown command type at position %d: %s", i, cmd.Type)
+			err = fmt.Errorf("unknown command type: %s", cmd.Type)
+			policy = doc.UnknownPolicy()
+		}
+
+		for _, sub := range e.printer.TakeSubstitutions() {
+			result.Warnings = append(result.Warnings, sub.String())
+		}
+
+		if err == nil {
+			result.Status = StatusSucceeded
+			report.add(result)
 			continue
 		}
 
-		// TODO: Check proper handling when one command fails (ignore or stop execution, print ticket: <type> failed)?
-		if err := handler(e.printer, cmd.Data); err != nil {
-			return fmt.Errorf("command %d (%s) failed: %w", i, cmd.Type, err)
+		// Unknown types are reported as skipped, handler errors as failed
+		result.Error = err.Error()
+		result.Status = StatusFailed
+		if !exists {
+			result.Status = StatusSkipped
+		}
+
+		switch policy {
+		case constants.Skip:
+			log.Printf("[EXECUTOR] command %d (%s) skipped: %v", i, cmd.Type, err)
+			e.recoverState()
+		case constants.Placeholder:
+			e.recoverState()
+			if perr := e.printPlaceholder(cmd.Type); perr != nil {
+				log.Printf("[EXECUTOR] failed to print placeholder for command %d (%s): %v", i, cmd.Type, perr)
+			} else {
+				result.Placeholder = true
+			}
+		default:
+			report.add(result)
+			report.Aborted = true
+			return report, fmt.Errorf("command %d (%s) failed: %w", i, cmd.Type, err)
 		}
+		report.add(result)
 	}
 
-	return nil
+	return report, nil
 }
 
-// setCodeTable configura la tabla de caracteres con fallback
+// recoverState restores the default alignment and text style after a failed
+// command so that it doesn't leak into the following ones.
+func (e *Executor) recoverState() {
+	resets := []func() error{
+		e.printer.AlignLeft,
+		e.printer.SingleSize,
+		e.printer.DisableBold,
+		e.printer.NoDot,
+		e.printer.InverseOff,
+		e.printer.FontA,
+	}
+	for _, reset := range resets {
+		if err := reset(); err != nil {
+			log.Printf("[EXECUTOR] failed to restore default style: %v", err)
+			return
+		}
+	}
+}
+
+// printPlaceholder prints a visible marker in place of a failed command
+func (e *Executor) printPlaceholder(cmdType string) error {
+	marker := fmt.Sprintf(constants.DefaultPlaceholderFormat, strings.ToUpper(cmdType))
+	return AlignScope(e.printer, constants.Center.String(), func() error {
+		return e.printer.PrintLine(marker)
+	})
+}
+
+// setCodeTable configura la tabla de caracteres por nombre ("PC850",
+// "ISO-8859-7") o número ESC t; falla si la tabla no tiene codificación
 func (e *Executor) setCodeTable(tableName string) error {
-	// Mapa de nombres a constantes
-	tables := map[string]character.CodeTable{
-		"PC437":   character.PC437,
-		"PC850":   character.PC850,
-		"PC852":   character.PC852,
-		"WPC1252": character.WPC1252,
-		"PC866":   character.PC866,
-		"PC858":   character.PC858,
-		// TODO: Add Go Compatible code tables (check package profile in escpos_encoding.go)
+	table, err := profile.ParseCodeTable(tableName)
+	if err != nil {
+		return err
 	}
+	return e.printer.SetCodeTable(table)
+}
 
-	table, ok := tables[tableName]
-	if !ok {
-		log.Printf("warning: unsupported code table %s, falling back to Windows-1252", tableName)
-		return e.printer.SetCodeTable(character.WPC1252)
+// setAutoCodeTables activa el cambio automático de tabla con la prioridad
+// indicada; el texto se divide en tramos codificables por cada tabla
+func (e *Executor) setAutoCodeTables(names []string) error {
+	tables := make([]character.CodeTable, 0, len(names))
+	for _, name := range names {
+		table, err := profile.ParseCodeTable(name)
+		if err != nil {
+			return err
+		}
+		if !e.printer.Profile.IsSupported(table) {
+			return fmt.Errorf("%w %s", profile.ErrUnsupportedCodeTable, name)
+		}
+		tables = append(tables, table)
 	}
+	e.printer.Profile.AutoCodeTables = tables
+	return nil
+}
 
-	return e.printer.SetCodeTable(table)
+// setUnencodable configura qué hacer con los caracteres que ninguna tabla
+// puede codificar: fallar, reemplazarlos o transliterarlos. Los campos
+// vacíos del documento conservan la política y el reemplazo del profile.
+func (e *Executor) setUnencodable(policy, replacement string) error {
+	if policy != "" {
+		unencodable, err := profile.ParseUnencodable(policy)
+		if err != nil {
+			return err
+		}
+		e.printer.Profile.Unencodable = unencodable
+		log.Printf("Profile: Unencodable set to %s from JSON", unencodable)
+	}
+	if replacement != "" {
+		e.printer.Profile.Replacement = replacement
+	}
+	return nil
 }
 
 // ExecuteJSON ejecuta un documento desde JSON
-func (e *Executor) ExecuteJSON(data []byte) error {
+func (e *Executor) ExecuteJSON(data []byte) (*Report, error) {
 	doc, err := schema.ParseDocument(data)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	return e.Execute(doc)
 }
 
+// ErrPartialJob is returned by Compile when a command failed and the
+// document error policy skipped it or printed a placeholder.
+var ErrPartialJob = errors.New("document compiled with failed commands")
+
+// Compile renders the whole document into a buffer without touching the
+// printer connection. The job is only returned once every command has
+// compiled without errors, so it can be saved, hashed or transmitted in a
+// single transaction with Printer.SendJob. A document whose failed commands
+// were skipped under its error policy returns ErrPartialJob; use
+// ExecuteBuffered to print it anyway and inspect the report.
+func (e *Executor) Compile(doc *schema.Document) ([]byte, error) {
+	job, report, err := e.compile(doc)
+	if err != nil {
+		return nil, err
+	}
+	if failed := report.Failed(); len(failed) > 0 {
+		return nil, fmt.Errorf("%w: command %d (%s): %s", ErrPartialJob, failed[0].Index, failed[0].Type, failed[0].Error)
+	}
+	return job, nil
+}
+
+// ExecuteBuffered compiles the document and then transmits it with
+// Printer.SendJob, so a failed compilation never reaches the printer.
+func (e *Executor) ExecuteBuffered(doc *schema.Document) (*Report, error) {
+	job, report, err := e.compile(doc)
+	if err != nil {
+		return report, err
+	}
+
+	if err := e.printer.SendJob(job); err != nil {
+		return report, fmt.Errorf("failed to transmit job: %w", err)
+	}
+
+	return report, nil
+}
+
+// compile swaps the printer connection for an in-memory buffer while the
+// document executes.
+func (e *Executor) compile(doc *schema.Document) ([]byte, *Report, error) {
+	if doc == nil {
+		return nil, nil, fmt.Errorf("document is nil")
+	}
+
+	buffer := connection.NewBufferConnector()
+	original := e.printer.Connection
+	e.printer.Connection = buffer
+	defer func() {
+		e.printer.Connection = original
+	}()
+
+	report, err := e.Execute(doc)
+	if err != nil {
+		return nil, report, fmt.Errorf("failed to compile document: %w", err)
+	}
+
+	return buffer.Bytes(), report, nil
+}
+
 // applyProfileFromDocument aplica la configuración del profile desde el documento JSON
 func (e *Executor) applyProfileFromDocument(doc *schema.Document) error {
 	profile := e.printer.Profile
+	// Bidi options belong to one document, never to the previous job
+	e.bidi = nil
 
 	if doc == nil {
 		return fmt.Errorf("document is nil")
@@ -182,6 +398,35 @@ func (e *Executor) applyProfileOrDefaults(config schema.ProfileConfig) error {
 		log.Printf("Profile: CodeTable set to %s from JSON", config.CodeTable)
 	}
 
+	if len(config.AutoCodeTables) > 0 {
+		if err := e.setAutoCodeTables(config.AutoCodeTables); err != nil {
+			return fmt.Errorf("failed to set auto code tables: %w", err)
+		}
+		log.Printf("Profile: AutoCodeTables set to %v from JSON", config.AutoCodeTables)
+	}
+
+	if config.UTF8 != nil && *config.UTF8 {
+		active, err := e.printer.EnableUTF8()
+		if err != nil {
+			return fmt.Errorf("failed to enable UTF-8: %w", err)
+		}
+		if active {
+			log.Printf("Profile: UTF-8 text enabled from JSON")
+		} else {
+			log.Printf("Profile: printer has no UTF-8 support, text is transcoded to the code table")
+		}
+	}
+
+	e.bidi = config.Bidi
+	if config.Bidi != nil {
+		log.Printf("Profile: Bidi enabled from JSON (align_right=%t, mirror_tables=%t)",
+			config.Bidi.AlignRight, config.Bidi.MirrorTables)
+	}
+
+	if err := e.setUnencodable(config.Unencodable, config.Replacement); err != nil {
+		return err
+	}
+
 	if config.DPI == 0 {
 		// Default DPI 203
 		profile.DPI = 203
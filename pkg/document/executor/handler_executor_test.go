@@ -1,7 +1,9 @@
 package executor
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"strings"
 	"testing"
 
@@ -153,6 +155,95 @@ func TestDocument_CompleteStructure(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// Buffered Compilation Tests
+// ============================================================================
+
+func TestCompile_DoesNotTouchConnection(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	job, err := exec.Compile(policyDocument("", okText))
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+	if conn.Len() != 0 {
+		t.Errorf("Expected nothing written to connection, got %d bytes", conn.Len())
+	}
+	if !bytes.Contains(job, []byte("Hello")) {
+		t.Error("Expected compiled job to contain text")
+	}
+	if printer.Connection != conn {
+		t.Error("Expected original connection to be restored")
+	}
+}
+
+func TestCompile_FailedJobIsNotReturned(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+
+	job, err := NewExecutor(printer).Compile(policyDocument("abort", okText, brokenQR))
+	if err == nil {
+		t.Fatal("Expected compile error")
+	}
+	if job != nil {
+		t.Error("Expected no job for a failed compilation")
+	}
+}
+
+func TestCompile_PartialJobIsNotReturned(t *testing.T) {
+	for _, policy := range []string{"skip", "placeholder"} {
+		t.Run(policy, func(t *testing.T) {
+			printer, _ := newBufferPrinter(t)
+
+			job, err := NewExecutor(printer).Compile(policyDocument(policy, okText, brokenQR))
+			if !errors.Is(err, ErrPartialJob) {
+				t.Fatalf("Expected ErrPartialJob, got %v", err)
+			}
+			if job != nil {
+				t.Error("Expected no job when a command failed")
+			}
+		})
+	}
+
+	// Skipped unknown commands are not failures
+	printer, _ := newBufferPrinter(t)
+	if _, err := NewExecutor(printer).Compile(policyDocument("", okText, unknownCmd)); err != nil {
+		t.Errorf("Compile error: %v", err)
+	}
+}
+
+func TestExecuteBuffered_SendsCompiledJob(t *testing.T) {
+	reference, _ := newBufferPrinter(t)
+	expected, err := NewExecutor(reference).Compile(policyDocument("", okText))
+	if err != nil {
+		t.Fatalf("Compile error: %v", err)
+	}
+
+	printer, conn := newBufferPrinter(t)
+	printer.Transmit.ChunkSize = 8
+	report, err := NewExecutor(printer).ExecuteBuffered(policyDocument("", okText))
+	if err != nil {
+		t.Fatalf("ExecuteBuffered error: %v", err)
+	}
+	if !report.OK() {
+		t.Errorf("Expected OK report, got %s", report)
+	}
+	if !bytes.Equal(conn.Bytes(), expected) {
+		t.Error("Expected transmitted bytes to match compiled job")
+	}
+}
+
+func TestExecuteBuffered_NothingSentOnFailure(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+
+	if _, err := NewExecutor(printer).ExecuteBuffered(policyDocument("", okText, brokenQR)); err == nil {
+		t.Fatal("Expected error")
+	}
+	if conn.Len() != 0 {
+		t.Errorf("Expected partial job not to be sent, got %d bytes", conn.Len())
+	}
+}
+
 // ============================================================================
 // Command Data Unmarshaling Tests
 // ============================================================================
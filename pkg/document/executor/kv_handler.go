@@ -0,0 +1,142 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/adcondev/poster/pkg/bidi"
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/numfmt"
+	"github.com/adcondev/poster/pkg/service"
+	"github.com/adcondev/poster/pkg/tables"
+)
+
+// KVCommand for key/value handler: the key is printed on the left and the
+// value on the right of the same line, joined by the leader
+type KVCommand struct {
+	Key    string     `json:"key"`
+	Value  string     `json:"value"`            // A string, or a raw number with a format
+	Leader string     `json:"leader,omitempty"` // Default: " "
+	Style  *TextStyle `json:"style,omitempty"`
+
+	// Raw numbers in Value are written with the spec, e.g.
+	// "format": "currency"
+	numfmt.Spec
+}
+
+// UnmarshalJSON accepts the value as a string or a JSON number
+func (c *KVCommand) UnmarshalJSON(data []byte) error {
+	type kvCommand KVCommand
+	var raw struct {
+		*kvCommand
+		Value json.RawMessage `json:"value"`
+	}
+	raw.kvCommand = (*kvCommand)(c)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	value, err := textOrNumber(raw.Value)
+	if err != nil {
+		return fmt.Errorf("value: %w", err)
+	}
+	c.Value = value
+	return nil
+}
+
+// handleKV manages key/value commands
+func (e *Executor) handleKV(printer *service.Printer, data json.RawMessage) error {
+	var cmd KVCommand
+	if err := json.Unmarshal(data, &cmd); err != nil {
+		return fmt.Errorf("failed to parse kv command: %w", err)
+	}
+	if cmd.Key == "" && cmd.Value == "" {
+		return fmt.Errorf("kv: key or value is required")
+	}
+	if cmd.Leader == "" {
+		cmd.Leader = " "
+	}
+	if utf8.RuneCountInString(cmd.Leader) != 1 {
+		return fmt.Errorf("kv: leader must be a single character, got %q", cmd.Leader)
+	}
+	value, err := cmd.Spec.Format(cmd.Value)
+	if err != nil {
+		return fmt.Errorf("kv: %w", err)
+	}
+
+	width := e.lineColumns(printer, cmd.Style)
+	// Substituted before the layout so transliterations are measured
+	key := e.withGlyphs(printer.Substitute(cmd.Key))
+	value = e.withGlyphs(printer.Substitute(value))
+	lines := kvLines(key, value, printer.Substitute(cmd.Leader), width)
+
+	if err := printer.AlignLeft(); err != nil {
+		return err
+	}
+	if err := e.applyTextStyle(printer, cmd.Style); err != nil {
+		return fmt.Errorf("failed to apply kv style: %w", err)
+	}
+	for _, line := range lines {
+		if err := e.printText(printer, e.visualText(printer, line, bidi.Auto), true); err != nil {
+			return err
+		}
+	}
+	if err := e.resetTextStyle(printer, cmd.Style); err != nil {
+		return fmt.Errorf("failed to reset kv style: %w", err)
+	}
+	return nil
+}
+
+// kvLines lays out a key/value pair in lines of width characters. The key
+// wraps to the width of the line, and its last line shares the line with the
+// value when they fit; a value that leaves no room for the key goes on its
+// own line, right-aligned.
+func kvLines(key, value, leader string, width int) []string {
+	valueWidth := utf8.RuneCountInString(value)
+	keyWidth := width - valueWidth - 1
+	if keyWidth < 1 {
+		lines := tables.WrapText(key, width)
+		if key == "" {
+			lines = nil
+		}
+		return append(lines, tables.PadString(value, width, constants.Right))
+	}
+
+	lines := tables.WrapText(key, width)
+	last := lines[len(lines)-1]
+	if utf8.RuneCountInString(last) > keyWidth {
+		lines = append(lines[:len(lines)-1], tables.WrapText(last, keyWidth)...)
+		last = lines[len(lines)-1]
+	}
+	fill := width - utf8.RuneCountInString(last) - valueWidth
+	lines[len(lines)-1] = last + strings.Repeat(leader, fill) + value
+	return lines
+}
+
+// lineColumns returns the characters per line with the font and width
+// multiplier of style
+func (e *Executor) lineColumns(printer *service.Printer, style *TextStyle) int {
+	font := "A"
+	if style != nil && style.Font != nil {
+		font = strings.ToUpper(*style.Font)
+	}
+	columns := printer.Profile.Columns(font)
+
+	// Fallback for incomplete profiles (e.g., mock printers in tests)
+	if columns == 0 {
+		columns = tables.Width58mm203dpi
+		if printer.Profile.PaperWidth >= 80 {
+			columns = tables.Width80mm203dpi
+		}
+		log.Printf("DotsPerLine not set, falling back to %d chars based on %.0fmm paper",
+			columns, printer.Profile.PaperWidth)
+	}
+
+	if style != nil && style.Size != nil {
+		w, _ := sizeMultipliers(*style.Size)
+		columns /= w
+	}
+	return columns
+}
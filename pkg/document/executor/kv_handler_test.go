@@ -0,0 +1,103 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/document/schema"
+)
+
+func TestKVLines(t *testing.T) {
+	tests := []struct {
+		name       string
+		key, value string
+		leader     string
+		width      int
+		want       []string
+	}{
+		{"leader", "Subtotal", "$45.00", ".", 20, []string{"Subtotal......$45.00"}},
+		{"spaces", "IVA", "$7.20", " ", 12, []string{"IVA    $7.20"}},
+		{"wrapped key", "Servicio a domicilio", "$30.00", ".", 16, []string{"Servicio a", "domicilio.$30.00"}},
+		{"rewrapped last line", "Envío express", "$30.00", ".", 16, []string{"Envío", "express...$30.00"}},
+		{"long value", "Ref", "ABCDEFGHIJ", ".", 10, []string{"Ref", "ABCDEFGHIJ"}},
+		{"no key", "", "$1.00", ".", 8, []string{"...$1.00"}},
+	}
+	for _, tt := range tests {
+		if got := kvLines(tt.key, tt.value, tt.leader, tt.width); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: kvLines() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExecute_KV(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{
+		Type: "kv",
+		Data: json.RawMessage(`{"key":"Total","value":"$45.00","leader":".","style":{"size":"2x1"}}`),
+	})
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	// 48 columns of font A at double width leave 24
+	want := append([]byte{0x1D, '!', 0x10}, []byte("Total.............$45.00\n")...)
+	if !bytes.Contains(conn.Bytes(), want) {
+		t.Errorf("output missing %q in %q", want, conn.Bytes())
+	}
+}
+
+func TestExecute_KVFormat(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{
+		Type: "kv",
+		Data: json.RawMessage(`{"key":"Descuento","value":-1234.5,"leader":".","format":"currency","negative":"parentheses"}`),
+	})
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	want := []byte("....($1,234.50)\n")
+	if !bytes.Contains(conn.Bytes(), want) {
+		t.Errorf("output missing %q in %q", want, conn.Bytes())
+	}
+}
+
+func TestExecute_KVErrors(t *testing.T) {
+	for _, data := range []string{
+		`{}`,
+		`{"key":"a","value":"b","leader":".-"}`,
+		`{"key":"a","value":"b","format":"currency"}`,
+		`{"key":"a","value":1,"format":"currency","locale":"xx-XX"}`,
+		`{"key":"a","value":true}`,
+	} {
+		printer, _ := newBufferPrinter(t)
+		doc := policyDocument("", schema.Command{Type: "kv", Data: json.RawMessage(data)})
+		if _, err := NewExecutor(printer).Execute(doc); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
+
+func TestExecute_KVTransliteratedBeforeLayout(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{
+		Type: "kv",
+		Data: json.RawMessage(`{"key":"Total…","value":"5€","leader":".","style":{"size":"2x1"}}`),
+	})
+	doc.Profile.CodeTable = "PC437"
+	doc.Profile.Unencodable = "transliterate"
+	report, err := NewExecutor(printer).Execute(doc)
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	// The 24 columns hold the transliterations
+	if want := []byte("Total...............5EUR\n"); !bytes.Contains(conn.Bytes(), want) {
+		t.Errorf("output missing %q in %q", want, conn.Bytes())
+	}
+	if n := len(report.Results[0].Warnings); n != 2 {
+		t.Errorf("Expected 2 warnings, got %v", report.Results[0].Warnings)
+	}
+}
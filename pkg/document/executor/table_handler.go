@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 
 	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/numfmt"
 	"github.com/adcondev/poster/pkg/service"
 	"github.com/adcondev/poster/pkg/tables"
 )
@@ -22,19 +24,89 @@ import (
 type TableCommand struct {
 	Definition  tables.Definition `json:"definition"`
 	ShowHeaders bool              `json:"show_headers,omitempty"`
-	Rows        [][]string        `json:"rows"`
+	Rows        []TableRow        `json:"rows"`
+	Totals      *tables.Totals    `json:"totals,omitempty"`
 	Options     *TableOptions     `json:"options,omitempty"`
 }
 
+// TableRow is a row of cells. In JSON it is an array of cells, or an object
+// with cells, the columns each cell spans and a style over the column styles.
+// Cells are strings or raw numbers for formatted columns.
+type TableRow struct {
+	Cells []string      `json:"cells"`
+	Spans []int         `json:"spans,omitempty"`
+	Style *tables.Style `json:"style,omitempty"`
+}
+
+// UnmarshalJSON accepts a row as an array of cells or as an object
+func (r *TableRow) UnmarshalJSON(data []byte) error {
+	type row TableRow
+	var raw struct {
+		*row
+		Cells []json.RawMessage `json:"cells"`
+	}
+	*r = TableRow{}
+	raw.row = (*row)(r)
+
+	var err error
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &raw.Cells)
+	} else {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		return err
+	}
+
+	r.Cells = make([]string, len(raw.Cells))
+	for i, cell := range raw.Cells {
+		if r.Cells[i], err = textOrNumber(cell); err != nil {
+			return fmt.Errorf("cell %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// textOrNumber returns a JSON string, or a JSON number as written so no
+// precision is lost; null is empty
+func textOrNumber(data json.RawMessage) (string, error) {
+	switch {
+	case len(data) == 0 || string(data) == "null":
+		return "", nil
+	case data[0] == '"':
+		var s string
+		err := json.Unmarshal(data, &s)
+		return s, err
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return "", fmt.Errorf("expected a string or a number, got %s", data)
+	}
+	return n.String(), nil
+}
+
+// MarshalJSON writes rows without spans or style as an array of cells
+func (r TableRow) MarshalJSON() ([]byte, error) {
+	if len(r.Spans) == 0 && r.Style == nil {
+		return json.Marshal(r.Cells)
+	}
+	type row TableRow
+	return json.Marshal(row(r))
+}
+
 // TableOptions for table configuration
 type TableOptions struct {
 	HeaderBold    bool   `json:"header_bold,omitempty"`
 	WordWrap      bool   `json:"word_wrap,omitempty"`
 	ColumnSpacing int    `json:"column_spacing,omitempty"`
 	Align         string `json:"align,omitempty"`
-	// TODO: Setup Font usage
-	Font       string `json:"font,omitempty"`
-	AutoReduce *bool  `json:"auto_reduce,omitempty"`
+	Font          string `json:"font,omitempty"` // "A" (default) or "B"
+	AutoReduce    *bool  `json:"auto_reduce,omitempty"`
+
+	// Rules: a character repeated across the table, e.g. "-"
+	HeaderRule string `json:"header_rule,omitempty"` // After the header
+	RowRule    string `json:"row_rule,omitempty"`    // Between rows
+	TotalsRule string `json:"totals_rule,omitempty"` // Before the totals row
 }
 
 // TODO: Reducir código duplicado entre handlers
@@ -57,14 +129,22 @@ func (e *Executor) handleTable(printer *service.Printer, data json.RawMessage) e
 		return fmt.Errorf("table must have at least one column defined")
 	}
 
-	// Sum all column widths
-	totalColumnWidth, err := ValidateColumns(cmd.Definition.Columns)
-	if err != nil {
-		return fmt.Errorf("invalid table definition: %w", err)
+	// Substituted before the widths are measured so transliterations fit
+	substituteTable(printer, &cmd)
+
+	// Right-to-left tables read from the right
+	e.mirrorTable(&cmd)
+
+	font := "A"
+	if cmd.Options != nil && cmd.Options.Font != "" {
+		font = strings.ToUpper(cmd.Options.Font)
+	}
+	if font != "A" && font != "B" {
+		return fmt.Errorf("invalid table font %q (use A or B)", cmd.Options.Font)
 	}
 
-	// Calculate max chars based on printer profile and Font A
-	maxChars := constants.MaxCharsForPaperFontA(printer.Profile.DotsPerLine)
+	// Calculate max chars based on printer profile and table font
+	maxChars := printer.Profile.Columns(font)
 
 	// Fallback for incomplete profiles (e.g., mock printers in tests)
 	if maxChars == 0 {
@@ -73,6 +153,9 @@ func (e *Executor) handleTable(printer *service.Printer, data json.RawMessage) e
 		} else {
 			maxChars = tables.Width58mm203dpi // 32 chars
 		}
+		if font == "B" {
+			maxChars = maxChars * constants.FontAWidth / constants.FontBWidth
+		}
 		log.Printf("DotsPerLine not set, falling back to %d chars based on %.0fmm paper",
 			maxChars, printer.Profile.PaperWidth)
 	}
@@ -82,6 +165,17 @@ func (e *Executor) handleTable(printer *service.Printer, data json.RawMessage) e
 		spacing = cmd.Options.ColumnSpacing
 	}
 
+	// Percentage, flexible and auto widths depend on the line
+	if err := ResolveColumnWidths(&cmd, maxChars, spacing, font); err != nil {
+		return fmt.Errorf("invalid table definition: %w", err)
+	}
+
+	// Sum all column widths
+	totalColumnWidth, err := ValidateColumns(cmd.Definition.Columns)
+	if err != nil {
+		return fmt.Errorf("invalid table definition: %w", err)
+	}
+
 	// Calculate gap width (spaces between columns)
 	numberOfGaps := len(cmd.Definition.Columns) - 1
 	if numberOfGaps < 0 {
@@ -126,13 +220,14 @@ func (e *Executor) handleTable(printer *service.Printer, data json.RawMessage) e
 			// AutoReduce disabled by user
 			return fmt.Errorf(
 				"table overflow: columns (%d) + gaps (%d) = %d chars, exceeds max %d chars "+
-					"(%.0fmm paper @ %d DPI, Font A)",
+					"(%.0fmm paper @ %d DPI, Font %s)",
 				totalColumnWidth,
 				totalGapWidth,
 				totalRequiredWidth,
 				maxChars,
 				printer.Profile.PaperWidth,
 				printer.Profile.DPI,
+				font,
 			)
 		}
 	}
@@ -144,6 +239,8 @@ func (e *Executor) handleTable(printer *service.Printer, data json.RawMessage) e
 		WordWrap:      constants.DefaultTableWordWrap,
 		ColumnSpacing: spacing,
 		HeaderStyle:   tables.Style{Bold: constants.DefaultTableHeaderBold},
+		Font:          font,
+		Visual:        e.tableVisual(printer),
 	}
 
 	// Apply custom options if provided
@@ -152,11 +249,19 @@ func (e *Executor) handleTable(printer *service.Printer, data json.RawMessage) e
 		if cmd.Options.HeaderBold {
 			opts.HeaderStyle.Bold = true
 		}
+		opts.HeaderRule = cmd.Options.HeaderRule
+		opts.RowRule = cmd.Options.RowRule
+		opts.TotalsRule = cmd.Options.TotalsRule
 	}
 
-	// Enforce Font A for consistent table rendering
-	if err := printer.FontA(); err != nil {
-		return fmt.Errorf("failed to set Font A for table:  %w", err)
+	// Enforce the table font for consistent table rendering
+	if font == "B" {
+		err = printer.FontB()
+	} else {
+		err = printer.FontA()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set Font %s for table:  %w", font, err)
 	}
 
 	// Set paper width
@@ -194,12 +299,48 @@ func (e *Executor) handleTable(printer *service.Printer, data json.RawMessage) e
 		return err
 	}
 
-	// Restaurar alineación
+	// Restaurar alineación y fuente
 	err = printer.AlignLeft()
 	if err != nil {
 		return err
 	}
+	if font == "B" {
+		if err := printer.FontA(); err != nil {
+			return err
+		}
+	}
 
 	// Send the raw output
 	return nil
 }
+
+// substituteTable applies the unencodable policy to the headers, cells,
+// totals label and currency symbols of a table
+func substituteTable(printer *service.Printer, cmd *TableCommand) {
+	for i := range cmd.Definition.Columns {
+		col := &cmd.Definition.Columns[i]
+		col.Name = printer.Substitute(col.Name)
+		if col.Kind != numfmt.Currency {
+			continue
+		}
+		symbol := col.Symbol
+		if symbol == "" {
+			loc, err := numfmt.LookupLocale(col.Locale)
+			if err != nil {
+				continue // Reported when the cells are formatted
+			}
+			symbol = loc.Symbol
+		}
+		if sub := printer.Substitute(symbol); sub != symbol {
+			col.Symbol = sub
+		}
+	}
+	for _, row := range cmd.Rows {
+		for j := range row.Cells {
+			row.Cells[j] = printer.Substitute(row.Cells[j])
+		}
+	}
+	if cmd.Totals != nil {
+		cmd.Totals.Label = printer.Substitute(cmd.Totals.Label)
+	}
+}
@@ -1,8 +1,12 @@
 package executor
 
 import (
+	"bytes"
 	"encoding/json"
+	"strings"
 	"testing"
+
+	"github.com/adcondev/poster/pkg/document/schema"
 )
 
 // ============================================================================
@@ -77,6 +81,33 @@ func TestTableCommand_Parsing(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "table with row objects and totals",
+			json: `{
+				"definition": {"columns": [{"name": "Item", "width": 10}, {"name": "Total", "width": 8, "total": true, "style": {"bold": true}}]},
+				"rows": [["Coffee", "$4.50"], {"cells": ["Gift"], "spans": [2], "style": {"size": "2x1"}}],
+				"totals": {"label": "SUM"},
+				"options": {"font": "b", "row_rule": "-"}
+			}`,
+			checkFunc: func(t *testing.T, cmd TableCommand) {
+				if len(cmd.Rows) != 2 || len(cmd.Rows[0].Cells) != 2 {
+					t.Fatalf("unexpected rows %+v", cmd.Rows)
+				}
+				row := cmd.Rows[1]
+				if len(row.Cells) != 1 || len(row.Spans) != 1 || row.Spans[0] != 2 || row.Style == nil || row.Style.Size != "2x1" {
+					t.Errorf("unexpected row object %+v", row)
+				}
+				if !cmd.Definition.Columns[1].Total || cmd.Definition.Columns[1].Style == nil {
+					t.Errorf("unexpected column %+v", cmd.Definition.Columns[1])
+				}
+				if cmd.Totals == nil || cmd.Totals.Label != "SUM" {
+					t.Errorf("unexpected totals %+v", cmd.Totals)
+				}
+				if cmd.Options.Font != "b" || cmd.Options.RowRule != "-" {
+					t.Errorf("unexpected options %+v", cmd.Options)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -243,3 +274,171 @@ func TestTableCommand_Validation(t *testing.T) {
 		})
 	}
 }
+
+func TestTableRow_MarshalJSON(t *testing.T) {
+	rows := []TableRow{
+		{Cells: []string{"a", "b"}},
+		{Cells: []string{"c"}, Spans: []int{2}},
+	}
+	got, err := json.Marshal(rows)
+	if err != nil {
+		t.Fatalf("Marshal error: %v", err)
+	}
+	want := `[["a","b"],{"cells":["c"],"spans":[2]}]`
+	if string(got) != want {
+		t.Errorf("Marshal() = %s, want %s", got, want)
+	}
+}
+
+func TestExecute_TableTotals(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{Type: "table", Data: json.RawMessage(`{
+		"definition": {"columns": [
+			{"name": "Item", "width": 10, "align": "left"},
+			{"name": "Total", "width": 9, "align": "right", "total": true}
+		]},
+		"rows": [["Coffee", "$4.50"], ["Muffin", "$1,003.00"]],
+		"totals": {},
+		"options": {"font": "B", "totals_rule": "="}
+	}`)})
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	out := conn.Bytes()
+	for _, want := range []string{
+		"\x1bM\x01",
+		"====================\n",
+		"\x1bE\x01TOTAL     \x1bE\x00 \x1bE\x01$1,007.50\x1bE\x00\n",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("output missing %q in %q", want, out)
+		}
+	}
+}
+
+func TestExecute_TableInvalidFont(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{Type: "table", Data: json.RawMessage(`{
+		"definition": {"columns": [{"name": "Item", "width": 10}]},
+		"rows": [["Coffee"]],
+		"options": {"font": "C"}
+	}`)})
+	if _, err := NewExecutor(printer).Execute(doc); err == nil {
+		t.Error("expected error for font C")
+	}
+}
+
+func TestResolveColumnWidths_PaperSizes(t *testing.T) {
+	data := `{
+		"definition": {"columns": [
+			{"name": "Qty", "width": "auto"},
+			{"name": "Item", "width": "*", "align": "left"},
+			{"name": "Price", "width": "25%", "align": "right"}
+		]},
+		"rows": [["2", "Coffee", "$7.00"], ["10", "Sandwich", "$80.00"]]
+	}`
+
+	for _, tt := range []struct {
+		maxChars int
+		want     []int
+	}{
+		{48, []int{3, 32, 11}}, // 80 mm
+		{32, []int{3, 20, 7}},  // 58 mm
+	} {
+		var cmd TableCommand
+		if err := json.Unmarshal([]byte(data), &cmd); err != nil {
+			t.Fatalf("Unmarshal error: %v", err)
+		}
+		if err := ResolveColumnWidths(&cmd, tt.maxChars, 1, "A"); err != nil {
+			t.Fatalf("ResolveColumnWidths error: %v", err)
+		}
+		for i, col := range cmd.Definition.Columns {
+			if col.Width != tt.want[i] {
+				t.Errorf("%d chars: column %s width = %d, want %d", tt.maxChars, col.Name, col.Width, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestExecute_TableRelativeWidths(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{Type: "table", Data: json.RawMessage(`{
+		"definition": {"columns": [
+			{"name": "Item", "width": "*", "align": "left"},
+			{"name": "Price", "width": "auto", "align": "right"}
+		]},
+		"rows": [["Coffee", "$7.00"]],
+		"options": {"align": "left"}
+	}`)})
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	// The flexible column fills the line the auto column leaves
+	cols := printer.Profile.Columns("A")
+	want := "Coffee" + strings.Repeat(" ", cols-6-5) + "$7.00\n"
+	if !bytes.Contains(conn.Bytes(), []byte(want)) {
+		t.Errorf("output missing %q in %q", want, conn.Bytes())
+	}
+}
+
+func TestExecute_TableNumericCells(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{Type: "table", Data: json.RawMessage(`{
+		"definition": {"columns": [
+			{"name": "Item", "width": 10, "align": "left"},
+			{"name": "Total", "width": 10, "align": "decimal", "format": "currency", "total": true}
+		]},
+		"rows": [["Coffee", 35.5], ["Muffin", 1200.10], ["Gift", null]],
+		"totals": {},
+		"options": {"align": "left"}
+	}`)})
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	out := conn.Bytes()
+	for _, want := range []string{
+		"Coffee         $35.50\n",
+		"Muffin      $1,200.10\n",
+		"Gift                 \n",
+		"\x1bE\x01 $1,235.60\x1bE\x00\n",
+	} {
+		if !bytes.Contains(out, []byte(want)) {
+			t.Errorf("output missing %q in %q", want, out)
+		}
+	}
+}
+
+func TestExecute_TableTransliteratedBeforeLayout(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := policyDocument("", schema.Command{
+		Type: "table",
+		Data: json.RawMessage(`{
+			"definition": {"columns": [
+				{"name": "Item", "width": 10, "align": "left"},
+				{"name": "Price", "width": 6, "align": "right"},
+				{"name": "Auto", "width": "auto", "align": "left"}
+			]},
+			"rows": [["Coffee", "€5", "x"], ["Tea", "4", "1…2"]],
+			"options": {"column_spacing": 1}
+		}`),
+	})
+	doc.Profile.CodeTable = "PC437"
+	doc.Profile.Unencodable = "transliterate"
+	report, err := NewExecutor(printer).Execute(doc)
+	if err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	// Widths are measured on "EUR5" and "1...2"
+	for _, want := range []string{"Coffee       EUR5 x    \n", "Tea             4 1...2\n"} {
+		if !bytes.Contains(conn.Bytes(), []byte(want)) {
+			t.Errorf("output missing %q in %q", want, conn.Bytes())
+		}
+	}
+	if n := len(report.Results[0].Warnings); n != 2 {
+		t.Errorf("Expected 2 warnings, got %v", report.Results[0].Warnings)
+	}
+}
@@ -33,24 +33,47 @@ func CalculateTotalWidth(columnWidth, columnCount, spacing int) int {
 	return columnWidth + (gaps * spacing)
 }
 
+// ResolveColumnWidths sets the width of the percentage, flexible and auto
+// columns for lines of maxChars characters of font.
+func ResolveColumnWidths(cmd *TableCommand, maxChars, spacing int, font string) error {
+	tableData := newTableData(cmd)
+	if err := tableData.ResolveWidths(maxChars, spacing, font); err != nil {
+		return err
+	}
+	cmd.Definition.Columns = tableData.Definition.Columns
+	return nil
+}
+
 // RenderTable renders the table to a string.
 func RenderTable(cmd *TableCommand, opts *tables.Options) (string, error) {
 	engine := tables.NewEngine(&cmd.Definition, opts)
 
+	var buf strings.Builder
+	if err := engine.Render(&buf, newTableData(cmd)); err != nil {
+		return "", fmt.Errorf("failed to render table: %w", err)
+	}
+
+	return buf.String(), nil
+}
+
+// newTableData converts the command rows to the table engine data
+func newTableData(cmd *TableCommand) *tables.Data {
 	tableData := &tables.Data{
 		Definition:  cmd.Definition,
 		ShowHeaders: cmd.ShowHeaders,
 		Rows:        make([]tables.Row, len(cmd.Rows)),
+		Totals:      cmd.Totals,
 	}
 
 	for i, row := range cmd.Rows {
-		tableData.Rows[i] = row
-	}
-
-	var buf strings.Builder
-	if err := engine.Render(&buf, tableData); err != nil {
-		return "", fmt.Errorf("failed to render table: %w", err)
+		tableData.Rows[i] = row.Cells
+		if len(row.Spans) > 0 || row.Style != nil {
+			if tableData.Formats == nil {
+				tableData.Formats = make(map[int]tables.RowFormat)
+			}
+			tableData.Formats[i] = tables.RowFormat{Style: row.Style, Spans: row.Spans}
+		}
 	}
 
-	return buf.String(), nil
+	return tableData
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/adcondev/poster/pkg/bidi"
 	"github.com/adcondev/poster/pkg/constants"
 	"github.com/adcondev/poster/pkg/service"
 )
@@ -13,13 +14,19 @@ type TextCommand struct {
 	Content Content `json:"content"`
 	Label   *Label  `json:"label,omitempty"`
 	NewLine *bool   `json:"new_line,omitempty"`
+	Render  string  `json:"render,omitempty"`
+
+	// spans of rich content, see Content.resolveSpans
+	spans []Span
 }
 
 // Content for text
 type Content struct {
-	Text  string     `json:"text"`
-	Style *TextStyle `json:"content_style,omitempty"`
-	Align *string    `json:"align,omitempty"`
+	Text   string     `json:"text"`
+	Spans  []Span     `json:"spans,omitempty"`
+	Markup bool       `json:"markup,omitempty"`
+	Style  *TextStyle `json:"content_style,omitempty"`
+	Align  *string    `json:"align,omitempty"`
 }
 
 // Label for text
@@ -39,6 +46,17 @@ type TextStyle struct {
 	Font      *string `json:"font,omitempty"`
 }
 
+// labelText returns the label followed by its separator, "" without label
+func (cmd *TextCommand) labelText() string {
+	if cmd.Label == nil || cmd.Label.Text == "" {
+		return ""
+	}
+	if cmd.Label.Separator != nil && *cmd.Label.Separator != "" {
+		return cmd.Label.Text + *cmd.Label.Separator
+	}
+	return cmd.Label.Text + ": "
+}
+
 func strPtr(s string) *string {
 	return &s
 }
@@ -50,6 +68,42 @@ func (e *Executor) handleText(printer *service.Printer, data json.RawMessage) er
 		return fmt.Errorf("failed to parse text command: %w", err)
 	}
 
+	// Tramos con estilo propio, desde spans o markup
+	spans, err := cmd.Content.resolveSpans()
+	if err != nil {
+		return err
+	}
+	cmd.spans = spans
+
+	// Párrafos RTL sin alineación van a la derecha si el documento lo pide
+	if cmd.Label != nil {
+		cmd.Label.Align = e.textAlign(cmd.Label.Align, cmd.labelText())
+	}
+	cmd.Content.Align = e.textAlign(cmd.Content.Align, cmd.Content.Text)
+
+	// Texto que la impresora no puede codificar se imprime como imagen
+	asImage, err := e.textAsImage(printer, &cmd)
+	if err != nil {
+		return err
+	}
+	if asImage {
+		return e.printTextImage(printer, &cmd)
+	}
+
+	// Referencias :nombre: a glifos definidos en el documento
+	if cmd.Label != nil {
+		cmd.Label.Text = e.withGlyphs(cmd.Label.Text)
+	}
+	cmd.Content.Text = e.withGlyphs(cmd.Content.Text)
+	for i := range cmd.spans {
+		cmd.spans[i].Text = e.withGlyphs(cmd.spans[i].Text)
+	}
+
+	// En una línea RTL el label queda a la derecha del contenido
+	if e.rtlLine(&cmd) {
+		return e.printRTLLine(printer, &cmd)
+	}
+
 	// Procesar label si existe
 	if cmd.Label != nil && cmd.Label.Text != "" {
 		// Aplicar estilo del label solo si existe
@@ -60,12 +114,7 @@ func (e *Executor) handleText(printer *service.Printer, data json.RawMessage) er
 		}
 
 		// Construir texto del label
-		labelText := cmd.Label.Text
-		if cmd.Label.Separator != nil && *cmd.Label.Separator != "" {
-			labelText += *cmd.Label.Separator
-		} else {
-			labelText += ": "
-		}
+		labelText := cmd.labelText()
 
 		// Aplicar alineación del label
 		if err := e.applyAlign(printer, cmd.Label.Align); err != nil {
@@ -75,7 +124,7 @@ func (e *Executor) handleText(printer *service.Printer, data json.RawMessage) er
 		// Si las alineaciones son diferentes, imprimir label y resetear
 		if !sameOrNil(cmd.Label.Align, cmd.Content.Align) {
 			// Imprimir label con salto de línea
-			if err := printer.PrintLine(labelText); err != nil {
+			if err := e.printText(printer, e.visualText(printer, labelText, bidi.Auto), true); err != nil {
 				return err
 			}
 			// Reset completo antes del content solo si había estilo
@@ -86,7 +135,7 @@ func (e *Executor) handleText(printer *service.Printer, data json.RawMessage) er
 			}
 		} else {
 			// Imprimir label sin salto
-			if err := printer.Print(labelText); err != nil {
+			if err := e.printText(printer, e.visualText(printer, labelText, bidi.Auto), false); err != nil {
 				return err
 			}
 			// Reset solo los estilos diferentes si ambos existen
@@ -110,6 +159,20 @@ func (e *Executor) handleText(printer *service.Printer, data json.RawMessage) er
 			return err
 		}
 
+		// Determinar si hacer salto de línea
+		newLine := true // default
+		if cmd.NewLine != nil {
+			newLine = *cmd.NewLine
+		}
+
+		// Cada tramo cambia solo los estilos que difieren del anterior
+		if cmd.spans != nil {
+			if err := e.printSpans(printer, cmd.spans, newLine); err != nil {
+				return err
+			}
+			return e.applyAlign(printer, strPtr(constants.Left.String()))
+		}
+
 		// Aplicar estilo del contenido solo si existe
 		if cmd.Content.Style != nil {
 			if err := e.applyTextStyle(printer, cmd.Content.Style); err != nil {
@@ -117,18 +180,12 @@ func (e *Executor) handleText(printer *service.Printer, data json.RawMessage) er
 			}
 		}
 
-		// Determinar si hacer salto de línea
-		newLine := true // default
-		if cmd.NewLine != nil {
-			newLine = *cmd.NewLine
-		}
-
 		if newLine {
-			if err := printer.PrintLine(cmd.Content.Text); err != nil {
+			if err := e.printText(printer, e.visualText(printer, cmd.Content.Text, bidi.Auto), true); err != nil {
 				return err
 			}
 		} else {
-			if err := printer.Print(cmd.Content.Text); err != nil {
+			if err := e.printText(printer, e.visualText(printer, cmd.Content.Text, bidi.Auto), false); err != nil {
 				return err
 			}
 		}
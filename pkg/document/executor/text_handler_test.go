@@ -1,11 +1,13 @@
 package executor
 
 import (
+	"bytes"
 	"encoding/json"
 	"testing"
 
 	"github.com/adcondev/poster/pkg/constants"
 	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
 )
 
 // ============================================================================
@@ -230,3 +232,68 @@ func TestParseDocument_UnicodeContent(t *testing.T) {
 		t.Errorf("Expected text '%s', got '%s'", expected, textCmd.Content.Text)
 	}
 }
+
+// ============================================================================
+// Double-Byte Text Tests
+// ============================================================================
+
+func TestExecute_MixedLatinKanjiLine(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	printer.Profile.DoubleByte = profile.DoubleByteGB18030
+
+	doc := policyDocument("", schema.Command{
+		Type: "text",
+		Data: json.RawMessage(`{"content": {"text": "Té 中文 1"}}`),
+	})
+	doc.Profile.CodeTable = "PC850"
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	// Latin runs stay on PC850, the CJK run is wrapped in FS & ... FS .
+	want := []byte{'T', 0x82, ' ', 0x1C, '&', 0xD6, 0xD0, 0xCE, 0xC4, ' ', '1', 0x1C, '.', 0x0A}
+	if !bytes.Contains(conn.Bytes(), want) {
+		t.Errorf("Expected % X in output % X", want, conn.Bytes())
+	}
+}
+
+func TestHandleText_NonCJKOnDoubleByteProfile(t *testing.T) {
+	kanjiOn := []byte{0x1C, '&'}
+
+	t.Run("substituted", func(t *testing.T) {
+		printer, conn := newBufferPrinter(t)
+		printer.Profile.DoubleByte = profile.DoubleByteGB18030
+		printer.Profile.Unencodable = profile.UnencodableReplace
+		exec := NewExecutor(printer)
+
+		if err := exec.handleText(printer, json.RawMessage(`{"content": {"text": "Pago مر 🎉"}}`)); err != nil {
+			t.Fatalf("handleText error: %v", err)
+		}
+		if bytes.Contains(conn.Bytes(), kanjiOn) {
+			t.Errorf("Arabic and emoji must not print in Kanji mode: % X", conn.Bytes())
+		}
+		if !bytes.Contains(conn.Bytes(), []byte("Pago ?? ?")) {
+			t.Errorf("Expected replaced runes in output % X", conn.Bytes())
+		}
+		if subs := printer.TakeSubstitutions(); len(subs) != 3 {
+			t.Errorf("Expected 3 substitutions, got %v", subs)
+		}
+	})
+
+	t.Run("rasterized", func(t *testing.T) {
+		printer, conn := newBufferPrinter(t)
+		printer.Profile.DoubleByte = profile.DoubleByteGB18030
+		printer.Profile.FallbackFont = testFont
+		exec := NewExecutor(printer)
+
+		if err := exec.handleText(printer, json.RawMessage(`{"content": {"text": "🎉"}}`)); err != nil {
+			t.Fatalf("handleText error: %v", err)
+		}
+		if bytes.Contains(conn.Bytes(), kanjiOn) {
+			t.Errorf("Emoji must not print in Kanji mode: % X", conn.Bytes())
+		}
+		if got := bytes.Count(conn.Bytes(), rasterHeader); got != 1 {
+			t.Errorf("Expected 1 raster image, got %d", got)
+		}
+	})
+}
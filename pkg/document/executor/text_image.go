@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/bidi"
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/graphics"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// textAsImage decides whether a text command is rasterized: when it asks
+// for render "image", or when the printer cannot encode its text and a
+// fallback font is loaded
+func (e *Executor) textAsImage(printer *service.Printer, cmd *TextCommand) (bool, error) {
+	switch constants.Render(strings.ToLower(cmd.Render)) {
+	case "", constants.RenderText:
+	case constants.RenderImage:
+		if e.textRaster == nil {
+			return false, fmt.Errorf("render image requires a fallback font (set the profile fallback_font)")
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("unknown text render: %s", cmd.Render)
+	}
+
+	if e.textRaster == nil {
+		return false, nil
+	}
+	// Shaped Arabic may be encodable when the letters are not
+	label := e.visualText(printer, cmd.labelText(), bidi.Auto)
+	content := e.visualText(printer, cmd.Content.Text, bidi.Auto)
+	return !printer.CanEncode(label) || !printer.CanEncode(content), nil
+}
+
+// printTextImage rasterizes the label and content with the fallback font and
+// prints them through the raster path. Label and content share the line when
+// their alignments match, like printed text.
+func (e *Executor) printTextImage(printer *service.Printer, cmd *TextCommand) error {
+	width := printer.Profile.DotsPerLine
+	if width <= 0 {
+		width = constants.PaperPxWidth80mm
+	}
+
+	type paragraph struct {
+		spans []graphics.TextSpan
+		align *string
+	}
+	var paragraphs []paragraph
+	// The fallback font has every presentation form
+	visual := func(text string) string {
+		if e.bidi == nil {
+			return text
+		}
+		return bidi.Reorder(bidi.Shape(text, nil), bidi.Auto)
+	}
+	if label := cmd.labelText(); label != "" {
+		paragraphs = append(paragraphs, paragraph{
+			spans: []graphics.TextSpan{textSpan(visual(label), cmd.Label.Style)},
+			align: cmd.Label.Align,
+		})
+	}
+	if cmd.Content.Text != "" {
+		spans := []graphics.TextSpan{textSpan(visual(cmd.Content.Text), cmd.Content.Style)}
+		if cmd.spans != nil {
+			spans = spans[:0]
+			for _, s := range cmd.spans {
+				spans = append(spans, textSpan(visual(s.Text), s.Style))
+			}
+		}
+		switch {
+		case e.rtlLine(cmd):
+			paragraphs[0].spans = append(spans, paragraphs[0].spans...)
+		case len(paragraphs) > 0 && sameOrNil(cmd.Label.Align, cmd.Content.Align):
+			paragraphs[0].spans = append(paragraphs[0].spans, spans...)
+		default:
+			paragraphs = append(paragraphs, paragraph{spans: spans, align: cmd.Content.Align})
+		}
+	}
+
+	// Alignment goes into the bitmap, which spans the whole line
+	if err := printer.AlignLeft(); err != nil {
+		return err
+	}
+	for _, p := range paragraphs {
+		align := constants.DefaultTextAlignment
+		if p.align != nil {
+			align = constants.Alignment(strings.ToLower(*p.align))
+		}
+		bitmap, err := e.textRaster.Render(p.spans, width, align)
+		if err != nil {
+			return fmt.Errorf("failed to rasterize text: %w", err)
+		}
+		if err := printer.PrintBitmap(bitmap); err != nil {
+			return fmt.Errorf("failed to print text image: %w", err)
+		}
+	}
+	return nil
+}
+
+// textSpan maps a text style to the fallback font: the height of font A or
+// B times the size multiplier, stretched for sizes like 2x1
+func textSpan(text string, style *TextStyle) graphics.TextSpan {
+	span := graphics.TextSpan{Text: text, Height: constants.FontAHeight, ScaleX: 1}
+	if style == nil {
+		return span
+	}
+	if style.Font != nil && constants.Font(strings.ToLower(*style.Font)) == constants.B {
+		span.Height = constants.FontBHeight
+	}
+	if style.Size != nil {
+		w, h := sizeMultipliers(*style.Size)
+		span.Height *= float64(h)
+		span.ScaleX = float64(w) / float64(h)
+	}
+	span.Bold = style.Bold != nil && *style.Bold
+	return span
+}
+
+// sizeMultipliers parses a "WxH" text size, 1x1 when invalid
+func sizeMultipliers(size string) (width, height int) {
+	ss := strings.ToLower(size)
+	if len(ss) == 3 && ss[1] == 'x' {
+		w, h := int(ss[0]-'0'), int(ss[2]-'0')
+		if w >= constants.MinScale && w <= constants.MaxScale && h >= constants.MinScale && h <= constants.MaxScale {
+			return w, h
+		}
+	}
+	return 1, 1
+}
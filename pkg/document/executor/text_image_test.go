@@ -0,0 +1,87 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/constants"
+)
+
+// testFont is an embedded emulator font, so tests don't depend on system fonts
+const testFont = "../../emulator/fonts/JetBrainsMono-Regular.ttf"
+
+var rasterHeader = []byte{0x1D, 'v', '0'}
+
+func TestHandleText_UnencodableWithoutFallbackFont(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+	exec := NewExecutor(printer)
+
+	err := exec.handleText(printer, json.RawMessage(`{"content": {"text": "Ωμέγα"}}`))
+	if err == nil {
+		t.Fatal("Expected encoding error without fallback font")
+	}
+
+	err = exec.handleText(printer, json.RawMessage(`{"content": {"text": "Hello"}, "render": "image"}`))
+	if err == nil || !strings.Contains(err.Error(), "fallback_font") {
+		t.Errorf("Expected missing fallback font error, got %v", err)
+	}
+}
+
+func TestHandleText_FallbackFont(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	printer.Profile.FallbackFont = testFont
+	exec := NewExecutor(printer)
+	if exec.textRaster == nil {
+		t.Fatal("Expected fallback font loaded from the profile")
+	}
+
+	tests := []struct {
+		name   string
+		data   string
+		images int
+	}{
+		{"encodable text", `{"content": {"text": "Hello"}}`, 0},
+		{"unencodable text", `{"content": {"text": "Ωμέγα", "align": "center"}}`, 1},
+		{"render image", `{"content": {"text": "Total", "content_style": {"bold": true, "size": "2x1"}}, "render": "image"}`, 1},
+		{"label on its own line", `{"label": {"text": "Nota", "align": "left"}, "content": {"text": "Ωμέγα", "align": "right"}}`, 2},
+		{"label on the same line", `{"label": {"text": "Nota"}, "content": {"text": "Ωμέγα"}}`, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn.Reset()
+			if err := exec.handleText(printer, json.RawMessage(tt.data)); err != nil {
+				t.Fatalf("handleText error: %v", err)
+			}
+			if got := bytes.Count(conn.Bytes(), rasterHeader); got != tt.images {
+				t.Errorf("Expected %d raster images, got %d", tt.images, got)
+			}
+		})
+	}
+}
+
+func TestHandleText_UnknownRender(t *testing.T) {
+	printer, _ := newBufferPrinter(t)
+	err := NewExecutor(printer).handleText(printer, json.RawMessage(`{"content": {"text": "Hello"}, "render": "svg"}`))
+	if err == nil {
+		t.Error("Expected error for unknown render")
+	}
+}
+
+func TestTextSpan(t *testing.T) {
+	bold := true
+	size := "2x1"
+	font := "B"
+
+	span := textSpan("x", &TextStyle{Bold: &bold, Size: &size, Font: &font})
+	if span.Height != constants.FontBHeight || span.ScaleX != 2 || !span.Bold {
+		t.Errorf("Expected font B height, 2x stretch and bold, got %+v", span)
+	}
+
+	span = textSpan("x", nil)
+	if span.Height != constants.FontAHeight || span.ScaleX != 1 || span.Bold {
+		t.Errorf("Expected font A defaults, got %+v", span)
+	}
+}
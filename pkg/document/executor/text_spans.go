@@ -0,0 +1,299 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/bidi"
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// ============================================================================
+// Inline Rich Text
+// ============================================================================
+
+// Span is a piece of content text with its own style, layered over the
+// content style
+type Span struct {
+	Text  string     `json:"text"`
+	Style *TextStyle `json:"style,omitempty"`
+}
+
+// resolveSpans returns the styled spans of the content, from spans or from
+// its markup text, with the content style merged into each one. Content.Text
+// becomes the plain text of the spans. It returns nil for plain content.
+func (c *Content) resolveSpans() ([]Span, error) {
+	var spans []Span
+	switch {
+	case len(c.Spans) > 0 && c.Text != "":
+		return nil, fmt.Errorf("content: use text or spans, not both")
+	case len(c.Spans) > 0:
+		spans = c.Spans
+	case c.Markup:
+		var err error
+		if spans, err = parseMarkup(c.Text); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, nil
+	}
+
+	var plain strings.Builder
+	resolved := make([]Span, 0, len(spans))
+	for _, span := range spans {
+		if span.Text == "" {
+			continue
+		}
+		plain.WriteString(span.Text)
+		resolved = append(resolved, Span{Text: span.Text, Style: mergeStyles(c.Style, span.Style)})
+	}
+	c.Text = plain.String()
+	return resolved, nil
+}
+
+// mergeStyles returns base with the fields set in over replaced
+func mergeStyles(base, over *TextStyle) *TextStyle {
+	if base == nil && over == nil {
+		return nil
+	}
+	merged := TextStyle{}
+	if base != nil {
+		merged = *base
+	}
+	if over == nil {
+		return &merged
+	}
+	if over.Bold != nil {
+		merged.Bold = over.Bold
+	}
+	if over.Size != nil {
+		merged.Size = over.Size
+	}
+	if over.Underline != nil {
+		merged.Underline = over.Underline
+	}
+	if over.Inverse != nil {
+		merged.Inverse = over.Inverse
+	}
+	if over.Font != nil {
+		merged.Font = over.Font
+	}
+	return &merged
+}
+
+// switchTextStyle changes the printer from one span style to the next,
+// sending only the commands for the fields that differ
+func (e *Executor) switchTextStyle(printer *service.Printer, from, to *TextStyle) error {
+	if from == nil {
+		from = &TextStyle{}
+	}
+	if to == nil {
+		to = &TextStyle{}
+	}
+
+	// Fields the next span sets are applied, fields it drops are reset
+	var apply, reset TextStyle
+	apply.Bold, reset.Bold = styleChange(from.Bold, to.Bold)
+	apply.Size, reset.Size = styleChange(from.Size, to.Size)
+	apply.Underline, reset.Underline = styleChange(from.Underline, to.Underline)
+	apply.Inverse, reset.Inverse = styleChange(from.Inverse, to.Inverse)
+	apply.Font, reset.Font = styleChange(from.Font, to.Font)
+
+	if err := e.resetTextStyle(printer, &reset); err != nil {
+		return err
+	}
+	return e.applyTextStyle(printer, &apply)
+}
+
+// styleChange returns the value to apply when a style field changes to a set
+// value, or the value to reset when it is dropped
+func styleChange[T comparable](from, to *T) (apply, reset *T) {
+	switch {
+	case sameOrNil(from, to):
+		return nil, nil
+	case to != nil:
+		return to, nil
+	default:
+		return nil, from
+	}
+}
+
+// printSpans prints the spans on the current line, switching styles between
+// them, and resets the last style at the end
+func (e *Executor) printSpans(printer *service.Printer, spans []Span, newLine bool) error {
+	spans = e.visualSpans(printer, spans)
+
+	var current *TextStyle
+	for i, span := range spans {
+		if err := e.switchTextStyle(printer, current, span.Style); err != nil {
+			return fmt.Errorf("failed to apply span style: %w", err)
+		}
+		current = span.Style
+		if err := e.printText(printer, span.Text, newLine && i == len(spans)-1); err != nil {
+			return err
+		}
+	}
+	if err := e.resetTextStyle(printer, current); err != nil {
+		return fmt.Errorf("failed to reset span style: %w", err)
+	}
+	return nil
+}
+
+// visualSpans reorders the spans of a right-to-left line when the document
+// enables bidi: the first span is printed last, and each span is reordered
+// with the direction of the whole line
+func (e *Executor) visualSpans(printer *service.Printer, spans []Span) []Span {
+	if e.bidi == nil {
+		return spans
+	}
+	var plain strings.Builder
+	for _, span := range spans {
+		plain.WriteString(span.Text)
+	}
+	base := bidi.ParagraphDirection(plain.String())
+
+	visual := make([]Span, len(spans))
+	for i, span := range spans {
+		j := i
+		if base == bidi.RightToLeft {
+			j = len(spans) - 1 - i
+		}
+		visual[j] = Span{Text: e.visualText(printer, span.Text, base), Style: span.Style}
+	}
+	return visual
+}
+
+// ============================================================================
+// Markup
+// ============================================================================
+
+// markupTag is a [name=value]...[/name] tag of the content markup
+type markupTag struct {
+	name  string
+	value string
+}
+
+// parseMarkup splits text with inline markup into styled spans:
+//
+//	**bold**  __underline__  [size=2x1]...[/size]  [inv]...[/inv]  [font=b]...[/font]
+//
+// A backslash prints the next character as is. Tags nest; unknown tags are
+// printed as text.
+func parseMarkup(text string) ([]Span, error) {
+	var spans []Span
+	var plain strings.Builder
+	var bold, underline bool
+	var tags []markupTag
+
+	flush := func() {
+		if plain.Len() == 0 {
+			return
+		}
+		spans = append(spans, Span{Text: plain.String(), Style: markupStyle(bold, underline, tags)})
+		plain.Reset()
+	}
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && i+1 < len(text):
+			i++
+			plain.WriteByte(text[i])
+		case strings.HasPrefix(text[i:], "**"):
+			flush()
+			bold = !bold
+			i++
+		case strings.HasPrefix(text[i:], "__"):
+			flush()
+			underline = !underline
+			i++
+		case text[i] == '[':
+			end := strings.IndexByte(text[i:], ']')
+			if end < 0 {
+				plain.WriteByte(text[i])
+				continue
+			}
+			tag, closing, ok := parseMarkupTag(text[i+1 : i+end])
+			if !ok {
+				plain.WriteByte(text[i])
+				continue
+			}
+			flush()
+			if closing {
+				if len(tags) == 0 || tags[len(tags)-1].name != tag.name {
+					return nil, fmt.Errorf("markup: unexpected [/%s]", tag.name)
+				}
+				tags = tags[:len(tags)-1]
+			} else {
+				tags = append(tags, tag)
+			}
+			i += end
+		default:
+			plain.WriteByte(text[i])
+		}
+	}
+	flush()
+
+	switch {
+	case bold:
+		return nil, fmt.Errorf("markup: unclosed **")
+	case underline:
+		return nil, fmt.Errorf("markup: unclosed __")
+	case len(tags) > 0:
+		return nil, fmt.Errorf("markup: unclosed [%s]", tags[len(tags)-1].name)
+	}
+	return spans, nil
+}
+
+// parseMarkupTag parses the inside of a [tag], reporting false for text
+// that is not a known tag
+func parseMarkupTag(s string) (tag markupTag, closing bool, ok bool) {
+	if name, found := strings.CutPrefix(s, "/"); found {
+		switch name {
+		case "size", "inv", "font":
+			return markupTag{name: name}, true, true
+		}
+		return markupTag{}, false, false
+	}
+
+	name, value, _ := strings.Cut(s, "=")
+	switch name {
+	case "inv":
+		return markupTag{name: name}, false, value == ""
+	case "size":
+		w, h := sizeMultipliers(value)
+		return markupTag{name: name, value: value}, false, fmt.Sprintf("%dx%d", w, h) == strings.ToLower(value)
+	case "font":
+		font := strings.ToLower(value)
+		return markupTag{name: name, value: value}, false, font == "a" || font == "b"
+	}
+	return markupTag{}, false, false
+}
+
+// markupStyle returns the style of the open markup, nil when there is none;
+// nested tags of the same name override the outer ones
+func markupStyle(bold, underline bool, tags []markupTag) *TextStyle {
+	if !bold && !underline && len(tags) == 0 {
+		return nil
+	}
+	style := &TextStyle{}
+	if bold {
+		style.Bold = new(bool)
+		*style.Bold = true
+	}
+	if underline {
+		style.Underline = strPtr(constants.OneDot.String())
+	}
+	for _, tag := range tags {
+		switch tag.name {
+		case "size":
+			style.Size = strPtr(strings.ToLower(tag.value))
+		case "inv":
+			style.Inverse = new(bool)
+			*style.Inverse = true
+		case "font":
+			style.Font = strPtr(strings.ToUpper(tag.value))
+		}
+	}
+	return style
+}
@@ -0,0 +1,133 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/document/schema"
+)
+
+func TestParseMarkup(t *testing.T) {
+	spans, err := parseMarkup(`Total: **$123.45** [size=2x1]__big__ [inv]x[/inv][/size] \**`)
+	if err != nil {
+		t.Fatalf("parseMarkup error: %v", err)
+	}
+
+	want := []struct {
+		text  string
+		style string
+	}{
+		{"Total: ", ""},
+		{"$123.45", "bold"},
+		{" ", ""},
+		{"big", "size=2x1 underline=1pt"},
+		{" ", "size=2x1"},
+		{"x", "size=2x1 inverse"},
+		{" **", ""},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("parseMarkup() = %d spans, want %d: %+v", len(spans), len(want), spans)
+	}
+	for i, w := range want {
+		if spans[i].Text != w.text || describeStyle(spans[i].Style) != w.style {
+			t.Errorf("span %d = %q (%s), want %q (%s)", i, spans[i].Text, describeStyle(spans[i].Style), w.text, w.style)
+		}
+	}
+
+	// Unknown tags and lone brackets are text
+	spans, err = parseMarkup("[note] a[b")
+	if err != nil || len(spans) != 1 || spans[0].Text != "[note] a[b" {
+		t.Errorf("unexpected spans %+v, %v", spans, err)
+	}
+}
+
+func TestParseMarkup_Errors(t *testing.T) {
+	tests := map[string]string{
+		"**open":                  "unclosed **",
+		"__open":                  "unclosed __",
+		"[inv]open":               "unclosed [inv]",
+		"[size=2x2][inv]x[/size]": "unexpected [/size]",
+		"[/font]":                 "unexpected [/font]",
+	}
+	for text, want := range tests {
+		if _, err := parseMarkup(text); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("parseMarkup(%q) error = %v, want %q", text, err, want)
+		}
+	}
+}
+
+// describeStyle lists the set fields of a style
+func describeStyle(style *TextStyle) string {
+	if style == nil {
+		return ""
+	}
+	var parts []string
+	if style.Bold != nil && *style.Bold {
+		parts = append(parts, "bold")
+	}
+	if style.Size != nil {
+		parts = append(parts, "size="+*style.Size)
+	}
+	if style.Underline != nil {
+		parts = append(parts, "underline="+*style.Underline)
+	}
+	if style.Inverse != nil && *style.Inverse {
+		parts = append(parts, "inverse")
+	}
+	if style.Font != nil {
+		parts = append(parts, "font="+*style.Font)
+	}
+	return strings.Join(parts, " ")
+}
+
+func spansDocument(data string) *schema.Document {
+	return policyDocument("", schema.Command{Type: "text", Data: json.RawMessage(data)})
+}
+
+func TestExecute_TextMarkup(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := spansDocument(`{"content":{"text":"Total: **$123.45**","markup":true}}`)
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	want := []byte("Total: \x1bE\x01$123.45\n\x1bE\x00")
+	if !bytes.Contains(conn.Bytes(), want) {
+		t.Errorf("output missing %q in %q", want, conn.Bytes())
+	}
+}
+
+func TestExecute_TextSpans(t *testing.T) {
+	printer, conn := newBufferPrinter(t)
+	doc := spansDocument(`{"content":{
+		"spans":[
+			{"text":"A","style":{"bold":true}},
+			{"text":"B","style":{"bold":true,"size":"2x2"}},
+			{"text":"C"}
+		],
+		"content_style":{"font":"B"}
+	},"new_line":false}`)
+	if _, err := NewExecutor(printer).Execute(doc); err != nil {
+		t.Fatalf("Execute error: %v", err)
+	}
+
+	// Font B stays on for every span; bold is only sent once
+	want := []byte("\x1bE\x01\x1bM\x01A\x1d!\x11B\x1bE\x00\x1d!\x00C\x1bM\x00")
+	if !bytes.Contains(conn.Bytes(), want) {
+		t.Errorf("output missing %q in %q", want, conn.Bytes())
+	}
+}
+
+func TestExecute_TextSpansErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"content":{"text":"x","spans":[{"text":"y"}]}}`,
+		`{"content":{"text":"**x","markup":true}}`,
+	} {
+		printer, _ := newBufferPrinter(t)
+		if _, err := NewExecutor(printer).Execute(spansDocument(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
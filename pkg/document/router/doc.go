@@ -0,0 +1,48 @@
+// Package router prints one document on several printers at once.
+//
+// A document declares named destinations in "printers" and sends commands,
+// or groups of commands, to them with "target". The router splits the
+// document into one sub-document per destination, each with its own profile
+// and connection, and executes them concurrently.
+//
+//	{
+//	  "version": "1.0",
+//	  "profile": {"model": "POS-80", "paper_width": 80},
+//	  "printers": {
+//	    "receipt": {"model": "POS-80"},
+//	    "kitchen": {"model": "Cocina", "paper_width": 58}
+//	  },
+//	  "commands": [
+//	    {"type": "text", "data": {"content": {"text": "Mesa 4"}}},
+//	    {"type": "group", "target": "kitchen", "data": {"commands": [...]}},
+//	    {"type": "text", "target": "receipt", "data": {"content": {"text": "Total $120.00"}}},
+//	    {"type": "cut", "data": {"mode": "partial"}}
+//	  ]
+//	}
+//
+// # Routing Rules
+//
+//   - Commands with a target go only to that destination.
+//   - Commands without a target (headers, cuts) go to every destination.
+//   - Destination profiles inherit empty fields from the document profile;
+//     "utf8": false turns UTF-8 off for one destination.
+//   - Groups pass their target and on_error to their children.
+//
+// # Quick Start
+//
+//	r, _ := router.New(func(dest string, p schema.ProfileConfig) (*service.Printer, error) {
+//		conn, err := connection.NewWindowsPrintConnector(p.Model)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), conn)
+//	}, router.Options{})
+//
+//	result, err := r.Route(doc)
+//	for _, d := range result.Destinations {
+//		fmt.Println(d.Destination, d.Report)
+//	}
+//
+// A failing destination does not stop the others; Route returns the joined
+// errors together with the result of every destination.
+package router
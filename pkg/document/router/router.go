@@ -0,0 +1,143 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// OpenFunc opens the printer of a destination. The profile is the
+// destination profile with the document defaults applied; its Model is the
+// printer name by convention. The router closes the printer when done.
+type OpenFunc func(destination string, profile schema.ProfileConfig) (*service.Printer, error)
+
+// Options configures how each destination is executed
+type Options struct {
+	// Buffered compiles every sub-document before transmitting it
+	Buffered bool
+	// OnProgress, when set, receives the result of every command
+	OnProgress func(destination string, result executor.CommandResult)
+}
+
+// Router splits documents by destination and prints them concurrently
+type Router struct {
+	open OpenFunc
+	opts Options
+}
+
+// New creates a Router that opens printers with open
+func New(open OpenFunc, opts Options) (*Router, error) {
+	if open == nil {
+		return nil, fmt.Errorf("open function cannot be nil")
+	}
+	return &Router{open: open, opts: opts}, nil
+}
+
+// DestinationResult is the outcome of one destination
+type DestinationResult struct {
+	Destination string           `json:"destination"`
+	Printer     string           `json:"printer"`
+	Report      *executor.Report `json:"report,omitempty"`
+	Error       string           `json:"error,omitempty"`
+
+	err error
+}
+
+// Result combines the outcome of every destination, sorted by name
+type Result struct {
+	Destinations []DestinationResult `json:"destinations"`
+}
+
+// OK reports whether every destination printed without errors
+func (r *Result) OK() bool {
+	for _, d := range r.Destinations {
+		if d.err != nil || d.Report == nil || !d.Report.OK() {
+			return false
+		}
+	}
+	return true
+}
+
+// Err joins the errors of the failed destinations, or returns nil
+func (r *Result) Err() error {
+	var errs []error
+	for _, d := range r.Destinations {
+		if d.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", d.Destination, d.err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Route splits doc with schema.Document.Split and executes every
+// sub-document on its own printer at the same time. A failing destination
+// does not stop the others; the returned error joins all failures.
+func (r *Router) Route(doc *schema.Document) (*Result, error) {
+	if doc == nil {
+		return nil, fmt.Errorf("document is nil")
+	}
+	subs, err := doc.Split()
+	if err != nil {
+		return nil, err
+	}
+
+	names := doc.Destinations()
+	if len(doc.Printers) == 0 {
+		names = []string{""}
+	}
+
+	result := &Result{}
+	for _, name := range names {
+		if sub, ok := subs[name]; ok {
+			result.Destinations = append(result.Destinations, DestinationResult{
+				Destination: name,
+				Printer:     sub.Profile.Model,
+			})
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i := range result.Destinations {
+		wg.Add(1)
+		go func(dest *DestinationResult) {
+			defer wg.Done()
+			dest.Report, dest.err = r.execute(dest.Destination, subs[dest.Destination])
+			if dest.err != nil {
+				dest.Error = dest.err.Error()
+			}
+		}(&result.Destinations[i])
+	}
+	wg.Wait()
+
+	return result, result.Err()
+}
+
+// execute prints one sub-document
+func (r *Router) execute(name string, doc *schema.Document) (*executor.Report, error) {
+	printer, err := r.open(name, doc.Profile)
+	if err != nil {
+		return nil, fmt.Errorf("open printer %s: %w", doc.Profile.Model, err)
+	}
+	defer func() {
+		if err := printer.Close(); err != nil {
+			log.Printf("[ROUTER] failed to close printer for %s: %v", name, err)
+		}
+	}()
+
+	exec := executor.NewExecutor(printer)
+	if r.opts.OnProgress != nil {
+		exec.OnProgress(func(result executor.CommandResult) {
+			r.opts.OnProgress(name, result)
+		})
+	}
+
+	if r.opts.Buffered {
+		return exec.ExecuteBuffered(doc)
+	}
+	return exec.Execute(doc)
+}
@@ -0,0 +1,160 @@
+package router
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+const orderDoc = `{
+	"version": "1.0",
+	"profile": {"model": "POS-80", "paper_width": 80},
+	"printers": {
+		"receipt": {"model": "POS-80"},
+		"kitchen": {"model": "Cocina", "paper_width": 58},
+		"bar": {"model": "Barra", "paper_width": 58}
+	},
+	"commands": [
+		{"type": "text", "data": {"content": {"text": "MESA 4"}}},
+		{"type": "group", "target": "kitchen", "data": {"commands": [
+			{"type": "text", "data": {"content": {"text": "2x TACOS PASTOR"}}},
+			{"type": "text", "data": {"content": {"text": "1x SOPA"}}}
+		]}},
+		{"type": "text", "target": "receipt", "data": {"content": {"text": "TOTAL 120.00"}}},
+		{"type": "cut", "data": {"mode": "partial"}}
+	]
+}`
+
+// printers opens buffer-backed printers and keeps their output by model
+type printers struct {
+	mu     sync.Mutex
+	output map[string]*connection.BufferConnector
+	fail   map[string]bool
+}
+
+func newPrinters() *printers {
+	return &printers{output: make(map[string]*connection.BufferConnector), fail: make(map[string]bool)}
+}
+
+func (p *printers) open(_ string, cfg schema.ProfileConfig) (*service.Printer, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.fail[cfg.Model] {
+		return nil, errors.New("printer offline")
+	}
+	conn := connection.NewBufferConnector()
+	p.output[cfg.Model] = conn
+	return service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), conn)
+}
+
+func parse(t *testing.T, data string) *schema.Document {
+	t.Helper()
+	doc, err := schema.ParseDocument([]byte(data))
+	require.NoError(t, err)
+	return doc
+}
+
+func TestRoute_SplitsByTarget(t *testing.T) {
+	p := newPrinters()
+	r, err := New(p.open, Options{})
+	require.NoError(t, err)
+
+	result, err := r.Route(parse(t, orderDoc))
+	require.NoError(t, err)
+	assert.True(t, result.OK())
+
+	// The bar receives no targeted command, only the shared ones
+	require.Len(t, result.Destinations, 3)
+	assert.Equal(t, "bar", result.Destinations[0].Destination)
+	assert.Len(t, result.Destinations[0].Report.Results, 2)
+	assert.Equal(t, "kitchen", result.Destinations[1].Destination)
+	assert.Equal(t, "Cocina", result.Destinations[1].Printer)
+	assert.Len(t, result.Destinations[1].Report.Results, 4)
+	assert.Equal(t, "receipt", result.Destinations[2].Destination)
+	assert.Len(t, result.Destinations[2].Report.Results, 3)
+
+	kitchen := p.output["Cocina"].Bytes()
+	assert.True(t, bytes.Contains(kitchen, []byte("MESA 4")))
+	assert.True(t, bytes.Contains(kitchen, []byte("TACOS PASTOR")))
+	assert.False(t, bytes.Contains(kitchen, []byte("TOTAL")))
+
+	receipt := p.output["POS-80"].Bytes()
+	assert.True(t, bytes.Contains(receipt, []byte("MESA 4")))
+	assert.True(t, bytes.Contains(receipt, []byte("TOTAL 120.00")))
+	assert.False(t, bytes.Contains(receipt, []byte("TACOS")))
+
+	bar := p.output["Barra"].Bytes()
+	assert.True(t, bytes.Contains(bar, []byte("MESA 4")))
+	assert.False(t, bytes.Contains(bar, []byte("TACOS")))
+	assert.False(t, bytes.Contains(bar, []byte("TOTAL")))
+}
+
+func TestRoute_FailingDestinationDoesNotStopOthers(t *testing.T) {
+	p := newPrinters()
+	p.fail["Cocina"] = true
+
+	var mu sync.Mutex
+	progress := make(map[string]int)
+	r, err := New(p.open, Options{
+		Buffered: true,
+		OnProgress: func(dest string, _ executor.CommandResult) {
+			mu.Lock()
+			progress[dest]++
+			mu.Unlock()
+		},
+	})
+	require.NoError(t, err)
+
+	result, err := r.Route(parse(t, orderDoc))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "kitchen")
+	assert.False(t, result.OK())
+
+	require.Len(t, result.Destinations, 3)
+	assert.True(t, result.Destinations[0].Report.OK())
+	assert.Contains(t, result.Destinations[1].Error, "printer offline")
+	assert.Nil(t, result.Destinations[1].Report)
+	assert.True(t, result.Destinations[2].Report.OK())
+	assert.NotEmpty(t, p.output["POS-80"].Bytes())
+	assert.Equal(t, map[string]int{"bar": 2, "receipt": 3}, progress)
+}
+
+func TestRoute_WithoutPrinters(t *testing.T) {
+	p := newPrinters()
+	r, err := New(p.open, Options{})
+	require.NoError(t, err)
+
+	doc := parse(t, `{"version":"1.0","profile":{"model":"POS-80"},"commands":[{"type":"text","data":{"content":{"text":"Hola"}}}]}`)
+	result, err := r.Route(doc)
+	require.NoError(t, err)
+	require.Len(t, result.Destinations, 1)
+	assert.Equal(t, "", result.Destinations[0].Destination)
+	assert.Equal(t, "POS-80", result.Destinations[0].Printer)
+}
+
+func TestRoute_InvalidTarget(t *testing.T) {
+	r, err := New(newPrinters().open, Options{})
+	require.NoError(t, err)
+
+	doc := parse(t, `{"version":"1.0","profile":{"model":"POS-80"},"printers":{"receipt":{}},
+		"commands":[{"type":"text","target":"bar","data":{"content":{"text":"Hola"}}}]}`)
+	_, err = r.Route(doc)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown target "bar"`)
+}
+
+func TestNew_RequiresOpen(t *testing.T) {
+	_, err := New(nil, Options{})
+	assert.Error(t, err)
+}
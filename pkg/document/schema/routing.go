@@ -0,0 +1,186 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/adcondev/poster/pkg/constants"
+)
+
+// GroupCommand agrupa comandos que comparten target y on_error
+type GroupCommand struct {
+	Commands []Command `json:"commands"`
+}
+
+// Destinations returns the names declared in Printers, sorted
+func (d *Document) Destinations() []string {
+	names := make([]string, 0, len(d.Printers))
+	for name := range d.Printers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DestinationProfile returns the profile of a destination. Fields left
+// empty in Printers[name] are inherited from Document.Profile; utf8 can be
+// set to false to turn it off for one destination, has_qr cannot.
+func (d *Document) DestinationProfile(name string) (ProfileConfig, bool) {
+	p, ok := d.Printers[name]
+	if !ok {
+		return ProfileConfig{}, false
+	}
+
+	if p.Model == "" {
+		p.Model = d.Profile.Model
+	}
+	if p.PaperWidth == 0 {
+		p.PaperWidth = d.Profile.PaperWidth
+	}
+	if p.CodeTable == "" {
+		p.CodeTable = d.Profile.CodeTable
+	}
+	if len(p.AutoCodeTables) == 0 {
+		p.AutoCodeTables = d.Profile.AutoCodeTables
+	}
+	if p.UTF8 == nil {
+		p.UTF8 = d.Profile.UTF8
+	}
+	if p.Bidi == nil {
+		p.Bidi = d.Profile.Bidi
+	}
+	if p.Unencodable == "" {
+		p.Unencodable = d.Profile.Unencodable
+	}
+	if p.Replacement == "" {
+		p.Replacement = d.Profile.Replacement
+	}
+	if p.DPI == 0 {
+		p.DPI = d.Profile.DPI
+	}
+	if !p.HasQR {
+		p.HasQR = d.Profile.HasQR
+	}
+	return p, true
+}
+
+// Flatten expands group commands into their children. Children inherit the
+// target and on_error of their group unless they set their own.
+func (d *Document) Flatten() ([]Command, error) {
+	return flatten(d.Commands, "", "")
+}
+
+func flatten(commands []Command, target, onError string) ([]Command, error) {
+	var out []Command
+	for i, cmd := range commands {
+		if cmd.Target == "" {
+			cmd.Target = target
+		}
+		if cmd.OnError == "" {
+			cmd.OnError = onError
+		}
+
+		if cmd.Type != "group" {
+			out = append(out, cmd)
+			continue
+		}
+
+		var group GroupCommand
+		if err := json.Unmarshal(cmd.Data, &group); err != nil {
+			return nil, fmt.Errorf("command %d (group): %w", i, err)
+		}
+		children, err := flatten(group.Commands, cmd.Target, cmd.OnError)
+		if err != nil {
+			return nil, fmt.Errorf("command %d (group): %w", i, err)
+		}
+		out = append(out, children...)
+	}
+	return out, nil
+}
+
+// validateRouting checks the destination profiles, the group commands and
+// that every target refers to a declared destination
+func (d *Document) validateRouting() error {
+	for _, name := range d.Destinations() {
+		if name == "" {
+			return fmt.Errorf("printers: destination name cannot be empty")
+		}
+		p, _ := d.DestinationProfile(name)
+		if p.Model == "" {
+			return fmt.Errorf("printers.%s: model is required", name)
+		}
+		if p.PaperWidth != 0 && !isValidPaperWidth(p.PaperWidth) {
+			return fmt.Errorf("printers.%s: invalid paper_width: %d (valid values: %v)",
+				name, p.PaperWidth, constants.ValidPaperWidths)
+		}
+		if p.DPI != 0 && !isValidDPI(p.DPI) {
+			return fmt.Errorf("printers.%s: invalid dpi: %d (valid values: %v)",
+				name, p.DPI, constants.ValidDPIs)
+		}
+	}
+
+	commands, err := d.Flatten()
+	if err != nil {
+		return err
+	}
+	for i, cmd := range commands {
+		if cmd.OnError != "" && !IsValidErrorPolicy(cmd.OnError) {
+			return fmt.Errorf("command %d (%s): invalid on_error: %s (valid values: %v)",
+				i, cmd.Type, cmd.OnError, validErrorPolicies)
+		}
+		if cmd.Target == "" {
+			continue
+		}
+		if _, ok := d.Printers[cmd.Target]; !ok {
+			return fmt.Errorf("command %d (%s): unknown target %q", i, cmd.Type, cmd.Target)
+		}
+	}
+	return nil
+}
+
+// Split divides the document into one sub-document per destination.
+// Commands with a target go only to that destination; commands without one
+// go to every destination. A document without printers is returned whole
+// under the empty name.
+func (d *Document) Split() (map[string]*Document, error) {
+	if err := d.Validate(); err != nil {
+		return nil, err
+	}
+	commands, err := d.Flatten()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(d.Printers) == 0 {
+		sub := d.derive(d.Profile)
+		sub.Commands = commands
+		return map[string]*Document{"": sub}, nil
+	}
+
+	out := make(map[string]*Document)
+	for _, name := range d.Destinations() {
+		profile, _ := d.DestinationProfile(name)
+		sub := d.derive(profile)
+		for _, cmd := range commands {
+			if cmd.Target == "" || cmd.Target == name {
+				cmd.Target = ""
+				sub.Commands = append(sub.Commands, cmd)
+			}
+		}
+		out[name] = sub
+	}
+	return out, nil
+}
+
+// derive copies the document settings without printers or commands
+func (d *Document) derive(profile ProfileConfig) *Document {
+	return &Document{
+		Version:   d.Version,
+		Profile:   profile,
+		DebugLog:  d.DebugLog,
+		OnError:   d.OnError,
+		OnUnknown: d.OnUnknown,
+		Glyphs:    d.Glyphs,
+	}
+}
@@ -5,18 +5,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"strings"
 
+	"github.com/adcondev/poster/pkg/commands/character"
 	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/profile"
 )
 
 // TODO: Define all_mayus y all_bold options for commands
 
 // Document representa un documento de impresión completo
 type Document struct {
-	Version  string        `json:"version"`             // Requerido: >1.0
-	Profile  ProfileConfig `json:"profile"`             // Requerido: profile.model
-	DebugLog bool          `json:"debug_log,omitempty"` // Default: false
-	Commands []Command     `json:"commands"`            // Requerido: len > 0
+	Version   string                   `json:"version"`              // Requerido: >1.0
+	Profile   ProfileConfig            `json:"profile"`              // Requerido: profile.model
+	DebugLog  bool                     `json:"debug_log,omitempty"`  // Default: false
+	OnError   string                   `json:"on_error,omitempty"`   // Default: abort
+	OnUnknown string                   `json:"on_unknown,omitempty"` // Default: skip
+	Printers  map[string]ProfileConfig `json:"printers,omitempty"`   // Destinos para Command.Target
+	Glyphs    map[string]Glyph         `json:"glyphs,omitempty"`     // Caracteres definidos por el usuario
+	Commands  []Command                `json:"commands"`             // Requerido: len > 0
 }
 
 // ToJSON convierte el documento a JSON
@@ -26,24 +33,49 @@ func (d *Document) ToJSON() ([]byte, error) {
 
 // ProfileConfig configuración del perfil de impresora
 type ProfileConfig struct {
-	Model      string `json:"model"`                 // Requerido
-	PaperWidth int    `json:"paper_width,omitempty"` // Default: 80
-	CodeTable  string `json:"code_table,omitempty"`  // Default: WPC1252
-	DPI        int    `json:"dpi,omitempty"`         // Default: 203
-	HasQR      bool   `json:"has_qr,omitempty"`      // Default: false
+	Model          string   `json:"model"`                      // Requerido
+	PaperWidth     int      `json:"paper_width,omitempty"`      // Default: 80
+	CodeTable      string   `json:"code_table,omitempty"`       // Default: WPC1252
+	AutoCodeTables []string `json:"auto_code_tables,omitempty"` // Prioridad del cambio automático de tabla
+	UTF8           *bool    `json:"utf8,omitempty"`             // Texto UTF-8 nativo si el perfil lo soporta
+	Bidi           *Bidi    `json:"bidi,omitempty"`             // Texto hebreo y árabe en orden visual
+	Unencodable    string   `json:"unencodable,omitempty"`      // Default: error (replace, transliterate)
+	Replacement    string   `json:"replacement,omitempty"`      // Default: "?"
+	DPI            int      `json:"dpi,omitempty"`              // Default: 203
+	HasQR          bool     `json:"has_qr,omitempty"`           // Default: false
+}
+
+// Bidi enables the bidirectional step for Hebrew and Arabic text: lines are
+// shaped and reordered before encoding
+type Bidi struct {
+	AlignRight   bool `json:"align_right,omitempty"`   // Alinea a la derecha párrafos RTL sin align
+	MirrorTables bool `json:"mirror_tables,omitempty"` // Invierte el orden de columnas en tablas con texto RTL
+}
+
+// Glyph is a user-defined character (ESC &) drawn from a small image or a
+// pixel grid and scaled to the font cell. Text references it as :name:.
+type Glyph struct {
+	Code      string   `json:"code,omitempty"`      // Imagen en base64 (PNG, JPG)
+	Bitmap    []string `json:"bitmap,omitempty"`    // Filas de píxeles: '#' negro, cualquier otro blanco
+	Threshold byte     `json:"threshold,omitempty"` // Default: 128
 }
 
 // TODO: Define an order field for reordering or grouping commands. Check if it's worth it.
 
 // Command represents a single command in the document
 type Command struct {
-	Type string          `json:"type"` // Tipo de comando
-	Data json.RawMessage `json:"data"` // Datos específicos del comando
+	Type    string          `json:"type"`               // Tipo de comando
+	Data    json.RawMessage `json:"data"`               // Datos específicos del comando
+	OnError string          `json:"on_error,omitempty"` // Sobrescribe Document.OnError
+	Target  string          `json:"target,omitempty"`   // Destino en Document.Printers
 }
 
 // Version pattern: X.Y where X and Y are digits
 var versionPattern = regexp.MustCompile(`^\d+\.\d+$`)
 
+// GlyphNamePattern matches glyph names, referenced in text as :name:
+var GlyphNamePattern = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
 // Validate checks the document structure and returns an error if invalid
 func (d *Document) Validate() error {
 	if d.Version == "" {
@@ -68,13 +100,90 @@ func (d *Document) Validate() error {
 			d.Profile.DPI, constants.ValidDPIs)
 	}
 
+	if _, err := profile.ParseUnencodable(d.Profile.Unencodable); err != nil {
+		return fmt.Errorf("invalid profile.unencodable: %w", err)
+	}
+
+	if d.OnError != "" && !IsValidErrorPolicy(d.OnError) {
+		return fmt.Errorf("invalid on_error: %s (valid values: %v)", d.OnError, validErrorPolicies)
+	}
+
+	if d.OnUnknown != "" && !IsValidErrorPolicy(d.OnUnknown) {
+		return fmt.Errorf("invalid on_unknown: %s (valid values: %v)", d.OnUnknown, validErrorPolicies)
+	}
+
 	if len(d.Commands) == 0 {
 		return fmt.Errorf("document must contain at least one command")
 	}
 
+	if err := d.validateGlyphs(); err != nil {
+		return err
+	}
+
+	for i, cmd := range d.Commands {
+		if cmd.OnError != "" && !IsValidErrorPolicy(cmd.OnError) {
+			return fmt.Errorf("command %d (%s): invalid on_error: %s (valid values: %v)",
+				i, cmd.Type, cmd.OnError, validErrorPolicies)
+		}
+	}
+
+	return d.validateRouting()
+}
+
+// validateGlyphs checks glyph names and that each glyph has an image or a
+// bitmap; ESC & takes at most one glyph per code from 32 to 126
+func (d *Document) validateGlyphs() error {
+	if limit := int(character.UserDefinedMaxCode-character.UserDefinedMinCode) + 1; len(d.Glyphs) > limit {
+		return fmt.Errorf("too many glyphs: %d (max %d)", len(d.Glyphs), limit)
+	}
+	for name, glyph := range d.Glyphs {
+		if !GlyphNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid glyph name: %q (expected %s)", name, GlyphNamePattern)
+		}
+		if (glyph.Code == "") == (len(glyph.Bitmap) == 0) {
+			return fmt.Errorf("glyph %s: exactly one of code or bitmap is required", name)
+		}
+	}
 	return nil
 }
 
+var validErrorPolicies = []constants.ErrorPolicy{
+	constants.Abort,
+	constants.Skip,
+	constants.Placeholder,
+}
+
+// IsValidErrorPolicy reports whether policy is one of abort, skip or placeholder
+func IsValidErrorPolicy(policy string) bool {
+	for _, valid := range validErrorPolicies {
+		if strings.ToLower(policy) == valid.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrorPolicyFor resolves the effective error policy for a command:
+// command override, then document setting, then the library default.
+func (d *Document) ErrorPolicyFor(cmd Command) constants.ErrorPolicy {
+	switch {
+	case cmd.OnError != "":
+		return constants.ErrorPolicy(strings.ToLower(cmd.OnError))
+	case d.OnError != "":
+		return constants.ErrorPolicy(strings.ToLower(d.OnError))
+	default:
+		return constants.DefaultErrorPolicy
+	}
+}
+
+// UnknownPolicy resolves the policy applied to commands with an unregistered type
+func (d *Document) UnknownPolicy() constants.ErrorPolicy {
+	if d.OnUnknown != "" {
+		return constants.ErrorPolicy(strings.ToLower(d.OnUnknown))
+	}
+	return constants.DefaultUnknownPolicy
+}
+
 func isValidPaperWidth(width int) bool {
 	for _, valid := range constants.ValidPaperWidths {
 		if width == valid {
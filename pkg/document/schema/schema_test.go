@@ -2,6 +2,7 @@ package schema
 
 import (
 	"encoding/json"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -310,6 +311,70 @@ func TestDocument_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+
+		// Error policy tests
+		{
+			name: "valid document error policies",
+			doc: Document{
+				Version:   "1.0",
+				Profile:   ProfileConfig{Model: "TestPrinter"},
+				OnError:   "skip",
+				OnUnknown: "Placeholder",
+				Commands:  []Command{{Type: "qr", Data: json.RawMessage(`{}`), OnError: "abort"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid document on_error",
+			doc: Document{
+				Version:  "1.0",
+				Profile:  ProfileConfig{Model: "TestPrinter"},
+				OnError:  "retry",
+				Commands: []Command{{Type: "text", Data: json.RawMessage(`{}`)}},
+			},
+			wantErr: true,
+			errMsg:  "invalid on_error",
+		},
+		{
+			name: "invalid on_unknown",
+			doc: Document{
+				Version:   "1.0",
+				Profile:   ProfileConfig{Model: "TestPrinter"},
+				OnUnknown: "ignore",
+				Commands:  []Command{{Type: "text", Data: json.RawMessage(`{}`)}},
+			},
+			wantErr: true,
+			errMsg:  "invalid on_unknown",
+		},
+		{
+			name: "invalid command on_error",
+			doc: Document{
+				Version:  "1.0",
+				Profile:  ProfileConfig{Model: "TestPrinter"},
+				Commands: []Command{{Type: "qr", Data: json.RawMessage(`{}`), OnError: "later"}},
+			},
+			wantErr: true,
+			errMsg:  "command 0 (qr): invalid on_error",
+		},
+		{
+			name: "valid unencodable policy",
+			doc: Document{
+				Version:  "1.0",
+				Profile:  ProfileConfig{Model: "TestPrinter", Unencodable: "transliterate", Replacement: "_"},
+				Commands: []Command{{Type: "text", Data: json.RawMessage(`{}`)}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid unencodable policy",
+			doc: Document{
+				Version:  "1.0",
+				Profile:  ProfileConfig{Model: "TestPrinter", Unencodable: "drop"},
+				Commands: []Command{{Type: "text", Data: json.RawMessage(`{}`)}},
+			},
+			wantErr: true,
+			errMsg:  "invalid profile.unencodable",
+		},
 	}
 
 	for _, tt := range tests {
@@ -347,6 +412,212 @@ func TestDocument_Validate_MultipleErrors(t *testing.T) {
 	}
 }
 
+func TestDocument_ErrorPolicyFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		docLevel string
+		cmdLevel string
+		want     constants.ErrorPolicy
+	}{
+		{"library default", "", "", constants.Abort},
+		{"document level", "skip", "", constants.Skip},
+		{"command overrides document", "skip", "placeholder", constants.Placeholder},
+		{"case insensitive", "", "SKIP", constants.Skip},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Document{OnError: tt.docLevel}
+			got := doc.ErrorPolicyFor(Command{Type: "text", OnError: tt.cmdLevel})
+			if got != tt.want {
+				t.Errorf("ErrorPolicyFor() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocument_UnknownPolicy(t *testing.T) {
+	doc := Document{}
+	if got := doc.UnknownPolicy(); got != constants.Skip {
+		t.Errorf("UnknownPolicy() default = %s, want %s", got, constants.Skip)
+	}
+
+	doc.OnUnknown = "abort"
+	if got := doc.UnknownPolicy(); got != constants.Abort {
+		t.Errorf("UnknownPolicy() = %s, want %s", got, constants.Abort)
+	}
+}
+
+func routedDocument() Document {
+	return Document{
+		Version: "1.0",
+		Profile: ProfileConfig{Model: "POS-80", PaperWidth: 80, CodeTable: "PC850"},
+		Printers: map[string]ProfileConfig{
+			"receipt": {},
+			"kitchen": {Model: "Cocina", PaperWidth: 58},
+			"bar":     {Model: "Barra"},
+		},
+		Commands: []Command{
+			{Type: "text", Data: json.RawMessage(`{"content":{"text":"Mesa 4"}}`)},
+			{Type: "group", Target: "kitchen", OnError: "skip", Data: json.RawMessage(
+				`{"commands":[{"type":"text","data":{}},{"type":"feed","target":"receipt","data":{}}]}`)},
+			{Type: "text", Target: "receipt", Data: json.RawMessage(`{}`)},
+			{Type: "cut", Data: json.RawMessage(`{}`)},
+		},
+	}
+}
+
+func TestDocument_DestinationProfile(t *testing.T) {
+	doc := routedDocument()
+
+	got, ok := doc.DestinationProfile("kitchen")
+	if !ok {
+		t.Fatal("Expected kitchen destination")
+	}
+	want := ProfileConfig{Model: "Cocina", PaperWidth: 58, CodeTable: "PC850"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DestinationProfile() = %+v, want %+v", got, want)
+	}
+
+	got, _ = doc.DestinationProfile("receipt")
+	if got.Model != "POS-80" || got.PaperWidth != 80 {
+		t.Errorf("Expected receipt to inherit the document profile, got %+v", got)
+	}
+
+	if _, ok := doc.DestinationProfile("patio"); ok {
+		t.Error("Expected unknown destination")
+	}
+
+	// utf8 false turns off the document setting for one destination
+	on, off := true, false
+	doc.Profile.UTF8 = &on
+	doc.Printers["bar"] = ProfileConfig{Model: "Barra", UTF8: &off}
+	if got, _ := doc.DestinationProfile("receipt"); got.UTF8 == nil || !*got.UTF8 {
+		t.Errorf("Expected receipt to inherit utf8, got %v", got.UTF8)
+	}
+	if got, _ := doc.DestinationProfile("bar"); got.UTF8 == nil || *got.UTF8 {
+		t.Errorf("Expected bar to turn utf8 off, got %v", got.UTF8)
+	}
+}
+
+func TestDocument_Split(t *testing.T) {
+	doc := routedDocument()
+
+	subs, err := doc.Split()
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(subs) != 3 {
+		t.Fatalf("Expected every destination, got %d", len(subs))
+	}
+
+	types := func(d *Document) string {
+		var out []string
+		for _, cmd := range d.Commands {
+			if cmd.Target != "" {
+				t.Errorf("Expected target to be cleared, got %q", cmd.Target)
+			}
+			out = append(out, cmd.Type)
+		}
+		return strings.Join(out, ",")
+	}
+
+	kitchen := subs["kitchen"]
+	if got := types(kitchen); got != "text,text,cut" {
+		t.Errorf("kitchen commands = %s", got)
+	}
+	if kitchen.Commands[1].OnError != "skip" {
+		t.Errorf("Expected group on_error to be inherited, got %q", kitchen.Commands[1].OnError)
+	}
+	if kitchen.Profile.Model != "Cocina" || kitchen.Printers != nil {
+		t.Errorf("Unexpected kitchen document: %+v", kitchen)
+	}
+
+	// The feed inside the kitchen group overrides its target
+	if got := types(subs["receipt"]); got != "text,feed,text,cut" {
+		t.Errorf("receipt commands = %s", got)
+	}
+
+	// Nothing targets the bar, so it gets only the untargeted commands
+	if got := types(subs["bar"]); got != "text,cut" {
+		t.Errorf("bar commands = %s", got)
+	}
+}
+
+func TestDocument_Split_NothingTargeted(t *testing.T) {
+	doc := routedDocument()
+	doc.Commands = doc.Commands[:1]
+
+	subs, err := doc.Split()
+	if err != nil {
+		t.Fatalf("Split() error = %v", err)
+	}
+	if len(subs) != 3 {
+		t.Errorf("Expected every destination, got %d", len(subs))
+	}
+}
+
+func TestDocument_Validate_Routing(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Document)
+		errMsg string
+	}{
+		{"unknown target", func(d *Document) { d.Commands[2].Target = "patio" }, `unknown target "patio"`},
+		{"target without printers", func(d *Document) { d.Printers = nil }, `unknown target "kitchen"`},
+		{"invalid destination width", func(d *Document) { d.Printers["bar"] = ProfileConfig{PaperWidth: 33} }, "printers.bar: invalid paper_width"},
+		{"malformed group", func(d *Document) { d.Commands[1].Data = json.RawMessage(`[]`) }, "command 1 (group)"},
+		{"invalid nested on_error", func(d *Document) {
+			d.Commands[1].Data = json.RawMessage(`{"commands":[{"type":"text","on_error":"retry","data":{}}]}`)
+		}, "invalid on_error: retry"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := routedDocument()
+			tt.modify(&doc)
+			err := doc.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestDocument_Validate_Glyphs(t *testing.T) {
+	tests := []struct {
+		name   string
+		glyphs map[string]Glyph
+		errMsg string
+	}{
+		{"valid", map[string]Glyph{"leaf": {Bitmap: []string{".#."}}, "star_1": {Code: "iVBORw0KGgo="}}, ""},
+		{"invalid name", map[string]Glyph{"Leaf:": {Bitmap: []string{"#"}}}, "invalid glyph name"},
+		{"no image", map[string]Glyph{"leaf": {}}, "exactly one of code or bitmap"},
+		{"both images", map[string]Glyph{"leaf": {Code: "x", Bitmap: []string{"#"}}}, "exactly one of code or bitmap"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := Document{
+				Version:  "1.0",
+				Profile:  ProfileConfig{Model: "TestPrinter"},
+				Glyphs:   tt.glyphs,
+				Commands: []Command{{Type: "text", Data: json.RawMessage(`{}`)}},
+			}
+			err := doc.Validate()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("Validate() error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
 func BenchmarkDocument_Validate(b *testing.B) {
 	doc := Document{
 		Version: "1.0",
@@ -5,6 +5,7 @@ import (
 	"image/color"
 	"image/draw"
 	"math"
+	"strings"
 
 	"github.com/adcondev/poster/pkg/constants"
 )
@@ -20,6 +21,31 @@ type DynamicCanvas struct {
 	width  int
 	height int
 	maxY   float64
+	ops    []vectorOp // Drawing operations replayed by WriteSVG and WritePDF
+}
+
+// vectorKind is the kind of a recorded drawing operation
+type vectorKind int
+
+const (
+	vectorRect vectorKind = iota
+	vectorLine
+	vectorText
+	vectorImage
+)
+
+// vectorOp is a drawing operation recorded alongside the raster canvas, so
+// the vector outputs come from the same rendering state as the PNG
+type vectorOp struct {
+	kind       vectorKind
+	x, y, w, h float64 // Box of rects, images and lines (h is the thickness); text starts at x on baseline y
+	col        color.Color
+	dash, gap  float64 // Dashed lines only
+	text       string
+	cell       float64 // Width of one character cell
+	size       float64 // Font em size
+	bold       bool
+	img        image.Image
 }
 
 // NewDynamicCanvas creates a new canvas with the specified width
@@ -93,16 +119,27 @@ func (dc *DynamicCanvas) Crop() image.Image {
 		return image.NewRGBA(image.Rect(0, 0, dc.width, 1))
 	}
 
-	finalHeight := int(math.Ceil(dc.maxY)) + constants.BottomPadding
-
 	// Create final image of exact size
-	rect := image.Rect(0, 0, dc.width, finalHeight)
+	rect := image.Rect(0, 0, dc.width, dc.contentHeight())
 	dst := image.NewRGBA(rect)
 
 	draw.Draw(dst, rect, dc.img, image.Point{}, draw.Src)
 	return dst
 }
 
+// contentHeight returns the height of the cropped output
+func (dc *DynamicCanvas) contentHeight() int {
+	if dc.maxY == 0 {
+		return 1
+	}
+	return int(math.Ceil(dc.maxY)) + constants.BottomPadding
+}
+
+// record appends a drawing operation for the vector outputs
+func (dc *DynamicCanvas) record(op vectorOp) {
+	dc.ops = append(dc.ops, op)
+}
+
 // DrawRect draws a filled rectangle
 func (dc *DynamicCanvas) DrawRect(x, y, w, h int, col color.Color) {
 	if w <= 0 || h <= 0 {
@@ -122,6 +159,9 @@ func (dc *DynamicCanvas) DrawRect(x, y, w, h int, col color.Color) {
 			dc.img.Set(x+dx, y+dy, col)
 		}
 	}
+	if w > 0 {
+		dc.record(vectorOp{kind: vectorRect, x: float64(x), y: float64(y), w: float64(w), h: float64(h), col: col})
+	}
 	dc.UpdateMaxY(float64(y + h))
 }
 
@@ -133,6 +173,9 @@ func (dc *DynamicCanvas) DrawLine(x1, y, x2 int, thickness int, col color.Color)
 			dc.img.Set(x, y+t, col)
 		}
 	}
+	if x2 >= x1 && thickness > 0 {
+		dc.record(vectorOp{kind: vectorLine, x: float64(x1), y: float64(y), w: float64(x2 - x1 + 1), h: float64(thickness), col: col})
+	}
 	dc.UpdateMaxY(float64(y + thickness))
 }
 
@@ -163,6 +206,12 @@ func (dc *DynamicCanvas) DrawDashedLine(x1, y, x2 int, dashLen, gapLen, thicknes
 			segmentLen = gapLen
 		}
 	}
+	if x2 >= x1 && thickness > 0 {
+		dc.record(vectorOp{
+			kind: vectorLine, x: float64(x1), y: float64(y), w: float64(x2 - x1 + 1), h: float64(thickness), col: col,
+			dash: float64(dashLen), gap: float64(gapLen),
+		})
+	}
 	dc.UpdateMaxY(float64(y + thickness))
 }
 
@@ -171,6 +220,28 @@ func (dc *DynamicCanvas) Set(x, y int, col color.Color) {
 	if x >= 0 && x < dc.width && y >= 0 {
 		dc.EnsureHeight(float64(y + 1))
 		dc.img.Set(x, y, col)
+		dc.record(vectorOp{kind: vectorRect, x: float64(x), y: float64(y), w: 1, h: 1, col: col})
 		dc.UpdateMaxY(float64(y + 1))
 	}
 }
+
+// DrawImage composites img over the canvas with its top-left corner at x, y
+func (dc *DynamicCanvas) DrawImage(img image.Image, x, y int) {
+	bounds := img.Bounds()
+	dc.EnsureHeight(float64(y + bounds.Dy()))
+	dst := image.Rect(x, y, x+bounds.Dx(), y+bounds.Dy())
+	draw.Draw(dc.img, dst, img, bounds.Min, draw.Over)
+	dc.record(vectorOp{kind: vectorImage, x: float64(x), y: float64(y), w: float64(bounds.Dx()), h: float64(bounds.Dy()), img: img})
+	dc.UpdateMaxY(float64(y + bounds.Dy()))
+}
+
+// DrawText records a run of text drawn by the text renderer. The glyphs
+// themselves are rasterized by the FontManager; text starts at x on
+// baseline y, each character cell is cell pixels wide and size is the em
+// size of the font.
+func (dc *DynamicCanvas) DrawText(text string, x, y, cell, size float64, bold bool, col color.Color) {
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	dc.record(vectorOp{kind: vectorText, x: x, y: y, text: text, cell: cell, size: size, bold: bold, col: col})
+}
@@ -1,7 +1,9 @@
 package emulator
 
 import (
+	"github.com/adcondev/poster/pkg/commands/character"
 	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/profile"
 )
 
 // Config holds configuration for the emulator engine
@@ -17,6 +19,18 @@ type Config struct {
 	// previous content.  Default:  true for ESC/POS-like behavior.
 	// Set to false for explicit cursor control.
 	AutoAdjustCursorOnScale bool
+
+	// CodePages maps the ESC t numbers of models that number their code
+	// tables differently (profile.Escpos.CodePages) to the standard tables
+	CodePages map[byte]character.CodeTable
+
+	// DoubleByte is the character set of Kanji mode (profile.Escpos.DoubleByte).
+	// Empty means GB18030, or Shift JIS once FS C selects it.
+	DoubleByte profile.DoubleByte
+
+	// FallbackFontPath is a TTF/OTF file with the glyphs missing from the
+	// embedded fonts, e.g. Noto Sans CJK (profile.Escpos.FallbackFont)
+	FallbackFontPath string
 }
 
 // DefaultConfig returns a default configuration for 80mm paper at 203 DPI
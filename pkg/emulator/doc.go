@@ -2,7 +2,8 @@
 Package emulator provides a high-fidelity thermal printer emulation engine for Go.
 
 It generates images (PNG) that accurately represent how a receipt would look
-when printed on standard ESC/POS thermal printers (58mm and 80mm).
+when printed on standard ESC/POS thermal printers (58mm and 80mm), and the
+same render as SVG or PDF.
 
 # Core Features
 
@@ -11,6 +12,7 @@ when printed on standard ESC/POS thermal printers (58mm and 80mm).
   - ESC/POS Styling: Bold, Underline, Inverse, Double Width/Height (1x-8x), and Justification
   - Image Embedding: Embed images with optional thermal preview simulation
   - Bitmap Fallback: Basic 5x7 bitmap font when TrueType fonts are unavailable
+  - Vector Output: SVG and single-page PDF with searchable text
 
 # Basic Usage
 
@@ -101,5 +103,34 @@ Disable auto-adjustment for manual cursor control:
 	config := emulator.DefaultConfig()
 	config.AutoAdjustCursorOnScale = false
 	eng, _ := emulator.NewEngine(config)
+
+# Replaying Compiled Jobs
+
+Replay interprets the ESC/POS bytes produced by executor.Compile, so a
+document can be previewed exactly as it would be sent to the printer:
+
+	job, _ := executor.NewExecutor(printer).Compile(doc)
+	eng, _ := emulator.NewDefaultEngine()
+	if err := eng.Replay(job); err != nil {
+		log.Fatal(err)
+	}
+	eng.WritePNG(f)
+
+Barcodes are drawn as a non-scannable stand-in with their human readable text.
+
+# Vector Output
+
+Every drawing operation is recorded alongside the canvas, so WriteSVG and
+WritePDF produce the same receipt as WritePNG, with the size RenderWithInfo
+reports. Text is written as text, rules as lines and images as embedded
+PNG (SVG) or RGB image objects (PDF):
+
+	eng.WriteSVG(svgFile) // viewBox in pixels, width and height in mm
+	eng.WritePDF(pdfFile) // one page: paper width x content length
+
+The PDF uses the standard Courier fonts with WinAnsi encoding, so no font is
+embedded and no external tool is needed; characters outside Windows-1252
+are written as '?'. The SVG asks for a monospaced font and stretches each
+run to its character cells.
 */
 package emulator
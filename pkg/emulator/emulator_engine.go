@@ -67,6 +67,11 @@ func NewEngine(config Config) (*Engine, error) {
 	if fonts.UseFallback() {
 		log.Printf("[Emulator] Using bitmap fallback renderer")
 	}
+	if config.FallbackFontPath != "" {
+		if err := fonts.LoadFallbackFont(config.FallbackFontPath); err != nil {
+			log.Printf("[Emulator] Warning: fallback font failed to load: %v", err)
+		}
+	}
 
 	// Create printer state
 	state := NewPrinterState(config.PaperPxWidth)
@@ -368,6 +373,26 @@ func (e *Engine) WritePNG(w io.Writer) error {
 	return png.Encode(w, img)
 }
 
+// WriteSVG writes the receipt as SVG: text as text, rules as lines and
+// images embedded as PNG. It has the size of the image RenderWithInfo
+// returns, in pixels, and the physical size of the paper at the engine DPI.
+func (e *Engine) WriteSVG(w io.Writer) error {
+	if e.debug {
+		log.Printf("[Emulator] Writing SVG output (%d operations)", len(e.canvas.ops))
+	}
+	return writeSVG(w, e.canvas, e.state.DPI)
+}
+
+// WritePDF writes the receipt as a single-page PDF sized to the paper width
+// and the content length. Text is searchable; characters outside
+// Windows-1252 are written as '?'.
+func (e *Engine) WritePDF(w io.Writer) error {
+	if e.debug {
+		log.Printf("[Emulator] Writing PDF output (%d operations)", len(e.canvas.ops))
+	}
+	return writePDF(w, e.canvas, e.state.DPI)
+}
+
 // ============================================================================
 // State Access
 // ============================================================================
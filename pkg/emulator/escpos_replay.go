@@ -0,0 +1,890 @@
+package emulator
+
+import (
+	"fmt"
+	"image/color"
+	"log"
+	"strings"
+
+	"golang.org/x/text/encoding/japanese"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/commands/qrcode"
+	"github.com/adcondev/poster/pkg/commands/shared"
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/graphics"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+// Control bytes handled by the replayer that are not defined in shared
+const (
+	lf  byte = 0x0A
+	cr  byte = 0x0D
+	dle byte = 0x10
+)
+
+// Barcode and QR power-on defaults (GS h, GS w, GS ( k fn 67)
+const (
+	defaultBarcodeHeight = 162
+	defaultBarcodeModule = 3
+	defaultQRModule      = 3
+)
+
+// replaySegment is a run of text printed with a single style
+type replaySegment struct {
+	text  string
+	style PrinterState
+	glyph *graphics.MonochromeBitmap // User-defined character, text is one cell
+}
+
+// replayer interprets a compiled ESC/POS job on an Engine
+type replayer struct {
+	e    *Engine
+	data []byte
+	pos  int
+
+	codeTable character.CodeTable
+	utf8      bool            // Text is UTF-8 (FS ( C), ESC t is ignored
+	kanji     bool            // Kanji mode (FS &): double-byte text
+	jisCode   *bool           // Kanji code system selected with FS C: JIS or Shift JIS
+	pending   []byte          // Encoded text not yet decoded
+	line      []replaySegment // Decoded text of the current line
+
+	userChars   map[string]map[byte]*graphics.MonochromeBitmap // ESC & glyphs by font name and code
+	userDefined bool                                           // User-defined set selected (ESC % 1)
+
+	// Barcode and QR parameters set by configuration commands
+	barcodeHeight int
+	barcodeModule int
+	hriPosition   byte
+	qrModule      int
+	qrEC          qrcode.ErrorCorrection
+	qrData        []byte
+}
+
+// Replay interprets a compiled ESC/POS job (as produced by executor.Compile)
+// and renders it on the emulated receipt.
+//
+// Text, alignment, emphasis, underline, reverse, fonts, character size,
+// feeds, cuts, raster images, user-defined characters and native QR codes
+// are rendered. Barcodes are drawn as a non-scannable stand-in with their
+// human readable text.
+// Commands without a visual effect (drawer kick, beeper, status requests)
+// are skipped.
+func (e *Engine) Replay(job []byte) error {
+	r := &replayer{
+		e:             e,
+		data:          job,
+		codeTable:     character.PC437,
+		barcodeHeight: defaultBarcodeHeight,
+		barcodeModule: defaultBarcodeModule,
+		qrModule:      defaultQRModule,
+		qrEC:          qrcode.LevelM,
+	}
+	if err := r.run(); err != nil {
+		return err
+	}
+	// Text left in the buffer is printed like a printer would on LF
+	if r.flushText(); len(r.line) > 0 {
+		r.printLine()
+		r.lineFeed(true)
+	}
+	return nil
+}
+
+func (r *replayer) run() error {
+	for r.pos < len(r.data) {
+		b := r.data[r.pos]
+		r.pos++
+
+		switch {
+		case b == lf:
+			r.flushText()
+			printed := r.printLine()
+			r.lineFeed(printed)
+		case b == shared.ESC:
+			if err := r.esc(); err != nil {
+				return err
+			}
+		case b == shared.GS:
+			if err := r.gs(); err != nil {
+				return err
+			}
+		case b == shared.FS:
+			if err := r.fs(); err != nil {
+				return err
+			}
+		case b == dle:
+			if err := r.dle(); err != nil {
+				return err
+			}
+		case b == shared.HT:
+			r.pending = append(r.pending, ' ')
+		case b == cr || b < 0x20:
+			// Carriage return and other control bytes have no visual effect
+		default:
+			r.pending = append(r.pending, b)
+		}
+	}
+	return nil
+}
+
+// take returns the next n parameter bytes of the command starting at start
+func (r *replayer) take(n int, start int) ([]byte, error) {
+	if r.pos+n > len(r.data) {
+		return nil, fmt.Errorf("truncated command at offset %d", start)
+	}
+	params := r.data[r.pos : r.pos+n]
+	r.pos += n
+	return params, nil
+}
+
+// skip discards n parameter bytes
+func (r *replayer) skip(n int, start int) error {
+	_, err := r.take(n, start)
+	return err
+}
+
+// esc handles ESC commands
+func (r *replayer) esc() error {
+	start := r.pos - 1
+	op, err := r.take(1, start)
+	if err != nil {
+		return err
+	}
+	r.flushText()
+	state := r.e.state
+
+	switch op[0] {
+	case '@':
+		// Initialize clears the print buffer and styles but not the paper
+		r.line = nil
+		y := state.CursorY
+		state.Reset()
+		state.CursorY = y
+		r.codeTable = character.PC437
+		r.utf8 = false
+		r.kanji = false
+		r.jisCode = nil
+		r.userChars = nil
+		r.userDefined = false
+	case 't':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		r.codeTable = character.CodeTable(p[0])
+		if table, ok := r.e.config.CodePages[p[0]]; ok {
+			r.codeTable = table
+		}
+	case 'a':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		switch p[0] {
+		case 1, '1':
+			state.Align = constants.Center.String()
+		case 2, '2':
+			state.Align = constants.Right.String()
+		default:
+			state.Align = constants.Left.String()
+		}
+	case 'E', 'G':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		state.IsBold = p[0]&1 == 1
+	case '-':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		switch p[0] {
+		case 1, '1':
+			state.IsUnderline = 1
+		case 2, '2':
+			state.IsUnderline = 2
+		default:
+			state.IsUnderline = 0
+		}
+	case 'M':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		if p[0]&1 == 1 {
+			state.FontName = "B"
+		} else {
+			state.FontName = "A"
+		}
+	case '!':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		r.printMode(p[0])
+	case 'd':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		r.feedLines(int(p[0]))
+	case 'J':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		r.printLine()
+		state.CursorY += float64(p[0])
+		r.e.canvas.UpdateMaxY(state.CursorY)
+	case '2':
+		state.LineSpacing = float64(constants.DefaultLineSpacing)
+	case '3':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		state.LineSpacing = float64(p[0])
+	case 'p':
+		return r.skip(3, start) // Drawer kick
+	case 'B', '$', 'c':
+		return r.skip(2, start) // Beeper, absolute position, panel/sensor settings
+	case '*':
+		return r.bitImage(start)
+	case '&':
+		return r.userDefinedChars(start)
+	case '%':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		r.userDefined = p[0]&1 == 1
+	default:
+		// ESC SP, ESC {, ESC V, ESC R, ESC r, ESC U... take one parameter
+		if op[0] != '<' && op[0] != 'i' && op[0] != 'm' {
+			return r.skip(1, start)
+		}
+	}
+	return nil
+}
+
+// printMode applies ESC ! n
+func (r *replayer) printMode(n byte) {
+	state := r.e.state
+	state.FontName = "A"
+	if n&0x01 != 0 {
+		state.FontName = "B"
+	}
+	state.IsBold = n&0x08 != 0
+	h, w := 1.0, 1.0
+	if n&0x10 != 0 {
+		h = 2
+	}
+	if n&0x20 != 0 {
+		w = 2
+	}
+	state.SetSize(w, h)
+	state.IsUnderline = 0
+	if n&0x80 != 0 {
+		state.IsUnderline = 1
+	}
+}
+
+// bitImage skips ESC * m nL nH d1...dk
+func (r *replayer) bitImage(start int) error {
+	p, err := r.take(3, start)
+	if err != nil {
+		return err
+	}
+	columns := int(p[1]) + int(p[2])<<8
+	if p[0] == 32 || p[0] == 33 {
+		columns *= 3
+	}
+	return r.skip(columns, start)
+}
+
+// userDefinedChars stores ESC & y c1 c2 [x d1...d(y*x)]... for the current
+// font; each column is y bytes, most significant bit on top
+func (r *replayer) userDefinedChars(start int) error {
+	p, err := r.take(3, start)
+	if err != nil {
+		return err
+	}
+	y := int(p[0])
+	if r.userChars == nil {
+		r.userChars = make(map[string]map[byte]*graphics.MonochromeBitmap)
+	}
+	font := r.e.state.FontName
+	if r.userChars[font] == nil {
+		r.userChars[font] = make(map[byte]*graphics.MonochromeBitmap)
+	}
+	for c := int(p[1]); c <= int(p[2]); c++ {
+		x, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		data, err := r.take(y*int(x[0]), start)
+		if err != nil {
+			return err
+		}
+		glyph := graphics.NewMonochromeBitmap(int(x[0]), y*8)
+		for i, b := range data {
+			for bit := 0; bit < 8; bit++ {
+				if b&(0x80>>bit) != 0 {
+					glyph.SetPixel(i/y, (i%y)*8+bit, true)
+				}
+			}
+		}
+		r.userChars[font][byte(c)] = glyph
+	}
+	return nil
+}
+
+// gs handles GS commands
+func (r *replayer) gs() error {
+	start := r.pos - 1
+	op, err := r.take(1, start)
+	if err != nil {
+		return err
+	}
+	r.flushText()
+	state := r.e.state
+
+	switch op[0] {
+	case '!':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		state.SetSize(float64(p[0]>>4)+1, float64(p[0]&0x0F)+1)
+	case 'B':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		state.IsInverse = p[0]&1 == 1
+	case 'V':
+		return r.cut(start)
+	case 'v':
+		return r.rasterImage(start)
+	case 'k':
+		return r.barcode(start)
+	case 'h':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		r.barcodeHeight = int(p[0])
+	case 'w':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		r.barcodeModule = int(p[0])
+	case 'H':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		r.hriPosition = p[0] & 0x03
+	case '(':
+		return r.extended(start, true)
+	case 'L', 'W', 'P', '$', '\\':
+		return r.skip(2, start) // Margins, print area, motion units, positions
+	case '*':
+		p, err := r.take(2, start)
+		if err != nil {
+			return err
+		}
+		return r.skip(int(p[0])*int(p[1])*8, start)
+	default:
+		// GS f, GS a, GS b, GS r, GS / ... take one parameter
+		return r.skip(1, start)
+	}
+	return nil
+}
+
+// cut handles GS V m [n]
+func (r *replayer) cut(start int) error {
+	p, err := r.take(1, start)
+	if err != nil {
+		return err
+	}
+	m := p[0]
+	if m >= 65 {
+		if err := r.skip(1, start); err != nil {
+			return err
+		}
+	}
+	if r.printLine() {
+		r.lineFeed(true)
+	}
+	partial := m == 1 || m == '1' || m == 66 || m == 104
+	r.e.Cut(partial)
+	return nil
+}
+
+// rasterImage renders GS v 0 m xL xH yL yH d1...dk
+func (r *replayer) rasterImage(start int) error {
+	p, err := r.take(6, start)
+	if err != nil {
+		return err
+	}
+	widthBytes := int(p[2]) + int(p[3])<<8
+	height := int(p[4]) + int(p[5])<<8
+	data, err := r.take(widthBytes*height, start)
+	if err != nil {
+		return err
+	}
+	if widthBytes == 0 || height == 0 {
+		return nil
+	}
+
+	bitmap := graphics.NewMonochromeBitmap(widthBytes*8, height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < widthBytes*8; x++ {
+			if data[y*widthBytes+x/8]&(0x80>>(x%8)) != 0 {
+				bitmap.SetPixel(x, y, true)
+			}
+		}
+	}
+
+	r.endLine()
+	img := bitmap.ToImage()
+	return r.e.PrintImageAligned(img, img.Bounds().Dx(), r.e.state.Align)
+}
+
+// barcode renders GS k as a stand-in with its human readable text
+func (r *replayer) barcode(start int) error {
+	p, err := r.take(1, start)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if p[0] <= 6 {
+		// Function A: NUL terminated
+		for {
+			c, err := r.take(1, start)
+			if err != nil {
+				return err
+			}
+			if c[0] == shared.NUL {
+				break
+			}
+			data = append(data, c[0])
+		}
+	} else {
+		n, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		if data, err = r.take(int(n[0]), start); err != nil {
+			return err
+		}
+	}
+
+	r.endLine()
+	r.drawBarcode(hriText(data))
+	return nil
+}
+
+// hriText strips the CODE128 code set prefix and escapes
+func hriText(data []byte) string {
+	if len(data) >= 2 && data[0] == '{' && data[1] >= 'A' && data[1] <= 'C' {
+		data = data[2:]
+	}
+	out := make([]byte, 0, len(data))
+	for i := 0; i < len(data); i++ {
+		if data[i] == '{' && i+1 < len(data) && data[i+1] == '{' {
+			i++
+		}
+		out = append(out, data[i])
+	}
+	return string(out)
+}
+
+// drawBarcode draws bars derived from the data bits. The result shows the
+// size and position of the barcode but is not scannable.
+func (r *replayer) drawBarcode(text string) {
+	state := r.e.state
+	module := r.barcodeModule
+	if module < 1 {
+		module = 1
+	}
+	width := (len(text)*8 + 4) * module
+	if width > state.PaperPxWidth {
+		module = 1
+		width = min(len(text)*8+4, state.PaperPxWidth)
+	}
+
+	if r.hriPosition == 1 || r.hriPosition == 3 {
+		r.e.PrintLine(text)
+	}
+
+	x := r.alignedX(float64(width), state.Align)
+	y := int(state.CursorY)
+	r.e.canvas.EnsureHeight(float64(y + r.barcodeHeight))
+	bar := 2 * module
+	r.e.canvas.DrawRect(int(x), y, module, r.barcodeHeight, color.Black)
+	for i := 0; i < len(text)*8; i++ {
+		if text[i/8]&(0x80>>(i%8)) != 0 {
+			r.e.canvas.DrawRect(int(x)+bar+i*module, y, module, r.barcodeHeight, color.Black)
+		}
+	}
+	r.e.canvas.DrawRect(int(x)+width-module, y, module, r.barcodeHeight, color.Black)
+	state.CursorY += float64(r.barcodeHeight)
+	r.e.canvas.UpdateMaxY(state.CursorY)
+
+	if r.hriPosition == 2 || r.hriPosition == 3 {
+		r.e.NewLine()
+		r.e.PrintLine(text)
+	} else {
+		r.e.NewLine()
+	}
+}
+
+// extended handles GS ( and FS ( functions: fn pL pH d1...dk
+func (r *replayer) extended(start int, isGS bool) error {
+	p, err := r.take(3, start)
+	if err != nil {
+		return err
+	}
+	fn := p[0]
+	params, err := r.take(int(p[1])+int(p[2])<<8, start)
+	if err != nil {
+		return err
+	}
+	if isGS && fn == 'k' {
+		return r.qr(params)
+	}
+	if !isGS && fn == 'C' && len(params) == 2 && params[0] == 0x30 {
+		// FS ( C fn 48: select the character encoding system
+		r.utf8 = params[1] == 2 || params[1] == '2'
+	}
+	return nil
+}
+
+// qr handles the QR code functions of GS ( k
+func (r *replayer) qr(params []byte) error {
+	if len(params) < 2 || params[0] != 49 {
+		return nil // Other 2D symbologies are not rendered
+	}
+
+	switch params[1] {
+	case 67: // Module size
+		if len(params) > 2 {
+			r.qrModule = int(params[2])
+		}
+	case 69: // Error correction
+		if len(params) > 2 {
+			r.qrEC = qrcode.ErrorCorrection(params[2])
+		}
+	case 80: // Store data (m = 48 precedes the data)
+		if len(params) > 3 {
+			r.qrData = append([]byte(nil), params[3:]...)
+		}
+	case 81: // Print stored data
+		return r.printQR()
+	}
+	return nil
+}
+
+func (r *replayer) printQR() error {
+	if len(r.qrData) == 0 {
+		return nil
+	}
+
+	opts := graphics.DefaultQROptions()
+	opts.ErrorCorrection = r.qrEC
+	opts.MaxPixelWidth = r.e.state.PaperPxWidth
+	// Native QR size depends on the version; approximate a version 2 symbol
+	opts.PixelWidth = min(r.qrModule*33, r.e.state.PaperPxWidth)
+
+	img, err := graphics.ProcessQRImage(string(r.qrData), opts)
+	if err != nil {
+		log.Printf("[Emulator] Warning: QR preview failed: %v", err)
+		return nil
+	}
+
+	r.endLine()
+	return r.e.PrintImageAligned(img, img.Bounds().Dx(), r.e.state.Align)
+}
+
+// fs handles FS commands
+func (r *replayer) fs() error {
+	start := r.pos - 1
+	op, err := r.take(1, start)
+	if err != nil {
+		return err
+	}
+	r.flushText()
+
+	switch op[0] {
+	case '&':
+		r.kanji = true
+		return nil
+	case '.':
+		r.kanji = false
+		return nil
+	case 'C':
+		p, err := r.take(1, start)
+		if err != nil {
+			return err
+		}
+		jis := p[0] == 0 || p[0] == '0'
+		r.jisCode = &jis
+		return nil
+	case '(':
+		return r.extended(start, false)
+	case 'S', 'p':
+		return r.skip(2, start)
+	default:
+		// FS !, FS C, FS -, FS W ... take one parameter
+		return r.skip(1, start)
+	}
+}
+
+// dle handles real-time commands, which have no visual effect
+func (r *replayer) dle() error {
+	start := r.pos - 1
+	op, err := r.take(1, start)
+	if err != nil {
+		return err
+	}
+	if op[0] == 0x14 { // DLE DC4 fn m t
+		return r.skip(3, start)
+	}
+	return r.skip(1, start)
+}
+
+// flushText decodes pending bytes into a segment with the current style.
+// With the user-defined set selected, defined codes become glyph segments.
+func (r *replayer) flushText() {
+	if len(r.pending) == 0 {
+		return
+	}
+	if glyphs := r.userChars[r.e.state.FontName]; r.userDefined && len(glyphs) > 0 {
+		pending := r.pending
+		r.pending = nil
+		for _, b := range pending {
+			glyph, ok := glyphs[b]
+			if !ok {
+				r.pending = append(r.pending, b)
+				continue
+			}
+			r.decodeText()
+			r.line = append(r.line, replaySegment{text: " ", style: *r.e.state, glyph: glyph})
+		}
+		r.decodeText()
+		return
+	}
+	r.decodeText()
+}
+
+// decodeText decodes pending bytes into a segment with the current style
+func (r *replayer) decodeText() {
+	if len(r.pending) == 0 {
+		return
+	}
+	var text string
+	var err error
+	switch {
+	case r.utf8:
+		text = strings.ToValidUTF8(string(r.pending), "\uFFFD")
+	case r.kanji:
+		text, err = r.decodeKanji()
+	default:
+		text, err = profile.DecodeBytes(r.codeTable, r.pending)
+	}
+	if err != nil {
+		text, _ = profile.DecodeBytes(character.WPC1252, r.pending)
+	}
+	r.pending = r.pending[:0]
+	r.line = append(r.line, replaySegment{text: text, style: *r.e.state})
+}
+
+// decodeKanji decodes the pending bytes of Kanji mode. FS C picks JIS or
+// Shift JIS on Japanese models; otherwise the configured double-byte set
+// is used, GB18030 by default.
+func (r *replayer) decodeKanji() (string, error) {
+	if r.jisCode != nil && *r.jisCode {
+		// JIS codes are EUC-JP without the high bit
+		data := make([]byte, len(r.pending))
+		for i, b := range r.pending {
+			data[i] = b | 0x80
+		}
+		text, err := japanese.EUCJP.NewDecoder().Bytes(data)
+		return string(text), err
+	}
+
+	set := r.e.config.DoubleByte
+	switch {
+	case r.jisCode != nil:
+		set = profile.DoubleByteShiftJIS
+	case set == "":
+		set = profile.DoubleByteGB18030
+	}
+	return profile.DecodeDoubleByte(set, r.pending)
+}
+
+// feedLines handles ESC d n: print the buffer and feed n lines in total
+func (r *replayer) feedLines(n int) {
+	if r.printLine() {
+		r.lineFeed(true)
+		n--
+	}
+	if n > 0 {
+		r.e.Feed(n)
+	}
+}
+
+// endLine prints pending text before a graphic so it is not overwritten
+func (r *replayer) endLine() {
+	r.flushText()
+	if r.printLine() {
+		r.lineFeed(true)
+	}
+}
+
+// printLine renders the buffered segments, wrapping at the paper width, and
+// leaves the cursor on the baseline of the last row. It reports whether
+// anything was printed.
+func (r *replayer) printLine() bool {
+	if len(r.line) == 0 {
+		return false
+	}
+	rows := r.wrap(r.line)
+	r.line = nil
+
+	state := r.e.state
+	saved := *state
+	for i, row := range rows {
+		if i > 0 {
+			r.lineFeed(true)
+		}
+		r.printRow(row)
+	}
+	applyStyle(state, saved)
+	return true
+}
+
+// wrap splits segments into rows that fit the paper width
+func (r *replayer) wrap(segments []replaySegment) [][]replaySegment {
+	paper := float64(r.e.state.PaperPxWidth)
+	var rows [][]replaySegment
+	var row []replaySegment
+	width := 0.0
+
+	for _, seg := range segments {
+		charWidth := r.e.fonts.GetScaledMetrics(seg.style.FontName, seg.style.ScaleW, seg.style.ScaleH).GlyphWidth
+		current := replaySegment{style: seg.style, glyph: seg.glyph}
+		for _, ch := range seg.text {
+			chWidth := charWidth * float64(runeCells(ch))
+			if width+chWidth > paper && width > 0 {
+				if current.text != "" {
+					row = append(row, current)
+				}
+				rows = append(rows, row)
+				row, width = nil, 0
+				current = replaySegment{style: seg.style}
+			}
+			current.text += string(ch)
+			width += chWidth
+		}
+		if current.text != "" {
+			row = append(row, current)
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// printRow renders one row of segments using the alignment of its first one
+func (r *replayer) printRow(row []replaySegment) {
+	state := r.e.state
+	width, maxScaleH := 0.0, 1.0
+	for _, seg := range row {
+		metrics := r.e.fonts.GetScaledMetrics(seg.style.FontName, seg.style.ScaleW, seg.style.ScaleH)
+		width += float64(textCells(seg.text)) * metrics.GlyphWidth
+		maxScaleH = max(maxScaleH, seg.style.ScaleH)
+	}
+
+	// Glyphs grow upwards from the baseline; make room for enlarged text
+	base := r.e.fonts.GetMetrics(row[0].style.FontName)
+	state.CursorY += base.GlyphHeight * (maxScaleH - 1)
+
+	x := r.alignedX(width, row[0].style.Align)
+	for _, seg := range row {
+		applyStyle(state, seg.style)
+		metrics := r.e.fonts.GetScaledMetrics(seg.style.FontName, seg.style.ScaleW, seg.style.ScaleH)
+		if seg.glyph != nil {
+			r.drawUserChar(seg.glyph, x, metrics.GlyphHeight)
+		} else {
+			r.e.textRenderer.renderAt(seg.text, x, metrics.GlyphWidth, metrics.GlyphHeight)
+		}
+		x += float64(textCells(seg.text)) * metrics.GlyphWidth
+	}
+}
+
+// drawUserChar draws a user-defined character in the cell at x that ends
+// on the cursor baseline, scaled by the character size
+func (r *replayer) drawUserChar(glyph *graphics.MonochromeBitmap, x, height float64) {
+	state := r.e.state
+	top := state.CursorY - height
+	r.e.canvas.EnsureHeight(state.CursorY)
+	for gy := 0; gy < glyph.Height; gy++ {
+		for gx := 0; gx < glyph.Width; gx++ {
+			if glyph.GetPixel(gx, gy) {
+				r.e.canvas.DrawRect(int(x+float64(gx)*state.ScaleW), int(top+float64(gy)*state.ScaleH),
+					int(state.ScaleW), int(state.ScaleH), color.Black)
+			}
+		}
+	}
+	state.CursorX = x + float64(glyph.Width)*state.ScaleW
+}
+
+// lineFeed advances to the next line. After a printed row the enlarged
+// height was already accounted for, so the unscaled line height is used.
+func (r *replayer) lineFeed(printed bool) {
+	state := r.e.state
+	metrics := r.e.fonts.GetMetrics(state.FontName)
+	lineHeight := metrics.LineHeight
+	if !printed {
+		lineHeight *= state.ScaleH
+	}
+	if lineHeight < state.LineSpacing {
+		lineHeight = state.LineSpacing
+	}
+	state.CursorY += lineHeight
+	state.CursorX = 0
+	r.e.canvas.UpdateMaxY(state.CursorY)
+}
+
+// alignedX returns the starting X for content of the given width
+func (r *replayer) alignedX(width float64, align string) float64 {
+	paper := float64(r.e.state.PaperPxWidth)
+	switch align {
+	case constants.Center.String():
+		return (paper - width) / 2
+	case constants.Right.String():
+		return paper - width
+	default:
+		return 0
+	}
+}
+
+// applyStyle copies the text style of src into dst, keeping the cursor
+func applyStyle(dst *PrinterState, src PrinterState) {
+	dst.FontName = src.FontName
+	dst.IsBold = src.IsBold
+	dst.IsUnderline = src.IsUnderline
+	dst.IsInverse = src.IsInverse
+	dst.ScaleW = src.ScaleW
+	dst.ScaleH = src.ScaleH
+	dst.Align = src.Align
+}
@@ -0,0 +1,140 @@
+package emulator_test
+
+import (
+	"testing"
+
+	"github.com/adcondev/poster/pkg/emulator"
+)
+
+// newReplayEngine creates an 80mm engine for replay tests
+func newReplayEngine(t *testing.T) *emulator.Engine {
+	t.Helper()
+	engine, err := emulator.NewDefaultEngine()
+	if err != nil {
+		t.Fatalf("NewDefaultEngine() error = %v", err)
+	}
+	return engine
+}
+
+func TestReplay_TextAndStyles(t *testing.T) {
+	engine := newReplayEngine(t)
+	before := engine.State().CursorY
+
+	job := []byte{
+		0x1B, '@', // Initialize
+		0x1B, 't', 2, // PC850
+		0x1B, 'a', 1, // Center
+		0x1B, 'E', 1, // Bold
+		0x1D, '!', 0x11, // 2x2
+	}
+	job = append(job, []byte("TOTAL")...)
+	job = append(job, '\n')
+
+	if err := engine.Replay(job); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	state := engine.State()
+	if state.Align != "center" {
+		t.Errorf("Align = %q, want center", state.Align)
+	}
+	if !state.IsBold {
+		t.Error("IsBold = false, want true")
+	}
+	if state.ScaleW != 2 || state.ScaleH != 2 {
+		t.Errorf("Scale = %vx%v, want 2x2", state.ScaleW, state.ScaleH)
+	}
+	if state.CursorY <= before {
+		t.Errorf("CursorY = %v, want > %v after line feed", state.CursorY, before)
+	}
+}
+
+func TestReplay_InitializeResetsStyle(t *testing.T) {
+	engine := newReplayEngine(t)
+
+	job := []byte{0x1B, 'E', 1, 0x1D, 'B', 1, 'A', '\n', 0x1B, '@'}
+	if err := engine.Replay(job); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+
+	state := engine.State()
+	if state.IsBold || state.IsInverse {
+		t.Errorf("style not reset: bold=%v inverse=%v", state.IsBold, state.IsInverse)
+	}
+}
+
+func TestReplay_RasterImage(t *testing.T) {
+	engine := newReplayEngine(t)
+	before := engine.RenderWithInfo().Height
+
+	// GS v 0: 2 bytes wide (16 px), 10 rows, all black
+	job := []byte{0x1D, 'v', '0', 0, 2, 0, 10, 0}
+	for i := 0; i < 20; i++ {
+		job = append(job, 0xFF)
+	}
+
+	if err := engine.Replay(job); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+	if got := engine.RenderWithInfo().Height; got <= before {
+		t.Errorf("rendered height = %d, want > %d", got, before)
+	}
+}
+
+func TestReplay_UserDefinedChars(t *testing.T) {
+	// countBlack counts the black pixels of a rendered job
+	countBlack := func(job []byte) int {
+		engine := newReplayEngine(t)
+		if err := engine.Replay(job); err != nil {
+			t.Fatalf("Replay() error = %v", err)
+		}
+		img := engine.Render()
+		n := 0
+		for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+			for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+				if r, _, _, _ := img.At(x, y).RGBA(); r < 0x8000 {
+					n++
+				}
+			}
+		}
+		return n
+	}
+
+	// ESC & 3 'A' 'A': a solid 12x24 block
+	define := []byte{0x1B, '&', 3, 'A', 'A', 12}
+	for i := 0; i < 36; i++ {
+		define = append(define, 0xFF)
+	}
+	plain := append(append([]byte{}, define...), 'A', '\n')
+	glyph := append(append([]byte{}, define...), 0x1B, '%', 1, 'A', 0x1B, '%', 0, '\n')
+
+	if got := countBlack(glyph); got < 12*24 {
+		t.Errorf("glyph black pixels = %d, want at least %d", got, 12*24)
+	}
+	if got, text := countBlack(glyph), countBlack(plain); got <= text {
+		t.Errorf("glyph black pixels = %d, want more than the letter (%d)", got, text)
+	}
+}
+
+func TestReplay_SkipsNonVisualCommands(t *testing.T) {
+	engine := newReplayEngine(t)
+
+	job := []byte{
+		0x1B, 'p', 0, 25, 250, // Drawer kick
+		0x1B, 'B', 2, 1, // Beeper
+		0x10, 0x04, 1, // Status request
+	}
+	job = append(job, []byte("OK\n")...)
+
+	if err := engine.Replay(job); err != nil {
+		t.Fatalf("Replay() error = %v", err)
+	}
+}
+
+func TestReplay_TruncatedCommand(t *testing.T) {
+	engine := newReplayEngine(t)
+
+	if err := engine.Replay([]byte{0x1D, 'v', '0', 0, 2}); err == nil {
+		t.Error("expected error for truncated raster command")
+	}
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/adcondev/poster/pkg/constants"
 	emfonts "github.com/adcondev/poster/pkg/emulator/fonts"
+	"github.com/adcondev/poster/pkg/graphics"
 )
 
 // FontMetrics contains calculated font dimensions
@@ -38,6 +39,7 @@ type FontManager struct {
 	// FIXME: he scaledFaces map is accessed from multiple methods without synchronization.
 	scaledFaces map[string]font.Face // Cache:  "fontName_scaleW_scaleH" -> face
 	useFallback bool
+	fallback    *opentype.Font // Glyphs missing from the A and B fonts (CJK...)
 }
 
 // NewFontManager creates a new FontManager instance
@@ -129,6 +131,58 @@ func (fm *FontManager) LoadFont(name, filename string, targetWidth, targetHeight
 	return nil
 }
 
+// LoadFallbackFont loads a TTF/OTF file used for the glyphs the A and B
+// fonts do not have, such as CJK characters
+func (fm *FontManager) LoadFallbackFont(path string) error {
+	f, err := graphics.LoadFont(path)
+	if err != nil {
+		return fmt.Errorf("fallback font: %w", err)
+	}
+	fm.fallback = f
+	return nil
+}
+
+// HasFallbackFont reports whether a fallback font is loaded
+func (fm *FontManager) HasFallbackFont() bool {
+	return fm.fallback != nil
+}
+
+// FallbackFace returns a face of the fallback font whose em box is height
+// pixels tall
+func (fm *FontManager) FallbackFace(height float64) (font.Face, error) {
+	if fm.fallback == nil {
+		return nil, fmt.Errorf("no fallback font loaded")
+	}
+	key := fmt.Sprintf("fallback_%.1f", height)
+	if face, ok := fm.scaledFaces[key]; ok {
+		return face, nil
+	}
+	face, err := opentype.NewFace(fm.fallback, &opentype.FaceOptions{
+		Size:    height,
+		DPI:     72.0,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating fallback face: %w", err)
+	}
+	fm.scaledFaces[key] = face
+	return face, nil
+}
+
+// faceFor returns face, or the fallback face when face has no glyph for char
+func (fm *FontManager) faceFor(face font.Face, char rune, height float64) font.Face {
+	if fm.fallback == nil {
+		return face
+	}
+	if _, ok := face.GlyphAdvance(char); ok {
+		return face
+	}
+	if fb, err := fm.FallbackFace(height); err == nil {
+		return fb
+	}
+	return face
+}
+
 // GetFont retrieves a loaded font by name
 func (fm *FontManager) GetFont(name string) (*ScaledFont, error) {
 	if f, ok := fm.fonts[name]; ok {
@@ -264,7 +318,7 @@ func (fm *FontManager) DrawChar(dst draw.Image, fontName string, char rune, x, y
 	d := &font.Drawer{
 		Dst:  dst,
 		Src:  image.NewUniform(col),
-		Face: sf.face,
+		Face: fm.faceFor(sf.face, char, sf.metrics.GlyphHeight),
 		Dot:  point,
 	}
 	d.DrawString(string(char))
@@ -300,7 +354,7 @@ func (fm *FontManager) DrawCharScaled(dst draw.Image, fontName string, char rune
 	d := &font.Drawer{
 		Dst:  dst,
 		Src:  image.NewUniform(col),
-		Face: face,
+		Face: fm.faceFor(face, char, metrics.GlyphHeight),
 		Dot:  point,
 	}
 	d.DrawString(string(char))
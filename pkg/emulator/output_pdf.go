@@ -0,0 +1,182 @@
+package emulator
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// writePDF writes the recorded operations of dc as a single-page PDF the
+// size of the cropped PNG at dpi. Text uses the standard Courier fonts with
+// WinAnsi encoding, so it can be searched and copied; characters outside
+// Windows-1252 are written as '?'.
+func writePDF(w io.Writer, dc *DynamicCanvas, dpi int) error {
+	width, height := dc.Width(), dc.contentHeight()
+	scale := 72 / float64(dpi)
+	top := float64(height)
+
+	var content bytes.Buffer
+	var images [][]byte
+	// Work in pixels, PDF y grows upwards
+	k := strconv.FormatFloat(scale, 'f', 6, 64)
+	fmt.Fprintf(&content, "%s 0 0 %s 0 0 cm\n", k, k)
+	for _, op := range dc.ops {
+		switch op.kind {
+		case vectorRect:
+			fmt.Fprintf(&content, "%s rg %s %s %s %s re f\n",
+				pdfColor(op.col), pdfNum(op.x), pdfNum(top-op.y-op.h), pdfNum(op.w), pdfNum(op.h))
+		case vectorLine:
+			dash := "[] 0 d"
+			if op.dash > 0 {
+				dash = fmt.Sprintf("[%s %s] 0 d", pdfNum(op.dash), pdfNum(op.gap))
+			}
+			y := pdfNum(top - op.y - op.h/2)
+			fmt.Fprintf(&content, "%s RG %s w %s %s %s m %s %s l S\n",
+				pdfColor(op.col), pdfNum(op.h), dash, pdfNum(op.x), y, pdfNum(op.x+op.w), y)
+		case vectorText:
+			font := "/F1"
+			if op.bold {
+				font = "/F2"
+			}
+			// Horizontal scaling makes each Courier glyph one cell wide
+			tz := op.cell / (monoAdvance * op.size) * 100
+			fmt.Fprintf(&content, "%s rg BT %s %s Tf %s Tz 1 0 0 1 %s %s Tm %s TJ ET\n",
+				pdfColor(op.col), font, pdfNum(op.size), pdfNum(tz), pdfNum(op.x), pdfNum(top-op.y), pdfText(op.text))
+		case vectorImage:
+			data, err := pdfImage(op.img)
+			if err != nil {
+				return err
+			}
+			images = append(images, data)
+			fmt.Fprintf(&content, "q %s 0 0 %s %s %s cm /Im%d Do Q\n",
+				pdfNum(op.w), pdfNum(op.h), pdfNum(op.x), pdfNum(top-op.y-op.h), len(images))
+		}
+	}
+
+	xobjects := ""
+	if len(images) > 0 {
+		names := make([]string, len(images))
+		for i := range images {
+			names[i] = fmt.Sprintf("/Im%d %d 0 R", i+1, i+7)
+		}
+		xobjects = " /XObject << " + strings.Join(names, " ") + " >>"
+	}
+	objects := [][]byte{
+		[]byte("<< /Type /Catalog /Pages 2 0 R >>"),
+		[]byte("<< /Type /Pages /Kids [3 0 R] /Count 1 >>"),
+		[]byte(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources << /Font << /F1 5 0 R /F2 6 0 R >>%s >> /Contents 4 0 R >>",
+			pdfNum(float64(width)*scale), pdfNum(float64(height)*scale), xobjects)),
+		pdfStream("", content.Bytes()),
+		[]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>"),
+		[]byte("<< /Type /Font /Subtype /Type1 /BaseFont /Courier-Bold /Encoding /WinAnsiEncoding >>"),
+	}
+	objects = append(objects, images...)
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects))
+	for i, obj := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n", i+1)
+		out.Write(obj)
+		out.WriteString("\nendobj\n")
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// pdfImage returns the image XObject of img: RGB samples composited over
+// white paper, Flate compressed
+func pdfImage(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	var samples bytes.Buffer
+	zw := zlib.NewWriter(&samples)
+	row := make([]byte, 0, bounds.Dx()*3)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row = row[:0]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			// Premultiplied, so adding the missing alpha composites over white
+			r, g, b, a := img.At(x, y).RGBA()
+			row = append(row, byte((r+0xffff-a)>>8), byte((g+0xffff-a)>>8), byte((b+0xffff-a)>>8))
+		}
+		if _, err := zw.Write(row); err != nil {
+			return nil, fmt.Errorf("compressing image: %w", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compressing image: %w", err)
+	}
+	dict := fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceRGB /BitsPerComponent 8 /Filter /FlateDecode ",
+		bounds.Dx(), bounds.Dy())
+	return pdfStream(dict, samples.Bytes()), nil
+}
+
+// pdfStream returns a stream object with the extra dictionary entries dict
+func pdfStream(dict string, data []byte) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<< %s/Length %d >>\nstream\n", dict, len(data))
+	buf.Write(data)
+	buf.WriteString("\nendstream")
+	return buf.Bytes()
+}
+
+// pdfText returns a TJ array for text in WinAnsi encoding. Full-width
+// characters take two cells, so they are followed by one cell of space.
+func pdfText(text string) string {
+	encoder := charmap.Windows1252.NewEncoder()
+	var b strings.Builder
+	b.WriteString("[(")
+	for _, r := range text {
+		c := byte('?')
+		if encoded, err := encoder.String(string(r)); err == nil && len(encoded) == 1 {
+			c = encoded[0]
+		}
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			if c < 32 || c > 126 {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+		if runeCells(r) == 2 {
+			fmt.Fprintf(&b, ") %d (", -int(monoAdvance*1000))
+		}
+	}
+	b.WriteString(")]")
+	return b.String()
+}
+
+// pdfNum formats a number with up to three decimals
+func pdfNum(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 3, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// pdfColor formats a color as PDF RGB components
+func pdfColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return pdfNum(float64(r)/0xffff) + " " + pdfNum(float64(g)/0xffff) + " " + pdfNum(float64(b)/0xffff)
+}
@@ -0,0 +1,82 @@
+package emulator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+)
+
+// svgFontFamily lists monospaced fonts close to the embedded ones
+const svgFontFamily = "'JetBrains Mono', 'DejaVu Sans Mono', 'Courier New', monospace"
+
+// writeSVG writes the recorded operations of dc as an SVG document whose
+// view box is the cropped PNG, in pixels, and whose size is the physical
+// size at dpi
+func writeSVG(w io.Writer, dc *DynamicCanvas, dpi int) error {
+	width, height := dc.Width(), dc.contentHeight()
+	mm := func(px int) string {
+		return svgNum(float64(px)*25.4/float64(dpi)) + "mm"
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" xmlns:xlink=\"http://www.w3.org/1999/xlink\" width=\"%s\" height=\"%s\" viewBox=\"0 0 %d %d\">\n",
+		mm(width), mm(height), width, height)
+	fmt.Fprintf(bw, "<rect width=\"%d\" height=\"%d\" fill=\"#ffffff\"/>\n", width, height)
+
+	for _, op := range dc.ops {
+		switch op.kind {
+		case vectorRect:
+			fmt.Fprintf(bw, "<rect x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" fill=\"%s\"/>\n",
+				svgNum(op.x), svgNum(op.y), svgNum(op.w), svgNum(op.h), svgColor(op.col))
+		case vectorLine:
+			dash := ""
+			if op.dash > 0 {
+				dash = fmt.Sprintf(" stroke-dasharray=\"%s %s\"", svgNum(op.dash), svgNum(op.gap))
+			}
+			y := svgNum(op.y + op.h/2)
+			fmt.Fprintf(bw, "<line x1=\"%s\" y1=\"%s\" x2=\"%s\" y2=\"%s\" stroke=\"%s\" stroke-width=\"%s\"%s/>\n",
+				svgNum(op.x), y, svgNum(op.x+op.w), y, svgColor(op.col), svgNum(op.h), dash)
+		case vectorText:
+			weight := ""
+			if op.bold {
+				weight = " font-weight=\"bold\""
+			}
+			fmt.Fprintf(bw, "<text x=\"%s\" y=\"%s\" font-family=\"%s\" font-size=\"%s\"%s fill=\"%s\" textLength=\"%s\" lengthAdjust=\"spacingAndGlyphs\" xml:space=\"preserve\">",
+				svgNum(op.x), svgNum(op.y), svgFontFamily, svgNum(op.size), weight, svgColor(op.col),
+				svgNum(float64(textCells(op.text))*op.cell))
+			if err := xml.EscapeText(bw, []byte(op.text)); err != nil {
+				return err
+			}
+			fmt.Fprintf(bw, "</text>\n")
+		case vectorImage:
+			var buf bytes.Buffer
+			if err := png.Encode(&buf, op.img); err != nil {
+				return fmt.Errorf("encoding image: %w", err)
+			}
+			fmt.Fprintf(bw, "<image x=\"%s\" y=\"%s\" width=\"%s\" height=\"%s\" xlink:href=\"data:image/png;base64,%s\"/>\n",
+				svgNum(op.x), svgNum(op.y), svgNum(op.w), svgNum(op.h), base64.StdEncoding.EncodeToString(buf.Bytes()))
+		}
+	}
+
+	fmt.Fprintf(bw, "</svg>\n")
+	return bw.Flush()
+}
+
+// svgNum formats a coordinate with up to two decimals
+func svgNum(v float64) string {
+	return strconv.FormatFloat(math.Round(v*100)/100, 'f', -1, 64)
+}
+
+// svgColor formats a color as #rrggbb
+func svgColor(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
@@ -0,0 +1,179 @@
+package emulator_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/emulator"
+)
+
+// newReceiptEngine prints a short receipt with text, rules, an image and a cut
+func newReceiptEngine(t *testing.T) *emulator.Engine {
+	t.Helper()
+	engine, err := emulator.NewDefaultEngine()
+	if err != nil {
+		t.Fatalf("NewDefaultEngine() error = %v", err)
+	}
+	engine.AlignCenter()
+	engine.SetBold(true)
+	engine.PrintLine("CAFE & PAN")
+	engine.SetBold(false)
+	engine.AlignLeft()
+	engine.PrintLine("Año (2) x $10.00")
+	engine.HorizontalLine(2)
+	if err := engine.PrintImageAligned(createTestImage(64, 32), 64, constants.Center.String()); err != nil {
+		t.Fatalf("PrintImageAligned() error = %v", err)
+	}
+	engine.Cut(true)
+	return engine
+}
+
+// ============================================================================
+// SVG Output Tests
+// ============================================================================
+
+func TestWriteSVG_Content(t *testing.T) {
+	engine := newReceiptEngine(t)
+	result := engine.RenderWithInfo()
+
+	var buf bytes.Buffer
+	if err := engine.WriteSVG(&buf); err != nil {
+		t.Fatalf("WriteSVG() error = %v", err)
+	}
+	out := buf.String()
+
+	// Well-formed XML
+	dec := xml.NewDecoder(strings.NewReader(out))
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("WriteSVG() output is not valid XML: %v", err)
+		}
+	}
+
+	for _, s := range []string{
+		fmt.Sprintf(`viewBox="0 0 %d %d"`, result.Width, result.Height),
+		`width="72.07mm"`,
+		`font-weight="bold" fill="#000000" textLength="120" lengthAdjust="spacingAndGlyphs" xml:space="preserve">CAFE &amp; PAN</text>`,
+		`>Año (2) x $10.00</text>`,
+		`<line x1="0" y1=`,
+		`stroke-dasharray=`,
+		`width="64" height="32" xlink:href="data:image/png;base64,`,
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("WriteSVG() output does not contain %s", s)
+		}
+	}
+}
+
+func TestWriteSVG_Inverse(t *testing.T) {
+	engine, _ := emulator.NewDefaultEngine()
+	engine.SetInverse(true)
+	engine.PrintLine("TOTAL")
+
+	var buf bytes.Buffer
+	if err := engine.WriteSVG(&buf); err != nil {
+		t.Fatalf("WriteSVG() error = %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `fill="#000000"/>`) || !strings.Contains(out, `fill="#ffffff" textLength="60"`) {
+		t.Errorf("WriteSVG() inverse text should be white on black rects:\n%s", out)
+	}
+}
+
+func TestWriteSVG_Reset(t *testing.T) {
+	engine := newReceiptEngine(t)
+	engine.Reset()
+
+	var buf bytes.Buffer
+	if err := engine.WriteSVG(&buf); err != nil {
+		t.Fatalf("WriteSVG() error = %v", err)
+	}
+	if strings.Contains(buf.String(), "<text") || strings.Contains(buf.String(), "<image") {
+		t.Error("WriteSVG() after Reset() should not contain previous content")
+	}
+}
+
+// ============================================================================
+// PDF Output Tests
+// ============================================================================
+
+func TestWritePDF_Structure(t *testing.T) {
+	engine := newReceiptEngine(t)
+	result := engine.RenderWithInfo()
+
+	var buf bytes.Buffer
+	if err := engine.WritePDF(&buf); err != nil {
+		t.Fatalf("WritePDF() error = %v", err)
+	}
+	out := buf.String()
+
+	if !strings.HasPrefix(out, "%PDF-1.4\n") || !strings.HasSuffix(out, "%%EOF\n") {
+		t.Fatal("WritePDF() output is not a PDF file")
+	}
+
+	// Paper width x content length in points
+	width := float64(result.Width) * 72 / constants.DefaultDPI
+	height := float64(result.Height) * 72 / constants.DefaultDPI
+	mediaBox := regexp.MustCompile(`/MediaBox \[0 0 ([0-9.]+) ([0-9.]+)\]`).FindStringSubmatch(out)
+	if mediaBox == nil {
+		t.Fatal("WritePDF() output has no MediaBox")
+	}
+	if w, _ := strconv.ParseFloat(mediaBox[1], 64); w < width-0.01 || w > width+0.01 {
+		t.Errorf("MediaBox width = %s, want %.3f", mediaBox[1], width)
+	}
+	if h, _ := strconv.ParseFloat(mediaBox[2], 64); h < height-0.01 || h > height+0.01 {
+		t.Errorf("MediaBox height = %s, want %.3f", mediaBox[2], height)
+	}
+
+	// startxref points at the cross-reference table
+	start := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(out)
+	if start == nil {
+		t.Fatal("WritePDF() output has no startxref")
+	}
+	if offset, _ := strconv.Atoi(start[1]); !strings.HasPrefix(out[offset:], "xref\n0 8\n") {
+		t.Errorf("startxref %d does not point at an xref table with 8 entries", offset)
+	}
+	// Every object offset points at its object
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(out, -1)
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(entry[1])
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !strings.HasPrefix(out[offset:], want) {
+			t.Errorf("xref entry %d does not point at %q", i+1, want)
+		}
+	}
+
+	for _, s := range []string{
+		"/BaseFont /Courier-Bold /Encoding /WinAnsiEncoding",
+		"/F2 20 Tf 100 Tz",
+		"[(CAFE & PAN)] TJ",
+		"[(A\\361o \\(2\\) x $10.00)] TJ",
+		"/Subtype /Image /Width 64 /Height 32",
+		"/Im1 Do",
+		"] 0 d",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("WritePDF() output does not contain %s", s)
+		}
+	}
+}
+
+func TestWritePDF_EmptyCanvas(t *testing.T) {
+	engine, _ := emulator.NewDefaultEngine()
+
+	var buf bytes.Buffer
+	if err := engine.WritePDF(&buf); err != nil {
+		t.Fatalf("WritePDF() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "/Count 1") || strings.Contains(buf.String(), " TJ") {
+		t.Error("WritePDF() of an empty canvas should be a blank page")
+	}
+}
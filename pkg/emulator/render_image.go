@@ -3,7 +3,6 @@ package emulator
 import (
 	"fmt"
 	"image"
-	"image/draw"
 
 	"github.com/adcondev/poster/pkg/constants"
 	"github.com/adcondev/poster/pkg/graphics"
@@ -100,13 +99,8 @@ func (ir *ImageRenderer) RenderImage(img image.Image, opts *ImageOptions) error
 	startX := ir.calculateAlignedX(imgWidth, opts.Align)
 	startY := int(ir.state.CursorY)
 
-	// Ensure canvas has enough height
-	requiredY := float64(startY + imgHeight)
-	ir.canvas.EnsureHeight(requiredY)
-
 	// Draw image onto canvas
-	dstRect := image.Rect(startX, startY, startX+imgWidth, startY+imgHeight)
-	draw.Draw(ir.canvas.Image(), dstRect, processed, imgBounds.Min, draw.Over)
+	ir.canvas.DrawImage(processed, startX, startY)
 
 	// Update cursor position
 	ir.state.CursorY = float64(startY + imgHeight)
@@ -5,9 +5,15 @@ import (
 	"strings"
 	"unicode/utf8"
 
+	"golang.org/x/text/width"
+
 	"github.com/adcondev/poster/pkg/constants"
 )
 
+// monoAdvance is the advance of a monospaced glyph in ems, both for the
+// embedded fonts and for Courier
+const monoAdvance = 0.6
+
 // TextStyle represents text formatting options for the emulator
 type TextStyle struct {
 	Bold      bool
@@ -67,31 +73,63 @@ func (tr *TextRenderer) RenderText(text string) {
 	charWidth := metrics.GlyphWidth
 	charHeight := metrics.GlyphHeight
 
-	// Count actual characters (runes), not bytes - important for UTF-8 text
-	runeCount := utf8.RuneCountInString(text)
-
-	// Calculate text width for alignment using rune count
-	textWidth := float64(runeCount) * charWidth
+	// Count character cells, not bytes - CJK characters take two cells
+	textWidth := float64(textCells(text)) * charWidth
 
 	// Determine starting X position based on alignment
 	startX := tr.calculateAlignedX(textWidth)
 
+	tr.renderAt(text, startX, charWidth, charHeight)
+}
+
+// renderAt draws text starting at x on the current line with the current style
+func (tr *TextRenderer) renderAt(text string, x, charWidth, charHeight float64) {
 	// Ensure canvas has enough height
 	requiredY := tr.state.CursorY + charHeight
 	tr.canvas.EnsureHeight(requiredY)
 
+	// Record the run for the vector outputs; the em size follows the
+	// character width, stretched by the height scale
+	col := tr.black
+	if tr.state.IsInverse {
+		col = tr.white
+	}
+	size := charWidth / monoAdvance * tr.state.ScaleH / tr.state.ScaleW
+	startX := x
+
 	// Render each character
-	x := startX
 	for _, char := range text {
-		tr.renderChar(char, x, tr.state.CursorY, charWidth, charHeight)
-		x += charWidth
+		width := charWidth * float64(runeCells(char))
+		tr.renderChar(char, x, tr.state.CursorY, width, charHeight)
+		x += width
 	}
+	tr.canvas.DrawText(text, startX, tr.state.CursorY, charWidth, size, tr.state.IsBold, col)
 
 	// Update cursor position
 	tr.state.CursorX = x
 	tr.canvas.UpdateMaxY(tr.state.CursorY + charHeight)
 }
 
+// runeCells returns the character cells a rune takes: two for full-width
+// (CJK) characters, one otherwise
+func runeCells(r rune) int {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// textCells returns the character cells text takes
+func textCells(text string) int {
+	n := 0
+	for _, r := range text {
+		n += runeCells(r)
+	}
+	return n
+}
+
 // RenderLine renders text and moves to next line
 func (tr *TextRenderer) RenderLine(text string) {
 	tr.RenderText(text)
@@ -95,3 +95,19 @@ func (m *MonochromeBitmap) ToImage() *image.RGBA {
 
 	return img
 }
+
+// ColumnData returns the bitmap in column format, as used by ESC & and
+// ESC *: for each column from left to right, (Height + 7) / 8 bytes from top
+// to bottom with the most significant bit on top
+func (m *MonochromeBitmap) ColumnData() []byte {
+	bytesPerCol := (m.Height + 7) / 8
+	data := make([]byte, m.Width*bytesPerCol)
+	for x := 0; x < m.Width; x++ {
+		for y := 0; y < m.Height; y++ {
+			if m.GetPixel(x, y) {
+				data[x*bytesPerCol+y/8] |= 0x80 >> (y % 8)
+			}
+		}
+	}
+	return data
+}
@@ -323,6 +323,24 @@ func TestMonochromeBitmap_ToImage_RoundTrip(t *testing.T) {
 	}
 }
 
+func TestMonochromeBitmap_ColumnData(t *testing.T) {
+	mb := graphics.NewMonochromeBitmap(2, 10)
+	mb.SetPixel(0, 0, true)
+	mb.SetPixel(0, 9, true)
+	mb.SetPixel(1, 7, true)
+
+	got := mb.ColumnData()
+	want := []byte{0x80, 0x40, 0x01, 0x00}
+	if len(got) != len(want) {
+		t.Fatalf("len = %d, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("byte %d = %#02x, want %#02x", i, got[i], want[i])
+		}
+	}
+}
+
 // ============================================================================
 // Benchmark Tests
 // ============================================================================
@@ -1,8 +1,10 @@
 package graphics
 
 import (
+	"fmt"
 	"image"
 	"image/color"
+	"math"
 
 	"golang.org/x/image/draw"
 
@@ -110,3 +112,44 @@ func CompositeOverWhite(img image.Image) *image.RGBA {
 
 	return rgba
 }
+
+// FitCell scales img to fit a character cell of width x height dots,
+// keeping its aspect ratio, and thresholds it into a bitmap of the cell size
+// with the image centered. Small images are scaled with nearest neighbor so
+// pixel art keeps its edges.
+func FitCell(img image.Image, width, height int, threshold byte) (*MonochromeBitmap, error) {
+	if img == nil {
+		return nil, fmt.Errorf("image is nil")
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid cell size: %dx%d", width, height)
+	}
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, fmt.Errorf("image is empty")
+	}
+
+	scale := math.Min(float64(width)/float64(bounds.Dx()), float64(height)/float64(bounds.Dy()))
+	w := max(1, int(math.Round(float64(bounds.Dx())*scale)))
+	h := max(1, int(math.Round(float64(bounds.Dy())*scale)))
+
+	scaled := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.Draw(scaled, scaled.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	var scaler draw.Scaler = draw.BiLinear
+	if scale >= 1 {
+		scaler = draw.NearestNeighbor
+	}
+	scaler.Scale(scaled, scaled.Bounds(), img, bounds, draw.Over, nil)
+
+	gray := ToGrayscale(scaled)
+	bitmap := NewMonochromeBitmap(width, height)
+	ox, oy := (width-w)/2, (height-h)/2
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if gray.GrayAt(x, y).Y < threshold {
+				bitmap.SetPixel(ox+x, oy+y, true)
+			}
+		}
+	}
+	return bitmap, nil
+}
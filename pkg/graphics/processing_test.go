@@ -514,6 +514,52 @@ func TestCompositeOverWhite_MixedTransparency(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// FitCell Tests
+// ============================================================================
+
+func TestFitCell_CentersAndScales(t *testing.T) {
+	// 2x2 black square scaled to a 12x24 cell: 12x12, centered vertically
+	img := createTestImage(2, 2, color.Black)
+	bitmap, err := graphics.FitCell(img, 12, 24, 128)
+	if err != nil {
+		t.Fatalf("FitCell() error = %v", err)
+	}
+	if bitmap.Width != 12 || bitmap.Height != 24 {
+		t.Fatalf("size = %dx%d, want 12x24", bitmap.Width, bitmap.Height)
+	}
+	for y := 0; y < 24; y++ {
+		want := y >= 6 && y < 18
+		if got := bitmap.GetPixel(0, y); got != want {
+			t.Errorf("pixel (0,%d) = %v, want %v", y, got, want)
+		}
+	}
+}
+
+func TestFitCell_TransparentIsWhite(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	bitmap, err := graphics.FitCell(img, 9, 17, 128)
+	if err != nil {
+		t.Fatalf("FitCell() error = %v", err)
+	}
+	for y := 0; y < bitmap.Height; y++ {
+		for x := 0; x < bitmap.Width; x++ {
+			if bitmap.GetPixel(x, y) {
+				t.Fatalf("pixel (%d,%d) is black", x, y)
+			}
+		}
+	}
+}
+
+func TestFitCell_Invalid(t *testing.T) {
+	if _, err := graphics.FitCell(nil, 12, 24, 128); err == nil {
+		t.Error("FitCell(nil) should fail")
+	}
+	if _, err := graphics.FitCell(createTestImage(2, 2, color.Black), 0, 24, 128); err == nil {
+		t.Error("FitCell with zero width should fail")
+	}
+}
+
 // ============================================================================
 // Integration Tests
 // ============================================================================
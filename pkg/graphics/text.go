@@ -0,0 +1,276 @@
+package graphics
+
+import (
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/adcondev/poster/pkg/constants"
+)
+
+// LoadFont reads and parses a TrueType or OpenType font file
+func LoadFont(path string) (*opentype.Font, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("loading font: %w", err)
+	}
+	f, err := opentype.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing font: %w", err)
+	}
+	return f, nil
+}
+
+// TextSpan is a piece of text drawn with a single style
+type TextSpan struct {
+	Text   string
+	Height float64 // Em height in dots
+	ScaleX float64 // Horizontal stretch, 1 when zero
+	Bold   bool
+}
+
+// TextRasterizer draws text with a TrueType or OpenType font into
+// monochrome bitmaps, for text no printer code table can encode
+type TextRasterizer struct {
+	font  *opentype.Font
+	faces map[float64]font.Face // Cache: em height -> face
+}
+
+// NewTextRasterizer creates a rasterizer for the given font
+func NewTextRasterizer(f *opentype.Font) *TextRasterizer {
+	return &TextRasterizer{
+		font:  f,
+		faces: make(map[float64]font.Face),
+	}
+}
+
+// face returns the cached face for an em height
+func (r *TextRasterizer) face(height float64) (font.Face, error) {
+	if face, ok := r.faces[height]; ok {
+		return face, nil
+	}
+	face, err := opentype.NewFace(r.font, &opentype.FaceOptions{
+		Size:    height,
+		DPI:     72.0,
+		Hinting: font.HintingFull,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating face: %w", err)
+	}
+	r.faces[height] = face
+	return face, nil
+}
+
+// textToken is a word or a run of spaces of a span
+type textToken struct {
+	text  string
+	span  int
+	space bool
+	width int
+}
+
+// textLine is a wrapped line of tokens
+type textLine struct {
+	tokens  []textToken
+	ascent  int
+	descent int
+}
+
+// Render draws the spans as a paragraph of the given width in dots. Lines
+// are wrapped at spaces (or inside words longer than the width), broken at
+// '\n' and aligned within the width.
+func (r *TextRasterizer) Render(spans []TextSpan, width int, align constants.Alignment) (*MonochromeBitmap, error) {
+	if width <= 0 {
+		return nil, fmt.Errorf("invalid width: %d", width)
+	}
+	if len(spans) == 0 {
+		return nil, fmt.Errorf("no text to render")
+	}
+
+	faces := make([]font.Face, len(spans))
+	for i, span := range spans {
+		if span.Height <= 0 {
+			return nil, fmt.Errorf("invalid text height: %.1f", span.Height)
+		}
+		face, err := r.face(span.Height)
+		if err != nil {
+			return nil, err
+		}
+		faces[i] = face
+	}
+
+	measure := func(tok textToken) int {
+		span := spans[tok.span]
+		w := float64(font.MeasureString(faces[tok.span], tok.text).Ceil()) * scaleX(span)
+		if span.Bold {
+			w++
+		}
+		return int(math.Ceil(w))
+	}
+
+	lines := []*textLine{{}}
+	cursor := 0
+	newLine := func() {
+		lines = append(lines, &textLine{})
+		cursor = 0
+	}
+	for i, span := range spans {
+		for j, paragraph := range strings.Split(span.Text, "\n") {
+			if j > 0 {
+				newLine()
+			}
+			for _, tok := range splitWords(paragraph, i) {
+				tok.width = measure(tok)
+				line := lines[len(lines)-1]
+				if cursor+tok.width > width && len(line.tokens) > 0 {
+					if tok.space {
+						continue
+					}
+					newLine()
+				}
+				// Break words wider than the line
+				for tok.width > width && utf8.RuneCountInString(tok.text) > 1 {
+					head := fitRunes(tok, width-cursor, measure)
+					rest := textToken{text: tok.text[len(head.text):], span: i}
+					lines[len(lines)-1].tokens = append(lines[len(lines)-1].tokens, head)
+					newLine()
+					tok = rest
+					tok.width = measure(tok)
+				}
+				lines[len(lines)-1].tokens = append(lines[len(lines)-1].tokens, tok)
+				cursor += tok.width
+			}
+		}
+	}
+
+	height := 0
+	for _, line := range lines {
+		line.ascent, line.descent = 0, 0
+		for _, tok := range line.tokens {
+			m := faces[tok.span].Metrics()
+			line.ascent = max(line.ascent, m.Ascent.Ceil())
+			line.descent = max(line.descent, m.Descent.Ceil())
+		}
+		if len(line.tokens) == 0 {
+			// Empty lines keep the height of the first span
+			m := faces[0].Metrics()
+			line.ascent, line.descent = m.Ascent.Ceil(), m.Descent.Ceil()
+		}
+		height += line.ascent + line.descent
+	}
+	if height == 0 {
+		return nil, fmt.Errorf("no text to render")
+	}
+
+	bitmap := NewMonochromeBitmap(width, height)
+	y := 0
+	for _, line := range lines {
+		// Trailing spaces don't count for alignment
+		tokens := line.tokens
+		for len(tokens) > 0 && tokens[len(tokens)-1].space {
+			tokens = tokens[:len(tokens)-1]
+		}
+		lineWidth := 0
+		for _, tok := range tokens {
+			lineWidth += tok.width
+		}
+
+		x := 0
+		switch align {
+		case constants.Center:
+			x = (width - lineWidth) / 2
+		case constants.Right:
+			x = width - lineWidth
+		default:
+		}
+
+		for _, tok := range tokens {
+			if !tok.space {
+				r.drawToken(bitmap, faces[tok.span], spans[tok.span], tok, x, y+line.ascent, line.ascent+line.descent)
+			}
+			x += tok.width
+		}
+		y += line.ascent + line.descent
+	}
+	return bitmap, nil
+}
+
+// drawToken draws a token with its baseline at y, stretching it
+// horizontally and thickening it for bold spans
+func (r *TextRasterizer) drawToken(dst *MonochromeBitmap, face font.Face, span TextSpan, tok textToken, x, baseline, lineHeight int) {
+	natural := font.MeasureString(face, tok.text).Ceil()
+	ascent := face.Metrics().Ascent.Ceil()
+	glyphs := image.NewAlpha(image.Rect(0, 0, natural+1, lineHeight))
+	d := &font.Drawer{
+		Dst:  glyphs,
+		Src:  image.Opaque,
+		Face: face,
+		Dot:  fixed.P(0, ascent),
+	}
+	d.DrawString(tok.text)
+
+	sx := scaleX(span)
+	top := baseline - ascent
+	for gy := 0; gy < lineHeight; gy++ {
+		for dx := 0; dx < tok.width; dx++ {
+			src := int(float64(dx) / sx)
+			black := glyphs.AlphaAt(src, gy).A >= 128
+			if !black && span.Bold && src > 0 {
+				black = glyphs.AlphaAt(src-1, gy).A >= 128
+			}
+			if black {
+				dst.SetPixel(x+dx, top+gy, true)
+			}
+		}
+	}
+}
+
+// splitWords splits text into words and runs of spaces
+func splitWords(text string, span int) []textToken {
+	var tokens []textToken
+	start := 0
+	for i, c := range text {
+		space := c == ' '
+		if i > start && space != (text[start] == ' ') {
+			tokens = append(tokens, textToken{text: text[start:i], span: span, space: text[start] == ' '})
+			start = i
+		}
+	}
+	if start < len(text) {
+		tokens = append(tokens, textToken{text: text[start:], span: span, space: text[start] == ' '})
+	}
+	return tokens
+}
+
+// fitRunes returns the longest prefix of tok, of at least one rune, that
+// fits in width
+func fitRunes(tok textToken, width int, measure func(textToken) int) textToken {
+	end := 0
+	for i, c := range tok.text {
+		next := i + utf8.RuneLen(c)
+		candidate := textToken{text: tok.text[:next], span: tok.span}
+		if end > 0 && measure(candidate) > width {
+			break
+		}
+		end = next
+	}
+	head := textToken{text: tok.text[:end], span: tok.span}
+	head.width = measure(head)
+	return head
+}
+
+// scaleX returns the horizontal stretch of a span
+func scaleX(span TextSpan) float64 {
+	if span.ScaleX <= 0 {
+		return 1
+	}
+	return span.ScaleX
+}
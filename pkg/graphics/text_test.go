@@ -0,0 +1,133 @@
+package graphics_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/graphics"
+)
+
+// testFont is an embedded emulator font, so tests don't depend on system fonts
+const testFont = "../emulator/fonts/JetBrainsMono-Regular.ttf"
+
+func newTestRasterizer(t *testing.T) *graphics.TextRasterizer {
+	t.Helper()
+	f, err := graphics.LoadFont(testFont)
+	if err != nil {
+		t.Fatalf("LoadFont(%q) error: %v", testFont, err)
+	}
+	return graphics.NewTextRasterizer(f)
+}
+
+// inkBounds returns the first and last columns with black pixels, and the
+// number of black pixels
+func inkBounds(mb *graphics.MonochromeBitmap) (first, last, count int) {
+	first, last = -1, -1
+	for x := 0; x < mb.Width; x++ {
+		for y := 0; y < mb.Height; y++ {
+			if mb.GetPixel(x, y) {
+				if first < 0 {
+					first = x
+				}
+				last = x
+				count++
+			}
+		}
+	}
+	return first, last, count
+}
+
+func TestLoadFont_Missing(t *testing.T) {
+	if _, err := graphics.LoadFont("missing.ttf"); err == nil {
+		t.Error("LoadFont(missing.ttf) expected error")
+	}
+}
+
+func TestTextRasterizer_Render_Align(t *testing.T) {
+	r := newTestRasterizer(t)
+	spans := []graphics.TextSpan{{Text: "Hi", Height: constants.FontAHeight}}
+
+	left, err := r.Render(spans, 200, constants.Left)
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if left.Width != 200 || left.Height == 0 {
+		t.Fatalf("Render() size = %dx%d, want width 200", left.Width, left.Height)
+	}
+	leftFirst, leftLast, _ := inkBounds(left)
+
+	center, _ := r.Render(spans, 200, constants.Center)
+	centerFirst, centerLast, _ := inkBounds(center)
+
+	right, _ := r.Render(spans, 200, constants.Right)
+	rightFirst, rightLast, _ := inkBounds(right)
+
+	if leftFirst < 0 || leftLast > 40 {
+		t.Errorf("left ink = [%d, %d], want it near the left edge", leftFirst, leftLast)
+	}
+	if centerFirst < 80 || centerLast > 120 {
+		t.Errorf("center ink = [%d, %d], want it around 100", centerFirst, centerLast)
+	}
+	if rightFirst < 160 || rightLast >= 200 {
+		t.Errorf("right ink = [%d, %d], want it near the right edge", rightFirst, rightLast)
+	}
+}
+
+func TestTextRasterizer_Render_Styles(t *testing.T) {
+	r := newTestRasterizer(t)
+	normal, _ := r.Render([]graphics.TextSpan{{Text: "Total", Height: 24}}, 300, constants.Left)
+	bold, _ := r.Render([]graphics.TextSpan{{Text: "Total", Height: 24, Bold: true}}, 300, constants.Left)
+	wide, _ := r.Render([]graphics.TextSpan{{Text: "Total", Height: 24, ScaleX: 2}}, 300, constants.Left)
+	tall, _ := r.Render([]graphics.TextSpan{{Text: "Total", Height: 48}}, 300, constants.Left)
+
+	_, normalLast, normalInk := inkBounds(normal)
+	_, _, boldInk := inkBounds(bold)
+	_, wideLast, _ := inkBounds(wide)
+
+	if boldInk <= normalInk {
+		t.Errorf("bold ink = %d, want more than %d", boldInk, normalInk)
+	}
+	if wideLast < normalLast*2-2 {
+		t.Errorf("2x wide text ends at %d, want about %d", wideLast, normalLast*2)
+	}
+	if tall.Height <= normal.Height*3/2 {
+		t.Errorf("48 dot text height = %d, want about twice %d", tall.Height, normal.Height)
+	}
+}
+
+func TestTextRasterizer_Render_Wrap(t *testing.T) {
+	r := newTestRasterizer(t)
+	one, _ := r.Render([]graphics.TextSpan{{Text: "one", Height: 24}}, 120, constants.Left)
+
+	words, err := r.Render([]graphics.TextSpan{{Text: "one two three four", Height: 24}}, 120, constants.Left)
+	if err != nil {
+		t.Fatalf("Render() error: %v", err)
+	}
+	if words.Height < one.Height*2 {
+		t.Errorf("wrapped height = %d, want at least 2 lines of %d", words.Height, one.Height)
+	}
+
+	long, _ := r.Render([]graphics.TextSpan{{Text: strings.Repeat("x", 40), Height: 24}}, 120, constants.Left)
+	if _, last, _ := inkBounds(long); long.Height < one.Height*2 || last >= 120 {
+		t.Errorf("long word: height %d, last ink %d; want it broken inside the width", long.Height, last)
+	}
+
+	lines, _ := r.Render([]graphics.TextSpan{{Text: "a\nb", Height: 24}}, 120, constants.Left)
+	if lines.Height != one.Height*2 {
+		t.Errorf("two lines height = %d, want %d", lines.Height, one.Height*2)
+	}
+}
+
+func TestTextRasterizer_Render_Errors(t *testing.T) {
+	r := newTestRasterizer(t)
+	if _, err := r.Render(nil, 100, constants.Left); err == nil {
+		t.Error("Render(nil) expected error")
+	}
+	if _, err := r.Render([]graphics.TextSpan{{Text: "x", Height: 24}}, 0, constants.Left); err == nil {
+		t.Error("Render() with zero width expected error")
+	}
+	if _, err := r.Render([]graphics.TextSpan{{Text: "x"}}, 100, constants.Left); err == nil {
+		t.Error("Render() with zero height expected error")
+	}
+}
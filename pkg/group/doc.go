@@ -0,0 +1,33 @@
+// Package group combines several printers into one logical printer with
+// health checks, automatic failover and round-robin load sharing.
+//
+// A group is used wherever a printer name is accepted. Before each job the
+// members are tried in order: a member is skipped when its connection cannot
+// be opened or when the health check fails. The default health check queries
+// DLE EOT status on bidirectional connectors (offline, cover open, paper out)
+// and accepts connectors that cannot report status once they are connected.
+//
+// # Strategies
+//
+//	failover     Always start with the first printer (default)
+//	round_robin  Start with the next printer on every job
+//
+// With Config.Banner, a job redirected to a backup printer starts with a
+// banner naming the unavailable primary, so the kitchen knows where the
+// ticket was supposed to print.
+//
+// # Quick Start
+//
+//	reg, _ := group.NewRegistry(dial, nil, group.Config{
+//		Name:     "kitchen",
+//		Printers: []string{"Cocina-1", "Cocina-2"},
+//		Banner:   true,
+//	})
+//
+//	printer, sel, err := reg.OpenPrinter("kitchen", func(conn connection.Connector) (*service.Printer, error) {
+//		return service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), conn)
+//	})
+//	if sel.Redirected() {
+//		log.Printf("printed on %s", sel.Printer)
+//	}
+package group
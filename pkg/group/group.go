@@ -0,0 +1,181 @@
+package group
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// Strategy selects the order in which the members of a group are tried
+type Strategy string
+
+const (
+	// Failover always starts with the first printer and moves down the list
+	Failover Strategy = "failover"
+	// RoundRobin starts with the next printer on every job to share the load
+	RoundRobin Strategy = "round_robin"
+)
+
+// Config describes a printer group
+type Config struct {
+	Name     string   `json:"name"`               // Name used in place of a printer name
+	Printers []string `json:"printers"`           // Members in priority order
+	Strategy Strategy `json:"strategy,omitempty"` // Default: failover
+	Banner   bool     `json:"banner,omitempty"`   // Print a banner on redirected jobs
+}
+
+// Dialer opens the connection to a member printer
+type Dialer func(name string) (connection.Connector, error)
+
+// HealthCheck decides whether an open connection can take a job. A non-nil
+// error skips the member.
+type HealthCheck func(name string, conn connection.Connector) error
+
+// CheckStatus queries the printer with DLE EOT when the connector supports
+// it; connectors without status reads are assumed healthy once connected.
+func CheckStatus(_ string, conn connection.Connector) error {
+	status, err := service.QueryStatus(conn)
+	if errors.Is(err, service.ErrStatusUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return status.Err()
+}
+
+// Selection is the member chosen for a job
+type Selection struct {
+	Printer string               // Member that took the job
+	Primary string               // Member that should have taken it
+	Conn    connection.Connector // Open connection to Printer
+}
+
+// Redirected reports whether the job went to a backup printer
+func (s *Selection) Redirected() bool {
+	return s.Printer != s.Primary
+}
+
+// Group is an ordered list of printers with health checks and failover
+type Group struct {
+	cfg   Config
+	dial  Dialer
+	check HealthCheck
+
+	mu   sync.Mutex
+	next int
+}
+
+// New creates a group. A nil check uses CheckStatus.
+func New(cfg Config, dial Dialer, check HealthCheck) (*Group, error) {
+	if cfg.Name == "" {
+		return nil, fmt.Errorf("group name cannot be empty")
+	}
+	if len(cfg.Printers) == 0 {
+		return nil, fmt.Errorf("group %s: at least one printer is required", cfg.Name)
+	}
+	if dial == nil {
+		return nil, fmt.Errorf("group %s: dialer cannot be nil", cfg.Name)
+	}
+
+	switch Strategy(strings.ToLower(string(cfg.Strategy))) {
+	case "":
+		cfg.Strategy = Failover
+	case Failover, RoundRobin:
+		cfg.Strategy = Strategy(strings.ToLower(string(cfg.Strategy)))
+	default:
+		return nil, fmt.Errorf("group %s: unknown strategy %q (valid values: %s, %s)",
+			cfg.Name, cfg.Strategy, Failover, RoundRobin)
+	}
+
+	if check == nil {
+		check = CheckStatus
+	}
+	return &Group{cfg: cfg, dial: dial, check: check}, nil
+}
+
+// Name returns the group name
+func (g *Group) Name() string {
+	return g.cfg.Name
+}
+
+// Config returns the group configuration
+func (g *Group) Config() Config {
+	return g.cfg
+}
+
+// order returns the members in the order they should be tried
+func (g *Group) order() []string {
+	members := g.cfg.Printers
+	if g.cfg.Strategy != RoundRobin {
+		return members
+	}
+
+	g.mu.Lock()
+	start := g.next
+	g.next = (g.next + 1) % len(members)
+	g.mu.Unlock()
+
+	ordered := make([]string, 0, len(members))
+	ordered = append(ordered, members[start:]...)
+	return append(ordered, members[:start]...)
+}
+
+// Connect opens the first healthy member. Members that fail to connect or
+// fail the health check are skipped; an error is returned when none is left.
+func (g *Group) Connect() (*Selection, error) {
+	members := g.order()
+
+	var errs []error
+	for _, name := range members {
+		conn, err := g.dial(name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if err := g.check(name, conn); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			if cerr := conn.Close(); cerr != nil {
+				log.Printf("[GROUP] failed to close %s: %v", name, cerr)
+			}
+			continue
+		}
+
+		if name != members[0] {
+			log.Printf("[GROUP] %s: %s unavailable, using %s", g.cfg.Name, members[0], name)
+		}
+		return &Selection{Printer: name, Primary: members[0], Conn: conn}, nil
+	}
+
+	return nil, fmt.Errorf("group %s: no printer available: %w", g.cfg.Name, errors.Join(errs...))
+}
+
+// NewPrinterFunc wraps a connection chosen by the group in a Printer
+type NewPrinterFunc func(conn connection.Connector) (*service.Printer, error)
+
+// OpenPrinter connects to a healthy member and creates its printer. When the
+// job is redirected and Config.Banner is set, the printer Banner names the
+// primary, so the job prints it right after initializing.
+func (g *Group) OpenPrinter(newPrinter NewPrinterFunc) (*service.Printer, *Selection, error) {
+	sel, err := g.Connect()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	printer, err := newPrinter(sel.Conn)
+	if err != nil {
+		_ = sel.Conn.Close()
+		return nil, nil, err
+	}
+
+	if g.cfg.Banner && sel.Redirected() {
+		printer.Banner = fmt.Sprintf(constants.DefaultBackupBannerFormat, sel.Primary)
+	}
+	return printer, sel, nil
+}
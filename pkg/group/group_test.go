@@ -0,0 +1,278 @@
+package group
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// statusConnector answers DLE EOT requests with fixed bytes
+type statusConnector struct {
+	*connection.BufferConnector
+	answers *bytes.Reader
+}
+
+func (c *statusConnector) ReadStatus(p []byte) (int, error) {
+	return c.answers.Read(p)
+}
+
+// fleet simulates printers that are offline, out of paper or ready
+type fleet struct {
+	mu      sync.Mutex
+	down    map[string]bool   // dial fails
+	answers map[string][]byte // DLE EOT answers, nil means no status support
+	conns   map[string]*connection.BufferConnector
+	closed  map[string]int
+}
+
+func newFleet() *fleet {
+	return &fleet{
+		down:    make(map[string]bool),
+		answers: make(map[string][]byte),
+		conns:   make(map[string]*connection.BufferConnector),
+		closed:  make(map[string]int),
+	}
+}
+
+type trackedConnector struct {
+	connection.Connector
+	close func()
+}
+
+func (c *trackedConnector) Close() error {
+	c.close()
+	return c.Connector.Close()
+}
+
+func (f *fleet) dial(name string) (connection.Connector, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down[name] {
+		return nil, errors.New("not found")
+	}
+
+	buf := connection.NewBufferConnector()
+	f.conns[name] = buf
+	onClose := func() {
+		f.mu.Lock()
+		f.closed[name]++
+		f.mu.Unlock()
+	}
+
+	if answers, ok := f.answers[name]; ok {
+		return &statusTracked{statusConnector{buf, bytes.NewReader(answers)}, onClose}, nil
+	}
+	return &trackedConnector{buf, onClose}, nil
+}
+
+type statusTracked struct {
+	statusConnector
+	close func()
+}
+
+func (c *statusTracked) Close() error {
+	c.close()
+	return c.BufferConnector.Close()
+}
+
+func newPrinter(conn connection.Connector) (*service.Printer, error) {
+	return service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), conn)
+}
+
+var (
+	ready    = []byte{0x12, 0x12, 0x12}
+	paperOut = []byte{0x1A, 0x32, 0x72}
+)
+
+func TestNew_Validation(t *testing.T) {
+	f := newFleet()
+	_, err := New(Config{Printers: []string{"a"}}, f.dial, nil)
+	assert.Error(t, err)
+	_, err = New(Config{Name: "g"}, f.dial, nil)
+	assert.Error(t, err)
+	_, err = New(Config{Name: "g", Printers: []string{"a"}, Strategy: "random"}, f.dial, nil)
+	assert.ErrorContains(t, err, "unknown strategy")
+
+	g, err := New(Config{Name: "g", Printers: []string{"a"}, Strategy: "ROUND_ROBIN"}, f.dial, nil)
+	require.NoError(t, err)
+	assert.Equal(t, RoundRobin, g.Config().Strategy)
+}
+
+func TestConnect_Failover(t *testing.T) {
+	f := newFleet()
+	f.down["k1"] = true
+	f.answers["k2"] = paperOut
+	f.answers["k3"] = ready
+
+	g, err := New(Config{Name: "kitchen", Printers: []string{"k1", "k2", "k3"}}, f.dial, nil)
+	require.NoError(t, err)
+
+	sel, err := g.Connect()
+	require.NoError(t, err)
+	assert.Equal(t, "k3", sel.Printer)
+	assert.Equal(t, "k1", sel.Primary)
+	assert.True(t, sel.Redirected())
+
+	// The unhealthy member was closed after its health check
+	assert.Equal(t, 1, f.closed["k2"])
+
+	// Primary is back
+	f.down["k1"] = false
+	sel, err = g.Connect()
+	require.NoError(t, err)
+	assert.Equal(t, "k1", sel.Printer)
+	assert.False(t, sel.Redirected())
+}
+
+func TestConnect_AllUnavailable(t *testing.T) {
+	f := newFleet()
+	f.down["k1"] = true
+	f.answers["k2"] = paperOut
+
+	g, err := New(Config{Name: "kitchen", Printers: []string{"k1", "k2"}}, f.dial, nil)
+	require.NoError(t, err)
+
+	_, err = g.Connect()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "k1: not found")
+	assert.Contains(t, err.Error(), "k2: paper out")
+}
+
+func TestConnect_RoundRobin(t *testing.T) {
+	f := newFleet()
+	g, err := New(Config{Name: "bar", Printers: []string{"b1", "b2", "b3"}, Strategy: RoundRobin}, f.dial, nil)
+	require.NoError(t, err)
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		sel, err := g.Connect()
+		require.NoError(t, err)
+		assert.False(t, sel.Redirected())
+		got = append(got, sel.Printer)
+	}
+	assert.Equal(t, []string{"b1", "b2", "b3", "b1"}, got)
+
+	// A down member is skipped and the job counts as redirected
+	f.down["b2"] = true
+	sel, err := g.Connect()
+	require.NoError(t, err)
+	assert.Equal(t, "b3", sel.Printer)
+	assert.Equal(t, "b2", sel.Primary)
+}
+
+func TestOpenPrinter_Banner(t *testing.T) {
+	f := newFleet()
+	f.down["k1"] = true
+
+	g, err := New(Config{Name: "kitchen", Printers: []string{"k1", "k2"}, Banner: true}, f.dial, nil)
+	require.NoError(t, err)
+
+	printer, sel, err := g.OpenPrinter(newPrinter)
+	require.NoError(t, err)
+	defer func() { _ = printer.Close() }()
+
+	assert.Equal(t, "k2", sel.Printer)
+	assert.Empty(t, f.conns["k2"].Bytes(), "nothing is printed before the job")
+
+	// The banner follows ESC @
+	require.NoError(t, printer.Initialize())
+	out := f.conns["k2"].Bytes()
+	assert.True(t, bytes.HasPrefix(out, []byte{0x1B, '@'}))
+	assert.Contains(t, string(out), "BACKUP PRINTER - k1 unavailable")
+
+	// No banner when the primary prints
+	f.down["k1"] = false
+	printer, _, err = g.OpenPrinter(newPrinter)
+	require.NoError(t, err)
+	require.NoError(t, printer.Initialize())
+	assert.NotContains(t, string(f.conns["k1"].Bytes()), "BACKUP")
+}
+
+// downConnector rejects writes, like a link that drops after the health check
+type downConnector struct {
+	connection.Connector
+}
+
+func (c *downConnector) Write([]byte) (int, error) {
+	return 0, errors.New("link down")
+}
+
+func TestOpenPrinter_BannerOnRetry(t *testing.T) {
+	f := newFleet()
+	f.down["k1"] = true
+	dials := 0
+	dial := func(name string) (connection.Connector, error) {
+		conn, err := f.dial(name)
+		if err != nil {
+			return nil, err
+		}
+		if dials++; dials == 2 {
+			return &downConnector{conn}, nil
+		}
+		return conn, nil
+	}
+	g, err := New(Config{Name: "kitchen", Printers: []string{"k1", "k2"}, Banner: true}, dial, nil)
+	require.NoError(t, err)
+
+	// Each attempt opens the group, compiles and sends the job, like the queue
+	var printed []byte
+	attempt := func(document string) error {
+		doc, err := schema.ParseDocument([]byte(document))
+		require.NoError(t, err)
+		printer, _, err := g.OpenPrinter(newPrinter)
+		require.NoError(t, err)
+		defer func() {
+			_ = printer.Close()
+			printed = append(printed, f.conns["k2"].Bytes()...)
+		}()
+		job, err := executor.NewExecutor(printer).Compile(doc)
+		if err != nil {
+			return err
+		}
+		return printer.SendJob(job)
+	}
+
+	// A job that fails to compile prints nothing
+	require.Error(t, attempt(`{"version":"1.0","profile":{"model":"Test","code_table":"PC999"},"commands":[{"type":"cut","data":{}}]}`))
+	assert.Empty(t, printed)
+
+	// The link drops and the job is retried: the banner prints once
+	const order = `{"version":"1.0","profile":{"model":"Test"},"commands":[{"type":"text","data":{"content":{"text":"2x TACOS"}}}]}`
+	require.Error(t, attempt(order))
+	require.NoError(t, attempt(order))
+	assert.Equal(t, 1, bytes.Count(printed, []byte("BACKUP PRINTER - k1 unavailable")))
+	assert.Equal(t, 1, bytes.Count(printed, []byte("2x TACOS")))
+}
+
+func TestRegistry(t *testing.T) {
+	f := newFleet()
+	f.down["k1"] = true
+
+	reg, err := NewRegistry(f.dial, nil, Config{Name: "kitchen", Printers: []string{"k1", "k2"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"kitchen"}, reg.Names())
+	assert.Error(t, reg.Add(Config{Name: "kitchen", Printers: []string{"x"}}))
+
+	_, sel, err := reg.OpenPrinter("kitchen", newPrinter)
+	require.NoError(t, err)
+	assert.Equal(t, "k2", sel.Printer)
+
+	// Plain printers are dialed directly
+	_, sel, err = reg.OpenPrinter("receipt", newPrinter)
+	require.NoError(t, err)
+	assert.Equal(t, "receipt", sel.Printer)
+
+	_, _, err = reg.OpenPrinter("k1", newPrinter)
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package group
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// Registry resolves names to printer groups, falling back to a single
+// printer for names that are not groups. It lets every place that accepts a
+// printer name (CLI, queue, router, server) accept a group name as well.
+type Registry struct {
+	dial   Dialer
+	check  HealthCheck
+	groups map[string]*Group
+}
+
+// NewRegistry creates a registry with the given groups
+func NewRegistry(dial Dialer, check HealthCheck, configs ...Config) (*Registry, error) {
+	if dial == nil {
+		return nil, fmt.Errorf("dialer cannot be nil")
+	}
+	r := &Registry{dial: dial, check: check, groups: make(map[string]*Group)}
+	for _, cfg := range configs {
+		if err := r.Add(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Add registers a group. Names must be unique.
+func (r *Registry) Add(cfg Config) error {
+	if _, dup := r.groups[cfg.Name]; dup {
+		return fmt.Errorf("duplicate group %q", cfg.Name)
+	}
+	g, err := New(cfg, r.dial, r.check)
+	if err != nil {
+		return err
+	}
+	r.groups[cfg.Name] = g
+	return nil
+}
+
+// Group returns the named group
+func (r *Registry) Group(name string) (*Group, bool) {
+	g, ok := r.groups[name]
+	return g, ok
+}
+
+// Names returns the registered group names, sorted
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.groups))
+	for name := range r.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// OpenPrinter opens the named group, or the printer itself when name is not
+// a group. Single printers are not health checked so that jobs fail (and
+// are retried) exactly as before.
+func (r *Registry) OpenPrinter(name string, newPrinter NewPrinterFunc) (*service.Printer, *Selection, error) {
+	if g, ok := r.groups[name]; ok {
+		return g.OpenPrinter(newPrinter)
+	}
+
+	conn, err := r.dial(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	printer, err := newPrinter(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, nil, err
+	}
+	return printer, &Selection{Printer: name, Primary: name, Conn: conn}, nil
+}
@@ -0,0 +1,21 @@
+// Package numfmt writes raw numbers as receipt amounts: thousands
+// separators, a fixed number of decimals, currency symbols and negative
+// amounts with a minus sign or in parentheses, following a locale.
+//
+// Values are decimal strings such as "1234.5" or "-0.10" and are never
+// converted to floating point, so sums and rounding are exact. Rounding
+// is half away from zero.
+//
+// # Quick Start
+//
+//	spec := numfmt.Spec{Kind: numfmt.Currency, Locale: "es-MX"}
+//	s, _ := spec.Format("1234.5") // "$1,234.50"
+//
+//	spec.Negative = numfmt.Parentheses
+//	s, _ = spec.Format("-80")     // "($80.00)"
+//
+// The same Spec is used by table columns and kv values, with its fields
+// inline in the JSON of the command:
+//
+//	{"format": "currency", "decimals": 2, "locale": "es-MX", "negative": "parentheses"}
+package numfmt
@@ -0,0 +1,59 @@
+package numfmt
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the locale of a Spec without one
+const DefaultLocale = "es-MX"
+
+// Locale holds the separators and currency of a locale
+type Locale struct {
+	Decimal     string // Decimal separator
+	Group       string // Thousands separator
+	Symbol      string // Currency symbol
+	SymbolAfter bool   // "1.234,50 €" instead of "$1,234.50"
+	SymbolSpace bool   // Space between symbol and number
+	Decimals    int    // Currency decimals
+}
+
+// locales are the supported locales, by lowercase name
+var locales = map[string]Locale{
+	"es-mx": {Decimal: ".", Group: ",", Symbol: "$", Decimals: 2},
+	"en-us": {Decimal: ".", Group: ",", Symbol: "$", Decimals: 2},
+	"es-us": {Decimal: ".", Group: ",", Symbol: "$", Decimals: 2},
+	"en-gb": {Decimal: ".", Group: ",", Symbol: "£", Decimals: 2},
+	"es-es": {Decimal: ",", Group: ".", Symbol: "€", SymbolAfter: true, SymbolSpace: true, Decimals: 2},
+	"de-de": {Decimal: ",", Group: ".", Symbol: "€", SymbolAfter: true, SymbolSpace: true, Decimals: 2},
+	"fr-fr": {Decimal: ",", Group: " ", Symbol: "€", SymbolAfter: true, SymbolSpace: true, Decimals: 2},
+	"pt-br": {Decimal: ",", Group: ".", Symbol: "R$", SymbolSpace: true, Decimals: 2},
+	"es-co": {Decimal: ",", Group: ".", Symbol: "$", Decimals: 2},
+	"es-ar": {Decimal: ",", Group: ".", Symbol: "$", SymbolSpace: true, Decimals: 2},
+	"es-cl": {Decimal: ",", Group: ".", Symbol: "$", Decimals: 0},
+}
+
+// LookupLocale returns a locale by name, e.g. "es-MX" or "en_US"; an empty
+// name is DefaultLocale
+func LookupLocale(name string) (Locale, error) {
+	if name == "" {
+		name = DefaultLocale
+	}
+	loc, ok := locales[strings.ReplaceAll(strings.ToLower(name), "_", "-")]
+	if !ok {
+		return Locale{}, fmt.Errorf("unknown locale %q (use %s)", name, localeNames())
+	}
+	return loc, nil
+}
+
+// localeNames lists the supported locales
+func localeNames() string {
+	names := make([]string, 0, len(locales))
+	for name := range locales {
+		lang, region, _ := strings.Cut(name, "-")
+		names = append(names, lang+"-"+strings.ToUpper(region))
+	}
+	sort.Strings(names)
+	return strings.Join(names[:len(names)-1], ", ") + " or " + names[len(names)-1]
+}
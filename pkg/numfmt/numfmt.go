@@ -0,0 +1,220 @@
+package numfmt
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Kind is the kind of number a Spec writes
+type Kind string
+
+// Kinds
+const (
+	None     Kind = ""         // Text, left as is
+	Number   Kind = "number"   // Plain number with thousands separators
+	Currency Kind = "currency" // Number with the currency symbol
+)
+
+// Negative is how a Spec writes negative numbers
+type Negative string
+
+// Negative styles
+const (
+	Minus       Negative = "minus"       // -$1,234.50 (default)
+	Parentheses Negative = "parentheses" // ($1,234.50)
+)
+
+// MaxDecimals is the largest number of decimals a Spec accepts
+const MaxDecimals = 8
+
+// Spec describes how to write a number. Its JSON fields are meant to be
+// inlined in the command that formats values.
+type Spec struct {
+	Kind      Kind     `json:"format,omitempty"`
+	Decimals  *int     `json:"decimals,omitempty"`  // Default: 2 for currency, as written for numbers
+	Locale    string   `json:"locale,omitempty"`    // Default: DefaultLocale
+	Thousands *bool    `json:"thousands,omitempty"` // Thousands separators, default true
+	Negative  Negative `json:"negative,omitempty"`  // Default: Minus
+	Symbol    string   `json:"symbol,omitempty"`    // Currency symbol instead of the locale one
+}
+
+// ParseKind parses a format name
+func ParseKind(s string) (Kind, error) {
+	switch k := Kind(strings.ToLower(s)); k {
+	case None, Number, Currency:
+		return k, nil
+	}
+	return None, fmt.Errorf("unknown format %q (use number or currency)", s)
+}
+
+// ParseNegative parses a negative style
+func ParseNegative(s string) (Negative, error) {
+	switch n := Negative(strings.ToLower(s)); n {
+	case "", Minus:
+		return Minus, nil
+	case Parentheses:
+		return n, nil
+	}
+	return Minus, fmt.Errorf("unknown negative style %q (use minus or parentheses)", s)
+}
+
+// IsZero reports whether the spec leaves values as they are
+func (s Spec) IsZero() bool {
+	return s.Kind == None
+}
+
+// Validate checks the kind, locale, decimals and negative style
+func (s Spec) Validate() error {
+	if _, err := ParseKind(string(s.Kind)); err != nil {
+		return err
+	}
+	if _, err := LookupLocale(s.Locale); err != nil {
+		return err
+	}
+	if s.Decimals != nil && (*s.Decimals < 0 || *s.Decimals > MaxDecimals) {
+		return fmt.Errorf("invalid decimals %d (use 0 to %d)", *s.Decimals, MaxDecimals)
+	}
+	if _, err := ParseNegative(string(s.Negative)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// DecimalSeparator returns the decimal separator of the spec locale, "."
+// for unknown locales
+func (s Spec) DecimalSeparator() string {
+	loc, err := LookupLocale(s.Locale)
+	if err != nil {
+		return "."
+	}
+	return loc.Decimal
+}
+
+// Format writes a raw number such as "1234.5" or "-3". Empty values stay
+// empty, and a spec without a kind returns the value as is.
+func (s Spec) Format(value string) (string, error) {
+	if s.IsZero() || strings.TrimSpace(value) == "" {
+		return value, nil
+	}
+	n, decimals, err := Parse(value)
+	if err != nil {
+		return "", err
+	}
+	return s.format(n, decimals)
+}
+
+// Sum adds raw numbers and writes the sum; empty values are skipped and
+// numbers keep the most decimals written
+func (s Spec) Sum(values []string) (string, error) {
+	sum := new(big.Rat)
+	decimals, count := 0, 0
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		n, d, err := Parse(value)
+		if err != nil {
+			return "", err
+		}
+		sum.Add(sum, n)
+		decimals = max(decimals, d)
+		count++
+	}
+	if count == 0 {
+		return "", nil
+	}
+	if s.IsZero() {
+		s.Kind = Number
+		s.Thousands = new(bool)
+	}
+	return s.format(sum, decimals)
+}
+
+// Parse reads a raw number: an optional sign, digits and an optional
+// fraction after '.'. It returns the number and its decimals.
+func Parse(value string) (*big.Rat, int, error) {
+	s := strings.TrimSpace(value)
+	digits := strings.TrimLeft(s, "+-")
+	whole, frac, found := strings.Cut(digits, ".")
+	if len(s)-len(digits) > 1 || whole == "" || (found && frac == "") || !isDigits(whole) || !isDigits(frac) {
+		return nil, 0, fmt.Errorf("invalid number %q", value)
+	}
+	n, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return nil, 0, fmt.Errorf("invalid number %q", value)
+	}
+	return n, len(frac), nil
+}
+
+// isDigits reports whether s has only ASCII digits
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// format writes n with the spec; decimals is the number of decimals the
+// value was written with
+func (s Spec) format(n *big.Rat, decimals int) (string, error) {
+	if err := s.Validate(); err != nil {
+		return "", err
+	}
+	loc, _ := LookupLocale(s.Locale)
+	switch {
+	case s.Decimals != nil:
+		decimals = *s.Decimals
+	case s.Kind == Currency:
+		decimals = loc.Decimals
+	}
+
+	digits := new(big.Rat).Abs(n).FloatString(decimals)
+	whole, frac, _ := strings.Cut(digits, ".")
+	if s.Thousands == nil || *s.Thousands {
+		whole = group(whole, loc.Group)
+	}
+	number := whole
+	if frac != "" {
+		number += loc.Decimal + frac
+	}
+
+	if s.Kind == Currency {
+		symbol := loc.Symbol
+		if s.Symbol != "" {
+			symbol = s.Symbol
+		}
+		space := ""
+		if loc.SymbolSpace {
+			space = " "
+		}
+		if loc.SymbolAfter {
+			number += space + symbol
+		} else {
+			number = symbol + space + number
+		}
+	}
+
+	// Zero after rounding has no sign
+	if n.Sign() >= 0 || strings.Trim(digits, "0.") == "" {
+		return number, nil
+	}
+	if s.Negative == Parentheses {
+		return "(" + number + ")", nil
+	}
+	return "-" + number, nil
+}
+
+// group inserts sep every three digits from the right
+func group(digits, sep string) string {
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteString(sep)
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
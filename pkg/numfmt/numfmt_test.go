@@ -0,0 +1,102 @@
+package numfmt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/numfmt"
+)
+
+// Naming Convention: Test{Function}_{Scenario}
+
+func intPtr(n int) *int { return &n }
+
+func TestSpec_Format(t *testing.T) {
+	noGroups := false
+	tests := []struct {
+		name  string
+		spec  numfmt.Spec
+		value string
+		want  string
+	}{
+		{"currency es-MX", numfmt.Spec{Kind: numfmt.Currency}, "1234.5", "$1,234.50"},
+		{"currency rounds half away from zero", numfmt.Spec{Kind: numfmt.Currency}, "0.125", "$0.13"},
+		{"negative currency", numfmt.Spec{Kind: numfmt.Currency}, "-80", "-$80.00"},
+		{"negative in parentheses", numfmt.Spec{Kind: numfmt.Currency, Negative: numfmt.Parentheses}, "-1234567.891", "($1,234,567.89)"},
+		{"negative zero after rounding", numfmt.Spec{Kind: numfmt.Currency}, "-0.001", "$0.00"},
+		{"currency es-ES", numfmt.Spec{Kind: numfmt.Currency, Locale: "es_ES"}, "1234.5", "1.234,50 €"},
+		{"currency pt-BR", numfmt.Spec{Kind: numfmt.Currency, Locale: "pt-BR"}, "99", "R$ 99,00"},
+		{"currency symbol", numfmt.Spec{Kind: numfmt.Currency, Symbol: "MX$"}, "5", "MX$5.00"},
+		{"currency no decimals", numfmt.Spec{Kind: numfmt.Currency, Locale: "es-CL"}, "1500.5", "$1.501"},
+		{"number keeps decimals", numfmt.Spec{Kind: numfmt.Number}, "12345.250", "12,345.250"},
+		{"number decimals", numfmt.Spec{Kind: numfmt.Number, Decimals: intPtr(1)}, "2.25", "2.3"},
+		{"number without thousands", numfmt.Spec{Kind: numfmt.Number, Thousands: &noGroups}, "12345", "12345"},
+		{"plus sign", numfmt.Spec{Kind: numfmt.Number}, "+7", "7"},
+		{"empty value", numfmt.Spec{Kind: numfmt.Currency}, " ", " "},
+		{"no kind", numfmt.Spec{}, "abc", "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.spec.Format(tt.value)
+			if err != nil {
+				t.Fatalf("Format(%q) error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("Format(%q) = %q; want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpec_Format_Errors(t *testing.T) {
+	tests := []struct {
+		spec  numfmt.Spec
+		value string
+		want  string
+	}{
+		{numfmt.Spec{Kind: numfmt.Currency}, "$5.00", `invalid number "$5.00"`},
+		{numfmt.Spec{Kind: numfmt.Currency}, "1,000", `invalid number "1,000"`},
+		{numfmt.Spec{Kind: numfmt.Currency}, "1/3", `invalid number "1/3"`},
+		{numfmt.Spec{Kind: numfmt.Currency}, "--1", `invalid number "--1"`},
+		{numfmt.Spec{Kind: numfmt.Currency}, "1.", `invalid number "1."`},
+		{numfmt.Spec{Kind: "percent"}, "1", `unknown format "percent"`},
+		{numfmt.Spec{Kind: numfmt.Currency, Locale: "xx-XX"}, "1", `unknown locale "xx-XX"`},
+		{numfmt.Spec{Kind: numfmt.Currency, Decimals: intPtr(9)}, "1", "invalid decimals 9"},
+		{numfmt.Spec{Kind: numfmt.Currency, Negative: "red"}, "1", `unknown negative style "red"`},
+	}
+
+	for _, tt := range tests {
+		if _, err := tt.spec.Format(tt.value); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Format(%q) error = %v; want %q", tt.value, err, tt.want)
+		}
+	}
+}
+
+func TestSpec_Sum(t *testing.T) {
+	spec := numfmt.Spec{Kind: numfmt.Currency}
+	got, err := spec.Sum([]string{"0.1", "0.2", "", "1000"})
+	if err != nil {
+		t.Fatalf("Sum error: %v", err)
+	}
+	if got != "$1,000.30" {
+		t.Errorf("Sum() = %q; want %q", got, "$1,000.30")
+	}
+
+	got, err = numfmt.Spec{}.Sum([]string{"1.5", "2.25"})
+	if err != nil || got != "3.75" {
+		t.Errorf("Sum() = %q, %v; want %q", got, err, "3.75")
+	}
+}
+
+func TestLookupLocale(t *testing.T) {
+	loc, err := numfmt.LookupLocale("")
+	if err != nil || loc.Decimal != "." || loc.Symbol != "$" {
+		t.Errorf("LookupLocale(\"\") = %+v, %v; want es-MX", loc, err)
+	}
+
+	_, err = numfmt.LookupLocale("tlh")
+	if err == nil || !strings.Contains(err.Error(), "es-MX") {
+		t.Errorf("LookupLocale(\"tlh\") error = %v; want the supported locales", err)
+	}
+}
@@ -0,0 +1,53 @@
+package profile
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sync"
+)
+
+// Names of the generic built-in profiles
+const (
+	Generic58mm = "generic-58mm"
+	Generic80mm = "generic-80mm"
+)
+
+//go:embed profiles/*.json
+var builtinFiles embed.FS
+
+var (
+	builtinOnce sync.Once
+	builtins    *Registry
+)
+
+// Builtin returns a new registry with the profiles shipped with the library
+// (pkg/profile/profiles). Callers can load more profile files into it.
+func Builtin() *Registry {
+	r := NewRegistry()
+	files, err := builtinFiles.ReadDir("profiles")
+	if err != nil {
+		panic(fmt.Sprintf("profile: built-in profiles: %v", err))
+	}
+	for _, f := range files {
+		name := path.Join("profiles", f.Name())
+		data, err := builtinFiles.ReadFile(name)
+		if err != nil {
+			panic(fmt.Sprintf("profile: built-in profiles: %v", err))
+		}
+		if err := r.Parse(data, name); err != nil {
+			panic(fmt.Sprintf("profile: built-in profiles: %v", err))
+		}
+	}
+	return r
+}
+
+// mustBuiltin returns a copy of a built-in profile
+func mustBuiltin(name string) *Escpos {
+	builtinOnce.Do(func() { builtins = Builtin() })
+	p, err := builtins.Get(name)
+	if err != nil {
+		panic(fmt.Sprintf("profile: built-in profiles: %v", err))
+	}
+	return p
+}
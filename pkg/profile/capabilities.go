@@ -0,0 +1,290 @@
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+)
+
+// capabilitiesCodePages maps the code page names of escpos-printer-db to
+// code tables. Pages without a code table (CP3011, ISO_8859-1 on some
+// models...) are reported by ImportCapabilities.
+var capabilitiesCodePages = map[string]character.CodeTable{
+	"CP437":       character.PC437,
+	"CP932":       character.Katakana,
+	"CP850":       character.PC850,
+	"CP860":       character.PC860,
+	"CP863":       character.PC863,
+	"CP865":       character.PC865,
+	"CP851":       character.PC851,
+	"CP853":       character.PC853,
+	"CP857":       character.PC857,
+	"CP737":       character.PC737,
+	"ISO_8859-7":  character.ISO88597,
+	"CP1252":      character.WPC1252,
+	"CP866":       character.PC866,
+	"CP852":       character.PC852,
+	"CP858":       character.PC858,
+	"Thai42":      character.ThaiCode42,
+	"Thai11":      character.ThaiCode11,
+	"Thai13":      character.ThaiCode13,
+	"Thai14":      character.ThaiCode14,
+	"Thai16":      character.ThaiCode16,
+	"Thai17":      character.ThaiCode17,
+	"Thai18":      character.ThaiCode18,
+	"TCVN-3-1":    character.TCVN31,
+	"TCVN-3-2":    character.TCVN32,
+	"CP720":       character.PC720,
+	"CP775":       character.WPC775,
+	"CP855":       character.PC855,
+	"CP861":       character.PC861,
+	"CP862":       character.PC862,
+	"CP864":       character.PC864,
+	"CP869":       character.PC869,
+	"ISO_8859-2":  character.ISO88592,
+	"ISO_8859-15": character.ISO885915,
+	"CP1098":      character.PC1098,
+	"CP1118":      character.PC1118,
+	"CP1119":      character.PC1119,
+	"CP1125":      character.PC1125,
+	"CP1250":      character.WPC1250,
+	"CP1251":      character.WPC1251,
+	"CP1253":      character.WPC1253,
+	"CP1254":      character.WPC1254,
+	"CP1255":      character.WPC1255,
+	"CP1256":      character.WPC1256,
+	"CP1257":      character.WPC1257,
+	"CP1258":      character.WPC1258,
+	"RK1048":      character.KZ1048,
+}
+
+// Capabilities is the capabilities.json file of escpos-printer-db, the
+// printer database shared by python-escpos and escpos-php. Only the parts
+// used by ImportCapabilities are decoded.
+type Capabilities struct {
+	Profiles map[string]CapabilitiesProfile `json:"profiles"`
+}
+
+// CapabilitiesProfile is one model of capabilities.json
+type CapabilitiesProfile struct {
+	Name      string                      `json:"name"`
+	Vendor    string                      `json:"vendor"`
+	Notes     string                      `json:"notes"`
+	CodePages map[string]string           `json:"codePages"` // ESC t number -> code page name
+	Features  map[string]bool             `json:"features"`
+	Fonts     map[string]CapabilitiesFont `json:"fonts"` // ESC M number -> font
+	Media     struct {
+		DPI   capabilitiesNumber `json:"dpi"`
+		Width struct {
+			MM     capabilitiesNumber `json:"mm"`
+			Pixels capabilitiesNumber `json:"pixels"`
+		} `json:"width"`
+	} `json:"media"`
+}
+
+// CapabilitiesFont is a font of capabilities.json
+type CapabilitiesFont struct {
+	Name    string `json:"name"`
+	Columns int    `json:"columns"`
+}
+
+// capabilitiesNumber is a number that the database writes as "Unknown"
+// when it is not known
+type capabilitiesNumber float64
+
+func (n *capabilitiesNumber) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		*n = 0
+		return nil
+	}
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return err
+	}
+	*n = capabilitiesNumber(f)
+	return nil
+}
+
+// CapabilitiesImport is the result of ImportCapabilities
+type CapabilitiesImport struct {
+	Definitions []Definition
+	Warnings    []string // Code pages that could not be mapped, non ESC/POS models
+}
+
+// IsCapabilities reports whether data looks like a capabilities.json file
+func IsCapabilities(data []byte) bool {
+	var probe struct {
+		Encodings json.RawMessage `json:"encodings"`
+		Profiles  json.RawMessage `json:"profiles"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return len(probe.Profiles) > 0 && bytes.HasPrefix(bytes.TrimSpace(probe.Profiles), []byte("{"))
+}
+
+// ImportCapabilities converts the models of a capabilities.json file into
+// profile definitions, sorted by name. The name of each definition is the
+// lowercase database key (e.g. "tm-t88iii", the key itself is an alias) and
+// code_pages keeps the ESC t number of every code page of the model.
+func ImportCapabilities(data []byte) (*CapabilitiesImport, error) {
+	var caps Capabilities
+	if err := json.Unmarshal(data, &caps); err != nil {
+		return nil, fmt.Errorf("invalid capabilities file: %w", err)
+	}
+	if len(caps.Profiles) == 0 {
+		return nil, fmt.Errorf("capabilities file has no profiles")
+	}
+
+	keys := make([]string, 0, len(caps.Profiles))
+	for key := range caps.Profiles {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := &CapabilitiesImport{}
+	for _, key := range keys {
+		def, warnings := importCapabilitiesProfile(key, caps.Profiles[key])
+		result.Definitions = append(result.Definitions, def)
+		result.Warnings = append(result.Warnings, warnings...)
+	}
+	return result, nil
+}
+
+func importCapabilitiesProfile(key string, cp CapabilitiesProfile) (Definition, []string) {
+	var warnings []string
+	def := Definition{
+		Name:  strings.ToLower(key),
+		Model: strings.TrimSpace(cp.Name),
+	}
+	if def.Name != key {
+		def.Aliases = []string{key}
+	}
+	// "Star TSP600" already names its vendor "Star Micronics"
+	if vendor := strings.Fields(cp.Vendor); len(vendor) > 0 &&
+		!strings.HasPrefix(strings.ToLower(def.Model), strings.ToLower(vendor[0])) {
+		def.Model = strings.TrimSpace(cp.Vendor + " " + def.Model)
+	}
+	if cp.Features["starCommands"] {
+		warnings = append(warnings, fmt.Sprintf("%s: uses Star commands, needs ESC/POS emulation", key))
+	}
+
+	// Media
+	def.DPI = int(cp.Media.DPI)
+	def.DotsPerLine = int(cp.Media.Width.Pixels)
+	if mm := int(cp.Media.Width.MM); mm > 0 {
+		def.PrintWidth = mm
+		def.PaperWidth = paperWidthFor(mm)
+	}
+
+	// Features
+	f := cp.Features
+	def.SupportsBarcode = f["barcodeA"] || f["barcodeB"]
+	def.HasQR = f["qrCode"]
+	def.SupportsCutter = f["paperFullCut"] || f["paperPartCut"]
+	def.SupportsDrawer = f["pulseStandard"] || f["pulseBel"]
+	switch {
+	case f["bitImageRaster"]:
+		def.ImageMode = ImageRaster
+	case f["graphics"]:
+		def.ImageMode = ImageGraphics
+	case f["bitImageColumn"]:
+		def.ImageMode = ImageBitImage
+	}
+	def.SupportsGraphics = def.ImageMode != ""
+	switch {
+	case f["paperPartCut"]:
+		def.Cut = &Cut{Mode: CutPartial}
+	case f["paperFullCut"]:
+		def.Cut = &Cut{Mode: CutFull}
+	}
+
+	// Fonts, ESC M 0 is font A
+	for _, n := range sortedIndexes(cp.Fonts) {
+		font := cp.Fonts[strconv.Itoa(n)]
+		if font.Columns <= 0 || n > 25 {
+			continue
+		}
+		f := Font{Name: string(rune('A' + n)), Columns: font.Columns}
+		if def.DotsPerLine > 0 {
+			f.Width = def.DotsPerLine / font.Columns
+		}
+		def.Fonts = append(def.Fonts, f)
+	}
+
+	// Code pages, keeping the first ESC t number of each table
+	var unmapped []string
+	for _, n := range sortedIndexes(cp.CodePages) {
+		name := cp.CodePages[strconv.Itoa(n)]
+		if name == "" || strings.EqualFold(name, "Unknown") {
+			continue
+		}
+		table, ok := capabilitiesCodePages[name]
+		if !ok || n > 255 {
+			unmapped = append(unmapped, fmt.Sprintf("%d=%s", n, name))
+			continue
+		}
+		if def.CodePages == nil {
+			def.CodePages = make(map[string]int)
+		}
+		tableName := CodeTableName(table)
+		if _, dup := def.CodePages[tableName]; !dup {
+			def.CodePages[tableName] = n
+		}
+	}
+	if len(unmapped) > 0 {
+		warnings = append(warnings, fmt.Sprintf("%s: code pages without a code table: %s", key, strings.Join(unmapped, ", ")))
+	}
+	def.CodeTable = defaultCodeTable(def.CodePages)
+
+	return def, warnings
+}
+
+// paperWidthFor returns the roll width for a printable width in mm
+func paperWidthFor(printMM int) float64 {
+	switch {
+	case printMM <= 48:
+		return 58
+	case printMM <= 72:
+		return 80
+	default:
+		return float64(printMM + 8)
+	}
+}
+
+// defaultCodeTable picks the code table of an imported model, preferring
+// the Latin tables used by the documents
+func defaultCodeTable(codePages map[string]int) string {
+	if len(codePages) == 0 {
+		return ""
+	}
+	for _, name := range []string{"WPC1252", "PC850", "PC858", "PC437"} {
+		if _, ok := codePages[name]; ok {
+			return name
+		}
+	}
+	first, firstIndex := "", 256
+	for name, n := range codePages {
+		if n < firstIndex || (n == firstIndex && name < first) {
+			first, firstIndex = name, n
+		}
+	}
+	return first
+}
+
+// sortedIndexes returns the numeric keys of m in ascending order
+func sortedIndexes[V any](m map[string]V) []int {
+	indexes := make([]int, 0, len(m))
+	for key := range m {
+		if n, err := strconv.Atoi(key); err == nil && n >= 0 {
+			indexes = append(indexes, n)
+		}
+	}
+	sort.Ints(indexes)
+	return indexes
+}
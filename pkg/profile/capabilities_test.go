@@ -0,0 +1,113 @@
+package profile_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+// capabilitiesSample follows the layout of escpos-printer-db dist/capabilities.json
+const capabilitiesSample = `{
+  "encodings": {"CP437": {"name": "CP437", "python_encode": "cp437"}},
+  "profiles": {
+    "TM-T88III": {
+      "codePages": {"0": "CP437", "1": "CP932", "2": "CP850", "16": "CP1252", "19": "CP858", "255": "Unknown"},
+      "colors": {"0": "black"},
+      "features": {"barcodeA": true, "bitImageRaster": true, "graphics": false, "paperFullCut": true, "paperPartCut": true, "pulseStandard": true, "qrCode": false, "starCommands": false},
+      "fonts": {"0": {"columns": 42, "name": "Font A"}, "1": {"columns": 56, "name": "Font B"}},
+      "media": {"dpi": 180, "width": {"mm": 72, "pixels": 512}},
+      "name": "TM-T88III",
+      "notes": "",
+      "vendor": "Epson"
+    },
+    "XP-58": {
+      "codePages": {"0": "CP437", "2": "CP850", "16": "CP1252", "17": "CP866", "71": "CP1252", "72": "CP3011"},
+      "features": {"barcodeB": true, "bitImageColumn": true, "paperFullCut": false, "qrCode": true},
+      "fonts": {"0": {"columns": 32, "name": "Font A"}},
+      "media": {"dpi": 203, "width": {"mm": 48, "pixels": 384}},
+      "name": "XP-58",
+      "vendor": "Xprinter"
+    },
+    "TSP600": {
+      "codePages": {"0": "CP437", "4": "CP858", "32": "CP1252"},
+      "features": {"starCommands": true, "graphics": true},
+      "fonts": {"0": {"columns": 48, "name": "Font A"}},
+      "media": {"dpi": "Unknown", "width": {"mm": "Unknown", "pixels": "Unknown"}},
+      "name": "Star TSP600",
+      "vendor": "Star Micronics"
+    }
+  }
+}`
+
+func TestImportCapabilities(t *testing.T) {
+	imported, err := profile.ImportCapabilities([]byte(capabilitiesSample))
+	if err != nil {
+		t.Fatalf("ImportCapabilities error: %v", err)
+	}
+	if len(imported.Definitions) != 3 {
+		t.Fatalf("expected 3 definitions, got %d", len(imported.Definitions))
+	}
+
+	epson := imported.Definitions[0]
+	if epson.Name != "tm-t88iii" || epson.Model != "Epson TM-T88III" || epson.Aliases[0] != "TM-T88III" {
+		t.Errorf("unexpected identity: %s %q %v", epson.Name, epson.Model, epson.Aliases)
+	}
+	if epson.PaperWidth != 80 || epson.PrintWidth != 72 || epson.DPI != 180 || epson.DotsPerLine != 512 {
+		t.Errorf("unexpected media: %+v", epson)
+	}
+	if epson.ImageMode != profile.ImageRaster || epson.Cut.Mode != profile.CutPartial || !epson.SupportsDrawer || epson.HasQR {
+		t.Errorf("unexpected features: %+v", epson)
+	}
+	if len(epson.Fonts) != 2 || epson.Fonts[1].Name != "B" || epson.Fonts[1].Columns != 56 || epson.Fonts[1].Width != 9 {
+		t.Errorf("unexpected fonts: %+v", epson.Fonts)
+	}
+	if epson.CodeTable != "WPC1252" || epson.CodePages["KATAKANA"] != 1 || epson.CodePages["PC858"] != 19 {
+		t.Errorf("unexpected code pages: %s %v", epson.CodeTable, epson.CodePages)
+	}
+
+	warnings := strings.Join(imported.Warnings, "\n")
+	for _, want := range []string{"XP-58: code pages without a code table: 72=CP3011", "TSP600: uses Star commands"} {
+		if !strings.Contains(warnings, want) {
+			t.Errorf("expected warning %q in %q", want, warnings)
+		}
+	}
+}
+
+func TestRegistry_ParseCapabilities(t *testing.T) {
+	reg := profile.Builtin()
+	if err := reg.Parse([]byte(capabilitiesSample), "capabilities.json"); err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+	if err := reg.Validate(); err != nil {
+		t.Fatalf("Validate error: %v", err)
+	}
+
+	// Xprinter puts CP1252 at 16 and again at 71, the first number is kept
+	xp, err := reg.Resolve("Xprinter XP-58")
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if xp.PaperWidth != 58 || xp.ImageMode != profile.ImageBitImage || xp.SupportsCutter || !xp.HasQR {
+		t.Errorf("unexpected profile: %+v", xp)
+	}
+	if xp.CodePageIndex(character.WPC1252) != 16 || xp.CodePageIndex(character.PC866) != 17 {
+		t.Errorf("unexpected code page numbers: %v", xp.CodePages)
+	}
+	if xp.IsSupported(character.PC852) {
+		t.Error("expected only the model code pages to be supported")
+	}
+
+	// Star numbers its tables differently
+	star, err := reg.Get("tsp600")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if star.CodePageIndex(character.WPC1252) != 32 || star.CodePageIndex(character.PC858) != 4 {
+		t.Errorf("unexpected code page numbers: %v", star.CodePages)
+	}
+	if star.DPI != 0 || star.DotsPerLine != 0 || star.Columns("A") != 48 {
+		t.Errorf("expected unknown media to stay unset: %+v", star)
+	}
+}
@@ -0,0 +1,150 @@
+package profile
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Built-in charmaps for ESC/POS code pages missing from golang.org/x/text
+var (
+	codePage737  = newCharmap("PC737", &pc737High, nil)
+	codePage775  = newCharmap("PC775", &pc775High, nil)
+	codePage851  = newCharmap("PC851", &pc851High, nil)
+	codePage857  = newCharmap("PC857", &pc857High, nil)
+	codePage861  = newCharmap("PC861", &pc861High, nil)
+	codePage864  = newCharmap("PC864", &pc864High, map[byte]rune{0x25: 0x066A})
+	codePage869  = newCharmap("PC869", &pc869High, nil)
+	codePage720  = newCharmap("PC720", &pc720High, nil)
+	codePage1125 = newCharmap("PC1125", &pc1125High, nil)
+	codePageKZ   = newCharmap("KZ-1048", &kz1048High, nil)
+	codePageTIS  = newCharmap("TIS-620", &tis620High, nil)
+	codePageTCVN = newCharmap("TCVN-3", &tcvn3High, nil)
+	codePageJIS  = newCharmap("JIS X 0201", &katakanaHigh, nil)
+)
+
+// byteCharmap is a single-byte encoding: ASCII in the lower half, except for
+// the bytes listed in low, and a 128-entry table for the upper half.
+type byteCharmap struct {
+	name   string
+	low    map[byte]rune
+	high   *[128]rune
+	encode map[rune]byte
+}
+
+// newCharmap builds the reverse table once so encoding is a map lookup
+func newCharmap(name string, high *[128]rune, low map[byte]rune) *byteCharmap {
+	c := &byteCharmap{name: name, low: low, high: high, encode: make(map[rune]byte, len(high)+len(low))}
+	for i, r := range high {
+		if r != 0 {
+			c.encode[r] = byte(0x80 + i)
+		}
+	}
+	for b, r := range low {
+		c.encode[r] = b
+	}
+	return c
+}
+
+// String returns the code page name
+func (c *byteCharmap) String() string {
+	return c.name
+}
+
+// NewDecoder implements encoding.Encoding
+func (c *byteCharmap) NewDecoder() *encoding.Decoder {
+	return &encoding.Decoder{Transformer: charmapDecoder{c: c}}
+}
+
+// NewEncoder implements encoding.Encoding
+func (c *byteCharmap) NewEncoder() *encoding.Encoder {
+	return &encoding.Encoder{Transformer: charmapEncoder{c: c}}
+}
+
+// decodeRune maps a byte to its rune, utf8.RuneError for unassigned bytes
+func (c *byteCharmap) decodeRune(b byte) rune {
+	if b < utf8.RuneSelf {
+		if r, ok := c.low[b]; ok {
+			return r
+		}
+		return rune(b)
+	}
+	if r := c.high[b-0x80]; r != 0 {
+		return r
+	}
+	return utf8.RuneError
+}
+
+// encodeRune maps a rune to its byte
+func (c *byteCharmap) encodeRune(r rune) (byte, bool) {
+	if r < utf8.RuneSelf {
+		return byte(r), true
+	}
+	b, ok := c.encode[r]
+	return b, ok
+}
+
+// unsupportedRuneError reports a rune the code page cannot represent. Its
+// Replacement method lets encoding.ReplaceUnsupported substitute '?'.
+type unsupportedRuneError struct {
+	r    rune
+	name string
+}
+
+func (e unsupportedRuneError) Error() string {
+	return fmt.Sprintf("encoding: rune %q not supported by %s", e.r, e.name)
+}
+
+// Replacement returns the byte used by encoding.ReplaceUnsupported
+func (e unsupportedRuneError) Replacement() byte {
+	return '?'
+}
+
+type charmapDecoder struct {
+	transform.NopResetter
+	c *byteCharmap
+}
+
+// Transform implements transform.Transformer
+func (d charmapDecoder) Transform(dst, src []byte, _ bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		r := d.c.decodeRune(src[nSrc])
+		if nDst+utf8.RuneLen(r) > len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		nDst += utf8.EncodeRune(dst[nDst:], r)
+		nSrc++
+	}
+	return nDst, nSrc, nil
+}
+
+type charmapEncoder struct {
+	transform.NopResetter
+	c *byteCharmap
+}
+
+// Transform implements transform.Transformer
+func (e charmapEncoder) Transform(dst, src []byte, atEOF bool) (nDst, nSrc int, err error) {
+	for nSrc < len(src) {
+		if nDst >= len(dst) {
+			return nDst, nSrc, transform.ErrShortDst
+		}
+		r, size := rune(src[nSrc]), 1
+		if r >= utf8.RuneSelf {
+			if !atEOF && !utf8.FullRune(src[nSrc:]) {
+				return nDst, nSrc, transform.ErrShortSrc
+			}
+			r, size = utf8.DecodeRune(src[nSrc:])
+		}
+		b, ok := e.c.encodeRune(r)
+		if !ok {
+			return nDst, nSrc, unsupportedRuneError{r: r, name: e.c.name}
+		}
+		dst[nDst] = b
+		nDst++
+		nSrc += size
+	}
+	return nDst, nSrc, nil
+}
@@ -0,0 +1,121 @@
+package profile
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+)
+
+// TextRun is a piece of text encoded with a single code table, or with the
+// double-byte character set of the profile when DoubleByte is set
+type TextRun struct {
+	CodeTable  character.CodeTable
+	DoubleByte bool
+	Data       []byte
+}
+
+// EncodeRuns encodes text for printing, switching code tables when needed.
+// Without AutoCodeTables or DoubleByte it returns one run in the profile
+// code table, like EncodeString. Otherwise a rune the current table cannot
+// encode starts a new run in the first table of AutoCodeTables that can, or
+// in a double-byte run for CJK text (two-byte characters only). The
+// following runes stay in that run while it can encode them, so switches
+// only happen when the script changes.
+func (e *Escpos) EncodeRuns(text string) ([]TextRun, error) {
+	if len(e.AutoCodeTables) == 0 && e.DoubleByte == "" {
+		data, err := e.EncodeString(text)
+		if err != nil {
+			return nil, err
+		}
+		return []TextRun{{CodeTable: e.CodeTable, Data: []byte(data)}}, nil
+	}
+
+	encoders := make(map[character.CodeTable]*encoding.Encoder)
+	encodeRune := func(table character.CodeTable, r rune) ([]byte, bool) {
+		enc, ok := encoders[table]
+		if !ok {
+			if cm, err := Encoding(table); err == nil && e.IsSupported(table) {
+				enc = cm.NewEncoder()
+			}
+			encoders[table] = enc
+		}
+		if enc == nil {
+			return nil, false
+		}
+		b, err := enc.Bytes([]byte(string(r)))
+		return b, err == nil
+	}
+
+	var doubleByte *encoding.Encoder
+	if e.DoubleByte != "" {
+		enc, err := e.DoubleByte.Encoding()
+		if err != nil {
+			return nil, err
+		}
+		doubleByte = enc.NewEncoder()
+	}
+
+	var runs []TextRun
+	current := e.CodeTable
+	for _, r := range text {
+		last := len(runs) - 1
+		// ASCII prints as single-byte in Kanji mode, stay in the run
+		if last >= 0 && runs[last].DoubleByte && r < utf8.RuneSelf {
+			runs[last].Data = append(runs[last].Data, byte(r))
+			continue
+		}
+
+		b, ok := encodeRune(current, r)
+		if !ok {
+			for _, table := range e.AutoCodeTables {
+				if b, ok = encodeRune(table, r); ok {
+					current = table
+					break
+				}
+			}
+		}
+		wide := false
+		if !ok && doubleByte != nil {
+			b, ok = encodeDoubleByte(doubleByte, r)
+			wide = true
+		}
+		if !ok {
+			return nil, fmt.Errorf("rune %q not supported by %s", r, e.encodingList())
+		}
+
+		if last >= 0 && runs[last].DoubleByte == wide && (wide || runs[last].CodeTable == current) {
+			runs[last].Data = append(runs[last].Data, b...)
+		} else {
+			runs = append(runs, TextRun{CodeTable: current, DoubleByte: wide, Data: b})
+		}
+	}
+	return runs, nil
+}
+
+// encodingList describes the encodings tried by EncodeRuns for error messages
+func (e *Escpos) encodingList() string {
+	tables := []character.CodeTable{e.CodeTable}
+	for _, t := range e.AutoCodeTables {
+		if t != e.CodeTable {
+			tables = append(tables, t)
+		}
+	}
+	list := "code tables " + codeTableList(tables)
+	if e.DoubleByte != "" {
+		list += " and " + string(e.DoubleByte)
+	}
+	return list
+}
+
+// codeTableList formats tables for error messages
+func codeTableList(tables []character.CodeTable) string {
+	names := make([]string, len(tables))
+	for i, t := range tables {
+		names[i] = CodeTableName(t)
+	}
+	return strings.Join(names, ", ")
+}
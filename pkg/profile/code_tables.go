@@ -0,0 +1,132 @@
+package profile
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+)
+
+// codeTableNames maps the names used in profile files to code tables
+var codeTableNames = map[string]character.CodeTable{
+	"PC437":         character.PC437,
+	"KATAKANA":      character.Katakana,
+	"PC850":         character.PC850,
+	"PC860":         character.PC860,
+	"PC863":         character.PC863,
+	"PC865":         character.PC865,
+	"HIRAGANA":      character.Hiragana,
+	"ONEPASSKANJI1": character.OnePassKanji1,
+	"ONEPASSKANJI2": character.OnePassKanji2,
+	"PC851":         character.PC851,
+	"PC853":         character.PC853,
+	"PC857":         character.PC857,
+	"PC737":         character.PC737,
+	"ISO88597":      character.ISO88597,
+	"WPC1252":       character.WPC1252,
+	"PC866":         character.PC866,
+	"PC852":         character.PC852,
+	"PC858":         character.PC858,
+	"THAI42":        character.ThaiCode42,
+	"THAI11":        character.ThaiCode11,
+	"THAI13":        character.ThaiCode13,
+	"THAI14":        character.ThaiCode14,
+	"THAI16":        character.ThaiCode16,
+	"THAI17":        character.ThaiCode17,
+	"THAI18":        character.ThaiCode18,
+	"TCVN31":        character.TCVN31,
+	"TCVN32":        character.TCVN32,
+	"PC720":         character.PC720,
+	"WPC775":        character.WPC775,
+	"PC855":         character.PC855,
+	"PC861":         character.PC861,
+	"PC862":         character.PC862,
+	"PC864":         character.PC864,
+	"PC869":         character.PC869,
+	"ISO88592":      character.ISO88592,
+	"ISO885915":     character.ISO885915,
+	"PC1098":        character.PC1098,
+	"PC1118":        character.PC1118,
+	"PC1119":        character.PC1119,
+	"PC1125":        character.PC1125,
+	"WPC1250":       character.WPC1250,
+	"WPC1251":       character.WPC1251,
+	"WPC1253":       character.WPC1253,
+	"WPC1254":       character.WPC1254,
+	"WPC1255":       character.WPC1255,
+	"WPC1256":       character.WPC1256,
+	"WPC1257":       character.WPC1257,
+	"WPC1258":       character.WPC1258,
+	"KZ1048":        character.KZ1048,
+	"DEVANAGARI":    character.Devanagari,
+	"BENGALI":       character.Bengali,
+	"TAMIL":         character.Tamil,
+	"TELUGU":        character.Telugu,
+	"ASSAMESE":      character.Assamese,
+	"ORIYA":         character.Oriya,
+	"KANNADA":       character.Kannada,
+	"MALAYALAM":     character.Malayalam,
+	"GUJARATI":      character.Gujarati,
+	"PUNJABI":       character.Punjabi,
+	"MARATHI":       character.Marathi,
+}
+
+// normalizeCodeTableName uppercases name and drops separators, so that
+// "ISO-8859-15", "iso8859_15" and "ISO885915" are the same table
+func normalizeCodeTableName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', ' ', '.':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(name)))
+}
+
+// ParseCodeTable returns the code table for a name such as "PC850",
+// "WPC1252" or "ISO-8859-15", or for its ESC t number ("2")
+func ParseCodeTable(name string) (character.CodeTable, error) {
+	if n, err := strconv.Atoi(strings.TrimSpace(name)); err == nil {
+		if n < 0 || n > 255 {
+			return 0, fmt.Errorf("code table %d out of range (0-255)", n)
+		}
+		return character.CodeTable(n), nil
+	}
+	table, ok := codeTableNames[normalizeCodeTableName(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown code table %q", name)
+	}
+	return table, nil
+}
+
+// CodeTableName returns the profile file name of a code table, or its
+// ESC t number when it has no name
+func CodeTableName(table character.CodeTable) string {
+	for name, t := range codeTableNames {
+		if t == table {
+			return name
+		}
+	}
+	return strconv.Itoa(int(table))
+}
+
+// fontFunctionNames maps the font_priority names of profile files to the
+// fonts selected with FS ( C in UTF-8 mode
+var fontFunctionNames = map[string]character.FontFunction{
+	"ank":                 character.AnkSansSerif,
+	"japanese":            character.JapaneseGothic,
+	"simplified_chinese":  character.SimplifiedChineseMincho,
+	"traditional_chinese": character.TraditionalChineseMincho,
+	"korean":              character.KoreanGothic,
+}
+
+// ParseFontFunction returns the UTF-8 mode font for a name such as
+// "japanese" or "korean"
+func ParseFontFunction(name string) (character.FontFunction, error) {
+	font, ok := fontFunctionNames[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return 0, fmt.Errorf("unknown font %q (use ank, japanese, simplified_chinese, traditional_chinese or korean)", name)
+	}
+	return font, nil
+}
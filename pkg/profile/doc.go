@@ -1,2 +1,60 @@
 // Package profile define las características físicas y capacidades de las impresoras térmicas.
+//
+// Los perfiles se describen en archivos JSON o YAML y se cargan en un
+// Registry. El modelo de un documento (profile.model) se resuelve contra el
+// nombre, los alias y el modelo de cada perfil, sin distinguir mayúsculas.
+// Agregar una impresora nueva solo requiere un archivo, no una versión nueva.
+//
+// # Archivos de perfil
+//
+//	{
+//	  "name": "tm-t20",
+//	  "extends": "generic-80mm",
+//	  "aliases": ["epson tm-t20"],
+//	  "model": "Epson TM-T20",
+//	  "code_tables": ["PC437", "PC850", "WPC1252"],
+//	  "fonts": [{"name": "A", "width": 12, "height": 24, "columns": 48}],
+//	  "image_mode": "graphics",
+//	  "cut": {"mode": "full", "feed": 3}
+//	}
+//
+// Un perfil con "extends" hereda todas las claves de su base excepto name,
+// aliases y model (model toma el valor de name si se omite). Los objetos se
+// combinan clave por clave y las listas reemplazan a la heredada. Un archivo
+// puede contener un perfil o una lista de perfiles; un perfil con el mismo
+// nombre que otro ya cargado lo reemplaza.
+//
+// # Perfiles integrados
+//
+//	generic-58mm  generic-80mm  pt-210  gp-58n  ec-pm-80250
+//
+// Los archivos están en pkg/profile/profiles y se incluyen en el binario.
+//
+// # escpos-printer-db
+//
+// El capabilities.json de escpos-printer-db (python-escpos, escpos-php) se
+// lee como un archivo de perfiles más, o se convierte con ImportCapabilities.
+// Cada modelo conserva su numeración ESC t en code_pages, que
+// Printer.SetCodeTable usa en lugar de la numeración de Epson.
+//
+// # Codificación de texto
+//
+// EncodeString codifica con la tabla del perfil y falla si la tabla no
+// tiene codificación o si un carácter no existe en ella. Las tablas que
+// golang.org/x/text no incluye (PC737, PC857, PC864...) están en charmaps.go.
+// Con auto_code_tables el texto se divide en tramos (EncodeRuns) y cada
+// tramo usa la primera tabla de la lista que lo puede codificar. Un perfil
+// con supports_utf8 recibe el texto en UTF-8 (FS ( C) y las fuentes de
+// font_priority cuando el documento lo pide con profile.utf8.
+// Con double_byte (gb18030, big5, shift_jis o ks_c_5601) los caracteres
+// CJK se imprimen en modo Kanji (FS &) con el juego de doble byte del modelo.
+// Los caracteres que nada de lo anterior codifica fallan, salvo que
+// unencodable sea replace (se imprime replacement) o transliterate (una
+// aproximación ASCII, ver Substitute).
+//
+//	reg := profile.Builtin()
+//	if err := reg.LoadDir("profiles"); err != nil {
+//		return err
+//	}
+//	prof, err := reg.Resolve(doc.Profile.Model)
 package profile
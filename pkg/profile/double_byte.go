@@ -0,0 +1,85 @@
+package profile
+
+import (
+	"fmt"
+	"unicode"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// DoubleByte is the character set a printer uses in Kanji mode (FS &)
+type DoubleByte string
+
+const (
+	// DoubleByteShiftJIS is used by Japanese models (FS C 1)
+	DoubleByteShiftJIS DoubleByte = "shift_jis"
+	// DoubleByteGB18030 is used by Simplified Chinese models
+	DoubleByteGB18030 DoubleByte = "gb18030"
+	// DoubleByteBig5 is used by Traditional Chinese models
+	DoubleByteBig5 DoubleByte = "big5"
+	// DoubleByteKSC5601 is used by Korean models (EUC-KR)
+	DoubleByteKSC5601 DoubleByte = "ks_c_5601"
+)
+
+// doubleByteMap maps double-byte character sets to Go encodings
+var doubleByteMap = map[DoubleByte]encoding.Encoding{
+	DoubleByteShiftJIS: japanese.ShiftJIS,
+	DoubleByteGB18030:  simplifiedchinese.GB18030,
+	DoubleByteBig5:     traditionalchinese.Big5,
+	DoubleByteKSC5601:  korean.EUCKR,
+}
+
+// Encoding returns the encoding of the character set
+func (d DoubleByte) Encoding() (encoding.Encoding, error) {
+	enc, ok := doubleByteMap[d]
+	if !ok {
+		return nil, fmt.Errorf("unknown double_byte %q (use shift_jis, gb18030, big5 or ks_c_5601)", d)
+	}
+	return enc, nil
+}
+
+// DecodeDoubleByte decodes text printed in Kanji mode with the given
+// character set. It is used to preview compiled jobs.
+func DecodeDoubleByte(d DoubleByte, data []byte) (string, error) {
+	enc, err := d.Encoding()
+	if err != nil {
+		return "", err
+	}
+	text, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode bytes: %w", err)
+	}
+	return string(text), nil
+}
+
+// isDoubleByteRune reports whether r belongs to the CJK scripts printed in
+// Kanji mode. GB18030 encodes every Unicode rune, so the other scripts must
+// not reach the double-byte set.
+func isDoubleByteRune(r rune) bool {
+	switch {
+	case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul, unicode.Bopomofo):
+		return true
+	case r >= 0x3000 && r <= 0x303F: // CJK symbols and punctuation
+		return true
+	case r >= 0xFF01 && r <= 0xFF60, r >= 0xFFE0 && r <= 0xFFE6: // Fullwidth forms
+		return true
+	}
+	return false
+}
+
+// encodeDoubleByte encodes a CJK rune as one two-byte character. Other
+// runes, and the four-byte sequences of GB18030, are rejected.
+func encodeDoubleByte(enc *encoding.Encoder, r rune) ([]byte, bool) {
+	if !isDoubleByteRune(r) {
+		return nil, false
+	}
+	b, err := enc.Bytes([]byte(string(r)))
+	if err != nil || len(b) != 2 {
+		return nil, false
+	}
+	return b, true
+}
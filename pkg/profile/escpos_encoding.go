@@ -1,21 +1,24 @@
 package profile
 
 import (
+	"errors"
 	"fmt"
-	"log"
 
 	"golang.org/x/text/encoding"
 	"golang.org/x/text/encoding/charmap"
-	"golang.org/x/text/encoding/japanese"
 
 	"github.com/adcondev/poster/pkg/commands/character"
 )
 
-// TODO: Add new encodings as needed, many of them defined in ESCPOS don't have a direct mapping in Go
+// TODO: PC853, PC1098, PC1118, PC1119, TCVN-3 (tabla 2), Hiragana, the remaining Thai pages and the Indic pages still have no charmap
 
 // TODO: Separate this concern to dedicated package, then add the state management concern to profile
 
-// codeTableMap maps ESC/POS code tables to Go encodings
+// ErrUnsupportedCodeTable is returned when a code table has no encoding
+var ErrUnsupportedCodeTable = errors.New("unsupported code table")
+
+// codeTableMap maps ESC/POS code tables to Go encodings. Pages missing from
+// golang.org/x/text use the built-in charmaps in charmaps.go.
 var codeTableMap = map[character.CodeTable]encoding.Encoding{
 	// Western European and Americas
 	character.PC437:     charmap.CodePage437,
@@ -25,6 +28,7 @@ var codeTableMap = map[character.CodeTable]encoding.Encoding{
 	character.PC860:     charmap.CodePage860,
 	character.PC863:     charmap.CodePage863,
 	character.PC865:     charmap.CodePage865,
+	character.PC861:     codePage861,
 	character.WPC1252:   charmap.Windows1252,
 	character.ISO885915: charmap.ISO8859_15,
 
@@ -32,21 +36,30 @@ var codeTableMap = map[character.CodeTable]encoding.Encoding{
 	character.PC855:   charmap.CodePage855,
 	character.PC866:   charmap.CodePage866,
 	character.WPC1251: charmap.Windows1251,
+	character.PC1125:  codePage1125,
+	character.KZ1048:  codePageKZ,
 
 	// Greek
 	character.ISO88597: charmap.ISO8859_7,
 	character.WPC1253:  charmap.Windows1253,
+	character.PC737:    codePage737,
+	character.PC851:    codePage851,
+	character.PC869:    codePage869,
 
 	// Turkish
 	character.WPC1254: charmap.Windows1254,
+	character.PC857:   codePage857,
 
 	// Baltic
 	character.WPC1257: charmap.Windows1257,
+	character.WPC775:  codePage775,
 
 	// Hebrew/Arabic
 	character.PC862:   charmap.CodePage862,
 	character.WPC1255: charmap.Windows1255,
 	character.WPC1256: charmap.Windows1256,
+	character.PC720:   codePage720,
+	character.PC864:   codePage864,
 
 	// Central European
 	character.WPC1250:  charmap.Windows1250,
@@ -54,38 +67,66 @@ var codeTableMap = map[character.CodeTable]encoding.Encoding{
 
 	// Vietnamese
 	character.WPC1258: charmap.Windows1258,
+	character.TCVN31:  codePageTCVN,
+
+	// Thai
+	character.ThaiCode42: codePageTIS,
 
-	// Asian (basic mappings, may need special handling)
-	character.Katakana: japanese.ShiftJIS,
-	character.Hiragana: japanese.ShiftJIS,
-	// Note: OnePassKanji would need special handling
+	// Japanese half-width katakana. Kanji, Hanzi and Hangul are double-byte
+	// and printed in Kanji mode, see Escpos.DoubleByte
+	character.Katakana: codePageJIS,
 }
 
-// EncodeString encodes a string using the specified code table
+// EncodeString encodes a string using the profile code table. It fails
+// when the table has no encoding or a rune does not exist in it.
 func (e *Escpos) EncodeString(text string) (string, error) {
-	enc := e.getEncoding(e.CodeTable)
-	result, err := enc.String(text)
+	enc, err := Encoding(e.CodeTable)
+	if err != nil {
+		return "", err
+	}
+	result, err := enc.NewEncoder().String(text)
 	if err != nil {
-		return "", fmt.Errorf("failed to encode string: %w", err)
+		return "", fmt.Errorf("failed to encode string for %s: %w", CodeTableName(e.CodeTable), err)
 	}
 	return result, nil
 }
 
-// getEncoding returns the encoding.Encoder for the specified code table
-func (e *Escpos) getEncoding(codeTable character.CodeTable) *encoding.Encoder {
+// Encoding returns the encoding for the specified code table
+func Encoding(codeTable character.CodeTable) (encoding.Encoding, error) {
 	enc, ok := codeTableMap[codeTable]
 	if !ok {
-		// FIXME: Side effect: logging directly avoids caller control. Return error or use configured logger.
-		log.Printf("warning: unsupported encoding for code table %v, falling back to Windows-1252", codeTable)
-		// FIXME: Silent fallback to Windows-1252 might lead to incorrect output without caller knowing.
-		return charmap.Windows1252.NewEncoder()
+		return nil, fmt.Errorf("%w %s", ErrUnsupportedCodeTable, CodeTableName(codeTable))
 	}
-	// TODO: Optimization: .NewEncoder() is called on every invocation. Consider caching or reusing encoders if strict state is not required.
-	return enc.NewEncoder()
+	return enc, nil
 }
 
-// IsSupported checks if the specified code table is supported
+// IsSupported checks if the specified code table has an encoding and, when
+// the profile lists its CodeTables, is one of them
 func (e *Escpos) IsSupported(codeTable character.CodeTable) bool {
-	_, ok := codeTableMap[codeTable]
-	return ok
+	if _, ok := codeTableMap[codeTable]; !ok {
+		return false
+	}
+	if len(e.CodeTables) == 0 {
+		return true
+	}
+	for _, t := range e.CodeTables {
+		if t == codeTable {
+			return true
+		}
+	}
+	return false
+}
+
+// DecodeBytes decodes text that was encoded for the given code table.
+// It is the inverse of EncodeString and is used to preview compiled jobs.
+func DecodeBytes(codeTable character.CodeTable, data []byte) (string, error) {
+	enc, err := Encoding(codeTable)
+	if err != nil {
+		return "", err
+	}
+	text, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode bytes: %w", err)
+	}
+	return string(text), nil
 }
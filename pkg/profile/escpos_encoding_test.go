@@ -1,6 +1,8 @@
 package profile_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	"github.com/adcondev/poster/pkg/commands/character"
@@ -45,12 +47,31 @@ func TestEncodeString(t *testing.T) {
 			expectedError: true, // Should return "encoding: rune not supported by encoding"
 		},
 		{
-			name:      "Fallback for unsupported CodeTable",
-			codeTable: character.CodeTable(99), // Unsupported
-			input:     "test",
-			// It should fallback to Windows1252
+			name:          "PC737 - Greek",
+			codeTable:     character.PC737,
+			input:         "Αθήνα",
 			expectedError: false,
-			expectedBytes: []byte("test"),
+			expectedBytes: []byte{0x80, 0x9F, 0xE3, 0xA4, 0x98},
+		},
+		{
+			name:          "PC857 - Turkish",
+			codeTable:     character.PC857,
+			input:         "İşçi ğ",
+			expectedError: false,
+			expectedBytes: []byte{0x98, 0x9F, 0x87, 'i', ' ', 0xA7},
+		},
+		{
+			name:          "Katakana - Half-width",
+			codeTable:     character.Katakana,
+			input:         "ｶﾀｶﾅ",
+			expectedError: false,
+			expectedBytes: []byte{0xB6, 0xC0, 0xB6, 0xC5},
+		},
+		{
+			name:          "Unsupported CodeTable",
+			codeTable:     character.CodeTable(99), // Unsupported
+			input:         "test",
+			expectedError: true, // No silent fallback to Windows1252
 		},
 	}
 
@@ -103,24 +124,140 @@ func TestIsSupported(t *testing.T) {
 	}
 }
 
-func TestFallbackBehavior(t *testing.T) {
-	// This test specifically targets the fallback log/behavior in getEncoding
-	// Since getEncoding is private, we test it via EncodeString
+func TestUnsupportedCodeTable(t *testing.T) {
+	p := profile.CreateProfile58mm()
+	p.CodeTable = character.PC853 // No charmap available
+
+	_, err := p.EncodeString("€")
+	if !errors.Is(err, profile.ErrUnsupportedCodeTable) {
+		t.Errorf("EncodeString() error = %v, want ErrUnsupportedCodeTable", err)
+	}
+}
+
+func TestBuiltinCharmapsRoundTrip(t *testing.T) {
+	tables := []character.CodeTable{
+		character.PC737, character.PC851, character.PC857, character.PC861,
+		character.PC864, character.PC869, character.PC720, character.PC1125,
+		character.WPC775, character.KZ1048, character.ThaiCode42,
+		character.TCVN31, character.Katakana,
+	}
+
+	for _, ct := range tables {
+		all := make([]byte, 256)
+		for i := range all {
+			all[i] = byte(i)
+		}
+		text, err := profile.DecodeBytes(ct, all)
+		if err != nil {
+			t.Fatalf("DecodeBytes(%v) error: %v", ct, err)
+		}
+
+		// Unassigned bytes decode to U+FFFD, drop them before encoding back
+		p := profile.CreateProfile58mm()
+		p.CodeTable = ct
+		var assigned []byte
+		for _, r := range text {
+			if r == '\uFFFD' {
+				continue
+			}
+			s, err := p.EncodeString(string(r))
+			if err != nil {
+				t.Errorf("code table %v: EncodeString(%q) error: %v", ct, r, err)
+				continue
+			}
+			assigned = append(assigned, s...)
+		}
+		decoded, _ := profile.DecodeBytes(ct, assigned)
+		if want := strings.ReplaceAll(text, "\uFFFD", ""); decoded != want {
+			t.Errorf("code table %v: round trip mismatch", ct)
+		}
+	}
+}
+
+func TestDecodeBytes(t *testing.T) {
 	p := profile.CreateProfile58mm()
-	p.CodeTable = character.CodeTable(123) // Random unsupported table
+	p.CodeTable = character.PC850
 
-	// Should fallback to Windows1252
-	// Windows1252 maps '€' to 0x80
+	encoded, err := p.EncodeString("Café ñandú")
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
 
-	input := "€"
-	encoded, err := p.EncodeString(input)
+	decoded, err := profile.DecodeBytes(character.PC850, []byte(encoded))
 	if err != nil {
-		t.Fatalf("unexpected error during fallback encoding: %v", err)
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if decoded != "Café ñandú" {
+		t.Errorf("DecodeBytes() = %q, want %q", decoded, "Café ñandú")
+	}
+
+	if _, err := profile.DecodeBytes(character.CodeTable(99), []byte("x")); err == nil {
+		t.Error("expected error for unsupported code table")
+	}
+}
+
+func TestEncodeRuns(t *testing.T) {
+	p := profile.CreateProfile80mm()
+	p.CodeTable = character.WPC1252
+	p.AutoCodeTables = []character.CodeTable{character.WPC1252, character.PC866, character.PC737}
+
+	runs, err := p.EncodeRuns("€5 Да Αα ok")
+	if err != nil {
+		t.Fatalf("EncodeRuns() error: %v", err)
+	}
+
+	want := []profile.TextRun{
+		{CodeTable: character.WPC1252, Data: []byte{0x80, '5', ' '}},
+		{CodeTable: character.PC866, Data: []byte{0x84, 0xA0, ' '}},
+		{CodeTable: character.PC737, Data: []byte{0x80, 0x98, ' ', 'o', 'k'}},
+	}
+	if len(runs) != len(want) {
+		t.Fatalf("EncodeRuns() = %d runs, want %d: %+v", len(runs), len(want), runs)
+	}
+	for i := range want {
+		if runs[i].CodeTable != want[i].CodeTable || string(runs[i].Data) != string(want[i].Data) {
+			t.Errorf("run %d = %v %X, want %v %X", i, runs[i].CodeTable, runs[i].Data, want[i].CodeTable, want[i].Data)
+		}
+	}
+
+	if _, err := p.EncodeRuns("漢"); err == nil {
+		t.Error("expected error for a rune no listed table can encode")
+	}
+}
+
+func TestEncodeRuns_DoubleByte(t *testing.T) {
+	p := profile.CreateProfile80mm()
+	p.CodeTable = character.PC850
+	p.DoubleByte = profile.DoubleByteGB18030
+
+	runs, err := p.EncodeRuns("Total 合计 12 é")
+	if err != nil {
+		t.Fatalf("EncodeRuns() error: %v", err)
+	}
+
+	want := []profile.TextRun{
+		{CodeTable: character.PC850, Data: []byte("Total ")},
+		{CodeTable: character.PC850, DoubleByte: true, Data: []byte{0xBA, 0xCF, 0xBC, 0xC6, ' ', '1', '2', ' '}},
+		{CodeTable: character.PC850, Data: []byte{0x82}},
+	}
+	if len(runs) != len(want) {
+		t.Fatalf("EncodeRuns() = %d runs, want %d: %+v", len(runs), len(want), runs)
+	}
+	for i := range want {
+		if runs[i].DoubleByte != want[i].DoubleByte || string(runs[i].Data) != string(want[i].Data) {
+			t.Errorf("run %d = %v %X, want %v %X", i, runs[i].DoubleByte, runs[i].Data, want[i].DoubleByte, want[i].Data)
+		}
+	}
+
+	// GB18030 encodes every rune, but only CJK prints in Kanji mode
+	for _, text := range []string{"مرحبا", "🎉"} {
+		if _, err := p.EncodeRuns(text); err == nil {
+			t.Errorf("EncodeRuns(%q) expected error, got double-byte run", text)
+		}
 	}
 
-	// Check if it used Windows1252
-	expected := string([]byte{0x80})
-	if encoded != expected {
-		t.Errorf("expected fallback to Windows1252 (result %x), got %x", expected, encoded)
+	p.DoubleByte = "utf16"
+	if _, err := p.EncodeRuns("合计"); err == nil {
+		t.Error("expected error for an unknown double_byte")
 	}
 }
@@ -1,8 +1,10 @@
 package profile
 
 import (
+	"strings"
+
 	"github.com/adcondev/poster/pkg/commands/character"
-	"github.com/adcondev/poster/pkg/commands/shared"
+	"github.com/adcondev/poster/pkg/constants"
 	"github.com/adcondev/poster/pkg/graphics"
 )
 
@@ -24,12 +26,39 @@ type Escpos struct {
 	HasQR            bool // Soporta códigos QR nativos
 	SupportsCutter   bool // Tiene cortador automático
 	SupportsDrawer   bool // Soporta cajón de dinero
+	SupportsUTF8     bool // Acepta texto UTF-8 con FS ( C
 
 	// Máxima versión soportada
 	QRMaxSize byte
 
 	// Code table and encoding configuration
-	CodeTable character.CodeTable
+	CodeTable  character.CodeTable
+	CodeTables []character.CodeTable        // Tablas soportadas (vacío = todas las que tienen encoding)
+	CodePages  map[character.CodeTable]byte // Número ESC t del modelo cuando difiere del estándar
+
+	// Prioridad para cambiar de tabla por tramo de texto (vacío = una sola tabla)
+	AutoCodeTables []character.CodeTable
+
+	// Fuentes de primera y segunda prioridad en modo UTF-8 (vacío = las del modelo)
+	FontPriority []character.FontFunction
+
+	// Juego de caracteres del modo Kanji (FS &) para texto CJK (vacío = sin modo Kanji)
+	DoubleByte DoubleByte
+
+	// Política para caracteres que ninguna tabla codifica (vacío = error)
+	Unencodable Unencodable
+	Replacement string // Texto impreso en su lugar con replace (vacío = "?")
+
+	// Fuente TTF/OTF para rasterizar el texto que el modelo no imprime (vacío = ninguna)
+	FallbackFont string
+
+	// Fuentes residentes
+	Fonts []Font
+
+	// Comandos de imagen y corte
+	ImageMode ImageMode // Comando usado para imprimir imágenes (default raster)
+	CutMode   CutMode   // Tipo de corte cuando el documento no lo indica
+	CutFeed   int       // Líneas de avance antes del corte (0 = default)
 
 	// Configuración avanzada (opcional)
 	ImageThreshold int                 // Umbral para conversión B/N (0-255)
@@ -39,72 +68,127 @@ type Escpos struct {
 	DebugLog bool // Habilitar logs de depuración
 }
 
-// CreatePt210 crea un perfil para impresora térmica de 58mm PT-58N
-// TODO: Hardcoded Models: Factory functions for specific models might become hard to maintain. Consider configuration-based approach.
-func CreatePt210() *Escpos {
-	p := CreateProfile58mm()
-	p.Model = "58mm PT-210"
-
-	p.CodeTable = character.PC850
-	p.QRMaxSize = 19 // Máxima versión QR soportada
-	p.HasQR = true   // Soporta QR nativo
-	return p
+// Font describes the metrics of a resident printer font
+type Font struct {
+	Name    string `json:"name"`              // "A", "B"...
+	Width   int    `json:"width,omitempty"`   // Ancho del carácter en puntos
+	Height  int    `json:"height,omitempty"`  // Alto del carácter en puntos
+	Columns int    `json:"columns,omitempty"` // Caracteres por línea (0 = DotsPerLine / Width)
 }
 
-// CreateGP58N crea un perfil para impresora térmica de 58mm GP-58N
-func CreateGP58N() *Escpos {
-	p := CreateProfile58mm()
-	p.Model = "58mm GP-58N"
+// ImageMode selects the command used to print images
+type ImageMode string
 
-	p.CodeTable = character.PC850
-	return p
-}
+const (
+	// ImageRaster prints images with GS v 0
+	ImageRaster ImageMode = "raster"
+	// ImageBitImage prints images in 24-dot stripes with ESC *
+	ImageBitImage ImageMode = "bit_image"
+	// ImageGraphics prints images with GS ( L
+	ImageGraphics ImageMode = "graphics"
+)
 
-// CreateProfile58mm crea un perfil para impresora térmica de 58mm común
-func CreateProfile58mm() *Escpos {
-	return &Escpos{
-		Model: "Generic 58mm",
+// CutMode selects the default paper cut
+type CutMode string
 
-		PaperWidth:  58,
-		DPI:         203,
-		DotsPerLine: 384, // Típico para 58mm a 203 DPI
-		PrintWidth:  48,  // Ancho de impresión efectivo
+const (
+	// CutPartial leaves a small hinge uncut
+	CutPartial CutMode = "partial"
+	// CutFull cuts the paper completely
+	CutFull CutMode = "full"
+)
 
-		SupportsGraphics: true,
-		SupportsBarcode:  true,
-		HasQR:            false, // Muchas impresoras baratas no soportan QR nativo
-		SupportsCutter:   false,
-		SupportsDrawer:   false,
+// Font returns the metrics of the named font
+func (e *Escpos) Font(name string) (Font, bool) {
+	for _, f := range e.Fonts {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return Font{}, false
+}
 
-		CodeTable: character.PC850,
+// Columns returns the characters per line for the named font. Profiles
+// without font metrics use the standard 12 (A) and 9 (B) dot widths.
+func (e *Escpos) Columns(font string) int {
+	if f, ok := e.Font(font); ok {
+		switch {
+		case f.Columns > 0:
+			return f.Columns
+		case f.Width > 0:
+			return constants.MaxCharsForPaper(e.DotsPerLine, f.Width)
+		}
+	}
+	if strings.EqualFold(font, "B") {
+		return constants.MaxCharsForPaperFontB(e.DotsPerLine)
 	}
+	return constants.MaxCharsForPaperFontA(e.DotsPerLine)
 }
 
-// CreateECPM80250 crea un perfil para impresora térmica de 80mm EC-PM-80250
-func CreateECPM80250() *Escpos {
-	p := CreateProfile80mm()
-	p.Model = "80mm EC-PM-80250"
-	return p
+// CellSize returns the character cell of the named font in dots. Metrics
+// the profile doesn't declare use the standard 12x24 (A) and 9x17 (B) cells.
+func (e *Escpos) CellSize(font string) (width, height int) {
+	width, height = constants.FontAWidth, constants.FontAHeight
+	if strings.EqualFold(font, "B") {
+		width, height = constants.FontBWidth, constants.FontBHeight
+	}
+	if f, ok := e.Font(font); ok {
+		if f.Width > 0 {
+			width = f.Width
+		}
+		if f.Height > 0 {
+			height = f.Height
+		}
+	}
+	return width, height
 }
 
-// CreateProfile80mm crea un perfil para impresora térmica de 80mm común
-func CreateProfile80mm() *Escpos {
-	return &Escpos{
-		Model: "Generic 80mm",
+// CodePageIndex returns the ESC t number of a code table on this model.
+// Models without CodePages use the standard Epson numbering.
+func (e *Escpos) CodePageIndex(table character.CodeTable) byte {
+	if n, ok := e.CodePages[table]; ok {
+		return n
+	}
+	return byte(table)
+}
 
-		PaperWidth:  80,
-		DPI:         203,
-		DotsPerLine: shared.Dpl80mm203dpi, // Típico para 80mm (72mm) a 203 DPI
+// Clone returns a copy of the profile that shares no slices or maps with e
+func (e *Escpos) Clone() *Escpos {
+	c := *e
+	c.CodeTables = append([]character.CodeTable(nil), e.CodeTables...)
+	c.AutoCodeTables = append([]character.CodeTable(nil), e.AutoCodeTables...)
+	c.FontPriority = append([]character.FontFunction(nil), e.FontPriority...)
+	c.Fonts = append([]Font(nil), e.Fonts...)
+	if e.CodePages != nil {
+		c.CodePages = make(map[character.CodeTable]byte, len(e.CodePages))
+		for t, n := range e.CodePages {
+			c.CodePages[t] = n
+		}
+	}
+	return &c
+}
 
-		SupportsGraphics: true,
-		SupportsBarcode:  true,
-		HasQR:            true, // Las 80mm suelen tener más funciones
-		SupportsCutter:   true,
-		SupportsDrawer:   true,
+// CreatePt210 crea un perfil para impresora térmica de 58mm PT-210 (perfil integrado "pt-210")
+func CreatePt210() *Escpos {
+	return mustBuiltin("pt-210")
+}
 
-		// Más juegos de caracteres
-		CodeTable: character.PC850, // CP850
+// CreateGP58N crea un perfil para impresora térmica de 58mm GP-58N (perfil integrado "gp-58n")
+func CreateGP58N() *Escpos {
+	return mustBuiltin("gp-58n")
+}
 
-		ImageThreshold: 128,
-	}
+// CreateProfile58mm crea un perfil para impresora térmica de 58mm común
+func CreateProfile58mm() *Escpos {
+	return mustBuiltin(Generic58mm)
+}
+
+// CreateECPM80250 crea un perfil para impresora térmica de 80mm EC-PM-80250 (perfil integrado "ec-pm-80250")
+func CreateECPM80250() *Escpos {
+	return mustBuiltin("ec-pm-80250")
+}
+
+// CreateProfile80mm crea un perfil para impresora térmica de 80mm común
+func CreateProfile80mm() *Escpos {
+	return mustBuiltin(Generic80mm)
 }
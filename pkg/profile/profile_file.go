@@ -0,0 +1,236 @@
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/graphics"
+)
+
+// Definition is the content of a profile file. Files are JSON or YAML with
+// the same keys; every Escpos field has a key.
+//
+//	{
+//	  "name": "tm-t20",
+//	  "extends": "generic-80mm",
+//	  "aliases": ["epson tm-t20"],
+//	  "model": "Epson TM-T20",
+//	  "code_tables": ["PC437", "PC850", "WPC1252"],
+//	  "code_pages": {"WPC1252": 71},
+//	  "fonts": [{"name": "A", "width": 12, "height": 24, "columns": 48}],
+//	  "image_mode": "graphics",
+//	  "cut": {"mode": "full", "feed": 3}
+//	}
+//
+// A profile that extends another one only lists the keys it changes.
+type Definition struct {
+	Name    string   `json:"name"`              // Nombre en el registro
+	Extends string   `json:"extends,omitempty"` // Perfil base
+	Aliases []string `json:"aliases,omitempty"` // Otros nombres aceptados en profile.model
+
+	Model string `json:"model,omitempty"`
+
+	PaperWidth  float64 `json:"paper_width,omitempty"`
+	PaperHeight float64 `json:"paper_height,omitempty"`
+	DPI         int     `json:"dpi,omitempty"`
+	DotsPerLine int     `json:"dots_per_line,omitempty"`
+	PrintWidth  int     `json:"print_width,omitempty"`
+
+	SupportsGraphics bool `json:"supports_graphics,omitempty"`
+	SupportsBarcode  bool `json:"supports_barcode,omitempty"`
+	HasQR            bool `json:"has_qr,omitempty"`
+	SupportsCutter   bool `json:"supports_cutter,omitempty"`
+	SupportsDrawer   bool `json:"supports_drawer,omitempty"`
+	SupportsUTF8     bool `json:"supports_utf8,omitempty"`
+	QRMaxSize        byte `json:"qr_max_size,omitempty"`
+
+	CodeTable  string         `json:"code_table,omitempty"`  // Default: WPC1252
+	CodeTables []string       `json:"code_tables,omitempty"` // Default: las de code_pages, o todas
+	CodePages  map[string]int `json:"code_pages,omitempty"`  // Tabla -> número ESC t del modelo
+
+	AutoCodeTables []string `json:"auto_code_tables,omitempty"` // Prioridad del cambio automático de tabla
+	FontPriority   []string `json:"font_priority,omitempty"`    // ank | japanese | simplified_chinese | traditional_chinese | korean
+
+	DoubleByte DoubleByte `json:"double_byte,omitempty"` // shift_jis | gb18030 | big5 | ks_c_5601
+
+	Unencodable string `json:"unencodable,omitempty"` // error | replace | transliterate
+	Replacement string `json:"replacement,omitempty"` // Default: "?"
+
+	FallbackFont string `json:"fallback_font,omitempty"` // Ruta de una fuente TTF/OTF
+
+	Fonts     []Font    `json:"fonts,omitempty"`
+	ImageMode ImageMode `json:"image_mode,omitempty"` // raster | bit_image | graphics
+	Cut       *Cut      `json:"cut,omitempty"`
+
+	ImageThreshold int    `json:"image_threshold,omitempty"`
+	Dithering      string `json:"dithering,omitempty"` // threshold | atkinson
+	DebugLog       bool   `json:"debug_log,omitempty"`
+}
+
+// Cut describes the cut behavior of a profile file
+type Cut struct {
+	Mode CutMode `json:"mode,omitempty"` // partial | full
+	Feed int     `json:"feed,omitempty"` // Líneas antes del corte
+}
+
+// decodeDefinition decodes the merged keys of a profile, rejecting unknown
+// keys so that typos in profile files are reported
+func decodeDefinition(raw map[string]any) (*Definition, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	def := &Definition{}
+	if err := dec.Decode(def); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// Escpos converts the definition into a printer profile
+func (d *Definition) Escpos() (*Escpos, error) {
+	p := &Escpos{
+		Model:            d.Model,
+		PaperWidth:       d.PaperWidth,
+		PaperHeight:      d.PaperHeight,
+		DPI:              d.DPI,
+		DotsPerLine:      d.DotsPerLine,
+		PrintWidth:       d.PrintWidth,
+		SupportsGraphics: d.SupportsGraphics,
+		SupportsBarcode:  d.SupportsBarcode,
+		HasQR:            d.HasQR,
+		SupportsCutter:   d.SupportsCutter,
+		SupportsDrawer:   d.SupportsDrawer,
+		SupportsUTF8:     d.SupportsUTF8,
+		QRMaxSize:        d.QRMaxSize,
+		CodeTable:        character.WPC1252,
+		Fonts:            append([]Font(nil), d.Fonts...),
+		ImageMode:        ImageRaster,
+		ImageThreshold:   d.ImageThreshold,
+		DebugLog:         d.DebugLog,
+	}
+	if p.Model == "" {
+		p.Model = d.Name
+	}
+
+	if d.CodeTable != "" {
+		table, err := ParseCodeTable(d.CodeTable)
+		if err != nil {
+			return nil, err
+		}
+		p.CodeTable = table
+	}
+	for _, name := range d.CodeTables {
+		table, err := ParseCodeTable(name)
+		if err != nil {
+			return nil, err
+		}
+		p.CodeTables = append(p.CodeTables, table)
+	}
+
+	for _, name := range d.AutoCodeTables {
+		table, err := ParseCodeTable(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := Encoding(table); err != nil {
+			return nil, fmt.Errorf("auto_code_tables: %w", err)
+		}
+		p.AutoCodeTables = append(p.AutoCodeTables, table)
+	}
+
+	if d.DoubleByte != "" {
+		if _, err := d.DoubleByte.Encoding(); err != nil {
+			return nil, err
+		}
+		p.DoubleByte = d.DoubleByte
+	}
+
+	policy, err := ParseUnencodable(d.Unencodable)
+	if err != nil {
+		return nil, err
+	}
+	p.Unencodable = policy
+	p.Replacement = d.Replacement
+	p.FallbackFont = d.FallbackFont
+
+	if len(d.FontPriority) > 2 {
+		return nil, fmt.Errorf("font_priority lists %d fonts, the printer keeps 2", len(d.FontPriority))
+	}
+	for _, name := range d.FontPriority {
+		font, err := ParseFontFunction(name)
+		if err != nil {
+			return nil, err
+		}
+		p.FontPriority = append(p.FontPriority, font)
+	}
+
+	if len(d.CodePages) > 0 {
+		p.CodePages = make(map[character.CodeTable]byte, len(d.CodePages))
+		for name, n := range d.CodePages {
+			table, err := ParseCodeTable(name)
+			if err != nil {
+				return nil, err
+			}
+			if n < 0 || n > 255 {
+				return nil, fmt.Errorf("code page %s: ESC t number %d out of range (0-255)", name, n)
+			}
+			p.CodePages[table] = byte(n)
+		}
+		if len(p.CodeTables) == 0 {
+			for table := range p.CodePages {
+				p.CodeTables = append(p.CodeTables, table)
+			}
+			sort.Slice(p.CodeTables, func(i, j int) bool {
+				return p.CodePages[p.CodeTables[i]] < p.CodePages[p.CodeTables[j]]
+			})
+		}
+	}
+
+	for _, f := range d.Fonts {
+		if f.Name == "" || (f.Width <= 0 && f.Columns <= 0) {
+			return nil, fmt.Errorf("font %q needs a name and a width or columns", f.Name)
+		}
+	}
+
+	switch d.ImageMode {
+	case "":
+	case ImageRaster, ImageBitImage, ImageGraphics:
+		p.ImageMode = d.ImageMode
+	default:
+		return nil, fmt.Errorf("unknown image_mode %q (use raster, bit_image or graphics)", d.ImageMode)
+	}
+
+	if d.Cut != nil {
+		switch d.Cut.Mode {
+		case "", CutPartial, CutFull:
+			p.CutMode = d.Cut.Mode
+		default:
+			return nil, fmt.Errorf("unknown cut mode %q (use partial or full)", d.Cut.Mode)
+		}
+		if d.Cut.Feed < 0 || d.Cut.Feed > 255 {
+			return nil, fmt.Errorf("cut feed %d out of range (0-255)", d.Cut.Feed)
+		}
+		p.CutFeed = d.Cut.Feed
+	}
+
+	if d.Dithering != "" {
+		mode, ok := graphics.DitherMap[strings.ToLower(d.Dithering)]
+		if !ok {
+			return nil, fmt.Errorf("unknown dithering %q", d.Dithering)
+		}
+		p.Dithering = mode
+	}
+
+	if d.ImageThreshold < 0 || d.ImageThreshold > 255 {
+		return nil, fmt.Errorf("image_threshold %d out of range (0-255)", d.ImageThreshold)
+	}
+	return p, nil
+}
@@ -0,0 +1,308 @@
+package profile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownProfile is returned when no profile matches a name or model
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// profileFileExts are the extensions read by LoadDir
+var profileFileExts = map[string]bool{".json": true, ".yaml": true, ".yml": true}
+
+// entry is a profile file as written, before inheritance is applied
+type entry struct {
+	name    string
+	extends string
+	aliases []string
+	source  string
+	raw     map[string]any
+}
+
+// Registry holds printer profiles loaded from profile files. Profiles are
+// resolved on every lookup, so a profile can extend one that is loaded
+// after it and every caller gets its own copy.
+type Registry struct {
+	mu      sync.RWMutex
+	entries map[string]*entry
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{entries: make(map[string]*entry)}
+}
+
+// Parse adds the profiles of a JSON or YAML document. The document holds a
+// single profile, a list of profiles or an escpos-printer-db
+// capabilities.json file. A profile replaces any previous profile with the
+// same name, so files can override the built-in ones.
+func (r *Registry) Parse(data []byte, source string) error {
+	if IsCapabilities(data) {
+		imported, err := ImportCapabilities(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source, err)
+		}
+		return r.AddDefinitions(source, imported.Definitions...)
+	}
+
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("%s: invalid profile file: %w", source, err)
+	}
+
+	var items []any
+	switch v := doc.(type) {
+	case map[string]any:
+		items = []any{v}
+	case []any:
+		items = v
+	default:
+		return fmt.Errorf("%s: expected a profile object or a list of profiles", source)
+	}
+
+	entries := make([]*entry, 0, len(items))
+	for i, item := range items {
+		raw, ok := item.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: profile %d is not an object", source, i)
+		}
+		e, err := newEntry(raw, source)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+	}
+
+	r.add(entries)
+	return nil
+}
+
+// AddDefinitions adds profiles built in Go. Boolean fields that are false
+// are omitted, so they do not override the profile a definition extends.
+func (r *Registry) AddDefinitions(source string, defs ...Definition) error {
+	entries := make([]*entry, 0, len(defs))
+	for _, def := range defs {
+		data, err := json.Marshal(def)
+		if err != nil {
+			return err
+		}
+		var raw map[string]any
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		e, err := newEntry(raw, source)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+	}
+
+	r.add(entries)
+	return nil
+}
+
+func (r *Registry) add(entries []*entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, e := range entries {
+		r.entries[strings.ToLower(e.name)] = e
+	}
+}
+
+// newEntry validates the keys of a single profile
+func newEntry(raw map[string]any, source string) (*entry, error) {
+	def, err := decodeDefinition(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+	if def.Name == "" {
+		return nil, fmt.Errorf("%s: profile name is required", source)
+	}
+	if strings.EqualFold(def.Name, def.Extends) {
+		return nil, fmt.Errorf("%s: profile %q extends itself", source, def.Name)
+	}
+	return &entry{
+		name:    def.Name,
+		extends: def.Extends,
+		aliases: def.Aliases,
+		source:  source,
+		raw:     raw,
+	}, nil
+}
+
+// Load adds the profiles of a .json, .yaml or .yml file
+func (r *Registry) Load(path string) error {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return fmt.Errorf("failed to read profile: %w", err)
+	}
+	return r.Parse(data, path)
+}
+
+// LoadDir adds every profile file in dir, in name order
+func (r *Registry) LoadDir(dir string) error {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read profiles directory: %w", err)
+	}
+	for _, f := range files {
+		if f.IsDir() || !profileFileExts[strings.ToLower(filepath.Ext(f.Name()))] {
+			continue
+		}
+		if err := r.Load(filepath.Join(dir, f.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Names returns the registered profile names, sorted
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.entries))
+	for _, e := range r.entries {
+		names = append(names, e.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the profile registered under name
+func (r *Registry) Get(name string) (*Escpos, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.entries[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownProfile, name)
+	}
+	return r.build(e)
+}
+
+// Resolve returns the profile for a document profile.model. The model is
+// matched, ignoring case, against profile names, then aliases, then the
+// model of each profile.
+func (r *Registry) Resolve(model string) (*Escpos, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	key := strings.ToLower(strings.TrimSpace(model))
+	if e, ok := r.entries[key]; ok {
+		return r.build(e)
+	}
+
+	names := r.namesLocked()
+	for _, name := range names {
+		for _, alias := range r.entries[name].aliases {
+			if strings.EqualFold(alias, key) {
+				return r.build(r.entries[name])
+			}
+		}
+	}
+	for _, name := range names {
+		p, err := r.build(r.entries[name])
+		if err == nil && strings.EqualFold(p.Model, key) {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w %q", ErrUnknownProfile, model)
+}
+
+// Validate resolves every profile, reporting missing parents, inheritance
+// cycles and invalid values
+func (r *Registry) Validate() error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var errs []error
+	for _, name := range r.namesLocked() {
+		if _, err := r.build(r.entries[name]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Registry) namesLocked() []string {
+	names := make([]string, 0, len(r.entries))
+	for name := range r.entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// build applies inheritance and converts the result into a profile
+func (r *Registry) build(e *entry) (*Escpos, error) {
+	raw, err := r.flatten(e, nil)
+	if err != nil {
+		return nil, err
+	}
+	def, err := decodeDefinition(raw)
+	if err != nil {
+		return nil, fmt.Errorf("profile %q (%s): %w", e.name, e.source, err)
+	}
+	p, err := def.Escpos()
+	if err != nil {
+		return nil, fmt.Errorf("profile %q (%s): %w", e.name, e.source, err)
+	}
+	return p, nil
+}
+
+// flatten returns the keys of e merged over the keys of its parents
+func (r *Registry) flatten(e *entry, chain []string) (map[string]any, error) {
+	for _, name := range chain {
+		if strings.EqualFold(name, e.name) {
+			return nil, fmt.Errorf("profile inheritance cycle: %s -> %s", strings.Join(chain, " -> "), e.name)
+		}
+	}
+	chain = append(chain, e.name)
+
+	merged := make(map[string]any)
+	if e.extends != "" {
+		parent, ok := r.entries[strings.ToLower(e.extends)]
+		if !ok {
+			return nil, fmt.Errorf("profile %q (%s) extends %w %q", e.name, e.source, ErrUnknownProfile, e.extends)
+		}
+		inherited, err := r.flatten(parent, chain)
+		if err != nil {
+			return nil, err
+		}
+		merged = inherited
+	}
+
+	// Identity is never inherited
+	delete(merged, "aliases")
+	delete(merged, "extends")
+	delete(merged, "model")
+	mergeKeys(merged, e.raw)
+	return merged, nil
+}
+
+// mergeKeys copies src over dst, merging nested objects key by key. Lists
+// replace the inherited list.
+func mergeKeys(dst, src map[string]any) {
+	for k, v := range src {
+		child, isMap := v.(map[string]any)
+		parent, parentIsMap := dst[k].(map[string]any)
+		if isMap && parentIsMap {
+			merged := make(map[string]any, len(parent)+len(child))
+			mergeKeys(merged, parent)
+			mergeKeys(merged, child)
+			dst[k] = merged
+			continue
+		}
+		dst[k] = v
+	}
+}
@@ -0,0 +1,275 @@
+package profile_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/graphics"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+func TestBuiltin_MatchesFactories(t *testing.T) {
+	reg := profile.Builtin()
+
+	want := []string{"ec-pm-80250", "generic-58mm", "generic-80mm", "gp-58n", "pt-210"}
+	if got := strings.Join(reg.Names(), ","); got != strings.Join(want, ",") {
+		t.Errorf("expected built-in profiles %v, got %v", want, reg.Names())
+	}
+
+	p, err := reg.Get("pt-210")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if p.Model != "58mm PT-210" || p.DotsPerLine != 384 || !p.HasQR || p.QRMaxSize != 19 {
+		t.Errorf("unexpected pt-210 profile: %+v", p)
+	}
+	if p.SupportsCutter {
+		t.Error("expected pt-210 to inherit SupportsCutter false")
+	}
+
+	p, err = reg.Get("ec-pm-80250")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if p.Model != "80mm EC-PM-80250" || p.DotsPerLine != 576 || p.ImageThreshold != 128 || p.CutMode != profile.CutPartial {
+		t.Errorf("unexpected ec-pm-80250 profile: %+v", p)
+	}
+}
+
+func TestRegistry_Resolve(t *testing.T) {
+	reg := profile.Builtin()
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"pt-210", "58mm PT-210"},
+		{"58mm PT-210", "58mm PT-210"},
+		{"80MM EC-PM-80250", "80mm EC-PM-80250"},
+		{"generic-80", "Generic 80mm"},
+		{"Generic 58mm", "Generic 58mm"},
+	}
+	for _, tt := range tests {
+		p, err := reg.Resolve(tt.model)
+		if err != nil {
+			t.Errorf("Resolve(%q) error: %v", tt.model, err)
+			continue
+		}
+		if p.Model != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.model, p.Model, tt.want)
+		}
+	}
+
+	if _, err := reg.Resolve("TM-T20"); !errors.Is(err, profile.ErrUnknownProfile) {
+		t.Errorf("expected ErrUnknownProfile, got %v", err)
+	}
+}
+
+func TestRegistry_Extends(t *testing.T) {
+	reg := profile.Builtin()
+	err := reg.Parse([]byte(`{
+		"name": "tm-t20",
+		"extends": "generic-80mm",
+		"aliases": ["epson tm-t20"],
+		"code_tables": ["PC437", "PC850", "WPC1252"],
+		"fonts": [{"name": "A", "width": 12, "height": 24, "columns": 48}],
+		"image_mode": "graphics",
+		"dithering": "atkinson",
+		"cut": {"feed": 4}
+	}`), "tm-t20.json")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	p, err := reg.Resolve("Epson TM-T20")
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if p.Model != "tm-t20" {
+		t.Errorf("expected model to default to the name, got %q", p.Model)
+	}
+	if p.PaperWidth != 80 || !p.SupportsCutter || p.CodeTable != character.PC850 {
+		t.Errorf("expected generic-80mm values to be inherited: %+v", p)
+	}
+	if p.ImageMode != profile.ImageGraphics || p.Dithering != graphics.Atkinson {
+		t.Errorf("unexpected image settings: %v %v", p.ImageMode, p.Dithering)
+	}
+	// Nested objects are merged key by key
+	if p.CutMode != profile.CutPartial || p.CutFeed != 4 {
+		t.Errorf("expected partial cut with feed 4, got %s %d", p.CutMode, p.CutFeed)
+	}
+	// Lists replace the inherited list
+	if len(p.Fonts) != 1 || p.Columns("A") != 48 || p.Columns("B") != 64 {
+		t.Errorf("unexpected fonts: %+v", p.Fonts)
+	}
+	if w, h := p.CellSize("A"); w != 12 || h != 24 {
+		t.Errorf("unexpected font A cell: %dx%d", w, h)
+	}
+	// Font B is not declared and keeps the standard cell
+	if w, h := p.CellSize("B"); w != 9 || h != 17 {
+		t.Errorf("unexpected font B cell: %dx%d", w, h)
+	}
+	if p.IsSupported(character.PC852) || !p.IsSupported(character.WPC1252) {
+		t.Error("expected code_tables to limit the supported tables")
+	}
+
+	// The parent profile is untouched
+	parent, _ := reg.Get("generic-80mm")
+	if len(parent.Fonts) != 2 || parent.CutFeed != 0 {
+		t.Errorf("parent profile changed: %+v", parent)
+	}
+}
+
+func TestRegistry_YAML(t *testing.T) {
+	reg := profile.Builtin()
+	err := reg.Parse([]byte(`
+- name: kiosk-58
+  extends: pt-210
+  model: Kiosk 58
+  supports_cutter: true
+  cut:
+    mode: full
+- name: kiosk-58-raw
+  extends: kiosk-58
+  image_mode: bit_image
+`), "kiosk.yaml")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	p, err := reg.Get("kiosk-58-raw")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if p.QRMaxSize != 19 || !p.SupportsCutter || p.CutMode != profile.CutFull || p.ImageMode != profile.ImageBitImage {
+		t.Errorf("unexpected profile: %+v", p)
+	}
+}
+
+func TestRegistry_TextOptions(t *testing.T) {
+	reg := profile.Builtin()
+	err := reg.Parse([]byte(`
+name: tm-m30
+extends: generic-80mm
+supports_utf8: true
+font_priority: [ank, japanese]
+auto_code_tables: [WPC1252, PC866, PC737]
+unencodable: transliterate
+replacement: "_"
+fallback_font: /usr/share/fonts/NotoSansCJK.ttc
+`), "tm-m30.yaml")
+	if err != nil {
+		t.Fatalf("Parse error: %v", err)
+	}
+
+	p, err := reg.Get("tm-m30")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if !p.SupportsUTF8 || len(p.FontPriority) != 2 || p.FontPriority[1] != character.JapaneseGothic {
+		t.Errorf("unexpected UTF-8 settings: %v %v", p.SupportsUTF8, p.FontPriority)
+	}
+	if len(p.AutoCodeTables) != 3 || p.AutoCodeTables[2] != character.PC737 {
+		t.Errorf("unexpected auto code tables: %v", p.AutoCodeTables)
+	}
+	if p.Unencodable != profile.UnencodableTransliterate || p.Replacement != "_" {
+		t.Errorf("unexpected unencodable policy: %q %q", p.Unencodable, p.Replacement)
+	}
+	if p.FallbackFont != "/usr/share/fonts/NotoSansCJK.ttc" {
+		t.Errorf("unexpected fallback font: %q", p.FallbackFont)
+	}
+
+	bad := profile.NewRegistry()
+	if err := bad.Parse([]byte(`{"name": "x", "font_priority": ["klingon"]}`), "x.json"); err == nil {
+		if _, err := bad.Get("x"); err == nil {
+			t.Error("expected error for unknown font_priority")
+		}
+	}
+}
+
+func TestRegistry_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"unknown key", `{"name": "x", "papr_width": 80}`, "unknown field"},
+		{"missing name", `{"model": "x"}`, "name is required"},
+		{"extends itself", `{"name": "x", "extends": "X"}`, "extends itself"},
+		{"not an object", `"x"`, "expected a profile"},
+	}
+	for _, tt := range tests {
+		err := profile.NewRegistry().Parse([]byte(tt.data), "test.json")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: expected error containing %q, got %v", tt.name, tt.want, err)
+		}
+	}
+
+	reg := profile.NewRegistry()
+	_ = reg.Parse([]byte(`[{"name": "a", "extends": "b"}, {"name": "b", "extends": "a"}, {"name": "c", "extends": "missing"}, {"name": "d", "code_table": "PC999"}, {"name": "e", "double_byte": "utf16"}, {"name": "f", "unencodable": "drop"}]`), "test.json")
+	err := reg.Validate()
+	for _, want := range []string{"inheritance cycle", `extends unknown profile "missing"`, `unknown code table "PC999"`, `unknown double_byte "utf16"`, `unknown unencodable policy "drop"`} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("expected Validate error containing %q, got %v", want, err)
+		}
+	}
+}
+
+func TestRegistry_LoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json":     `{"name": "generic-58mm", "model": "Overridden", "dpi": 180}`,
+		"b.yml":      "name: b\nextends: generic-58mm\n",
+		"notes.txt":  "not a profile",
+		"broken.txt": "{",
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reg := profile.Builtin()
+	if err := reg.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir error: %v", err)
+	}
+
+	// Files override built-in profiles with the same name
+	p, err := reg.Get("b")
+	if err != nil {
+		t.Fatalf("Get error: %v", err)
+	}
+	if p.DPI != 180 || p.PaperWidth != 0 {
+		t.Errorf("expected b to extend the overridden generic-58mm, got %+v", p)
+	}
+
+	// Built-ins that extended the old definition see the override too
+	if p, _ := reg.Get("pt-210"); p.DPI != 180 {
+		t.Errorf("expected pt-210 DPI 180, got %d", p.DPI)
+	}
+}
+
+func TestParseCodeTable(t *testing.T) {
+	tests := map[string]character.CodeTable{
+		"PC850":       character.PC850,
+		"wpc1252":     character.WPC1252,
+		"ISO-8859-15": character.ISO885915,
+		"19":          character.PC858,
+	}
+	for name, want := range tests {
+		got, err := profile.ParseCodeTable(name)
+		if err != nil || got != want {
+			t.Errorf("ParseCodeTable(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := profile.ParseCodeTable("PC999"); err == nil {
+		t.Error("expected error for unknown code table")
+	}
+	if name := profile.CodeTableName(character.PC858); name != "PC858" {
+		t.Errorf("expected PC858, got %s", name)
+	}
+}
@@ -0,0 +1,184 @@
+package profile
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+)
+
+// Unencodable is the policy for runes that no code table of the profile can
+// encode
+type Unencodable string
+
+const (
+	// UnencodableError fails the text, the default
+	UnencodableError Unencodable = "error"
+	// UnencodableReplace prints Escpos.Replacement instead of the rune
+	UnencodableReplace Unencodable = "replace"
+	// UnencodableTransliterate prints an ASCII approximation ("ő" as "o",
+	// "€" as "EUR"), or Escpos.Replacement when there is none
+	UnencodableTransliterate Unencodable = "transliterate"
+)
+
+// DefaultReplacement is printed for unencodable runes when the profile sets
+// no replacement
+const DefaultReplacement = "?"
+
+// ParseUnencodable parses an unencodable policy, "" is UnencodableError
+func ParseUnencodable(name string) (Unencodable, error) {
+	switch p := Unencodable(strings.ToLower(name)); p {
+	case "", UnencodableError:
+		return UnencodableError, nil
+	case UnencodableReplace, UnencodableTransliterate:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown unencodable policy %q (use error, replace or transliterate)", name)
+	}
+}
+
+// Substitution is an unencodable rune and the text printed instead
+type Substitution struct {
+	Rune rune
+	With string
+}
+
+// String describes the substitution for warnings
+func (s Substitution) String() string {
+	return fmt.Sprintf("%q printed as %q", s.Rune, s.With)
+}
+
+// transliterations are ASCII approximations of common punctuation, symbols
+// and letters that don't decompose into a base letter and marks
+var transliterations = map[rune]string{
+	// Quotes and apostrophes
+	'“': `"`, '”': `"`, '„': `"`, '‟': `"`, '″': `"`, '«': `"`, '»': `"`,
+	'‘': "'", '’': "'", '‚': "'", '‛': "'", '′': "'", '‹': "'", '›': "'",
+	// Dashes, spaces and invisible characters
+	'‐': "-", '‑': "-", '‒': "-", '–': "-", '—': "-", '―': "-", '−': "-",
+	'\u00A0': " ", '\u2002': " ", '\u2003': " ", '\u2009': " ", '\u202F': " ",
+	'\u00AD': "", '\u200B': "", '\u200C': "", '\u200D': "", '\u2060': "", '\uFEFF': "",
+	// Punctuation
+	'…': "...", '•': "*", '·': ".", '‰': "o/oo", '¡': "!", '¿': "?",
+	// Symbols
+	'€': "EUR", '£': "GBP", '¥': "JPY", '¢': "c", '₹': "INR", '₩': "KRW", '₽': "RUB",
+	'©': "(C)", '®': "(R)", '™': "TM", '°': "o", 'º': "o", 'ª': "a",
+	'×': "x", '÷': "/", '±': "+/-", '≤': "<=", '≥': ">=", '≠': "!=", '→': "->", '←': "<-",
+	'½': "1/2", '¼': "1/4", '¾': "3/4", '²': "2", '³': "3", '¹': "1",
+	// Letters without decomposition
+	'ß': "ss", 'æ': "ae", 'Æ': "AE", 'œ': "oe", 'Œ': "OE", 'ø': "o", 'Ø': "O",
+	'ł': "l", 'Ł': "L", 'đ': "d", 'Đ': "D", 'ð': "d", 'Ð': "D", 'þ': "th", 'Þ': "Th", 'ı': "i",
+}
+
+// Transliterate returns an ASCII approximation of r: the table above, or
+// the base letter of an accented letter ("ő" is "o"). It reports false when
+// there is none.
+func Transliterate(r rune) (string, bool) {
+	if s, ok := transliterations[r]; ok {
+		return s, true
+	}
+	var base strings.Builder
+	for _, c := range norm.NFD.String(string(r)) {
+		if !unicode.Is(unicode.Mn, c) {
+			base.WriteRune(c)
+		}
+	}
+	if s := base.String(); s != string(r) && s != "" && isASCII(s) {
+		return s, true
+	}
+	return "", false
+}
+
+// isASCII reports whether s only has ASCII characters
+func isASCII(s string) bool {
+	for _, c := range s {
+		if c > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// Substitute applies the Unencodable policy to text: runes the profile
+// cannot encode, with its code table, AutoCodeTables or DoubleByte set, are
+// transliterated or replaced, and each change is returned. With the error
+// policy the text is returned unchanged and encoding fails as usual.
+func (e *Escpos) Substitute(text string) (string, []Substitution) {
+	if e.Unencodable == "" || e.Unencodable == UnencodableError {
+		return text, nil
+	}
+	if _, err := e.EncodeRuns(text); err == nil {
+		return text, nil
+	}
+	return e.substitute(text, e.runeEncoder())
+}
+
+// substitute transliterates or replaces the runes of text that don't encode
+func (e *Escpos) substitute(text string, encodes func(rune) bool) (string, []Substitution) {
+	replacement := e.Replacement
+	if replacement == "" {
+		replacement = DefaultReplacement
+	}
+	var out strings.Builder
+	var subs []Substitution
+	for _, r := range text {
+		if encodes(r) {
+			out.WriteRune(r)
+			continue
+		}
+		with := replacement
+		if e.Unencodable == UnencodableTransliterate {
+			if s, ok := Transliterate(r); ok && allEncode(s, encodes) {
+				with = s
+			}
+		}
+		out.WriteString(with)
+		subs = append(subs, Substitution{Rune: r, With: with})
+	}
+	return out.String(), subs
+}
+
+// allEncode reports whether every rune of s encodes
+func allEncode(s string, encodes func(rune) bool) bool {
+	for _, r := range s {
+		if !encodes(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// runeEncoder returns a function that reports whether a rune encodes with
+// the code table, one of the AutoCodeTables or, for CJK runes, the
+// DoubleByte set
+func (e *Escpos) runeEncoder() func(rune) bool {
+	var encoders []*encoding.Encoder
+	for i, table := range append([]character.CodeTable{e.CodeTable}, e.AutoCodeTables...) {
+		// The code table always encodes, like EncodeString
+		if cm, err := Encoding(table); err == nil && (i == 0 || e.IsSupported(table)) {
+			encoders = append(encoders, cm.NewEncoder())
+		}
+	}
+	var doubleByte *encoding.Encoder
+	if e.DoubleByte != "" {
+		if enc, err := e.DoubleByte.Encoding(); err == nil {
+			doubleByte = enc.NewEncoder()
+		}
+	}
+	return func(r rune) bool {
+		for _, enc := range encoders {
+			if _, err := enc.String(string(r)); err == nil {
+				return true
+			}
+		}
+		if doubleByte != nil {
+			_, ok := encodeDoubleByte(doubleByte, r)
+			return ok
+		}
+		return false
+	}
+}
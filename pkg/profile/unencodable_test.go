@@ -0,0 +1,80 @@
+package profile_test
+
+import (
+	"testing"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+func TestParseUnencodable(t *testing.T) {
+	tests := map[string]profile.Unencodable{
+		"":              profile.UnencodableError,
+		"error":         profile.UnencodableError,
+		"Replace":       profile.UnencodableReplace,
+		"transliterate": profile.UnencodableTransliterate,
+	}
+	for name, want := range tests {
+		if got, err := profile.ParseUnencodable(name); err != nil || got != want {
+			t.Errorf("ParseUnencodable(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := profile.ParseUnencodable("drop"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestTransliterate(t *testing.T) {
+	tests := map[rune]string{
+		'ő': "o",
+		'Ç': "C",
+		'“': `"`,
+		'—': "-",
+		'€': "EUR",
+		'ß': "ss",
+		'…': "...",
+	}
+	for r, want := range tests {
+		if got, ok := profile.Transliterate(r); !ok || got != want {
+			t.Errorf("Transliterate(%q) = %q, %v; want %q", r, got, ok, want)
+		}
+	}
+	for _, r := range []rune{'漢', 'Ж', 'a'} {
+		if got, ok := profile.Transliterate(r); ok {
+			t.Errorf("Transliterate(%q) = %q, want none", r, got)
+		}
+	}
+}
+
+func TestSubstitute(t *testing.T) {
+	p := profile.CreateProfile80mm()
+	p.CodeTable = character.PC437
+
+	text := "“Größe” — 5€ ő 漢"
+	if got, subs := p.Substitute(text); got != text || subs != nil {
+		t.Errorf("error policy should not change text, got %q %v", got, subs)
+	}
+
+	p.Unencodable = profile.UnencodableTransliterate
+	got, subs := p.Substitute(text)
+	// PC437 has ö and ß but no curly quotes, dashes, euro or ő
+	if want := `"Größe" - 5EUR o ?`; got != want {
+		t.Errorf("Substitute() = %q, want %q", got, want)
+	}
+	if len(subs) != 6 || subs[5].Rune != '漢' || subs[5].With != "?" {
+		t.Errorf("unexpected substitutions: %v", subs)
+	}
+	if _, err := p.EncodeString(got); err != nil {
+		t.Errorf("substituted text should encode: %v", err)
+	}
+
+	p.Unencodable = profile.UnencodableReplace
+	p.Replacement = "_"
+	if got, _ := p.Substitute("5€ ő"); got != "5_ _" {
+		t.Errorf("Substitute() = %q, want %q", got, "5_ _")
+	}
+
+	if got, _ := p.Substitute("plain ascii"); got != "plain ascii" {
+		t.Errorf("encodable text changed to %q", got)
+	}
+}
@@ -0,0 +1,72 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// retryableError marks transient failures such as connector errors
+type retryableError struct {
+	err error
+}
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+// Retryable marks err as transient so the queue retries the job with backoff
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// IsRetryable reports whether err was marked with Retryable
+func IsRetryable(err error) bool {
+	var r *retryableError
+	return errors.As(err, &r)
+}
+
+// PrinterFactory opens the printer registered under name
+type PrinterFactory func(name string) (*service.Printer, error)
+
+// ExecutorDispatcher returns a Dispatcher that compiles each document with
+// executor.Executor and sends it in a single transaction.
+//
+// Parse and compile errors are permanent; failures to open the printer or to
+// transmit the job are retried.
+func ExecutorDispatcher(open PrinterFactory) Dispatcher {
+	return func(_ context.Context, name string, document []byte) error {
+		doc, err := schema.ParseDocument(document)
+		if err != nil {
+			return err
+		}
+
+		printer, err := open(name)
+		if err != nil {
+			return Retryable(fmt.Errorf("open printer %s: %w", name, err))
+		}
+		defer func() {
+			if err := printer.Close(); err != nil {
+				log.Printf("queue: failed to close printer %s: %v", name, err)
+			}
+		}()
+
+		exec := executor.NewExecutor(printer)
+		job, err := exec.Compile(doc)
+		if err != nil {
+			return err
+		}
+
+		if err := printer.SendJob(job); err != nil {
+			return Retryable(fmt.Errorf("send job to %s: %w", name, err))
+		}
+		return nil
+	}
+}
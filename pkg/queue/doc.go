@@ -0,0 +1,30 @@
+// Package queue provides a durable print-job queue that sits in front of the
+// executor package.
+//
+// Jobs are stored in an append-only JSON Lines file, so receipts survive a
+// restart of the POS host. Each printer is served in FIFO order; jobs for
+// different printers are delivered concurrently.
+//
+// # Quick Start
+//
+//	q, err := queue.Open("spool/jobs.jsonl", queue.ExecutorDispatcher(openPrinter), queue.Options{})
+//	defer q.Close()
+//
+//	job, err := q.Enqueue("kitchen", "order-1042", documentJSON)
+//	go q.Run(ctx, time.Second)
+//
+// # Delivery Semantics
+//
+//   - Errors wrapped with Retryable (connector failures) are retried with
+//     exponential backoff up to Options.MaxAttempts.
+//   - Any other error (invalid document, compile failure) moves the job to
+//     the dead-letter state; Retry puts it back in the queue.
+//   - A job waiting for a retry blocks later jobs of the same printer.
+//   - Idempotency keys prevent duplicate receipts when a client resubmits.
+//   - Delivery is at-least-once: a job that was being printed when the host
+//     stopped is delivered again on the next Open.
+//
+// # Thread Safety
+//
+// Queue methods are safe for concurrent use.
+package queue
@@ -0,0 +1,50 @@
+package queue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// State represents the lifecycle stage of a queued job
+type State string
+
+const (
+	// StatePending jobs wait for delivery or for their next retry
+	StatePending State = "pending"
+	// StateDone jobs were delivered to the printer
+	StateDone State = "done"
+	// StateDead jobs failed permanently or exhausted their attempts
+	StateDead State = "dead"
+)
+
+// Job is a print document waiting to be delivered to a named printer
+type Job struct {
+	ID          string          `json:"id"`
+	Key         string          `json:"key,omitempty"` // Idempotency key
+	Printer     string          `json:"printer"`
+	Document    json.RawMessage `json:"document"`
+	State       State           `json:"state"`
+	Attempts    int             `json:"attempts"`
+	LastError   string          `json:"last_error,omitempty"`
+	NextAttempt time.Time       `json:"next_attempt"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// clone returns a copy safe to hand out to callers
+func (j *Job) clone() *Job {
+	c := *j
+	c.Document = append(json.RawMessage(nil), j.Document...)
+	return &c
+}
+
+// newJobID returns a random 128-bit hex identifier
+func newJobID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,375 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/adcondev/poster/pkg/constants"
+)
+
+// Dispatcher delivers a document to the named printer.
+// Errors wrapped with Retryable are retried with backoff; any other error
+// moves the job to the dead-letter state immediately.
+type Dispatcher func(ctx context.Context, printer string, document []byte) error
+
+// Options configures retry behavior
+type Options struct {
+	MaxAttempts int           // Default: constants.DefaultQueueMaxAttempts
+	BaseDelay   time.Duration // Default: constants.DefaultQueueBaseDelay
+	MaxDelay    time.Duration // Default: constants.DefaultQueueMaxDelay
+	// Retention is how long delivered jobs are kept for idempotency checks
+	// when compacting; 0 keeps them forever.
+	Retention time.Duration
+	// CompactInterval is how often Run compacts the queue file.
+	// Default: constants.DefaultQueueCompactInterval
+	CompactInterval time.Duration
+	// Now returns the current time; it can be replaced in tests.
+	Now func() time.Time
+}
+
+func (o *Options) applyDefaults() {
+	if o.MaxAttempts <= 0 {
+		o.MaxAttempts = constants.DefaultQueueMaxAttempts
+	}
+	if o.BaseDelay <= 0 {
+		o.BaseDelay = constants.DefaultQueueBaseDelay
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = constants.DefaultQueueMaxDelay
+	}
+	if o.CompactInterval <= 0 {
+		o.CompactInterval = constants.DefaultQueueCompactInterval
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+}
+
+// ErrJobNotFound is returned when a job ID is unknown
+var ErrJobNotFound = errors.New("job not found")
+
+// Queue is a durable print-job queue with per-printer FIFO ordering
+type Queue struct {
+	mu       sync.Mutex
+	store    *FileStore
+	dispatch Dispatcher
+	opts     Options
+
+	jobs  map[string]*Job
+	order []string          // Job IDs in enqueue order
+	keys  map[string]string // Idempotency key -> job ID
+	// inflight holds the IDs of jobs being dispatched, so a concurrent
+	// Process neither delivers them again nor overtakes them
+	inflight map[string]bool
+}
+
+// Open loads the queue stored at path and compacts it. Jobs that were
+// pending when the host stopped are delivered again, so delivery is
+// at-least-once.
+func Open(path string, dispatch Dispatcher, opts Options) (*Queue, error) {
+	if dispatch == nil {
+		return nil, fmt.Errorf("dispatcher cannot be nil")
+	}
+	opts.applyDefaults()
+
+	store, err := OpenFileStore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := store.Load()
+	if err != nil {
+		_ = store.Close()
+		return nil, err
+	}
+
+	q := &Queue{
+		store:    store,
+		dispatch: dispatch,
+		opts:     opts,
+		jobs:     make(map[string]*Job, len(jobs)),
+		keys:     make(map[string]string),
+		inflight: make(map[string]bool),
+	}
+	for _, job := range jobs {
+		q.index(job)
+	}
+	if err := q.Compact(); err != nil {
+		log.Printf("queue: failed to compact %s: %v", path, err)
+	}
+
+	return q, nil
+}
+
+func (q *Queue) index(job *Job) {
+	q.jobs[job.ID] = job
+	q.order = append(q.order, job.ID)
+	if job.Key != "" {
+		q.keys[job.Key] = job.ID
+	}
+}
+
+// Enqueue durably adds a document for printer. When key is not empty and a
+// job with the same key already exists, that job is returned instead of
+// creating a duplicate receipt.
+func (q *Queue) Enqueue(printer, key string, document []byte) (*Job, error) {
+	if printer == "" {
+		return nil, fmt.Errorf("printer name cannot be empty")
+	}
+	if !json.Valid(document) {
+		return nil, fmt.Errorf("document is not valid JSON")
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if key != "" {
+		if id, ok := q.keys[key]; ok {
+			return q.jobs[id].clone(), nil
+		}
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return nil, fmt.Errorf("generate job id: %w", err)
+	}
+
+	now := q.opts.Now()
+	job := &Job{
+		ID:          id,
+		Key:         key,
+		Printer:     printer,
+		Document:    append(json.RawMessage(nil), document...),
+		State:       StatePending,
+		NextAttempt: now,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	if err := q.store.Append(job); err != nil {
+		return nil, err
+	}
+	q.index(job)
+
+	return job.clone(), nil
+}
+
+// Get returns a snapshot of the job with the given ID
+func (q *Queue) Get(id string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return nil, ErrJobNotFound
+	}
+	return job.clone(), nil
+}
+
+// Jobs returns snapshots of all jobs in the given state, in enqueue order.
+// An empty state returns every job.
+func (q *Queue) Jobs(state State) []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var out []*Job
+	for _, id := range q.order {
+		job := q.jobs[id]
+		if state == "" || job.State == state {
+			out = append(out, job.clone())
+		}
+	}
+	return out
+}
+
+// DeadLetters returns the jobs that failed permanently
+func (q *Queue) DeadLetters() []*Job {
+	return q.Jobs(StateDead)
+}
+
+// Retry moves a dead job back to pending with a fresh attempt counter
+func (q *Queue) Retry(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return ErrJobNotFound
+	}
+	if job.State != StateDead {
+		return fmt.Errorf("job %s is %s, only dead jobs can be retried", id, job.State)
+	}
+
+	updated := *job
+	updated.State = StatePending
+	updated.Attempts = 0
+	updated.LastError = ""
+	updated.NextAttempt = q.opts.Now()
+	updated.UpdatedAt = updated.NextAttempt
+	return q.commit(&updated)
+}
+
+// commit persists a job snapshot and then updates the in-memory copy
+func (q *Queue) commit(job *Job) error {
+	if err := q.store.Append(job); err != nil {
+		return err
+	}
+	q.jobs[job.ID] = job
+	return nil
+}
+
+// due returns, for every printer, the oldest pending job if it is ready,
+// and marks it in flight until deliver records the outcome. A pending job
+// that is still backing off or in flight blocks later jobs of its printer.
+func (q *Queue) due() []*Job {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.opts.Now()
+	seen := make(map[string]bool)
+	var ready []*Job
+	for _, id := range q.order {
+		job := q.jobs[id]
+		if job.State != StatePending || seen[job.Printer] {
+			continue
+		}
+		seen[job.Printer] = true
+		if !q.inflight[id] && !job.NextAttempt.After(now) {
+			q.inflight[id] = true
+			ready = append(ready, job.clone())
+		}
+	}
+	return ready
+}
+
+// Process delivers every job that is due, one per printer, and returns the
+// number of jobs attempted. Different printers are served concurrently.
+func (q *Queue) Process(ctx context.Context) int {
+	ready := q.due()
+
+	var wg sync.WaitGroup
+	for _, job := range ready {
+		wg.Add(1)
+		go func(job *Job) {
+			defer wg.Done()
+			q.deliver(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+
+	return len(ready)
+}
+
+// deliver dispatches a single job and records the outcome
+func (q *Queue) deliver(ctx context.Context, job *Job) {
+	err := q.dispatch(ctx, job.Printer, job.Document)
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	defer delete(q.inflight, job.ID)
+
+	now := q.opts.Now()
+	job.Attempts++
+	job.UpdatedAt = now
+
+	switch {
+	case err == nil:
+		job.State = StateDone
+		job.LastError = ""
+	case IsRetryable(err) && job.Attempts < q.opts.MaxAttempts:
+		job.LastError = err.Error()
+		job.NextAttempt = now.Add(q.backoff(job.Attempts))
+		log.Printf("queue: job %s on %s failed (attempt %d/%d), retrying at %s: %v",
+			job.ID, job.Printer, job.Attempts, q.opts.MaxAttempts, job.NextAttempt.Format(time.RFC3339), err)
+	default:
+		job.State = StateDead
+		job.LastError = err.Error()
+		log.Printf("queue: job %s on %s moved to dead letters: %v", job.ID, job.Printer, err)
+	}
+
+	if cerr := q.commit(job); cerr != nil {
+		log.Printf("queue: failed to persist job %s: %v", job.ID, cerr)
+	}
+}
+
+// backoff returns BaseDelay * 2^(attempts-1), capped at MaxDelay
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.opts.BaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= q.opts.MaxDelay {
+			return q.opts.MaxDelay
+		}
+	}
+	return delay
+}
+
+// Run processes due jobs every interval, and compacts the queue every
+// Options.CompactInterval, until ctx is cancelled
+func (q *Queue) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = constants.DefaultQueuePollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	compact := time.NewTicker(q.opts.CompactInterval)
+	defer compact.Stop()
+
+	for {
+		q.Process(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-compact.C:
+			if err := q.Compact(); err != nil {
+				log.Printf("queue: failed to compact: %v", err)
+			}
+		case <-ticker.C:
+		}
+	}
+}
+
+// Compact rewrites the queue file, dropping delivered jobs older than
+// Options.Retention. If the file cannot be rewritten the queue is left
+// unchanged.
+func (q *Queue) Compact() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.opts.Now()
+	var keep, expired []*Job
+	var order []string
+	for _, id := range q.order {
+		job := q.jobs[id]
+		if job.State == StateDone && q.opts.Retention > 0 &&
+			now.Sub(job.UpdatedAt) > q.opts.Retention {
+			expired = append(expired, job)
+			continue
+		}
+		keep = append(keep, job)
+		order = append(order, id)
+	}
+
+	if err := q.store.Compact(keep); err != nil {
+		return err
+	}
+	for _, job := range expired {
+		delete(q.jobs, job.ID)
+		if job.Key != "" {
+			delete(q.keys, job.Key)
+		}
+	}
+	q.order = order
+	return nil
+}
+
+// Close closes the queue file
+func (q *Queue) Close() error {
+	return q.store.Close()
+}
@@ -0,0 +1,401 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+const testDoc = `{"version":"1.0","profile":{"model":"Test"},"commands":[{"type":"text","data":{"content":{"text":"Hola"}}}]}`
+
+// fakeClock is a manually advanced time source
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// recorder is a Dispatcher that records deliveries and returns queued errors
+type recorder struct {
+	mu        sync.Mutex
+	delivered []string
+	errs      map[string][]error
+}
+
+func (r *recorder) dispatch(_ context.Context, printer string, _ []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if errs := r.errs[printer]; len(errs) > 0 {
+		r.errs[printer] = errs[1:]
+		return errs[0]
+	}
+	r.delivered = append(r.delivered, printer)
+	return nil
+}
+
+func openTestQueue(t *testing.T, path string, r *recorder, clock *fakeClock) *Queue {
+	t.Helper()
+	q, err := Open(path, r.dispatch, Options{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    10 * time.Second,
+		Now:         clock.Now,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = q.Close() })
+	return q
+}
+
+func newFixture(t *testing.T) (string, *recorder, *fakeClock) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "spool", "jobs.jsonl")
+	return path, &recorder{errs: map[string][]error{}}, &fakeClock{now: time.Unix(1_700_000_000, 0)}
+}
+
+func TestEnqueue_Validation(t *testing.T) {
+	path, r, clock := newFixture(t)
+	q := openTestQueue(t, path, r, clock)
+
+	_, err := q.Enqueue("", "", []byte(testDoc))
+	assert.Error(t, err)
+
+	_, err = q.Enqueue("kitchen", "", []byte("{not json"))
+	assert.Error(t, err)
+}
+
+func TestEnqueue_IdempotencyKey(t *testing.T) {
+	path, r, clock := newFixture(t)
+	q := openTestQueue(t, path, r, clock)
+
+	first, err := q.Enqueue("receipt", "order-1", []byte(testDoc))
+	require.NoError(t, err)
+	second, err := q.Enqueue("receipt", "order-1", []byte(testDoc))
+	require.NoError(t, err)
+
+	assert.Equal(t, first.ID, second.ID)
+	assert.Len(t, q.Jobs(""), 1)
+}
+
+func TestProcess_DeliversInFIFOOrderPerPrinter(t *testing.T) {
+	path, r, clock := newFixture(t)
+	q := openTestQueue(t, path, r, clock)
+
+	a, _ := q.Enqueue("kitchen", "", []byte(testDoc))
+	b, _ := q.Enqueue("kitchen", "", []byte(testDoc))
+	c, _ := q.Enqueue("bar", "", []byte(testDoc))
+
+	assert.Equal(t, 2, q.Process(context.Background()), "one job per printer per pass")
+
+	jobA, _ := q.Get(a.ID)
+	jobB, _ := q.Get(b.ID)
+	jobC, _ := q.Get(c.ID)
+	assert.Equal(t, StateDone, jobA.State)
+	assert.Equal(t, StatePending, jobB.State)
+	assert.Equal(t, StateDone, jobC.State)
+
+	assert.Equal(t, 1, q.Process(context.Background()))
+	jobB, _ = q.Get(b.ID)
+	assert.Equal(t, StateDone, jobB.State)
+}
+
+func TestProcess_RetriesWithBackoffAndBlocksPrinter(t *testing.T) {
+	path, r, clock := newFixture(t)
+	r.errs["kitchen"] = []error{Retryable(errors.New("offline")), Retryable(errors.New("offline"))}
+	q := openTestQueue(t, path, r, clock)
+
+	first, _ := q.Enqueue("kitchen", "", []byte(testDoc))
+	second, _ := q.Enqueue("kitchen", "", []byte(testDoc))
+
+	q.Process(context.Background())
+	job, _ := q.Get(first.ID)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Equal(t, clock.Now().Add(time.Second), job.NextAttempt)
+
+	// Still backing off: nothing is attempted and the second job waits
+	assert.Equal(t, 0, q.Process(context.Background()))
+
+	clock.Advance(time.Second)
+	q.Process(context.Background())
+	job, _ = q.Get(first.ID)
+	assert.Equal(t, 2, job.Attempts)
+	assert.Equal(t, clock.Now().Add(2*time.Second), job.NextAttempt, "delay doubles")
+
+	clock.Advance(2 * time.Second)
+	q.Process(context.Background())
+	job, _ = q.Get(first.ID)
+	assert.Equal(t, StateDone, job.State)
+
+	pending, _ := q.Get(second.ID)
+	assert.Equal(t, StatePending, pending.State)
+}
+
+func TestProcess_DeadLetters(t *testing.T) {
+	t.Run("permanent error", func(t *testing.T) {
+		path, r, clock := newFixture(t)
+		r.errs["receipt"] = []error{errors.New("invalid document")}
+		q := openTestQueue(t, path, r, clock)
+
+		job, _ := q.Enqueue("receipt", "", []byte(testDoc))
+		q.Process(context.Background())
+
+		dead := q.DeadLetters()
+		require.Len(t, dead, 1)
+		assert.Equal(t, job.ID, dead[0].ID)
+		assert.Equal(t, "invalid document", dead[0].LastError)
+
+		require.NoError(t, q.Retry(job.ID))
+		q.Process(context.Background())
+		job, _ = q.Get(job.ID)
+		assert.Equal(t, StateDone, job.State)
+	})
+
+	t.Run("attempts exhausted", func(t *testing.T) {
+		path, r, clock := newFixture(t)
+		offline := Retryable(errors.New("offline"))
+		r.errs["receipt"] = []error{offline, offline, offline}
+		q := openTestQueue(t, path, r, clock)
+
+		job, _ := q.Enqueue("receipt", "", []byte(testDoc))
+		for i := 0; i < 3; i++ {
+			q.Process(context.Background())
+			clock.Advance(time.Minute)
+		}
+
+		job, _ = q.Get(job.ID)
+		assert.Equal(t, StateDead, job.State)
+		assert.Equal(t, 3, job.Attempts)
+	})
+}
+
+func TestOpen_SurvivesRestart(t *testing.T) {
+	path, r, clock := newFixture(t)
+	q := openTestQueue(t, path, r, clock)
+
+	done, _ := q.Enqueue("receipt", "order-1", []byte(testDoc))
+	q.Process(context.Background())
+	pending, _ := q.Enqueue("receipt", "order-2", []byte(testDoc))
+	require.NoError(t, q.Close())
+
+	reopened := openTestQueue(t, path, r, clock)
+	job, err := reopened.Get(pending.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StatePending, job.State)
+
+	dup, err := reopened.Enqueue("receipt", "order-1", []byte(testDoc))
+	require.NoError(t, err)
+	assert.Equal(t, done.ID, dup.ID, "idempotency keys survive restart")
+	assert.Equal(t, StateDone, dup.State)
+}
+
+func TestCompact_DropsExpiredJobs(t *testing.T) {
+	path, r, clock := newFixture(t)
+	q, err := Open(path, r.dispatch, Options{Retention: time.Hour, Now: clock.Now})
+	require.NoError(t, err)
+	defer func() { _ = q.Close() }()
+
+	old, _ := q.Enqueue("receipt", "old", []byte(testDoc))
+	q.Process(context.Background())
+	clock.Advance(2 * time.Hour)
+	kept, _ := q.Enqueue("receipt", "new", []byte(testDoc))
+
+	require.NoError(t, q.Compact())
+
+	_, err = q.Get(old.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	_, err = q.Get(kept.ID)
+	assert.NoError(t, err)
+
+	jobs, err := q.store.Load()
+	require.NoError(t, err)
+	assert.Len(t, jobs, 1)
+}
+
+func TestOpen_CompactsExpiredJobs(t *testing.T) {
+	path, r, clock := newFixture(t)
+	opts := Options{Retention: time.Hour, Now: clock.Now}
+	q, err := Open(path, r.dispatch, opts)
+	require.NoError(t, err)
+
+	old, _ := q.Enqueue("receipt", "old", []byte(testDoc))
+	q.Process(context.Background())
+	require.NoError(t, q.Close())
+	clock.Advance(2 * time.Hour)
+
+	reopened, err := Open(path, r.dispatch, opts)
+	require.NoError(t, err)
+	defer func() { _ = reopened.Close() }()
+
+	_, err = reopened.Get(old.ID)
+	assert.ErrorIs(t, err, ErrJobNotFound)
+	jobs, err := reopened.store.Load()
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestRun_CompactsPeriodically(t *testing.T) {
+	path, r, clock := newFixture(t)
+	q, err := Open(path, r.dispatch, Options{
+		Retention:       time.Hour,
+		CompactInterval: 10 * time.Millisecond,
+		Now:             clock.Now,
+	})
+	require.NoError(t, err)
+	defer func() { _ = q.Close() }()
+
+	old, _ := q.Enqueue("receipt", "old", []byte(testDoc))
+	q.Process(context.Background())
+	clock.Advance(2 * time.Hour)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx, time.Hour)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, err := q.Get(old.ID)
+		return errors.Is(err, ErrJobNotFound)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestProcess_ConcurrentCallsDeliverOnce(t *testing.T) {
+	path, _, clock := newFixture(t)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var delivered []string
+	dispatch := func(_ context.Context, printer string, _ []byte) error {
+		mu.Lock()
+		delivered = append(delivered, printer)
+		first := len(delivered) == 1
+		mu.Unlock()
+		if first {
+			close(started)
+			<-release
+		}
+		return nil
+	}
+	q, err := Open(path, dispatch, Options{Now: clock.Now})
+	require.NoError(t, err)
+	defer func() { _ = q.Close() }()
+
+	first, _ := q.Enqueue("receipt", "", []byte(testDoc))
+	second, _ := q.Enqueue("receipt", "", []byte(testDoc))
+
+	done := make(chan int)
+	go func() { done <- q.Process(context.Background()) }()
+	<-started
+
+	// The first job is in flight: it is not delivered again, and the next
+	// job of the same printer waits behind it
+	assert.Equal(t, 0, q.Process(context.Background()))
+	close(release)
+	assert.Equal(t, 1, <-done)
+
+	job, _ := q.Get(first.ID)
+	assert.Equal(t, StateDone, job.State)
+	job, _ = q.Get(second.ID)
+	assert.Equal(t, StatePending, job.State)
+
+	assert.Equal(t, 1, q.Process(context.Background()))
+	assert.Equal(t, []string{"receipt", "receipt"}, delivered)
+}
+
+func TestCompact_RenameFailureKeepsQueue(t *testing.T) {
+	path, r, clock := newFixture(t)
+	q, err := Open(path, r.dispatch, Options{Retention: time.Hour, Now: clock.Now})
+	require.NoError(t, err)
+	defer func() { _ = q.Close() }()
+
+	old, _ := q.Enqueue("receipt", "old", []byte(testDoc))
+	q.Process(context.Background())
+	clock.Advance(2 * time.Hour)
+
+	rename = func(string, string) error { return errors.New("disk full") }
+	defer func() { rename = os.Rename }()
+
+	require.Error(t, q.Compact())
+
+	// Nothing was dropped from memory and the key still deduplicates
+	job, err := q.Get(old.ID)
+	require.NoError(t, err)
+	assert.Equal(t, StateDone, job.State)
+	dup, err := q.Enqueue("receipt", "old", []byte(testDoc))
+	require.NoError(t, err)
+	assert.Equal(t, old.ID, dup.ID)
+
+	// The original file is still open for appends
+	next, err := q.Enqueue("receipt", "next", []byte(testDoc))
+	require.NoError(t, err)
+	jobs, err := q.store.Load()
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, next.ID, jobs[1].ID)
+	_, err = os.Stat(path + ".tmp")
+	assert.True(t, os.IsNotExist(err), "temporary file is removed")
+}
+
+func TestExecutorDispatcher(t *testing.T) {
+	conn := connection.NewBufferConnector()
+	open := func(name string) (*service.Printer, error) {
+		if name != "receipt" {
+			return nil, errors.New("unknown printer")
+		}
+		conn.Reset()
+		return service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), conn)
+	}
+	dispatch := ExecutorDispatcher(open)
+
+	require.NoError(t, dispatch(context.Background(), "receipt", []byte(testDoc)))
+	assert.Contains(t, string(conn.Bytes()), "Hola")
+
+	err := dispatch(context.Background(), "missing", []byte(testDoc))
+	assert.True(t, IsRetryable(err), "connection errors are retryable")
+
+	err = dispatch(context.Background(), "receipt", []byte(`{"version":"1.0","commands":[]}`))
+	assert.Error(t, err)
+	assert.False(t, IsRetryable(err), "invalid documents are permanent")
+}
+
+func TestExecutorDispatcher_RefusesPartialJobs(t *testing.T) {
+	conn := connection.NewBufferConnector()
+	open := func(string) (*service.Printer, error) {
+		return service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), conn)
+	}
+
+	doc := `{"version":"1.0","profile":{"model":"Test"},"on_error":"skip","commands":[` +
+		`{"type":"text","data":{"content":{"text":"Hola"}}},{"type":"qr","data":{"data":""}}]}`
+	err := ExecutorDispatcher(open)(context.Background(), "receipt", []byte(doc))
+	assert.ErrorIs(t, err, executor.ErrPartialJob)
+	assert.False(t, IsRetryable(err), "partial jobs are permanent")
+	assert.Zero(t, conn.Len())
+}
@@ -0,0 +1,162 @@
+package queue
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// rename replaces the queue file when compacting; tests swap it to simulate
+// a failed rename
+var rename = os.Rename
+
+// FileStore persists job snapshots in an append-only JSON Lines file.
+//
+// Every state change appends the full job record and syncs the file, so the
+// last record of each job ID is its current state. Compact rewrites the file
+// with only the latest records.
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// OpenFileStore opens (or creates) the log file at path.
+func OpenFileStore(path string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("create queue directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600) //nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("open queue file: %w", err)
+	}
+
+	return &FileStore{path: path, file: file}, nil
+}
+
+// Load replays the log and returns the latest snapshot of every job in the
+// order the jobs were first recorded. A truncated last line, as left by a
+// crash mid-write, is ignored.
+func (s *FileStore) Load() ([]*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Seek(0, 0); err != nil {
+		return nil, fmt.Errorf("rewind queue file: %w", err)
+	}
+
+	latest := make(map[string]*Job)
+	var order []string
+
+	scanner := bufio.NewScanner(s.file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		var job Job
+		if err := json.Unmarshal(scanner.Bytes(), &job); err != nil || job.ID == "" {
+			log.Printf("queue: ignoring corrupt record at line %d of %s", line, s.path)
+			continue
+		}
+		if _, seen := latest[job.ID]; !seen {
+			order = append(order, job.ID)
+		}
+		latest[job.ID] = &job
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read queue file: %w", err)
+	}
+
+	jobs := make([]*Job, 0, len(order))
+	for _, id := range order {
+		jobs = append(jobs, latest[id])
+	}
+	return jobs, nil
+}
+
+// Append durably records a job snapshot.
+func (s *FileStore) Append(job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("encode job %s: %w", job.ID, err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return fmt.Errorf("append job %s: %w", job.ID, err)
+	}
+	return s.file.Sync()
+}
+
+// Compact atomically replaces the log with one record per job.
+func (s *FileStore) Compact(jobs []*Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tmpPath := s.path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("create compacted queue file: %w", err)
+	}
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, job := range jobs {
+		if err := enc.Encode(job); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("encode job %s: %w", job.ID, err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("write compacted queue file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("sync compacted queue file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("close compacted queue file: %w", err)
+	}
+
+	if err := s.file.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("close queue file: %w", err)
+	}
+	if err := rename(tmpPath, s.path); err != nil {
+		// Keep appending to the original log
+		_ = os.Remove(tmpPath)
+		file, rerr := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0o600) //nolint:gosec
+		if rerr != nil {
+			return fmt.Errorf("replace queue file: %w (reopen: %v)", err, rerr)
+		}
+		s.file = file
+		return fmt.Errorf("replace queue file: %w", err)
+	}
+
+	file, err := os.OpenFile(s.path, os.O_RDWR|os.O_APPEND, 0o600) //nolint:gosec
+	if err != nil {
+		return fmt.Errorf("reopen queue file: %w", err)
+	}
+	s.file = file
+	return nil
+}
+
+// Close closes the underlying file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
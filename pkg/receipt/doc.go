@@ -0,0 +1,36 @@
+// Package receipt models a sale ticket and lays it out as a print document.
+//
+// A Receipt holds a Header, line items with quantities, unit prices,
+// discounts, modifiers and taxes, payments and a Footer. Amounts are decimal
+// strings worked out with math/big: every line, discount and tax is rounded
+// to the currency decimals before it is added, so the printed amounts always
+// add up to the printed total. Receipt discounts are prorated across the
+// items by their net amount, so they lower the tax base too.
+//
+// # Quick Start
+//
+//	r := &receipt.Receipt{
+//	    Header: receipt.Header{BusinessName: "LA RAZÓN", Folio: "326"},
+//	    Items: []receipt.LineItem{
+//	        {Description: "Crayolas", Quantity: "2", UnitPrice: "30",
+//	            Taxes: []receipt.Tax{{Name: "IVA", Rate: "0.16"}}},
+//	    },
+//	    Payments: []receipt.Payment{{Method: "Efectivo", Amount: "100"}},
+//	}
+//
+//	totals, _ := r.Totals()             // Total "69.60", Change "30.40"
+//	doc, _ := r.Render(receipt.Compact58) // *schema.Document
+//
+// # Layouts
+//
+// A Layout chooses the printer, the sizes and which sections print.
+// Compact58 fits 58 mm paper with one line per item; Detailed80 adds contact
+// and customer data, SKUs, unit prices, serials and one line per tax rate.
+// Write adds the receipt to an existing builder.DocumentBuilder instead of
+// a new document.
+//
+// # POS Tickets
+//
+// ParseTicket reads the sale JSON the POS frontend sends (Spanish field
+// names, see assets/json/ticket_from_frontend.json) into a Receipt.
+package receipt
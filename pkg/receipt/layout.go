@@ -0,0 +1,165 @@
+package receipt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Layout decides what a receipt prints and how
+type Layout struct {
+	Name       string
+	Model      string // Printer profile of the rendered document
+	PaperWidth int    // Millimeters
+	Width      int    // Characters per line in font A
+	LogoWidth  int    // Pixels, 0 for the default width
+	QRSize     int    // Pixels, 0 for the default size
+	TitleSize  string // Size of the business name, e.g. "2x1"
+	TotalSize  string // Size of the total line
+	Rule       string // Separator character
+
+	ShowLegal     bool // Legal name, tax ID and tax regime
+	ShowContact   bool // Address, phone, email and store
+	ShowCustomer  bool // Customer name and tax ID
+	ShowSKU       bool // SKU line under each item
+	ShowUnitPrice bool // Unit price column
+	ShowSerials   bool // Serial numbers under each item
+	ShowTaxDetail bool // One line per tax and rate instead of the tax sums
+	ShowItemCount bool // Units sold
+
+	Labels Labels // Default: SpanishLabels
+}
+
+// Labels are the texts a layout prints
+type Labels struct {
+	Quantity    string
+	Description string
+	UnitPrice   string
+	Amount      string
+	SKU         string
+	Serials     string
+	Folio       string
+	Date        string
+	Cashier     string
+	Store       string
+	Customer    string
+	TaxID       string
+	Phone       string
+	Items       string
+	Subtotal    string
+	Discount    string
+	Taxes       string
+	Withheld    string
+	Total       string
+	Change      string
+	Balance     string
+	Void        string
+}
+
+// SpanishLabels are the labels of Mexican receipts
+var SpanishLabels = Labels{
+	Quantity:    "CANT",
+	Description: "DESCRIPCION",
+	UnitPrice:   "P.UNIT",
+	Amount:      "IMPORTE",
+	SKU:         "Clave",
+	Serials:     "Series",
+	Folio:       "Folio",
+	Date:        "Fecha",
+	Cashier:     "Atendió",
+	Store:       "Sucursal",
+	Customer:    "Cliente",
+	TaxID:       "RFC",
+	Phone:       "Tel.",
+	Items:       "Artículos",
+	Subtotal:    "Subtotal",
+	Discount:    "Descuento",
+	Taxes:       "Impuestos",
+	Withheld:    "Retención",
+	Total:       "TOTAL",
+	Change:      "Cambio",
+	Balance:     "Saldo",
+	Void:        "*** CANCELADO ***",
+}
+
+// EnglishLabels are labels for English receipts
+var EnglishLabels = Labels{
+	Quantity:    "QTY",
+	Description: "DESCRIPTION",
+	UnitPrice:   "PRICE",
+	Amount:      "AMOUNT",
+	SKU:         "SKU",
+	Serials:     "Serials",
+	Folio:       "Receipt",
+	Date:        "Date",
+	Cashier:     "Cashier",
+	Store:       "Store",
+	Customer:    "Customer",
+	TaxID:       "Tax ID",
+	Phone:       "Tel.",
+	Items:       "Items",
+	Subtotal:    "Subtotal",
+	Discount:    "Discount",
+	Taxes:       "Taxes",
+	Withheld:    "Withheld",
+	Total:       "TOTAL",
+	Change:      "Change",
+	Balance:     "Balance due",
+	Void:        "*** VOID ***",
+}
+
+// Compact58 fits 58 mm paper: business and legal data, one line per item
+// and the tax sums
+var Compact58 = Layout{
+	Name:       "compact",
+	Model:      "58mm PT-210",
+	PaperWidth: 58,
+	Width:      32,
+	LogoWidth:  256,
+	QRSize:     160,
+	Rule:       "-",
+	ShowLegal:  true,
+	Labels:     SpanishLabels,
+}
+
+// Detailed80 prints everything on 80 mm paper: legal and contact data,
+// customer, SKUs, unit prices, serials and each tax rate
+var Detailed80 = Layout{
+	Name:          "detailed",
+	Model:         "80mm EC-PM-80250",
+	PaperWidth:    80,
+	Width:         48,
+	LogoWidth:     384,
+	QRSize:        200,
+	TitleSize:     "2x1",
+	TotalSize:     "2x1",
+	Rule:          "=",
+	ShowLegal:     true,
+	ShowContact:   true,
+	ShowCustomer:  true,
+	ShowSKU:       true,
+	ShowUnitPrice: true,
+	ShowSerials:   true,
+	ShowTaxDetail: true,
+	ShowItemCount: true,
+	Labels:        SpanishLabels,
+}
+
+// LookupLayout returns a built-in layout by name ("compact" or "detailed")
+// or paper width ("58" or "80")
+func LookupLayout(name string) (Layout, error) {
+	switch strings.ToLower(name) {
+	case "compact", "58", "58mm":
+		return Compact58, nil
+	case "", "detailed", "80", "80mm":
+		return Detailed80, nil
+	}
+	return Layout{}, fmt.Errorf("unknown layout %q (use compact or detailed)", name)
+}
+
+// labels returns the layout labels, SpanishLabels when unset
+func (l Layout) labels() Labels {
+	if l.Labels == (Labels{}) {
+		return SpanishLabels
+	}
+	return l.Labels
+}
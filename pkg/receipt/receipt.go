@@ -0,0 +1,104 @@
+package receipt
+
+// Receipt is a sale ticket. Amounts are raw decimal strings such as "78" or
+// "96.774131113464"; they are never converted to floating point.
+type Receipt struct {
+	Header    Header
+	Items     []LineItem
+	Discounts []Discount // Discounts on the whole receipt, after the item discounts
+	Payments  []Payment
+	Footer    Footer
+	Locale    string // Currency locale, default numfmt.DefaultLocale
+	Void      bool   // Cancelled sale, printed with a banner
+}
+
+// Header identifies the business and the sale
+type Header struct {
+	Logo         string   // Base64 image
+	Title        string   // Line above the business name
+	BusinessName string   // Trade name
+	LegalName    string   // Legal (registered) name
+	TaxID        string   // e.g. RFC in Mexico
+	TaxRegime    string   // e.g. "601 General de Ley Personas Morales"
+	Address      []string // Address lines
+	Phone        string
+	Email        string
+	Store        string // Branch or store name
+	Series       string
+	Folio        string
+	Date         string // As it should be printed
+	Cashier      string
+	Customer     Customer
+}
+
+// Customer is the buyer of the sale
+type Customer struct {
+	Name       string
+	TaxID      string
+	PostalCode string
+	TaxRegime  string // Catalog code, e.g. "616"
+	CFDIUse    string // Catalog code, e.g. "G03"
+	Email      string
+}
+
+// LineItem is a product or service sold
+type LineItem struct {
+	SKU         string
+	Description string
+	Unit        string
+	ProductCode string // Catalog code of the product, e.g. SAT "27112309"
+	UnitCode    string // Catalog code of the unit, e.g. SAT "H87"
+	Quantity    string // Default: 1
+	UnitPrice   string // Before taxes
+	Discounts   []Discount
+	Modifiers   []Modifier
+	Serials     []string
+	Taxes       []Tax
+}
+
+// Discount is an amount off, or a percentage of the amount it applies to;
+// set one of Amount and Percent
+type Discount struct {
+	Label   string
+	Amount  string
+	Percent string // "10" is 10%
+}
+
+// Modifier is an option added to each unit of an item (e.g. "Extra cheese")
+type Modifier struct {
+	Name  string
+	Price string // Per unit of the item, "0" or empty when free
+}
+
+// TaxKind tells whether a tax is added to the total or withheld from it
+type TaxKind string
+
+// Tax kinds
+const (
+	Transferred TaxKind = "transferred" // Added to the total (default)
+	Withheld    TaxKind = "withheld"    // Withheld from the total
+)
+
+// Tax is a tax of a line item
+type Tax struct {
+	Name   string // e.g. "IVA"
+	Code   string // Catalog code, e.g. SAT "002"
+	Kind   TaxKind
+	Rate   string // Fraction, "0.16" is 16%
+	Base   string // Default: the item amount after discounts, receipt discounts prorated
+	Amount string // Default: Base × Rate
+}
+
+// Payment is an amount paid with one method
+type Payment struct {
+	Method    string // e.g. "Efectivo" or "Tarjeta"
+	Amount    string
+	Reference string // Card or transfer reference
+}
+
+// Footer closes the receipt
+type Footer struct {
+	Lines  []string // Centered lines, e.g. legends or return policy
+	QR     string   // QR data, e.g. a self-invoicing link
+	QRText string   // Line under the QR
+}
@@ -0,0 +1,233 @@
+package receipt_test
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/receipt"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// Naming Convention: Test{Function}_{Scenario}
+
+func sample() *receipt.Receipt {
+	return &receipt.Receipt{
+		Header: receipt.Header{BusinessName: "LA RAZÓN", TaxID: "EKU9003173C9", Series: "ABC1", Folio: "326"},
+		Items: []receipt.LineItem{
+			{
+				SKU: "HAM-01", Description: "Hamburguesa", Quantity: "2", UnitPrice: "85.50",
+				Modifiers: []receipt.Modifier{{Name: "Extra queso", Price: "12"}, {Name: "Sin cebolla"}},
+				Discounts: []receipt.Discount{{Label: "Promo", Percent: "10"}},
+				Taxes:     []receipt.Tax{{Name: "IVA", Rate: "0.16"}},
+			},
+			{
+				Description: "Agua destilada", Quantity: "1.5", UnitPrice: "68.965517241379",
+				Taxes: []receipt.Tax{
+					{Name: "IVA", Rate: "0.160000"},
+					{Name: "ISR", Kind: receipt.Withheld, Rate: "0.10", Base: "100", Amount: "10"},
+				},
+			},
+		},
+		Discounts: []receipt.Discount{{Label: "Cupón", Amount: "5"}},
+		Payments:  []receipt.Payment{{Method: "Efectivo", Amount: "400"}},
+		Footer:    receipt.Footer{Lines: []string{"¡GRACIAS POR SU COMPRA!"}, QR: "https://example.com/af"},
+	}
+}
+
+func TestReceipt_Totals(t *testing.T) {
+	got, err := sample().Totals()
+	if err != nil {
+		t.Fatalf("Totals error: %v", err)
+	}
+
+	// Hamburguesa: 171.00 + 24.00 + 0.00 = 195.00, less 19.50 = 175.50
+	// Agua: 1.5 × 68.965517241379 = 103.45
+	// Cupón 5.00 by net: 5 × 175.50 / 278.95 = 3.15 and 1.85
+	// IVA: 172.35 × 0.16 = 27.58 and 101.60 × 0.16 = 16.26; ISR withheld 10.00
+	// Total: 298.45 - 24.50 + 43.84 - 10.00 = 307.79
+	want := &receipt.Totals{
+		Items: "3.5", Subtotal: "298.45", Discount: "24.50",
+		Taxes: []receipt.TaxTotal{
+			{Name: "IVA", Kind: receipt.Transferred, Rate: "0.16", Base: "273.95", Amount: "43.84"},
+			{Name: "ISR", Kind: receipt.Withheld, Rate: "0.1", Base: "100.00", Amount: "10.00"},
+		},
+		Transferred: "43.84", Withheld: "10.00", Total: "307.79",
+		Paid: "400.00", Change: "92.21", Balance: "0.00",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Totals() = %+v; want %+v", got, want)
+	}
+}
+
+func TestReceipt_Totals_Balance(t *testing.T) {
+	r := &receipt.Receipt{
+		Items:    []receipt.LineItem{{Description: "Crayolas", UnitPrice: "0.125"}},
+		Payments: []receipt.Payment{{Method: "Tarjeta", Amount: "0.10"}},
+		Locale:   "en-US",
+	}
+	got, err := r.Totals()
+	if err != nil {
+		t.Fatalf("Totals error: %v", err)
+	}
+	if got.Total != "0.13" || got.Balance != "0.03" || got.Change != "0.00" || got.Items != "1" {
+		t.Errorf("Totals() = %+v; want total 0.13, balance 0.03", *got)
+	}
+}
+
+func TestReceipt_Totals_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		r    receipt.Receipt
+		want string
+	}{
+		{"missing price", receipt.Receipt{Items: []receipt.LineItem{{Description: "A"}}}, "item 0 (A): unit price is required"},
+		{"invalid price", receipt.Receipt{Items: []receipt.LineItem{{Description: "A", UnitPrice: "$5"}}}, `invalid unit price: invalid number "$5"`},
+		{"zero quantity", receipt.Receipt{Items: []receipt.LineItem{{SKU: "B", Quantity: "0", UnitPrice: "1"}}}, `item 0 (B): invalid quantity "0"`},
+		{"discount without amount", receipt.Receipt{Items: []receipt.LineItem{{UnitPrice: "1", Discounts: []receipt.Discount{{Label: "X"}}}}}, `discount "X" needs an amount or a percent`},
+		{"tax kind", receipt.Receipt{Items: []receipt.LineItem{{UnitPrice: "1", Taxes: []receipt.Tax{{Kind: "exempt"}}}}}, `unknown tax kind "exempt"`},
+		{"payment", receipt.Receipt{Payments: []receipt.Payment{{Method: "Efectivo"}}}, "payment 0 (Efectivo): payment amount is required"},
+		{"locale", receipt.Receipt{Locale: "xx"}, `unknown locale "xx"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.r.Totals(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Totals() error = %v; want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTicket(t *testing.T) {
+	data, err := os.ReadFile("../../assets/json/ticket_from_frontend.json")
+	if err != nil {
+		t.Fatalf("ReadFile error: %v", err)
+	}
+	r, err := receipt.ParseTicket(data)
+	if err != nil {
+		t.Fatalf("ParseTicket error: %v", err)
+	}
+
+	h := r.Header
+	if h.BusinessName != "LA RAZÓN" || h.TaxID != "EKU9003173C9" || h.Folio != "326" || h.Series != "ABC1" {
+		t.Errorf("Header = %+v", h)
+	}
+	if h.TaxRegime != "601 RÉGIMEN ACTIVIDAD EMPRESARIAL Y PROFESIONAL PERSONA FÍSICA" {
+		t.Errorf("TaxRegime = %q", h.TaxRegime)
+	}
+	if want := "MAZATLÁN, Sinaloa C.P. 82050"; len(h.Address) != 3 || h.Address[2] != want {
+		t.Errorf("Address = %q; want last line %q", h.Address, want)
+	}
+	if h.Customer.TaxID != "XAXX010101000" || h.Customer.CFDIUse != "G03" || h.Customer.TaxRegime != "616" {
+		t.Errorf("Customer = %+v", h.Customer)
+	}
+	if len(r.Items) != 5 || len(r.Items[0].Serials) != 3 || r.Items[0].UnitCode != "H87" {
+		t.Fatalf("Items = %+v", r.Items)
+	}
+	if tax := r.Items[2].Taxes[2]; tax.Name != "IVA" || tax.Kind != receipt.Withheld || tax.Amount != "10.320000" {
+		t.Errorf("Items[2].Taxes[2] = %+v", tax)
+	}
+	if len(r.Payments) != 1 || r.Payments[0].Amount != "234" || r.Footer.QR == "" || len(r.Footer.Lines) != 2 {
+		t.Errorf("Payments = %+v, Footer = %+v", r.Payments, r.Footer)
+	}
+
+	if _, err := r.Totals(); err != nil {
+		t.Errorf("Totals error: %v", err)
+	}
+}
+
+func TestParseTicket_Unwrapped(t *testing.T) {
+	r, err := receipt.ParseTicket([]byte(`{"folio": "7", "descuento": "10.5", "anulada": "1",
+		"conceptos": [{"descripcion": "A", "cantidad": "1", "precio_venta": "20"}]}`))
+	if err != nil {
+		t.Fatalf("ParseTicket error: %v", err)
+	}
+	if r.Header.Folio != "7" || !r.Void || len(r.Discounts) != 1 || r.Discounts[0].Amount != "10.5" {
+		t.Errorf("ParseTicket() = %+v", r)
+	}
+
+	if _, err := receipt.ParseTicket([]byte(`[`)); err == nil {
+		t.Error("ParseTicket([) error = nil; want error")
+	}
+}
+
+func TestReceipt_Render(t *testing.T) {
+	tests := []struct {
+		layout   receipt.Layout
+		printer  *profile.Escpos
+		want     []string
+		wantNone []string
+	}{
+		{
+			receipt.Compact58, profile.CreateProfile58mm(),
+			[]string{"ABC1-326", "Hamburguesa", "+ Extra queso", "- Promo 10%", "-$19.50", "-$5.00", "Impuestos", "-$10.00", "$307.79", "Cambio", "$92.21"},
+			[]string{"HAM-01", "P.UNIT", "IVA 16%"},
+		},
+		{
+			receipt.Detailed80, profile.CreateProfile80mm(),
+			[]string{"Clave: HAM-01", "P.UNIT", "$85.50", "$68.97", "IVA 16%", "ISR 10%", "-$10.00", "3.5", "$307.79"},
+			[]string{"Impuestos"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout.Name, func(t *testing.T) {
+			doc, err := sample().Render(tt.layout)
+			if err != nil {
+				t.Fatalf("Render error: %v", err)
+			}
+			if err := doc.Validate(); err != nil {
+				t.Fatalf("Validate error: %v", err)
+			}
+			if doc.Profile.PaperWidth != tt.layout.PaperWidth || doc.Commands[len(doc.Commands)-1].Type != "cut" {
+				t.Errorf("Profile = %+v, last command %q; want paper %d and a cut", doc.Profile, doc.Commands[len(doc.Commands)-1].Type, tt.layout.PaperWidth)
+			}
+
+			conn := connection.NewBufferConnector()
+			printer, err := service.NewPrinter(composer.NewEscpos(), tt.printer, conn)
+			if err != nil {
+				t.Fatalf("NewPrinter error: %v", err)
+			}
+			report, err := executor.NewExecutor(printer).Execute(doc)
+			if err != nil {
+				t.Fatalf("Execute error: %v (%+v)", err, report)
+			}
+			// Accented labels are encoded for the printer, so only ASCII is checked
+			out := string(conn.Bytes())
+			for _, s := range tt.want {
+				if !strings.Contains(out, s) {
+					t.Errorf("output does not contain %q", s)
+				}
+			}
+			for _, s := range tt.wantNone {
+				if strings.Contains(out, s) {
+					t.Errorf("output contains %q", s)
+				}
+			}
+		})
+	}
+}
+
+func TestReceipt_Render_Errors(t *testing.T) {
+	r := &receipt.Receipt{Items: []receipt.LineItem{{Description: "A", UnitPrice: "x"}}}
+	if _, err := r.Render(receipt.Compact58); err == nil {
+		t.Error("Render() error = nil; want invalid unit price")
+	}
+}
+
+func TestLookupLayout(t *testing.T) {
+	for name, want := range map[string]string{"58": "compact", "Compact": "compact", "80mm": "detailed", "": "detailed"} {
+		if got, err := receipt.LookupLayout(name); err != nil || got.Name != want {
+			t.Errorf("LookupLayout(%q) = %q, %v; want %q", name, got.Name, err, want)
+		}
+	}
+	if _, err := receipt.LookupLayout("wide"); err == nil || !strings.Contains(err.Error(), "use compact or detailed") {
+		t.Errorf("LookupLayout(\"wide\") error = %v", err)
+	}
+}
@@ -0,0 +1,287 @@
+package receipt
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/document/builder"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/numfmt"
+)
+
+// Render lays the receipt out in a new document for the layout printer,
+// ending with a cut
+func (r *Receipt) Render(layout Layout) (*schema.Document, error) {
+	b := builder.NewDocument().SetProfile(layout.Model, layout.PaperWidth, "")
+	if err := r.Write(b, layout); err != nil {
+		return nil, err
+	}
+	return b.Cut().Build(), nil
+}
+
+// Write adds the receipt commands to b, so a receipt can be part of a
+// larger document
+func (r *Receipt) Write(b *builder.DocumentBuilder, layout Layout) error {
+	c, err := r.compute()
+	if err != nil {
+		return err
+	}
+	w := &writer{b: b, layout: layout, labels: layout.labels(), receipt: r, computed: c,
+		currency: numfmt.Spec{Kind: numfmt.Currency, Locale: r.Locale}}
+	w.header()
+	w.items()
+	w.totals()
+	w.footer()
+	return nil
+}
+
+// writer adds the sections of a receipt to a document
+type writer struct {
+	b        *builder.DocumentBuilder
+	layout   Layout
+	labels   Labels
+	receipt  *Receipt
+	computed *computed
+	currency numfmt.Spec
+}
+
+func (w *writer) rule() {
+	char := w.layout.Rule
+	if char == "" {
+		char = "-"
+	}
+	w.b.SeparatorWithLength(char, w.layout.Width)
+}
+
+// center prints a centered line when text is not empty
+func (w *writer) center(text string) {
+	if text != "" {
+		w.b.Text(text).Center().End()
+	}
+}
+
+// kv prints a key/value line when value is not empty
+func (w *writer) kv(key, value string) {
+	if value != "" {
+		w.b.KV(key, value).End()
+	}
+}
+
+func (w *writer) header() {
+	h, l := w.receipt.Header, w.labels
+	if h.Logo != "" {
+		img := w.b.Image(h.Logo).Center()
+		if w.layout.LogoWidth > 0 {
+			img.Width(w.layout.LogoWidth)
+		}
+		img.End()
+	}
+	w.center(h.Title)
+	if h.BusinessName != "" {
+		name := w.b.Text(h.BusinessName).Bold().Center()
+		if w.layout.TitleSize != "" {
+			name.Size(w.layout.TitleSize)
+		}
+		name.End()
+	}
+	if w.layout.ShowLegal {
+		w.center(h.LegalName)
+		if h.TaxID != "" {
+			w.center(l.TaxID + ": " + h.TaxID)
+		}
+		w.center(h.TaxRegime)
+	}
+	if w.layout.ShowContact {
+		for _, line := range h.Address {
+			w.center(line)
+		}
+		if h.Phone != "" {
+			w.center(l.Phone + " " + h.Phone)
+		}
+		w.center(h.Email)
+		if h.Store != "" {
+			w.center(l.Store + ": " + h.Store)
+		}
+	}
+	w.rule()
+
+	if w.receipt.Void {
+		w.b.Text(l.Void).Bold().Size("2x1").Center().End()
+	}
+	folio := h.Folio
+	if h.Series != "" && folio != "" {
+		folio = h.Series + "-" + folio
+	}
+	w.kv(l.Folio, folio)
+	w.kv(l.Date, h.Date)
+	w.kv(l.Cashier, h.Cashier)
+	if w.layout.ShowCustomer {
+		w.kv(l.Customer, h.Customer.Name)
+		w.kv(l.TaxID, h.Customer.TaxID)
+	}
+	w.rule()
+}
+
+func (w *writer) items() {
+	l := w.labels
+	quantity := numfmt.Spec{Kind: numfmt.Number, Locale: w.receipt.Locale}
+
+	t := w.b.Table().
+		RelativeColumn(l.Quantity, "auto", constants.Right).ColumnFormat(quantity).
+		RelativeColumn(l.Description, "*", constants.Left)
+	if w.layout.ShowUnitPrice {
+		t.RelativeColumn(l.UnitPrice, "auto", constants.Right).ColumnFormat(w.currency)
+	}
+	t.RelativeColumn(l.Amount, "auto", constants.Right).ColumnFormat(w.currency).
+		HeaderRule("-")
+
+	// row adds a row of the description and amount columns
+	row := func(qty, description, price, amount string) {
+		cells := []string{qty, description}
+		if w.layout.ShowUnitPrice {
+			cells = append(cells, price)
+		}
+		t.Row(append(cells, amount)...)
+	}
+	// note adds a line under an item, across all but the quantity column
+	note := func(text string) {
+		columns := 3
+		if w.layout.ShowUnitPrice {
+			columns = 4
+		}
+		t.Row("", text).RowSpans(1, columns-1)
+	}
+
+	d := w.computed.decimals
+	for i, item := range w.receipt.Items {
+		line := w.computed.lines[i]
+		row(line.quantity.FloatString(line.qtyDigits), item.Description, unitPrice(item.UnitPrice, d), line.amount.FloatString(d))
+		if w.layout.ShowSKU && item.SKU != "" {
+			note(l.SKU + ": " + item.SKU)
+		}
+		for k, m := range item.Modifiers {
+			price := ""
+			if line.modifiers[k].Sign() != 0 {
+				price = line.modifiers[k].FloatString(d)
+			}
+			row("", "+ "+m.Name, "", price)
+		}
+		for k, x := range item.Discounts {
+			row("", "- "+discountLabel(x, l), "", negate(line.discounts[k]).FloatString(d))
+		}
+		if w.layout.ShowSerials && len(item.Serials) > 0 {
+			note(l.Serials + ": " + strings.Join(item.Serials, ", "))
+		}
+	}
+	t.End()
+	w.rule()
+}
+
+func (w *writer) totals() {
+	l, tt, d := w.labels, w.computed.totals, w.computed.decimals
+	amount := func(key, value string) {
+		w.b.KV(key, value).Format(w.currency).End()
+	}
+
+	if w.layout.ShowItemCount {
+		w.kv(l.Items, tt.Items)
+	}
+	amount(l.Subtotal, tt.Subtotal)
+	for i, x := range w.receipt.Discounts {
+		amount(discountLabel(x, l), negate(w.computed.receipt[i]).FloatString(d))
+	}
+	if w.layout.ShowTaxDetail {
+		for _, tax := range tt.Taxes {
+			name := strings.TrimSpace(tax.Name + " " + percent(tax.Rate))
+			if tax.Kind == Withheld {
+				amount(l.Withheld+" "+name, "-"+tax.Amount)
+			} else {
+				amount(name, tax.Amount)
+			}
+		}
+	} else {
+		if !isZero(tt.Transferred) {
+			amount(l.Taxes, tt.Transferred)
+		}
+		if !isZero(tt.Withheld) {
+			amount(l.Withheld, "-"+tt.Withheld)
+		}
+	}
+
+	total := w.b.KV(l.Total, tt.Total).Format(w.currency).Bold()
+	if w.layout.TotalSize != "" {
+		total.Size(w.layout.TotalSize)
+	}
+	total.End()
+
+	for _, p := range w.receipt.Payments {
+		amount(strings.TrimSpace(p.Method+" "+p.Reference), p.Amount)
+	}
+	if !isZero(tt.Change) {
+		amount(l.Change, tt.Change)
+	}
+	if len(w.receipt.Payments) > 0 && !isZero(tt.Balance) {
+		amount(l.Balance, tt.Balance)
+	}
+}
+
+func (w *writer) footer() {
+	f := w.receipt.Footer
+	if len(f.Lines) == 0 && f.QR == "" {
+		return
+	}
+	w.rule()
+	for _, line := range f.Lines {
+		w.center(line)
+	}
+	if f.QR != "" {
+		qr := w.b.QR(f.QR).Center()
+		if w.layout.QRSize > 0 {
+			qr.Size(w.layout.QRSize)
+		}
+		if f.QRText != "" {
+			qr.WithText(f.QRText)
+		}
+		qr.End()
+	}
+}
+
+// discountLabel names a discount, with its percent if it has one
+func discountLabel(x Discount, l Labels) string {
+	label := x.Label
+	if label == "" {
+		label = l.Discount
+	}
+	if x.Percent != "" {
+		label += " " + x.Percent + "%"
+	}
+	return label
+}
+
+// unitPrice rounds a unit price for printing; prices with more decimals
+// (e.g. "96.774131113464") print as the currency rounds them
+func unitPrice(price string, decimals int) string {
+	n, _, err := numfmt.Parse(price)
+	if err != nil {
+		return price
+	}
+	return n.FloatString(decimals)
+}
+
+// percent writes a rate such as "0.16" as "16%"
+func percent(rate string) string {
+	n, _, err := numfmt.Parse(rate)
+	if err != nil {
+		return ""
+	}
+	return rateString(n.Mul(n, big.NewRat(100, 1))) + "%"
+}
+
+func negate(x *big.Rat) *big.Rat {
+	return new(big.Rat).Neg(x)
+}
+
+func isZero(amount string) bool {
+	return strings.Trim(amount, "0.") == ""
+}
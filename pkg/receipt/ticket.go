@@ -0,0 +1,199 @@
+package receipt
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ticket is the sale JSON the POS frontend sends (see
+// assets/json/ticket_from_frontend.json). Amounts are strings.
+type ticket struct {
+	Vendedor string `json:"vendedor"`
+
+	Cliente              string `json:"cliente"`
+	ClienteRFC           string `json:"cliente_rfc"`
+	ClienteCP            string `json:"cliente_cp"`
+	ClienteUsoCFDI       string `json:"cliente_uso_cfdi"`
+	ClienteRegimenFiscal string `json:"cliente_regimen_fiscal"`
+	ClienteEmails        string `json:"cliente_emails"`
+
+	SucursalRFC             string `json:"sucursal_rfc"`
+	SucursalNombre          string `json:"sucursal_nombre"`
+	SucursalCP              string `json:"sucursal_cp"`
+	SucursalRegimenClave    string `json:"sucursal_regimen_clave"`
+	SucursalRegimen         string `json:"sucursal_regimen"`
+	SucursalNombreComercial string `json:"sucursal_nombre_comercial"`
+	SucursalTienda          string `json:"sucursal_tienda"`
+	SucursalEmail           string `json:"sucursal_email"`
+	SucursalTelefono        string `json:"sucursal_telefono"`
+	SucursalCalle           string `json:"sucursal_calle"`
+	SucursalNumero          string `json:"sucursal_numero"`
+	SucursalNumeroInt       string `json:"sucursal_numero_int"`
+	SucursalColonia         string `json:"sucursal_colonia"`
+	SucursalEstado          string `json:"sucursal_estado"`
+	SucursalLocalidad       string `json:"sucursal_localidad"`
+	SucursalMunicipio       string `json:"sucursal_municipio"`
+	SucursalLeyenda1        string `json:"sucursal_leyenda_1"`
+	SucursalLeyenda2        string `json:"sucursal_leyenda_2"`
+
+	Comentario        string `json:"comentario"`
+	DescuentoMotivo   string `json:"descuento_motivo"`
+	Descuento         string `json:"descuento"`
+	Anulada           string `json:"anulada"`
+	FechaSistema      string `json:"fecha_sistema"`
+	Folio             string `json:"folio"`
+	Serie             string `json:"serie"`
+	AutofacturaLinkQR string `json:"autofactura_link_qr"`
+
+	Conceptos []ticketConcepto `json:"conceptos"`
+	Pago      []ticketPago     `json:"pago"`
+}
+
+type ticketConcepto struct {
+	Clave                 string           `json:"clave"`
+	Descripcion           string           `json:"descripcion"`
+	PrecioVenta           string           `json:"precio_venta"`
+	Cantidad              string           `json:"cantidad"`
+	Unidad                string           `json:"unidad"`
+	ClaveProductoServicio string           `json:"clave_producto_servicio"`
+	ClaveUnidadSAT        string           `json:"clave_unidad_sat"`
+	Series                []string         `json:"series"`
+	Impuestos             []ticketImpuesto `json:"impuestos"`
+}
+
+type ticketImpuesto struct {
+	Base      string `json:"base"`
+	Importe   string `json:"importe"`
+	Impuestos string `json:"impuestos"` // SAT tax code
+	Tasa      string `json:"tasa"`
+	Tipo      string `json:"tipo"` // T: traslado, R: retención
+}
+
+type ticketPago struct {
+	FormaPago string `json:"forma_pago"`
+	Cantidad  string `json:"cantidad"`
+}
+
+// taxNames are the SAT tax codes
+var taxNames = map[string]string{
+	"001": "ISR",
+	"002": "IVA",
+	"003": "IEPS",
+}
+
+// ParseTicket reads the sale JSON of the POS frontend, either the object
+// itself or wrapped in {"data": ...}
+func ParseTicket(data []byte) (*Receipt, error) {
+	var wrapped struct {
+		Data *ticket `json:"data"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		return nil, fmt.Errorf("invalid ticket JSON: %w", err)
+	}
+	t := wrapped.Data
+	if t == nil {
+		t = &ticket{}
+		if err := json.Unmarshal(data, t); err != nil {
+			return nil, fmt.Errorf("invalid ticket JSON: %w", err)
+		}
+	}
+	return t.receipt(), nil
+}
+
+func (t *ticket) receipt() *Receipt {
+	r := &Receipt{
+		Header: Header{
+			BusinessName: t.SucursalNombreComercial,
+			LegalName:    t.SucursalNombre,
+			TaxID:        t.SucursalRFC,
+			TaxRegime:    join(" ", t.SucursalRegimenClave, t.SucursalRegimen),
+			Address:      t.address(),
+			Phone:        t.SucursalTelefono,
+			Email:        t.SucursalEmail,
+			Store:        t.SucursalTienda,
+			Series:       t.Serie,
+			Folio:        t.Folio,
+			Date:         t.FechaSistema,
+			Cashier:      t.Vendedor,
+			Customer: Customer{
+				Name:       t.Cliente,
+				TaxID:      t.ClienteRFC,
+				PostalCode: t.ClienteCP,
+				TaxRegime:  t.ClienteRegimenFiscal,
+				CFDIUse:    t.ClienteUsoCFDI,
+				Email:      t.ClienteEmails,
+			},
+		},
+		Void: t.Anulada == "1",
+		Footer: Footer{
+			Lines: nonEmpty(t.Comentario, t.SucursalLeyenda1, t.SucursalLeyenda2),
+			QR:    t.AutofacturaLinkQR,
+		},
+	}
+
+	for _, c := range t.Conceptos {
+		item := LineItem{
+			SKU:         c.Clave,
+			Description: c.Descripcion,
+			Unit:        c.Unidad,
+			ProductCode: c.ClaveProductoServicio,
+			UnitCode:    c.ClaveUnidadSAT,
+			Quantity:    c.Cantidad,
+			UnitPrice:   c.PrecioVenta,
+			Serials:     c.Series,
+		}
+		for _, tax := range c.Impuestos {
+			kind := Transferred
+			if strings.EqualFold(tax.Tipo, "R") {
+				kind = Withheld
+			}
+			item.Taxes = append(item.Taxes, Tax{
+				Name:   taxNames[tax.Impuestos],
+				Code:   tax.Impuestos,
+				Kind:   kind,
+				Rate:   tax.Tasa,
+				Base:   tax.Base,
+				Amount: tax.Importe,
+			})
+		}
+		r.Items = append(r.Items, item)
+	}
+
+	if !isZero(strings.TrimSpace(t.Descuento)) {
+		r.Discounts = []Discount{{Label: t.DescuentoMotivo, Amount: t.Descuento}}
+	}
+	for _, p := range t.Pago {
+		r.Payments = append(r.Payments, Payment{Method: p.FormaPago, Amount: p.Cantidad})
+	}
+	return r
+}
+
+// address writes the branch address as street, neighborhood and city lines
+func (t *ticket) address() []string {
+	number := t.SucursalNumero
+	if t.SucursalNumeroInt != "" {
+		number = join(" ", number, "Int. "+t.SucursalNumeroInt)
+	}
+	city := join(", ", t.SucursalLocalidad, t.SucursalMunicipio, t.SucursalEstado)
+	if t.SucursalCP != "" {
+		city = join(" ", city, "C.P. "+t.SucursalCP)
+	}
+	return nonEmpty(join(" ", t.SucursalCalle, number), t.SucursalColonia, city)
+}
+
+// join joins the non-empty parts with sep
+func join(sep string, parts ...string) string {
+	return strings.Join(nonEmpty(parts...), sep)
+}
+
+// nonEmpty returns the parts that are not blank
+func nonEmpty(parts ...string) []string {
+	var out []string
+	for _, p := range parts {
+		if strings.TrimSpace(p) != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,339 @@
+package receipt
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/numfmt"
+)
+
+// TaxTotal is the sum of the taxes with the same name, kind and rate
+type TaxTotal struct {
+	Name   string
+	Kind   TaxKind
+	Rate   string
+	Base   string
+	Amount string
+}
+
+// Totals are the amounts of a receipt as raw decimal strings, rounded to
+// the decimals of the currency. They add up as printed: Total is Subtotal
+// less Discount plus Transferred less Withheld.
+type Totals struct {
+	Items       string     // Units sold
+	Subtotal    string     // Items and modifiers before discounts
+	Discount    string     // Item and receipt discounts
+	Taxes       []TaxTotal // By name, kind and rate, in order of appearance
+	Transferred string     // Taxes added to the total
+	Withheld    string     // Taxes withheld from the total
+	Total       string
+	Paid        string
+	Change      string // Paid over the total
+	Balance     string // Total not yet paid
+}
+
+// line holds the rounded amounts of a line item
+type line struct {
+	quantity  *big.Rat
+	qtyDigits int        // Decimals the quantity is written with
+	amount    *big.Rat   // Quantity × unit price
+	modifiers []*big.Rat // Quantity × modifier price
+	discounts []*big.Rat
+	net       *big.Rat // Amount and modifiers less discounts
+}
+
+// computed is a receipt with its amounts worked out
+type computed struct {
+	lines    []line
+	receipt  []*big.Rat // Receipt discounts
+	totals   *Totals
+	decimals int
+}
+
+// Totals works out the amounts of the receipt. Every amount is rounded to
+// the currency decimals, half away from zero, before it is added.
+func (r *Receipt) Totals() (*Totals, error) {
+	c, err := r.compute()
+	if err != nil {
+		return nil, err
+	}
+	return c.totals, nil
+}
+
+func (r *Receipt) compute() (*computed, error) {
+	loc, err := numfmt.LookupLocale(r.Locale)
+	if err != nil {
+		return nil, err
+	}
+	c := &computed{decimals: loc.Decimals, totals: &Totals{}}
+	d := c.decimals
+
+	items, subtotal, discount := new(big.Rat), new(big.Rat), new(big.Rat)
+	transferred, withheld := new(big.Rat), new(big.Rat)
+	quantityDecimals := 0
+	taxes := map[string]int{}
+	var taxBases, taxAmounts []*big.Rat
+
+	for i, item := range r.Items {
+		l, err := item.compute(d)
+		if err != nil {
+			return nil, fmt.Errorf("item %d (%s): %w", i, item.name(), err)
+		}
+		quantityDecimals = max(quantityDecimals, l.qtyDigits)
+		items.Add(items, l.quantity)
+		subtotal.Add(subtotal, l.amount)
+		for _, m := range l.modifiers {
+			subtotal.Add(subtotal, m)
+		}
+		for _, x := range l.discounts {
+			discount.Add(discount, x)
+		}
+		c.lines = append(c.lines, l)
+	}
+
+	// Receipt discounts apply to what is left after the item discounts
+	net := new(big.Rat).Sub(subtotal, discount)
+	receiptDiscount := new(big.Rat)
+	for i, x := range r.Discounts {
+		amount, err := x.compute(net, d)
+		if err != nil {
+			return nil, fmt.Errorf("discount %d: %w", i, err)
+		}
+		c.receipt = append(c.receipt, amount)
+		receiptDiscount.Add(receiptDiscount, amount)
+	}
+	discount.Add(discount, receiptDiscount)
+
+	// and lower the tax base of each item in proportion to its net
+	shares := c.prorate(receiptDiscount)
+	for i, item := range r.Items {
+		wrap := func(err error) error { return fmt.Errorf("item %d (%s): %w", i, item.name(), err) }
+		net := new(big.Rat).Sub(c.lines[i].net, shares[i])
+
+		for _, tax := range item.Taxes {
+			kind, err := ParseTaxKind(string(tax.Kind))
+			if err != nil {
+				return nil, wrap(err)
+			}
+			rate, err := number(tax.Rate, "tax rate", "0")
+			if err != nil {
+				return nil, wrap(err)
+			}
+			base := net
+			if tax.Base != "" {
+				if base, err = number(tax.Base, "tax base", ""); err != nil {
+					return nil, wrap(err)
+				}
+				base = round(base, d)
+			}
+			amount := round(new(big.Rat).Mul(base, rate), d)
+			if tax.Amount != "" {
+				if amount, err = number(tax.Amount, "tax amount", ""); err != nil {
+					return nil, wrap(err)
+				}
+				amount = round(amount, d)
+			}
+
+			if kind == Withheld {
+				withheld.Add(withheld, amount)
+			} else {
+				transferred.Add(transferred, amount)
+			}
+			key := tax.Name + "\x00" + string(kind) + "\x00" + rate.RatString()
+			k, ok := taxes[key]
+			if !ok {
+				k = len(c.totals.Taxes)
+				taxes[key] = k
+				c.totals.Taxes = append(c.totals.Taxes, TaxTotal{Name: tax.Name, Kind: kind, Rate: rateString(rate)})
+				taxBases = append(taxBases, new(big.Rat))
+				taxAmounts = append(taxAmounts, new(big.Rat))
+			}
+			taxBases[k].Add(taxBases[k], base)
+			taxAmounts[k].Add(taxAmounts[k], amount)
+		}
+	}
+
+	total := new(big.Rat).Sub(subtotal, discount)
+	total.Add(total, transferred)
+	total.Sub(total, withheld)
+
+	paid := new(big.Rat)
+	for i, p := range r.Payments {
+		amount, err := number(p.Amount, "payment amount", "")
+		if err != nil {
+			return nil, fmt.Errorf("payment %d (%s): %w", i, p.Method, err)
+		}
+		paid.Add(paid, round(amount, d))
+	}
+	change, balance := new(big.Rat), new(big.Rat)
+	if diff := new(big.Rat).Sub(paid, total); diff.Sign() > 0 {
+		change = diff
+	} else {
+		balance = diff.Neg(diff)
+	}
+
+	for k := range c.totals.Taxes {
+		c.totals.Taxes[k].Base = taxBases[k].FloatString(d)
+		c.totals.Taxes[k].Amount = taxAmounts[k].FloatString(d)
+	}
+	c.totals.Items = items.FloatString(quantityDecimals)
+	c.totals.Subtotal = subtotal.FloatString(d)
+	c.totals.Discount = discount.FloatString(d)
+	c.totals.Transferred = transferred.FloatString(d)
+	c.totals.Withheld = withheld.FloatString(d)
+	c.totals.Total = total.FloatString(d)
+	c.totals.Paid = paid.FloatString(d)
+	c.totals.Change = change.FloatString(d)
+	c.totals.Balance = balance.FloatString(d)
+	return c, nil
+}
+
+// prorate splits a receipt discount across the lines in proportion to
+// their net amounts. Each share is rounded and the last line with a net
+// amount takes the remainder, so the shares add up to the discount.
+func (c *computed) prorate(discount *big.Rat) []*big.Rat {
+	shares := make([]*big.Rat, len(c.lines))
+	sum := new(big.Rat)
+	last := -1
+	for i, l := range c.lines {
+		shares[i] = new(big.Rat)
+		if l.net.Sign() > 0 {
+			sum.Add(sum, l.net)
+			last = i
+		}
+	}
+	if discount.Sign() == 0 || last < 0 {
+		return shares
+	}
+
+	left := new(big.Rat).Set(discount)
+	for i, l := range c.lines[:last] {
+		if l.net.Sign() <= 0 {
+			continue
+		}
+		share := new(big.Rat).Mul(discount, l.net)
+		shares[i] = round(share.Quo(share, sum), c.decimals)
+		left.Sub(left, shares[i])
+	}
+	shares[last] = left
+	return shares
+}
+
+// name identifies an item in errors
+func (item LineItem) name() string {
+	if item.Description == "" {
+		return item.SKU
+	}
+	return item.Description
+}
+
+// compute works out the amounts of an item
+func (item LineItem) compute(decimals int) (line, error) {
+	quantity, err := number(item.Quantity, "quantity", "1")
+	if err != nil {
+		return line{}, err
+	}
+	if quantity.Sign() <= 0 {
+		return line{}, fmt.Errorf("invalid quantity %q (must be > 0)", item.Quantity)
+	}
+	_, digits, _ := numfmt.Parse(defaultString(item.Quantity, "1"))
+
+	price, err := number(item.UnitPrice, "unit price", "")
+	if err != nil {
+		return line{}, err
+	}
+	l := line{
+		quantity:  quantity,
+		qtyDigits: digits,
+		amount:    round(new(big.Rat).Mul(quantity, price), decimals),
+	}
+	gross := new(big.Rat).Set(l.amount)
+	for _, m := range item.Modifiers {
+		price, err := number(m.Price, "modifier price", "0")
+		if err != nil {
+			return line{}, fmt.Errorf("modifier %s: %w", m.Name, err)
+		}
+		amount := round(new(big.Rat).Mul(quantity, price), decimals)
+		l.modifiers = append(l.modifiers, amount)
+		gross.Add(gross, amount)
+	}
+
+	l.net = new(big.Rat).Set(gross)
+	for _, x := range item.Discounts {
+		amount, err := x.compute(gross, decimals)
+		if err != nil {
+			return line{}, err
+		}
+		l.discounts = append(l.discounts, amount)
+		l.net.Sub(l.net, amount)
+	}
+	return l, nil
+}
+
+// compute returns the amount of a discount on base
+func (x Discount) compute(base *big.Rat, decimals int) (*big.Rat, error) {
+	if (x.Amount == "") == (x.Percent == "") {
+		return nil, fmt.Errorf("discount %q needs an amount or a percent", x.Label)
+	}
+	if x.Percent != "" {
+		percent, err := number(x.Percent, "discount percent", "")
+		if err != nil {
+			return nil, err
+		}
+		amount := new(big.Rat).Mul(base, percent)
+		return round(amount.Quo(amount, big.NewRat(100, 1)), decimals), nil
+	}
+	amount, err := number(x.Amount, "discount amount", "")
+	if err != nil {
+		return nil, err
+	}
+	return round(amount, decimals), nil
+}
+
+// ParseTaxKind parses a tax kind
+func ParseTaxKind(s string) (TaxKind, error) {
+	switch k := TaxKind(strings.ToLower(s)); k {
+	case "", Transferred:
+		return Transferred, nil
+	case Withheld:
+		return k, nil
+	}
+	return Transferred, fmt.Errorf("unknown tax kind %q (use transferred or withheld)", s)
+}
+
+// number parses a raw decimal; an empty value is def, or an error when def
+// is empty too
+func number(value, field, def string) (*big.Rat, error) {
+	if strings.TrimSpace(value) == "" {
+		if def == "" {
+			return nil, fmt.Errorf("%s is required", field)
+		}
+		value = def
+	}
+	n, _, err := numfmt.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", field, err)
+	}
+	return n, nil
+}
+
+// round rounds x to decimals, half away from zero
+func round(x *big.Rat, decimals int) *big.Rat {
+	r, _ := new(big.Rat).SetString(x.FloatString(decimals))
+	return r
+}
+
+// rateString writes a tax rate without trailing zeros, e.g. "0.16"
+func rateString(rate *big.Rat) string {
+	s := rate.FloatString(numfmt.MaxDecimals)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
+
+func defaultString(s, def string) string {
+	if strings.TrimSpace(s) == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,352 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/queue"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// Message types sent by clients
+const (
+	MsgPrint  = "print"  // Data: document. Prints immediately with progress events
+	MsgStatus = "status" // Queries the current status of Printer
+	MsgPing   = "ping"
+)
+
+// Message types sent by the bridge
+const (
+	MsgAccepted      = "accepted"       // The print request passed validation
+	MsgProgress      = "progress"       // Data: executor.CommandResult
+	MsgDone          = "done"           // Data: executor.Report
+	MsgError         = "error"          // Data: ErrorData
+	MsgPrinterStatus = "printer_status" // Data: service.Status
+	MsgPong          = "pong"
+)
+
+// Message is the envelope exchanged over the bridge in both directions.
+// ID is chosen by the client and echoed in every event of that request.
+type Message struct {
+	Type    string          `json:"type"`
+	ID      string          `json:"id,omitempty"`
+	Printer string          `json:"printer,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// ErrorData is the payload of MsgError events
+type ErrorData struct {
+	Message string `json:"message"`
+}
+
+// Bridge executes documents for connected clients and streams progress
+// and printer status events back to them. It is transport agnostic: the
+// WebSocket endpoint and in-process callers both use Connect.
+type Bridge struct {
+	open     queue.PrinterFactory
+	printers map[string]*bridgePrinter
+
+	mu      sync.Mutex
+	clients map[*Client]struct{}
+}
+
+// bridgePrinter serializes access to one printer and remembers its status
+type bridgePrinter struct {
+	mu          sync.Mutex
+	status      *service.Status
+	unsupported bool // The connection cannot read status, stop polling it
+}
+
+// NewBridge creates a bridge for the named printers
+func NewBridge(open queue.PrinterFactory, printers []string) (*Bridge, error) {
+	if open == nil {
+		return nil, fmt.Errorf("printer factory cannot be nil")
+	}
+	b := &Bridge{
+		open:     open,
+		printers: make(map[string]*bridgePrinter, len(printers)),
+		clients:  make(map[*Client]struct{}),
+	}
+	for _, name := range printers {
+		b.printers[name] = &bridgePrinter{}
+	}
+	return b, nil
+}
+
+// Client is one connection to the bridge
+type Client struct {
+	bridge   *Bridge
+	requests chan Message
+	events   chan Message
+	done     chan struct{}
+	once     sync.Once
+	wg       sync.WaitGroup
+}
+
+// Connect registers a new client. Events are read from Client.Events until
+// Client.Close is called.
+func (b *Bridge) Connect() *Client {
+	c := &Client{
+		bridge:   b,
+		requests: make(chan Message, constants.DefaultBridgeRequestBuffer),
+		events:   make(chan Message, constants.DefaultBridgeEventBuffer),
+		done:     make(chan struct{}),
+	}
+	b.mu.Lock()
+	b.clients[c] = struct{}{}
+	b.mu.Unlock()
+
+	c.wg.Add(1)
+	go c.run()
+	return c
+}
+
+// run handles the messages of the client one at a time, in the order they
+// were sent, so consecutive print jobs reach the printer in order
+func (c *Client) run() {
+	defer c.wg.Done()
+	for {
+		select {
+		case msg := <-c.requests:
+			c.handle(msg)
+		case <-c.done:
+			// Messages sent before Close still run
+			for {
+				select {
+				case msg := <-c.requests:
+					c.handle(msg)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// Events returns the channel of events for this client
+func (c *Client) Events() <-chan Message {
+	return c.events
+}
+
+// Done is closed when the client is closed
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+// Close unregisters the client and waits for its pending requests
+func (c *Client) Close() {
+	c.once.Do(func() {
+		c.bridge.mu.Lock()
+		delete(c.bridge.clients, c)
+		c.bridge.mu.Unlock()
+		close(c.done)
+	})
+	c.wg.Wait()
+}
+
+// emit delivers an event unless the client is gone
+func (c *Client) emit(msg Message) {
+	select {
+	case c.events <- msg:
+	case <-c.done:
+	}
+}
+
+func (c *Client) emitData(typ, id, printer string, data interface{}) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("bridge: failed to encode %s event: %v", typ, err)
+		return
+	}
+	c.emit(Message{Type: typ, ID: id, Printer: printer, Data: raw})
+}
+
+func (c *Client) emitError(msg Message, err error) {
+	c.emitData(MsgError, msg.ID, msg.Printer, ErrorData{Message: err.Error()})
+}
+
+// Send queues a client message; messages are handled in order and their
+// results arrive as events. Send blocks while the queue is full.
+func (c *Client) Send(msg Message) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.requests <- msg:
+	case <-c.done:
+	}
+}
+
+func (c *Client) handle(msg Message) {
+	switch msg.Type {
+	case MsgPing:
+		c.emit(Message{Type: MsgPong, ID: msg.ID})
+	case MsgPrint:
+		c.print(msg)
+	case MsgStatus:
+		status, err := c.bridge.Status(msg.Printer)
+		if err != nil {
+			c.emitError(msg, err)
+			return
+		}
+		c.emitData(MsgPrinterStatus, msg.ID, msg.Printer, status)
+	default:
+		c.emitError(msg, fmt.Errorf("unknown message type %q", msg.Type))
+	}
+}
+
+// print compiles the document, emitting one progress event per command, and
+// sends it to the printer as a single job before the final done event. A
+// document that fails to compile never reaches the printer.
+func (c *Client) print(msg Message) {
+	p, ok := c.bridge.printers[msg.Printer]
+	if !ok {
+		c.emitError(msg, fmt.Errorf("unknown printer %q", msg.Printer))
+		return
+	}
+
+	doc, err := schema.ParseDocument(msg.Data)
+	if err == nil {
+		err = doc.Validate()
+	}
+	if err != nil {
+		c.emitError(msg, err)
+		return
+	}
+	c.emit(Message{Type: MsgAccepted, ID: msg.ID, Printer: msg.Printer})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	printer, err := c.bridge.open(msg.Printer)
+	if err != nil {
+		c.emitError(msg, fmt.Errorf("open printer %s: %w", msg.Printer, err))
+		return
+	}
+	defer func() {
+		if err := printer.Close(); err != nil {
+			log.Printf("bridge: failed to close printer %s: %v", msg.Printer, err)
+		}
+	}()
+
+	exec := executor.NewExecutor(printer)
+	exec.OnProgress(func(result executor.CommandResult) {
+		c.emitData(MsgProgress, msg.ID, msg.Printer, result)
+	})
+
+	report, err := exec.ExecuteBuffered(doc)
+	if err != nil {
+		c.emitError(msg, err)
+		return
+	}
+	c.emitData(MsgDone, msg.ID, msg.Printer, report)
+}
+
+// broadcast sends an event to every connected client
+func (b *Bridge) broadcast(msg Message) {
+	b.mu.Lock()
+	clients := make([]*Client, 0, len(b.clients))
+	for c := range b.clients {
+		clients = append(clients, c)
+	}
+	b.mu.Unlock()
+
+	for _, c := range clients {
+		c.emit(msg)
+	}
+}
+
+// Status queries the named printer. Printers that cannot be opened are
+// reported offline.
+func (b *Bridge) Status(name string) (service.Status, error) {
+	p, ok := b.printers[name]
+	if !ok {
+		return service.Status{}, fmt.Errorf("unknown printer %q", name)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return b.queryStatus(name, p)
+}
+
+// queryStatus opens the printer and reads its status. The caller holds p.mu.
+func (b *Bridge) queryStatus(name string, p *bridgePrinter) (service.Status, error) {
+	printer, err := b.open(name)
+	if err != nil {
+		return service.Status{Online: false}, nil
+	}
+	defer func() {
+		if err := printer.Close(); err != nil {
+			log.Printf("bridge: failed to close printer %s: %v", name, err)
+		}
+	}()
+
+	status, err := printer.Status()
+	if errors.Is(err, service.ErrStatusUnsupported) {
+		p.unsupported = true
+	}
+	return status, err
+}
+
+// PollStatus queries every printer once and broadcasts the ones whose
+// status changed. Printers that are printing are skipped until the next
+// poll, and printers whose connection cannot report status are not opened
+// again.
+func (b *Bridge) PollStatus() {
+	for name, p := range b.printers {
+		if !p.mu.TryLock() {
+			continue
+		}
+		if p.unsupported {
+			p.mu.Unlock()
+			continue
+		}
+
+		status, err := b.queryStatus(name, p)
+		if err != nil {
+			p.mu.Unlock()
+			if !errors.Is(err, service.ErrStatusUnsupported) {
+				log.Printf("bridge: status of %s failed: %v", name, err)
+			}
+			continue
+		}
+		changed := p.status == nil || *p.status != status
+		p.status = &status
+		p.mu.Unlock()
+
+		if changed {
+			data, _ := json.Marshal(status)
+			b.broadcast(Message{Type: MsgPrinterStatus, Printer: name, Data: data})
+		}
+	}
+}
+
+// WatchStatus calls PollStatus every interval until ctx is cancelled
+func (b *Bridge) WatchStatus(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = constants.DefaultBridgeStatusInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		b.PollStatus()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
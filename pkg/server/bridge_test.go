@@ -0,0 +1,329 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/queue"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+const bridgeDoc = `{"version":"1.0","profile":{"model":"Test"},"commands":[` +
+	`{"type":"text","data":{"content":{"text":"Hola"}}},` +
+	`{"type":"feed","data":{"lines":1}},` +
+	`{"type":"cut","data":{"mode":"partial"}}]}`
+
+// statusConnector is a buffer connector that answers DLE EOT requests
+type statusConnector struct {
+	*connection.BufferConnector
+	answers *bytes.Reader
+}
+
+func (c *statusConnector) ReadStatus(p []byte) (int, error) {
+	return c.answers.Read(p)
+}
+
+// fakePrinters opens printers whose status answers can be changed by tests
+type fakePrinters struct {
+	mu      sync.Mutex
+	answers []byte
+	fail    bool
+	opened  []*connection.BufferConnector // In open order
+}
+
+func (f *fakePrinters) set(answers ...byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.answers = answers
+}
+
+func (f *fakePrinters) open(string) (*service.Printer, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, errors.New("printer unavailable")
+	}
+	conn := &statusConnector{
+		BufferConnector: connection.NewBufferConnector(),
+		answers:         bytes.NewReader(f.answers),
+	}
+	f.opened = append(f.opened, conn.BufferConnector)
+	return service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), conn)
+}
+
+// next waits for the next event of the client
+func next(t *testing.T, c *Client) Message {
+	t.Helper()
+	select {
+	case msg := <-c.Events():
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for bridge event")
+		return Message{}
+	}
+}
+
+func TestBridge_PrintEmitsProgress(t *testing.T) {
+	printers := &fakePrinters{}
+	bridge, err := NewBridge(printers.open, []string{"kitchen"})
+	require.NoError(t, err)
+
+	client := bridge.Connect()
+	defer client.Close()
+
+	client.Send(Message{Type: MsgPrint, ID: "r1", Printer: "kitchen", Data: json.RawMessage(bridgeDoc)})
+
+	msg := next(t, client)
+	assert.Equal(t, MsgAccepted, msg.Type)
+	assert.Equal(t, "r1", msg.ID)
+
+	for i, typ := range []string{"text", "feed", "cut"} {
+		msg = next(t, client)
+		require.Equal(t, MsgProgress, msg.Type)
+		var result executor.CommandResult
+		require.NoError(t, json.Unmarshal(msg.Data, &result))
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, typ, result.Type)
+		assert.Equal(t, executor.StatusSucceeded, result.Status)
+	}
+
+	msg = next(t, client)
+	require.Equal(t, MsgDone, msg.Type)
+	var report executor.Report
+	require.NoError(t, json.Unmarshal(msg.Data, &report))
+	assert.True(t, report.OK())
+	assert.Len(t, report.Results, 3)
+}
+
+func TestBridge_PrintsInOrder(t *testing.T) {
+	printers := &fakePrinters{}
+	bridge, err := NewBridge(printers.open, []string{"kitchen"})
+	require.NoError(t, err)
+
+	client := bridge.Connect()
+	defer client.Close()
+
+	const jobs = 5
+	for i := 0; i < jobs; i++ {
+		doc := fmt.Sprintf(`{"version":"1.0","profile":{"model":"Test"},"commands":[`+
+			`{"type":"text","data":{"content":{"text":"Ticket %d"}}}]}`, i)
+		client.Send(Message{Type: MsgPrint, ID: strconv.Itoa(i), Printer: "kitchen", Data: json.RawMessage(doc)})
+	}
+
+	// Each request runs to completion before the next one is accepted
+	for i := 0; i < jobs; i++ {
+		id := strconv.Itoa(i)
+		for _, typ := range []string{MsgAccepted, MsgProgress, MsgDone} {
+			msg := next(t, client)
+			require.Equal(t, typ, msg.Type)
+			require.Equal(t, id, msg.ID)
+		}
+	}
+
+	printers.mu.Lock()
+	defer printers.mu.Unlock()
+	require.Len(t, printers.opened, jobs)
+	for i, conn := range printers.opened {
+		assert.Contains(t, string(conn.Bytes()), fmt.Sprintf("Ticket %d", i))
+	}
+}
+
+func TestBridge_FailedDocumentDoesNotPrint(t *testing.T) {
+	printers := &fakePrinters{}
+	bridge, err := NewBridge(printers.open, []string{"kitchen"})
+	require.NoError(t, err)
+
+	client := bridge.Connect()
+	defer client.Close()
+
+	doc := `{"version":"1.0","profile":{"model":"Test"},"on_unknown":"abort","commands":[` +
+		`{"type":"text","data":{"content":{"text":"Half a ticket"}}},{"type":"hologram","data":{}}]}`
+	client.Send(Message{Type: MsgPrint, ID: "r1", Printer: "kitchen", Data: json.RawMessage(doc)})
+
+	for _, typ := range []string{MsgAccepted, MsgProgress, MsgProgress, MsgError} {
+		require.Equal(t, typ, next(t, client).Type)
+	}
+
+	printers.mu.Lock()
+	defer printers.mu.Unlock()
+	require.Len(t, printers.opened, 1)
+	assert.Empty(t, printers.opened[0].Bytes())
+}
+
+func TestBridge_Errors(t *testing.T) {
+	printers := &fakePrinters{}
+	bridge, err := NewBridge(printers.open, []string{"kitchen"})
+	require.NoError(t, err)
+
+	client := bridge.Connect()
+	defer client.Close()
+
+	tests := []struct {
+		name string
+		msg  Message
+		want string
+	}{
+		{"unknown type", Message{Type: "shout", ID: "1"}, "unknown message type"},
+		{"unknown printer", Message{Type: MsgPrint, ID: "2", Printer: "bar", Data: json.RawMessage(bridgeDoc)}, "unknown printer"},
+		{"invalid document", Message{Type: MsgPrint, ID: "3", Printer: "kitchen", Data: json.RawMessage(`{"version":"9"}`)}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client.Send(tt.msg)
+			msg := next(t, client)
+			require.Equal(t, MsgError, msg.Type)
+			assert.Equal(t, tt.msg.ID, msg.ID)
+
+			var data ErrorData
+			require.NoError(t, json.Unmarshal(msg.Data, &data))
+			assert.Contains(t, data.Message, tt.want)
+		})
+	}
+
+	printers.mu.Lock()
+	printers.fail = true
+	printers.mu.Unlock()
+	client.Send(Message{Type: MsgPrint, ID: "4", Printer: "kitchen", Data: json.RawMessage(bridgeDoc)})
+	assert.Equal(t, MsgAccepted, next(t, client).Type)
+	assert.Equal(t, MsgError, next(t, client).Type)
+}
+
+func TestBridge_PollStatusBroadcastsChanges(t *testing.T) {
+	printers := &fakePrinters{}
+	printers.set(0x12, 0x12, 0x12)
+	bridge, err := NewBridge(printers.open, []string{"kitchen"})
+	require.NoError(t, err)
+
+	a := bridge.Connect()
+	defer a.Close()
+	b := bridge.Connect()
+	defer b.Close()
+
+	bridge.PollStatus()
+	for _, c := range []*Client{a, b} {
+		msg := next(t, c)
+		require.Equal(t, MsgPrinterStatus, msg.Type)
+		assert.Equal(t, "kitchen", msg.Printer)
+		var status service.Status
+		require.NoError(t, json.Unmarshal(msg.Data, &status))
+		assert.Equal(t, service.Status{Online: true}, status)
+	}
+
+	// Unchanged status is not broadcast again
+	bridge.PollStatus()
+	select {
+	case msg := <-a.Events():
+		t.Fatalf("unexpected event %+v", msg)
+	default:
+	}
+
+	// A printer that is printing is skipped instead of waited for
+	bridge.printers["kitchen"].mu.Lock()
+	printers.set(0x1A, 0x32, 0x72)
+	bridge.PollStatus()
+	bridge.printers["kitchen"].mu.Unlock()
+	select {
+	case msg := <-a.Events():
+		t.Fatalf("unexpected event %+v", msg)
+	default:
+	}
+
+	// Paper runs out
+	bridge.PollStatus()
+	var status service.Status
+	require.NoError(t, json.Unmarshal(next(t, a).Data, &status))
+	assert.True(t, status.PaperOut)
+	assert.False(t, status.Online)
+}
+
+func TestBridge_StatusUnsupported(t *testing.T) {
+	opened := 0
+	open := func(string) (*service.Printer, error) {
+		opened++
+		return service.NewPrinter(composer.NewEscpos(), profile.CreateProfile80mm(), connection.NewBufferConnector())
+	}
+	bridge, err := NewBridge(open, []string{"kitchen"})
+	require.NoError(t, err)
+
+	client := bridge.Connect()
+	defer client.Close()
+
+	bridge.PollStatus()
+	select {
+	case msg := <-client.Events():
+		t.Fatalf("unexpected event %+v", msg)
+	default:
+	}
+
+	// The printer is not opened again by later polls
+	bridge.PollStatus()
+	assert.Equal(t, 1, opened)
+
+	client.Send(Message{Type: MsgStatus, ID: "s", Printer: "kitchen"})
+	msg := next(t, client)
+	assert.Equal(t, MsgError, msg.Type)
+}
+
+func TestWebSocket_Print(t *testing.T) {
+	printers := &fakePrinters{}
+	dispatch := func(context.Context, string, []byte) error { return nil }
+	q, err := queue.Open(filepath.Join(t.TempDir(), "jobs.jsonl"), dispatch, queue.Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = q.Close() })
+
+	srv, err := New(Config{
+		Token:       "s3cret",
+		Printers:    []PrinterConfig{{Name: "kitchen", Profile: "generic-80"}},
+		Profiles:    map[string]*profile.Escpos{"generic-80": profile.CreateProfile80mm()},
+		OpenPrinter: printers.open,
+	}, q)
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/v1/ws"
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?token=s3cret", nil)
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("not json")))
+	var msg Message
+	require.NoError(t, conn.ReadJSON(&msg))
+	assert.Equal(t, MsgError, msg.Type)
+
+	require.NoError(t, conn.WriteJSON(Message{Type: MsgPrint, ID: "r1", Printer: "kitchen", Data: json.RawMessage(bridgeDoc)}))
+
+	var types []string
+	for msg.Type != MsgDone {
+		require.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, "r1", msg.ID)
+		types = append(types, msg.Type)
+	}
+	assert.Equal(t, []string{MsgAccepted, MsgProgress, MsgProgress, MsgProgress, MsgDone}, types)
+}
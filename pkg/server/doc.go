@@ -0,0 +1,54 @@
+// Package server exposes poster as a local HTTP print service for
+// browser-based POS frontends.
+//
+// Documents are submitted to a named printer and delivered through the
+// durable queue package, so a receipt accepted by the API survives a restart
+// and is retried while the printer is offline.
+//
+// # Endpoints
+//
+//	GET  /v1/printers               Configured printers and their backlog
+//	GET  /v1/profiles               Available printer profiles
+//	POST /v1/printers/{name}/jobs   Queue a document (Idempotency-Key header supported)
+//	GET  /v1/jobs/{id}              Job status
+//	POST /v1/jobs/{id}/retry        Re-queue a dead job
+//	POST /v1/validate               Validate a document without printing
+//	POST /v1/preview                Render a document as PNG (?profile= or ?printer=)
+//	GET  /v1/ws                     WebSocket bridge (when Config.OpenPrinter is set)
+//
+// # WebSocket Bridge
+//
+// The bridge prints directly instead of queueing, so the client sees one
+// event per command as the executor runs it, and receives printer status
+// changes (paper out, cover open) as soon as they are detected. Every frame
+// is a JSON Message in both directions:
+//
+//	{"type": "print", "id": "r1", "printer": "POS-80", "data": {...document...}}
+//
+// A print request answers with "accepted", one "progress" per command
+// (executor.CommandResult), and a final "done" (executor.Report) or "error".
+// Messages of one connection are handled in the order they arrive, so
+// tickets sent back to back print in order. "status" queries a printer and "ping" answers "pong". "printer_status"
+// events are broadcast to every client by Bridge.WatchStatus for printers whose connection reads status
+// (connection.NetworkConnector). Bridge.Connect
+// gives in-process clients the same events without a socket.
+//
+// # Security
+//
+// The server listens on the loopback interface by default. When Config.Token
+// is set every request must carry "Authorization: Bearer <token>"; WebSocket
+// handshakes may send "?token=" instead because browsers cannot set headers
+// on them. Browser requests are only accepted from Config.AllowedOrigins.
+//
+// # Quick Start
+//
+//	q, _ := queue.Open("spool/jobs.jsonl", queue.ExecutorDispatcher(openPrinter), queue.Options{})
+//	srv, _ := server.New(server.Config{
+//		Token:          "secret",
+//		AllowedOrigins: []string{"http://localhost:5173"},
+//		Printers:       []server.PrinterConfig{{Name: "POS-80", Profile: "ec-pm-80250"}},
+//		Profiles:       map[string]*profile.Escpos{"ec-pm-80250": profile.CreateECPM80250()},
+//	}, q)
+//	go q.Run(ctx, time.Second)
+//	http.ListenAndServe("127.0.0.1:8420", srv.Handler())
+package server
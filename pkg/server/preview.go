@@ -0,0 +1,101 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/connection"
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/document/executor"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/emulator"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/service"
+)
+
+// previewProfile selects the profile for a preview request.
+// Priority: 1) ?profile=, 2) ?printer=, 3) profile.model of the document,
+// 4) a generic profile for the document paper width.
+func (s *Server) previewProfile(r *http.Request, doc *schema.Document) (*profile.Escpos, error) {
+	if name := r.URL.Query().Get("profile"); name != "" {
+		p, ok := s.cfg.Profiles[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown profile %q", name)
+		}
+		return p.Clone(), nil
+	}
+
+	if name := r.URL.Query().Get("printer"); name != "" {
+		printer, ok := s.printers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown printer %q", name)
+		}
+		return s.cfg.Profiles[printer.Profile].Clone(), nil
+	}
+
+	if model := doc.Profile.Model; model != "" {
+		for name, p := range s.cfg.Profiles {
+			if strings.EqualFold(name, model) || strings.EqualFold(p.Model, model) {
+				return p.Clone(), nil
+			}
+		}
+	}
+
+	if doc.Profile.PaperWidth > 0 && doc.Profile.PaperWidth < constants.Paper80mm {
+		return profile.CreateProfile58mm(), nil
+	}
+	return profile.CreateProfile80mm(), nil
+}
+
+// RenderPreview compiles doc for prof and renders the job through the
+// emulator, returning a PNG image.
+func RenderPreview(doc *schema.Document, prof *profile.Escpos) ([]byte, error) {
+	printer, err := service.NewPrinter(composer.NewEscpos(), prof, connection.NewBufferConnector())
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := executor.NewExecutor(printer).Compile(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := emulator.DefaultConfig()
+	cfg.Debug = false
+	if prof.DPI > 0 {
+		cfg.DPI = prof.DPI
+	}
+	switch {
+	case prof.DotsPerLine > 0:
+		cfg.PaperPxWidth = prof.DotsPerLine
+	case prof.PaperWidth < constants.Paper80mm:
+		cfg.PaperPxWidth = constants.PaperPxWidth58mm
+	}
+	if len(prof.CodePages) > 0 {
+		cfg.CodePages = make(map[byte]character.CodeTable, len(prof.CodePages))
+		for table, n := range prof.CodePages {
+			cfg.CodePages[n] = table
+		}
+	}
+
+	cfg.DoubleByte = prof.DoubleByte
+	cfg.FallbackFontPath = prof.FallbackFont
+
+	engine, err := emulator.NewEngine(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := engine.Replay(job); err != nil {
+		return nil, fmt.Errorf("render preview: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.WritePNG(&buf); err != nil {
+		return nil, fmt.Errorf("encode preview: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,339 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/document/schema"
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/queue"
+)
+
+// PrinterConfig describes a printer exposed by the API
+type PrinterConfig struct {
+	Name    string `json:"name"`    // Name used in URLs and to open the connection
+	Profile string `json:"profile"` // Key in Config.Profiles
+}
+
+// Config configures the HTTP API
+type Config struct {
+	// Token is required as "Authorization: Bearer <token>" when not empty
+	Token string
+	// AllowedOrigins lists the browser origins allowed by CORS; "*" allows any
+	AllowedOrigins []string
+	// Printers that accept jobs
+	Printers []PrinterConfig
+	// Profiles available for printers and previews, by name
+	Profiles map[string]*profile.Escpos
+	// MaxBodyBytes limits request bodies. Default: constants.DefaultServerMaxBodyBytes
+	MaxBodyBytes int64
+	// OpenPrinter enables the WebSocket bridge at /v1/ws when set
+	OpenPrinter queue.PrinterFactory
+}
+
+// Server exposes the print queue over a local REST API
+type Server struct {
+	cfg      Config
+	queue    *queue.Queue
+	printers map[string]PrinterConfig
+	bridge   *Bridge
+	handler  http.Handler
+}
+
+// New creates a Server that submits jobs to q
+func New(cfg Config, q *queue.Queue) (*Server, error) {
+	if q == nil {
+		return nil, fmt.Errorf("queue cannot be nil")
+	}
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = constants.DefaultServerMaxBodyBytes
+	}
+
+	printers := make(map[string]PrinterConfig, len(cfg.Printers))
+	for _, p := range cfg.Printers {
+		if p.Name == "" {
+			return nil, fmt.Errorf("printer name cannot be empty")
+		}
+		if _, dup := printers[p.Name]; dup {
+			return nil, fmt.Errorf("duplicate printer %q", p.Name)
+		}
+		if _, ok := cfg.Profiles[p.Profile]; !ok {
+			return nil, fmt.Errorf("printer %q: unknown profile %q", p.Name, p.Profile)
+		}
+		printers[p.Name] = p
+	}
+
+	s := &Server{cfg: cfg, queue: q, printers: printers}
+	if cfg.OpenPrinter != nil {
+		names := make([]string, 0, len(cfg.Printers))
+		for _, p := range cfg.Printers {
+			names = append(names, p.Name)
+		}
+		bridge, err := NewBridge(cfg.OpenPrinter, names)
+		if err != nil {
+			return nil, err
+		}
+		s.bridge = bridge
+	}
+	s.handler = s.cors(s.auth(s.routes()))
+	return s, nil
+}
+
+// Bridge returns the WebSocket bridge, or nil when Config.OpenPrinter is unset
+func (s *Server) Bridge() *Bridge {
+	return s.bridge
+}
+
+// Handler returns the HTTP handler with authentication and CORS applied
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/printers", s.handleListPrinters)
+	mux.HandleFunc("GET /v1/profiles", s.handleListProfiles)
+	mux.HandleFunc("POST /v1/printers/{name}/jobs", s.handleSubmitJob)
+	mux.HandleFunc("GET /v1/jobs/{id}", s.handleGetJob)
+	mux.HandleFunc("POST /v1/jobs/{id}/retry", s.handleRetryJob)
+	mux.HandleFunc("POST /v1/validate", s.handleValidate)
+	mux.HandleFunc("POST /v1/preview", s.handlePreview)
+	if s.bridge != nil {
+		mux.HandleFunc("GET /v1/ws", s.handleWebSocket)
+	}
+	return mux
+}
+
+// ============================================================================
+// Responses
+// ============================================================================
+
+// JobStatus is the public view of a queued job
+type JobStatus struct {
+	ID          string      `json:"id"`
+	Key         string      `json:"key,omitempty"`
+	Printer     string      `json:"printer"`
+	State       queue.State `json:"state"`
+	Attempts    int         `json:"attempts"`
+	LastError   string      `json:"last_error,omitempty"`
+	NextAttempt time.Time   `json:"next_attempt"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+func newJobStatus(job *queue.Job) JobStatus {
+	return JobStatus{
+		ID:          job.ID,
+		Key:         job.Key,
+		Printer:     job.Printer,
+		State:       job.State,
+		Attempts:    job.Attempts,
+		LastError:   job.LastError,
+		NextAttempt: job.NextAttempt,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
+
+// PrinterStatus describes a configured printer and its backlog
+type PrinterStatus struct {
+	Name    string `json:"name"`
+	Profile string `json:"profile"`
+	Pending int    `json:"pending"`
+	Dead    int    `json:"dead"`
+}
+
+// ProfileInfo describes a printer profile
+type ProfileInfo struct {
+	Name        string  `json:"name"`
+	Model       string  `json:"model"`
+	PaperWidth  float64 `json:"paper_width"`
+	DPI         int     `json:"dpi"`
+	DotsPerLine int     `json:"dots_per_line"`
+	HasQR       bool    `json:"has_qr"`
+	Cutter      bool    `json:"cutter"`
+	Drawer      bool    `json:"drawer"`
+}
+
+// ValidationResult is returned by /v1/validate
+type ValidationResult struct {
+	Valid    bool   `json:"valid"`
+	Error    string `json:"error,omitempty"`
+	Commands int    `json:"commands,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("server: failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// readDocument reads and validates the request body
+func (s *Server) readDocument(w http.ResponseWriter, r *http.Request) ([]byte, *schema.Document, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.MaxBodyBytes))
+	if err != nil {
+		return nil, nil, fmt.Errorf("read body: %w", err)
+	}
+	doc, err := schema.ParseDocument(body)
+	if err != nil {
+		return body, nil, err
+	}
+	if err := doc.Validate(); err != nil {
+		return body, nil, err
+	}
+	return body, doc, nil
+}
+
+// ============================================================================
+// Handlers
+// ============================================================================
+
+func (s *Server) handleListPrinters(w http.ResponseWriter, _ *http.Request) {
+	pending := make(map[string]int)
+	dead := make(map[string]int)
+	for _, job := range s.queue.Jobs("") {
+		switch job.State {
+		case queue.StatePending:
+			pending[job.Printer]++
+		case queue.StateDead:
+			dead[job.Printer]++
+		}
+	}
+
+	out := make([]PrinterStatus, 0, len(s.cfg.Printers))
+	for _, p := range s.cfg.Printers {
+		out = append(out, PrinterStatus{
+			Name:    p.Name,
+			Profile: p.Profile,
+			Pending: pending[p.Name],
+			Dead:    dead[p.Name],
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleListProfiles(w http.ResponseWriter, _ *http.Request) {
+	names := make([]string, 0, len(s.cfg.Profiles))
+	for name := range s.cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]ProfileInfo, 0, len(names))
+	for _, name := range names {
+		p := s.cfg.Profiles[name]
+		out = append(out, ProfileInfo{
+			Name:        name,
+			Model:       p.Model,
+			PaperWidth:  p.PaperWidth,
+			DPI:         p.DPI,
+			DotsPerLine: p.DotsPerLine,
+			HasQR:       p.HasQR,
+			Cutter:      p.SupportsCutter,
+			Drawer:      p.SupportsDrawer,
+		})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) handleSubmitJob(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if _, ok := s.printers[name]; !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown printer %q", name))
+		return
+	}
+
+	body, _, err := s.readDocument(w, r)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	job, err := s.queue.Enqueue(name, r.Header.Get("Idempotency-Key"), body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Location", "/v1/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, newJobStatus(job))
+}
+
+func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
+	job, err := s.queue.Get(r.PathValue("id"))
+	if err != nil {
+		s.writeQueueError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newJobStatus(job))
+}
+
+func (s *Server) handleRetryJob(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := s.queue.Retry(id); err != nil {
+		s.writeQueueError(w, err)
+		return
+	}
+	job, err := s.queue.Get(id)
+	if err != nil {
+		s.writeQueueError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, newJobStatus(job))
+}
+
+func (s *Server) writeQueueError(w http.ResponseWriter, err error) {
+	if errors.Is(err, queue.ErrJobNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeError(w, http.StatusConflict, err)
+}
+
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	_, doc, err := s.readDocument(w, r)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, ValidationResult{Valid: false, Error: err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, ValidationResult{Valid: true, Commands: len(doc.Commands)})
+}
+
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	_, doc, err := s.readDocument(w, r)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	prof, err := s.previewProfile(r, doc)
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	png, err := RenderPreview(doc, prof)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(png); err != nil {
+		log.Printf("server: failed to write preview: %v", err)
+	}
+}
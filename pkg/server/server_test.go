@@ -0,0 +1,205 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adcondev/poster/pkg/profile"
+	"github.com/adcondev/poster/pkg/queue"
+)
+
+const testDoc = `{"version":"1.0","profile":{"model":"Test"},"commands":[{"type":"text","data":{"content":{"text":"Hola"}}}]}`
+
+func newTestServer(t *testing.T, cfg Config) (*Server, *queue.Queue) {
+	t.Helper()
+	dispatch := func(context.Context, string, []byte) error { return nil }
+	q, err := queue.Open(filepath.Join(t.TempDir(), "jobs.jsonl"), dispatch, queue.Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = q.Close() })
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*profile.Escpos{
+			"generic-80": profile.CreateProfile80mm(),
+			"generic-58": profile.CreateProfile58mm(),
+		}
+	}
+	if cfg.Printers == nil {
+		cfg.Printers = []PrinterConfig{{Name: "kitchen", Profile: "generic-58"}}
+	}
+
+	srv, err := New(cfg, q)
+	require.NoError(t, err)
+	return srv, q
+}
+
+func do(t *testing.T, srv *Server, method, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNew_ValidatesPrinters(t *testing.T) {
+	dispatch := func(context.Context, string, []byte) error { return nil }
+	q, err := queue.Open(filepath.Join(t.TempDir(), "jobs.jsonl"), dispatch, queue.Options{})
+	require.NoError(t, err)
+	defer func() { _ = q.Close() }()
+
+	_, err = New(Config{Printers: []PrinterConfig{{Name: "bar", Profile: "missing"}}}, q)
+	assert.Error(t, err)
+
+	_, err = New(Config{}, nil)
+	assert.Error(t, err)
+}
+
+func TestSubmitJob_AndStatus(t *testing.T) {
+	srv, q := newTestServer(t, Config{})
+
+	rec := do(t, srv, http.MethodPost, "/v1/printers/kitchen/jobs", testDoc, map[string]string{"Idempotency-Key": "order-1"})
+	require.Equal(t, http.StatusAccepted, rec.Code)
+
+	var status JobStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, "kitchen", status.Printer)
+	assert.Equal(t, queue.StatePending, status.State)
+	assert.Equal(t, "/v1/jobs/"+status.ID, rec.Header().Get("Location"))
+
+	// Resubmitting with the same key returns the same job
+	rec = do(t, srv, http.MethodPost, "/v1/printers/kitchen/jobs", testDoc, map[string]string{"Idempotency-Key": "order-1"})
+	var again JobStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &again))
+	assert.Equal(t, status.ID, again.ID)
+
+	q.Process(context.Background())
+
+	rec = do(t, srv, http.MethodGet, "/v1/jobs/"+status.ID, "", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, queue.StateDone, status.State)
+
+	rec = do(t, srv, http.MethodGet, "/v1/jobs/unknown", "", nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestSubmitJob_Rejections(t *testing.T) {
+	srv, _ := newTestServer(t, Config{})
+
+	rec := do(t, srv, http.MethodPost, "/v1/printers/missing/jobs", testDoc, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = do(t, srv, http.MethodPost, "/v1/printers/kitchen/jobs", `{"version":"1.0","commands":[]}`, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+func TestValidate(t *testing.T) {
+	srv, _ := newTestServer(t, Config{})
+
+	rec := do(t, srv, http.MethodPost, "/v1/validate", testDoc, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var result ValidationResult
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.True(t, result.Valid)
+	assert.Equal(t, 1, result.Commands)
+
+	rec = do(t, srv, http.MethodPost, "/v1/validate", `{"version":"1.0","on_error":"explode","commands":[{"type":"text","data":{}}]}`, nil)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.False(t, result.Valid)
+	assert.NotEmpty(t, result.Error)
+}
+
+func TestPreview_ReturnsPNG(t *testing.T) {
+	srv, _ := newTestServer(t, Config{})
+
+	rec := do(t, srv, http.MethodPost, "/v1/preview?printer=kitchen", testDoc, nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+
+	img, err := png.Decode(bytes.NewReader(rec.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, profile.CreateProfile58mm().DotsPerLine, img.Bounds().Dx())
+
+	rec = do(t, srv, http.MethodPost, "/v1/preview?profile=missing", testDoc, nil)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestPreview_RefusesPartialJobs(t *testing.T) {
+	srv, _ := newTestServer(t, Config{})
+
+	doc := `{"version":"1.0","profile":{"model":"Test"},"on_error":"skip","commands":[` +
+		`{"type":"text","data":{"content":{"text":"Hola"}}},{"type":"qr","data":{"data":""}}]}`
+	rec := do(t, srv, http.MethodPost, "/v1/preview?printer=kitchen", doc, nil)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), "failed commands")
+}
+
+func TestListPrintersAndProfiles(t *testing.T) {
+	srv, _ := newTestServer(t, Config{})
+	do(t, srv, http.MethodPost, "/v1/printers/kitchen/jobs", testDoc, nil)
+
+	rec := do(t, srv, http.MethodGet, "/v1/printers", "", nil)
+	var printers []PrinterStatus
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &printers))
+	require.Len(t, printers, 1)
+	assert.Equal(t, PrinterStatus{Name: "kitchen", Profile: "generic-58", Pending: 1}, printers[0])
+
+	rec = do(t, srv, http.MethodGet, "/v1/profiles", "", nil)
+	var profiles []ProfileInfo
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &profiles))
+	require.Len(t, profiles, 2)
+	assert.Equal(t, "generic-58", profiles[0].Name)
+	assert.Equal(t, float64(58), profiles[0].PaperWidth)
+}
+
+func TestAuth(t *testing.T) {
+	srv, _ := newTestServer(t, Config{Token: "s3cret"})
+
+	rec := do(t, srv, http.MethodGet, "/v1/printers", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = do(t, srv, http.MethodGet, "/v1/printers", "", map[string]string{"Authorization": "Bearer wrong"})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = do(t, srv, http.MethodGet, "/v1/printers", "", map[string]string{"Authorization": "Bearer s3cret"})
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestCORS(t *testing.T) {
+	srv, _ := newTestServer(t, Config{Token: "s3cret", AllowedOrigins: []string{"http://localhost:5173"}})
+
+	// Preflight is answered before authentication
+	rec := do(t, srv, http.MethodOptions, "/v1/printers/kitchen/jobs", "", map[string]string{
+		"Origin":                        "http://localhost:5173",
+		"Access-Control-Request-Method": "POST",
+	})
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "http://localhost:5173", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	rec = do(t, srv, http.MethodGet, "/v1/printers", "", map[string]string{
+		"Origin":        "http://localhost:5173",
+		"Authorization": "Bearer s3cret",
+	})
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "http://localhost:5173", rec.Header().Get("Access-Control-Allow-Origin"))
+
+	rec = do(t, srv, http.MethodGet, "/v1/printers", "", map[string]string{
+		"Origin":        "http://evil.example",
+		"Authorization": "Bearer s3cret",
+	})
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
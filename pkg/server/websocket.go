@@ -0,0 +1,67 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// Origins are checked by the cors middleware before the upgrade
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// handleWebSocket connects a WebSocket to the bridge. Every text frame is a
+// Message; events are written back as they happen.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade already wrote the HTTP error
+		log.Printf("server: websocket upgrade failed: %v", err)
+		return
+	}
+	conn.SetReadLimit(s.cfg.MaxBodyBytes)
+
+	client := s.bridge.Connect()
+	writerDone := make(chan struct{})
+
+	go func() {
+		defer close(writerDone)
+		for {
+			select {
+			case msg := <-client.Events():
+				if err := conn.WriteJSON(msg); err != nil {
+					log.Printf("server: websocket write failed: %v", err)
+					_ = conn.Close()
+					return
+				}
+			case <-client.Done():
+				return
+			}
+		}
+	}()
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				log.Printf("server: websocket read failed: %v", err)
+			}
+			break
+		}
+
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			client.emitError(msg, fmt.Errorf("invalid message: %w", err))
+			continue
+		}
+		client.Send(msg)
+	}
+
+	client.Close()
+	<-writerDone
+	_ = conn.Close()
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/adcondev/poster/pkg/commands/bitimage"
+	"github.com/adcondev/poster/pkg/commands/linespacing"
+	"github.com/adcondev/poster/pkg/graphics"
+)
+
+// printRaster prints the bitmap with GS v 0
+func (p *Printer) printRaster(bitmap *graphics.MonochromeBitmap) error {
+	cmd, err := p.Protocol.BitImage.PrintRasterBitImage(
+		0, // normal mode
+		uint16(bitmap.GetWidthBytes()),
+		uint16(bitmap.Height),
+		bitmap.GetRasterData(),
+	)
+	if err != nil {
+		return fmt.Errorf("generate raster command: %w", err)
+	}
+
+	return p.Write(cmd)
+}
+
+// bitImageStripe is the height in dots of an ESC * 24-dot stripe
+const bitImageStripe = 24
+
+// printBitImage prints the bitmap in 24-dot stripes with ESC *, for
+// printers without GS v 0
+func (p *Printer) printBitImage(bitmap *graphics.MonochromeBitmap) error {
+	if bitmap.Width > bitimage.MaxHorizontalDots {
+		return fmt.Errorf("bitmap width %d exceeds %d dots", bitmap.Width, bitimage.MaxHorizontalDots)
+	}
+
+	// Stripes must touch each other
+	if err := p.Write(p.Protocol.LineSpacing.SetLineSpacing(linespacing.Spacing(bitImageStripe))); err != nil {
+		return err
+	}
+
+	for top := 0; top < bitmap.Height; top += bitImageStripe {
+		data := make([]byte, 0, bitmap.Width*3)
+		for x := 0; x < bitmap.Width; x++ {
+			for k := 0; k < 3; k++ {
+				var b byte
+				for bit := 0; bit < 8; bit++ {
+					y := top + k*8 + bit
+					if y < bitmap.Height && bitmap.GetPixel(x, y) {
+						b |= 0x80 >> bit
+					}
+				}
+				data = append(data, b)
+			}
+		}
+
+		cmd, err := p.Protocol.BitImage.SelectBitImageMode(bitimage.DoubleDensity24, uint16(bitmap.Width), data)
+		if err != nil {
+			return fmt.Errorf("generate bit image command: %w", err)
+		}
+		if err := p.Write(cmd); err != nil {
+			return err
+		}
+		if err := p.Write(p.Protocol.Print.PrintAndLineFeed()); err != nil {
+			return err
+		}
+	}
+
+	return p.Write(p.Protocol.LineSpacing.SelectDefaultLineSpacing())
+}
+
+// printGraphics stores the bitmap with GS ( L and prints it. Protocols
+// without graphics commands fall back to raster graphics.
+func (p *Printer) printGraphics(bitmap *graphics.MonochromeBitmap) error {
+	cmds, ok := p.Protocol.BitImage.(*bitimage.Commands)
+	if !ok || cmds.Graphics == nil {
+		return p.printRaster(bitmap)
+	}
+
+	width, height := uint16(bitmap.Width), uint16(bitmap.Height)
+	data := bitmap.GetRasterData()
+
+	store, err := cmds.Graphics.StoreRasterGraphicsInBuffer(bitimage.Monochrome,
+		bitimage.NormalScale, bitimage.NormalScale, bitimage.Color1, width, height, data)
+	if err != nil {
+		store, err = cmds.Graphics.StoreRasterGraphicsInBufferLarge(bitimage.Monochrome,
+			bitimage.NormalScale, bitimage.NormalScale, bitimage.Color1, width, height, data)
+	}
+	if err != nil {
+		return fmt.Errorf("generate graphics command: %w", err)
+	}
+	if err := p.Write(store); err != nil {
+		return err
+	}
+
+	printCmd, err := cmds.Graphics.PrintBufferedGraphics(bitimage.FunctionCodePrint50)
+	if err != nil {
+		return fmt.Errorf("generate graphics command: %w", err)
+	}
+	return p.Write(printCmd)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/graphics"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+func TestPrintBitmap_ImageModes(t *testing.T) {
+	bitmap := graphics.NewMonochromeBitmap(16, 30)
+	bitmap.SetPixel(0, 0, true)
+	bitmap.SetPixel(15, 29, true)
+
+	tests := []struct {
+		mode   profile.ImageMode
+		prefix []byte
+		count  int // Commands expected with prefix
+	}{
+		{profile.ImageRaster, []byte{0x1D, 'v', '0'}, 1},
+		{"", []byte{0x1D, 'v', '0'}, 1},
+		{profile.ImageBitImage, []byte{0x1B, '*', 33, 16, 0}, 2}, // Two 24-dot stripes
+		{profile.ImageGraphics, []byte{0x1D, '(', 'L'}, 2},       // Store and print
+	}
+
+	for _, tt := range tests {
+		conn := &recordingConnector{}
+		p := newRecordingPrinter(t, conn)
+		p.Profile.ImageMode = tt.mode
+
+		if err := p.PrintBitmap(bitmap); err != nil {
+			t.Fatalf("%s: PrintBitmap error: %v", tt.mode, err)
+		}
+		job := bytes.Join(conn.chunks, nil)
+		if got := bytes.Count(job, tt.prefix); got != tt.count {
+			t.Errorf("%s: expected %d commands %X, got %d in %X", tt.mode, tt.count, tt.prefix, got, job)
+		}
+	}
+}
+
+func TestPrintBitmap_BitImageColumns(t *testing.T) {
+	bitmap := graphics.NewMonochromeBitmap(1, 24)
+	bitmap.SetPixel(0, 0, true)  // Top dot, MSB of the first byte
+	bitmap.SetPixel(0, 23, true) // Bottom dot, LSB of the third byte
+
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.ImageMode = profile.ImageBitImage
+
+	if err := p.PrintBitmap(bitmap); err != nil {
+		t.Fatalf("PrintBitmap error: %v", err)
+	}
+	job := bytes.Join(conn.chunks, nil)
+	if !bytes.Contains(job, []byte{0x1B, '*', 33, 1, 0, 0x80, 0x00, 0x01, 0x0A}) {
+		t.Errorf("unexpected bit image data: %X", job)
+	}
+}
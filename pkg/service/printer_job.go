@@ -0,0 +1,46 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// TransmitOptions controls how a compiled job is sent to the connector
+type TransmitOptions struct {
+	// ChunkSize is the number of bytes per write; 0 sends the job in a single write
+	ChunkSize int
+	// ChunkDelay is the pause between chunks, useful for slow serial links
+	ChunkDelay time.Duration
+}
+
+// SendJob transmits a fully compiled job, split according to p.Transmit
+func (p *Printer) SendJob(job []byte) error {
+	if len(job) == 0 {
+		return fmt.Errorf("job cannot be empty")
+	}
+
+	size := p.Transmit.ChunkSize
+	if size <= 0 || size >= len(job) {
+		return p.Write(job)
+	}
+
+	for offset := 0; offset < len(job); offset += size {
+		end := min(offset+size, len(job))
+		if err := p.Write(job[offset:end]); err != nil {
+			return fmt.Errorf("transmit chunk at offset %d of %d: %w", offset, len(job), err)
+		}
+		if p.Transmit.ChunkDelay > 0 && end < len(job) {
+			time.Sleep(p.Transmit.ChunkDelay)
+		}
+	}
+
+	return nil
+}
+
+// JobHash returns the hex-encoded SHA-256 digest of a compiled job
+func JobHash(job []byte) string {
+	sum := sha256.Sum256(job)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+// recordingConnector records each write as a separate chunk
+type recordingConnector struct {
+	chunks  [][]byte
+	failAt  int
+	written int
+}
+
+func (c *recordingConnector) Write(data []byte) (int, error) {
+	if c.failAt > 0 && len(c.chunks)+1 == c.failAt {
+		return 0, errors.New("link down")
+	}
+	c.chunks = append(c.chunks, append([]byte(nil), data...))
+	c.written += len(data)
+	return len(data), nil
+}
+
+func (c *recordingConnector) Close() error { return nil }
+
+func newRecordingPrinter(t *testing.T, conn *recordingConnector) *Printer {
+	t.Helper()
+	p, err := NewPrinter(composer.NewEscpos(), profile.CreateProfile58mm(), conn)
+	if err != nil {
+		t.Fatalf("NewPrinter error: %v", err)
+	}
+	return p
+}
+
+func TestSendJob_SingleWrite(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+
+	if err := p.SendJob([]byte("0123456789")); err != nil {
+		t.Fatalf("SendJob error: %v", err)
+	}
+	if len(conn.chunks) != 1 {
+		t.Errorf("Expected 1 write, got %d", len(conn.chunks))
+	}
+}
+
+func TestSendJob_Chunked(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Transmit.ChunkSize = 4
+
+	if err := p.SendJob([]byte("0123456789")); err != nil {
+		t.Fatalf("SendJob error: %v", err)
+	}
+	if len(conn.chunks) != 3 {
+		t.Fatalf("Expected 3 chunks, got %d", len(conn.chunks))
+	}
+	if string(conn.chunks[2]) != "89" {
+		t.Errorf("Expected last chunk '89', got %q", conn.chunks[2])
+	}
+}
+
+func TestSendJob_Errors(t *testing.T) {
+	conn := &recordingConnector{failAt: 2}
+	p := newRecordingPrinter(t, conn)
+	p.Transmit.ChunkSize = 4
+
+	if err := p.SendJob(nil); err == nil {
+		t.Error("Expected error for empty job")
+	}
+	if err := p.SendJob([]byte("0123456789")); err == nil {
+		t.Error("Expected error when a chunk fails")
+	}
+	if conn.written != 4 {
+		t.Errorf("Expected transmission to stop after first chunk, wrote %d bytes", conn.written)
+	}
+}
+
+func TestJobHash(t *testing.T) {
+	a := JobHash([]byte("ticket"))
+	if len(a) != 64 {
+		t.Errorf("Expected 64 hex chars, got %d", len(a))
+	}
+	if a != JobHash([]byte("ticket")) {
+		t.Error("Expected deterministic hash")
+	}
+	if a == JobHash([]byte("ticket2")) {
+		t.Error("Expected different hash for different jobs")
+	}
+}
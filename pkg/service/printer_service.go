@@ -4,9 +4,12 @@ package service
 import (
 	"fmt"
 	"log"
+	"unicode/utf8"
 
 	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/commands/kanji"
 	"github.com/adcondev/poster/pkg/commands/mechanismcontrol"
+	"github.com/adcondev/poster/pkg/commands/shared"
 	"github.com/adcondev/poster/pkg/composer"
 	"github.com/adcondev/poster/pkg/connection"
 	"github.com/adcondev/poster/pkg/constants"
@@ -21,6 +24,19 @@ type Printer struct {
 	Profile    profile.Escpos
 	Connection connection.Connector
 	Protocol   composer.EscposProtocol
+
+	// Transmit controls how compiled jobs are sent by SendJob
+	Transmit TransmitOptions
+
+	// Banner is printed centered and bold by Initialize, so it is part of
+	// the job, e.g. the notice of a job redirected to a backup printer
+	Banner string
+
+	// utf8 is set while the printer takes UTF-8 text, see EnableUTF8
+	utf8 bool
+
+	// substitutions made under Profile.Unencodable, see TakeSubstitutions
+	substitutions []profile.Substitution
 }
 
 // NewPrinter creates a new Printer instance
@@ -49,12 +65,9 @@ func NewPrinter(proto *composer.EscposProtocol, prof *profile.Escpos, conn conne
 // Initialize resets the printer to default settings
 func (p *Printer) Initialize() error {
 	// TODO: Add profile-specific initialization if needed
-	var ct []byte
-	if p.Profile.IsSupported(p.Profile.CodeTable) {
-		ct, _ = p.Protocol.Character.SelectCharacterCodeTable(p.Profile.CodeTable)
-	} else {
-		ct, _ = p.Protocol.Character.SelectCharacterCodeTable(character.WPC1252)
-		log.Printf("warning: unsupported code table %v, falling back to Windows-1252", p.Profile.CodeTable)
+	ct, err := p.codeTableCommand(p.Profile.CodeTable)
+	if err != nil {
+		return fmt.Errorf("initialize: %w", err)
 	}
 
 	init := append(p.Protocol.InitializePrinter(), ct...)
@@ -63,7 +76,33 @@ func (p *Printer) Initialize() error {
 	// IMPORTANT: The idea is to notify the user that the printer is ready
 	// but it can be annoying for my other dev colleagues :)
 	// FIXME: init = append(init, []byte{0x1B, 0x42, 0x01, 0x05}...) // Beep!
-	return p.Write(init)
+	if err := p.Write(init); err != nil {
+		return err
+	}
+	// ESC @ goes back to 1-byte text
+	p.utf8 = false
+
+	if p.Banner != "" {
+		return p.printBanner()
+	}
+	return nil
+}
+
+// printBanner prints Banner centered and bold, and restores the defaults
+func (p *Printer) printBanner() error {
+	steps := []func() error{
+		p.AlignCenter,
+		p.EnableBold,
+		func() error { return p.PrintLine(p.Banner) },
+		p.DisableBold,
+		p.AlignLeft,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			return fmt.Errorf("banner: %w", err)
+		}
+	}
+	return nil
 }
 
 // Close closes the connection to the printer
@@ -83,30 +122,157 @@ func (p *Printer) Write(data []byte) error {
 
 // Print sends text without line feed
 func (p *Printer) Print(text string) error {
-	encText, err := p.Profile.EncodeString(text)
-	if err != nil {
-		return err
+	return p.printText(text, false)
+}
+
+// PrintLine sends text with line feed
+func (p *Printer) PrintLine(text string) error {
+	return p.printText(text, true)
+}
+
+// TakeSubstitutions returns the characters replaced under
+// Profile.Unencodable since the last call, and forgets them
+func (p *Printer) TakeSubstitutions() []profile.Substitution {
+	subs := p.substitutions
+	p.substitutions = nil
+	return subs
+}
+
+// Substitute applies Profile.Unencodable to text and records the changes
+// for TakeSubstitutions. Print does it too, but text laid out in columns
+// must be substituted before it is measured, since a transliteration can be
+// longer than the rune it replaces ("€" as "EUR"). In UTF-8 mode text is
+// returned unchanged.
+func (p *Printer) Substitute(text string) string {
+	if p.utf8 {
+		return text
+	}
+	text, subs := p.Profile.Substitute(text)
+	p.substitutions = append(p.substitutions, subs...)
+	return text
+}
+
+// CanEncode reports whether Print can send text with the current code
+// tables, double-byte set or UTF-8 mode
+func (p *Printer) CanEncode(text string) bool {
+	if p.utf8 {
+		return utf8.ValidString(text)
+	}
+	_, err := p.Profile.EncodeRuns(text)
+	return err == nil
+}
+
+// printText encodes text and sends it. In UTF-8 mode text is sent as is.
+// With Profile.AutoCodeTables or Profile.DoubleByte the text is split in
+// runs: ESC t is sent before each run in a different table, and CJK runs
+// are wrapped in Kanji mode (FS & ... FS .).
+func (p *Printer) printText(text string, lineFeed bool) error {
+	if p.utf8 && !utf8.ValidString(text) {
+		return fmt.Errorf("print: text is not valid UTF-8")
+	}
+	text = p.Substitute(text)
+	if p.utf8 || (len(p.Profile.AutoCodeTables) == 0 && p.Profile.DoubleByte == "") || text == "" {
+		encText := text
+		if !p.utf8 {
+			var err error
+			if encText, err = p.Profile.EncodeString(text); err != nil {
+				return err
+			}
+		}
+		var cmd []byte
+		var err error
+		if lineFeed {
+			cmd, err = p.Protocol.PrintLn(encText)
+		} else {
+			cmd, err = p.Protocol.Print.Text(encText)
+		}
+		if err != nil {
+			return err
+		}
+		return p.Write(cmd)
 	}
-	cmd, err := p.Protocol.Print.Text(encText)
+
+	runs, err := p.Profile.EncodeRuns(text)
 	if err != nil {
 		return err
 	}
+	kanjiMode := false
+	for _, run := range runs {
+		if run.DoubleByte != kanjiMode {
+			if err := p.setKanjiMode(run.DoubleByte); err != nil {
+				return err
+			}
+			kanjiMode = run.DoubleByte
+		}
+		if !run.DoubleByte && run.CodeTable != p.Profile.CodeTable {
+			if err := p.SetCodeTable(run.CodeTable); err != nil {
+				return err
+			}
+		}
+		cmd, err := p.Protocol.Print.Text(string(run.Data))
+		if err != nil {
+			return err
+		}
+		if err := p.Write(cmd); err != nil {
+			return err
+		}
+	}
+	if kanjiMode {
+		if err := p.setKanjiMode(false); err != nil {
+			return err
+		}
+	}
+	if lineFeed {
+		return p.Write(p.Protocol.Print.PrintAndLineFeed())
+	}
+	return nil
+}
+
+// setKanjiMode enters or leaves Kanji mode. Japanese models also get the
+// Shift JIS code system, their default is JIS.
+func (p *Printer) setKanjiMode(on bool) error {
+	if !on {
+		return p.Write(p.Protocol.Kanji.CancelKanjiMode())
+	}
+	var cmd []byte
+	if p.Profile.DoubleByte == profile.DoubleByteShiftJIS {
+		sys, err := p.Protocol.Kanji.SelectCodeSystem(kanji.ShiftJIS)
+		if err != nil {
+			return fmt.Errorf("select kanji code system: %w", err)
+		}
+		cmd = append(cmd, sys...)
+	}
+	cmd = append(cmd, p.Protocol.Kanji.SelectKanjiMode()...)
 	return p.Write(cmd)
 }
 
-// PrintLine sends text with line feed
-func (p *Printer) PrintLine(text string) error {
-	encText, err := p.Profile.EncodeString(text)
-	if err != nil {
-		return err
+// DefineUserChars defines user-defined characters (ESC &) for consecutive
+// codes starting at first, in the currently selected font. height is the
+// number of bytes per column.
+func (p *Printer) DefineUserChars(height, first byte, chars []character.UserDefinedChar) error {
+	if len(chars) == 0 {
+		return fmt.Errorf("define user chars: no characters")
+	}
+	last := int(first) + len(chars) - 1
+	if last > int(character.UserDefinedMaxCode) {
+		return fmt.Errorf("define user chars: %w", character.ErrCodeRange)
 	}
-	cmd, err := p.Protocol.PrintLn(encText)
+	cmd, err := p.Protocol.UserDefined.DefineUserDefinedCharacters(height, first, byte(last), chars)
 	if err != nil {
-		return err
+		return fmt.Errorf("define user chars: %w", err)
 	}
 	return p.Write(cmd)
 }
 
+// PrintUserChars prints user-defined characters, selecting the user-defined
+// set (ESC % 1) only around them
+func (p *Printer) PrintUserChars(codes []byte) error {
+	cmd := p.Protocol.UserDefined.SelectUserDefinedCharacterSet(character.UserDefinedOn)
+	cmd = append(cmd, codes...)
+	cmd = append(cmd, p.Protocol.UserDefined.SelectUserDefinedCharacterSet(character.UserDefinedOff)...)
+	return p.Write(cmd)
+}
+
 // FeedLines advances paper by n lines
 func (p *Printer) FeedLines(lines byte) error {
 	return p.Write(p.Protocol.Print.PrintAndFeedLines(lines))
@@ -259,7 +425,8 @@ func (p *Printer) PartialFeedAndCut(lines byte) error {
 // Image Printing Methods
 // ============================================================================
 
-// PrintBitmap prints a monochrome bitmap using raster graphics
+// PrintBitmap prints a monochrome bitmap with the image command selected
+// by Profile.ImageMode (raster graphics by default)
 func (p *Printer) PrintBitmap(bitmap *graphics.MonochromeBitmap) error {
 	if bitmap == nil {
 		return fmt.Errorf("bitmap cannot be nil")
@@ -283,17 +450,13 @@ func (p *Printer) PrintBitmap(bitmap *graphics.MonochromeBitmap) error {
 		return fmt.Errorf("bitmap height %d exceeds uint16 max %d", height, maxUint16)
 	}
 
-	cmd, err := p.Protocol.BitImage.PrintRasterBitImage(
-		0, // normal mode
-		uint16(width),
-		uint16(height),
-		bitmap.GetRasterData(),
-	)
-	if err != nil {
-		return fmt.Errorf("generate raster command: %w", err)
+	switch p.Profile.ImageMode {
+	case profile.ImageBitImage:
+		return p.printBitImage(bitmap)
+	case profile.ImageGraphics:
+		return p.printGraphics(bitmap)
 	}
-
-	return p.Write(cmd)
+	return p.printRaster(bitmap)
 }
 
 // ============================================================================
@@ -302,14 +465,7 @@ func (p *Printer) PrintBitmap(bitmap *graphics.MonochromeBitmap) error {
 
 // SetCodeTable changes the character code table
 func (p *Printer) SetCodeTable(codeTable character.CodeTable) error {
-	var cmd []byte
-	var err error
-	if !p.Profile.IsSupported(codeTable) {
-		cmd, err = p.Protocol.Character.SelectCharacterCodeTable(character.WPC1252)
-		log.Printf("warning: unsupported code table %v, falling back to Windows-1252", codeTable)
-	} else {
-		cmd, err = p.Protocol.Character.SelectCharacterCodeTable(codeTable)
-	}
+	cmd, err := p.codeTableCommand(codeTable)
 	if err != nil {
 		return fmt.Errorf("set code table: %w", err)
 	}
@@ -320,6 +476,77 @@ func (p *Printer) SetCodeTable(codeTable character.CodeTable) error {
 	return nil
 }
 
+// codeTableCommand builds the ESC t command that selects codeTable with the
+// index the model uses for it
+func (p *Printer) codeTableCommand(codeTable character.CodeTable) ([]byte, error) {
+	if !p.Profile.IsSupported(codeTable) {
+		return nil, fmt.Errorf("%w %s", profile.ErrUnsupportedCodeTable, profile.CodeTableName(codeTable))
+	}
+	if index := p.Profile.CodePageIndex(codeTable); index != byte(codeTable) {
+		// The model numbers its tables differently, the profile is the
+		// authority on which ESC t values are valid
+		return []byte{shared.ESC, 't', index}, nil
+	}
+	return p.Protocol.Character.SelectCharacterCodeTable(codeTable)
+}
+
+// EnableUTF8 switches the printer to UTF-8 text (FS ( C) and sets the font
+// priority of the profile. Printers whose profile does not declare UTF-8
+// keep transcoding text to the code table. It reports whether UTF-8 mode
+// is active.
+func (p *Printer) EnableUTF8() (bool, error) {
+	if !p.Profile.SupportsUTF8 {
+		return false, nil
+	}
+	cmd, err := p.utf8Commands()
+	if err != nil {
+		return false, fmt.Errorf("enable utf-8: %w", err)
+	}
+	if err := p.Write(cmd); err != nil {
+		return false, fmt.Errorf("write utf-8 command: %w", err)
+	}
+	p.utf8 = true
+	return true, nil
+}
+
+// DisableUTF8 goes back to 1-byte text in the current code table
+func (p *Printer) DisableUTF8() error {
+	if !p.utf8 {
+		return nil
+	}
+	cmd, err := p.Protocol.CodeConversion.SelectCharacterEncodeSystem(character.OneByte)
+	if err != nil {
+		return fmt.Errorf("disable utf-8: %w", err)
+	}
+	if err := p.Write(cmd); err != nil {
+		return fmt.Errorf("write utf-8 command: %w", err)
+	}
+	p.utf8 = false
+	return nil
+}
+
+// UTF8 reports whether text is sent as UTF-8
+func (p *Printer) UTF8() bool {
+	return p.utf8
+}
+
+// utf8Commands selects UTF-8 and the profile font priority
+func (p *Printer) utf8Commands() ([]byte, error) {
+	cc := p.Protocol.CodeConversion
+	cmd, err := cc.SelectCharacterEncodeSystem(character.UTF8)
+	if err != nil {
+		return nil, err
+	}
+	for i, font := range p.Profile.FontPriority {
+		prio, err := cc.SetFontPriority(character.FontPriority(i), font)
+		if err != nil {
+			return nil, err
+		}
+		cmd = append(cmd, prio...)
+	}
+	return cmd, nil
+}
+
 // ============================================================================
 // QR Code Printing Methods
 // ============================================================================
@@ -451,6 +678,14 @@ func (p *Printer) PrintBarcode(cfg graphics.BarcodeConfig, data []byte) error {
 		return fmt.Errorf("barcode data cannot be empty")
 	}
 
+	// Barcode data, and the HRI printed from it, is ASCII. Rewriting it
+	// would encode a different value, so it is never substituted.
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return fmt.Errorf("barcode data must be ASCII: %q", data)
+		}
+	}
+
 	// Delegamos la construcción completa al Composer
 	fullCommand, err := p.Protocol.GenerateBarcode(cfg, data)
 	if err != nil {
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/commands/barcode"
+	"github.com/adcondev/poster/pkg/commands/character"
+	"github.com/adcondev/poster/pkg/graphics"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+func TestSetCodeTable_ModelCodePages(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.CodePages = map[character.CodeTable]byte{character.WPC1252: 71, character.PC850: 2}
+	p.Profile.CodeTables = []character.CodeTable{character.WPC1252, character.PC850}
+
+	if err := p.SetCodeTable(character.WPC1252); err != nil {
+		t.Fatalf("SetCodeTable error: %v", err)
+	}
+	if err := p.SetCodeTable(character.PC850); err != nil {
+		t.Fatalf("SetCodeTable error: %v", err)
+	}
+
+	want := []byte{0x1B, 't', 71, 0x1B, 't', 2}
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, want) {
+		t.Errorf("Expected %X, got %X", want, got)
+	}
+	// Text keeps being encoded with the standard table
+	if p.Profile.CodeTable != character.PC850 {
+		t.Errorf("Expected code table PC850, got %v", p.Profile.CodeTable)
+	}
+}
+
+func TestInitialize_ModelCodePages(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.CodeTable = character.WPC1252
+	p.Profile.CodePages = map[character.CodeTable]byte{character.WPC1252: 71}
+
+	if err := p.Initialize(); err != nil {
+		t.Fatalf("Initialize error: %v", err)
+	}
+	want := []byte{0x1B, '@', 0x1B, 't', 71}
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, want) {
+		t.Errorf("Expected %X, got %X", want, got)
+	}
+}
+
+func TestSetCodeTable_Unsupported(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+
+	err := p.SetCodeTable(character.PC853)
+	if !errors.Is(err, profile.ErrUnsupportedCodeTable) {
+		t.Fatalf("Expected ErrUnsupportedCodeTable, got %v", err)
+	}
+	if len(conn.chunks) != 0 {
+		t.Errorf("Expected nothing written, got %X", bytes.Join(conn.chunks, nil))
+	}
+}
+
+func TestPrintLine_AutoCodeTables(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.CodeTable = character.WPC1252
+	p.Profile.AutoCodeTables = []character.CodeTable{character.WPC1252, character.PC737}
+
+	if err := p.PrintLine("€ Αθ €"); err != nil {
+		t.Fatalf("PrintLine error: %v", err)
+	}
+
+	want := []byte{
+		0x80, ' ',
+		0x1B, 't', byte(character.PC737), 0x80, 0x9F, ' ',
+		0x1B, 't', byte(character.WPC1252), 0x80,
+		0x0A,
+	}
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, want) {
+		t.Errorf("Expected %X, got %X", want, got)
+	}
+	if p.Profile.CodeTable != character.WPC1252 {
+		t.Errorf("Expected code table WPC1252, got %v", p.Profile.CodeTable)
+	}
+}
+
+func TestEnableUTF8(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.SupportsUTF8 = true
+	p.Profile.FontPriority = []character.FontFunction{character.AnkSansSerif, character.JapaneseGothic}
+
+	active, err := p.EnableUTF8()
+	if err != nil || !active {
+		t.Fatalf("EnableUTF8() = %v, %v", active, err)
+	}
+	if err := p.PrintLine("Ñandú €"); err != nil {
+		t.Fatalf("PrintLine error: %v", err)
+	}
+
+	want := []byte{
+		0x1C, '(', 'C', 0x02, 0x00, 0x30, 2,
+		0x1C, '(', 'C', 0x03, 0x00, 0x3C, 0, 0,
+		0x1C, '(', 'C', 0x03, 0x00, 0x3C, 1, 11,
+	}
+	want = append(want, "Ñandú €\n"...)
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, want) {
+		t.Errorf("Expected %X, got %X", want, got)
+	}
+}
+
+func TestEnableUTF8_Fallback(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.CodeTable = character.WPC1252
+
+	active, err := p.EnableUTF8()
+	if err != nil || active {
+		t.Fatalf("EnableUTF8() = %v, %v, want inactive", active, err)
+	}
+	if err := p.Print("€"); err != nil {
+		t.Fatalf("Print error: %v", err)
+	}
+
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, []byte{0x80}) {
+		t.Errorf("Expected 80, got %X", got)
+	}
+}
+
+func TestPrintLine_DoubleByte(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.CodeTable = character.PC850
+	p.Profile.DoubleByte = profile.DoubleByteGB18030
+
+	if err := p.PrintLine("Té 中文 1"); err != nil {
+		t.Fatalf("PrintLine error: %v", err)
+	}
+
+	want := []byte{'T', 0x82, ' ', 0x1C, '&', 0xD6, 0xD0, 0xCE, 0xC4, ' ', '1', 0x1C, '.', 0x0A}
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, want) {
+		t.Errorf("Expected %X, got %X", want, got)
+	}
+}
+
+func TestUserChars(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+
+	chars := []character.UserDefinedChar{
+		{Width: 1, Data: []byte{0x80, 0x00, 0x01}},
+		{Width: 0},
+	}
+	if err := p.DefineUserChars(3, ' ', chars); err != nil {
+		t.Fatalf("DefineUserChars error: %v", err)
+	}
+	if err := p.PrintUserChars([]byte{' ', '!'}); err != nil {
+		t.Fatalf("PrintUserChars error: %v", err)
+	}
+
+	want := []byte{
+		0x1B, '&', 3, ' ', '!', 1, 0x80, 0x00, 0x01, 0,
+		0x1B, '%', 1, ' ', '!', 0x1B, '%', 0,
+	}
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, want) {
+		t.Errorf("Expected %X, got %X", want, got)
+	}
+}
+
+func TestDefineUserChars_OutOfRange(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+
+	chars := make([]character.UserDefinedChar, 2)
+	err := p.DefineUserChars(3, character.UserDefinedMaxCode, chars)
+	if !errors.Is(err, character.ErrCodeRange) {
+		t.Fatalf("Expected ErrCodeRange, got %v", err)
+	}
+}
+
+func TestPrint_DoubleByteShiftJIS(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.DoubleByte = profile.DoubleByteShiftJIS
+
+	if err := p.Print("日本"); err != nil {
+		t.Fatalf("Print error: %v", err)
+	}
+
+	want := []byte{0x1C, 'C', 1, 0x1C, '&', 0x93, 0xFA, 0x96, 0x7B, 0x1C, '.'}
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, want) {
+		t.Errorf("Expected %X, got %X", want, got)
+	}
+}
+
+func TestPrintLine_Unencodable(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.CodeTable = character.PC437
+
+	if err := p.PrintLine("5€"); err == nil {
+		t.Fatal("expected encoding error with the default policy")
+	}
+
+	p.Profile.Unencodable = profile.UnencodableTransliterate
+	if err := p.PrintLine("5€ — ok"); err != nil {
+		t.Fatalf("PrintLine error: %v", err)
+	}
+	if got := bytes.Join(conn.chunks, nil); !bytes.Equal(got, []byte("5EUR - ok\n")) {
+		t.Errorf("Expected %q, got %q", "5EUR - ok\n", got)
+	}
+
+	subs := p.TakeSubstitutions()
+	if len(subs) != 2 || subs[0].Rune != '€' || subs[1].With != "-" {
+		t.Errorf("unexpected substitutions: %v", subs)
+	}
+	if subs := p.TakeSubstitutions(); subs != nil {
+		t.Errorf("substitutions should be cleared, got %v", subs)
+	}
+}
+
+func TestPrintBarcode_NonASCII(t *testing.T) {
+	conn := &recordingConnector{}
+	p := newRecordingPrinter(t, conn)
+	p.Profile.CodeTable = character.WPC1252
+	p.Profile.Unencodable = profile.UnencodableTransliterate
+
+	// é encodes in WPC1252, but barcode data is never rewritten
+	cfg := graphics.BarcodeConfig{Symbology: barcode.CODE128}
+	if err := p.PrintBarcode(cfg, []byte("CAFÉ-001")); err == nil {
+		t.Fatal("expected error for non-ASCII barcode data")
+	}
+	if len(conn.chunks) != 0 || p.TakeSubstitutions() != nil {
+		t.Errorf("nothing should be printed or substituted, got %q", conn.chunks)
+	}
+}
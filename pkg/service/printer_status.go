@@ -0,0 +1,90 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/adcondev/poster/pkg/connection"
+)
+
+// ErrStatusUnsupported is returned when the connection cannot read answers
+// from the printer (e.g. the Windows spooler).
+var ErrStatusUnsupported = errors.New("connection does not support status queries")
+
+// Real-time status requests: DLE EOT n
+const (
+	dle = 0x10
+	eot = 0x04
+
+	statusPrinter = 1 // Printer status
+	statusOffline = 2 // Offline cause
+	statusPaper   = 4 // Roll paper sensor
+)
+
+// Status is the real-time state reported by the printer
+type Status struct {
+	Online       bool `json:"online"`
+	CoverOpen    bool `json:"cover_open"`
+	PaperOut     bool `json:"paper_out"`
+	PaperNearEnd bool `json:"paper_near_end"`
+	Error        bool `json:"error"`
+}
+
+// ParseStatus decodes the answers to DLE EOT 1, 2 and 4
+func ParseStatus(printer, offline, paper byte) Status {
+	return Status{
+		Online:       printer&0x08 == 0,
+		CoverOpen:    offline&0x04 != 0,
+		PaperOut:     offline&0x20 != 0 || paper&0x60 != 0,
+		PaperNearEnd: paper&0x0C != 0,
+		Error:        offline&0x40 != 0,
+	}
+}
+
+// Status queries the printer with DLE EOT. The connection must implement
+// connection.StatusReader, otherwise ErrStatusUnsupported is returned.
+func (p *Printer) Status() (Status, error) {
+	return QueryStatus(p.Connection)
+}
+
+// QueryStatus sends DLE EOT 1, 2 and 4 on conn and parses the answers,
+// without the need of a Printer
+func QueryStatus(conn connection.Connector) (Status, error) {
+	reader, ok := conn.(connection.StatusReader)
+	if !ok {
+		return Status{}, ErrStatusUnsupported
+	}
+
+	var answers [3]byte
+	for i, n := range []byte{statusPrinter, statusOffline, statusPaper} {
+		if _, err := conn.Write([]byte{dle, eot, n}); err != nil {
+			return Status{}, fmt.Errorf("request status %d: %w", n, err)
+		}
+		read, err := reader.ReadStatus(answers[i : i+1])
+		if err == nil && read == 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return Status{}, fmt.Errorf("read status %d: %w", n, err)
+		}
+	}
+
+	return ParseStatus(answers[0], answers[1], answers[2]), nil
+}
+
+// Err describes why the printer cannot print, or returns nil when it is
+// ready. A paper near-end warning is not an error.
+func (s Status) Err() error {
+	switch {
+	case s.CoverOpen:
+		return errors.New("cover open")
+	case s.PaperOut:
+		return errors.New("paper out")
+	case s.Error:
+		return errors.New("printer error")
+	case !s.Online:
+		return errors.New("offline")
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/profile"
+)
+
+// statusConnector answers DLE EOT requests from a fixed list of bytes
+type statusConnector struct {
+	recordingConnector
+	answers *bytes.Reader
+}
+
+func (c *statusConnector) ReadStatus(p []byte) (int, error) {
+	return c.answers.Read(p)
+}
+
+func TestParseStatus(t *testing.T) {
+	tests := []struct {
+		name                    string
+		printer, offline, paper byte
+		want                    Status
+	}{
+		{"ready", 0x12, 0x12, 0x12, Status{Online: true}},
+		{"cover open", 0x1A, 0x16, 0x12, Status{CoverOpen: true}},
+		{"paper out", 0x1A, 0x32, 0x72, Status{PaperOut: true}},
+		{"near end", 0x12, 0x12, 0x1E, Status{Online: true, PaperNearEnd: true}},
+		{"error", 0x1A, 0x52, 0x12, Status{Error: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseStatus(tt.printer, tt.offline, tt.paper); got != tt.want {
+				t.Errorf("ParseStatus() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStatus_QueriesPrinter(t *testing.T) {
+	conn := &statusConnector{answers: bytes.NewReader([]byte{0x1A, 0x32, 0x72})}
+	p, err := NewPrinter(composer.NewEscpos(), profile.CreateProfile58mm(), conn)
+	if err != nil {
+		t.Fatalf("NewPrinter error: %v", err)
+	}
+
+	status, err := p.Status()
+	if err != nil {
+		t.Fatalf("Status() error: %v", err)
+	}
+	if !status.PaperOut || status.Online {
+		t.Errorf("Status() = %+v, want offline with paper out", status)
+	}
+
+	want := [][]byte{{0x10, 0x04, 1}, {0x10, 0x04, 2}, {0x10, 0x04, 4}}
+	for i, w := range want {
+		if !bytes.Equal(conn.chunks[i], w) {
+			t.Errorf("request %d = %v, want %v", i, conn.chunks[i], w)
+		}
+	}
+}
+
+func TestStatus_Unsupported(t *testing.T) {
+	p := newRecordingPrinter(t, &recordingConnector{})
+	if _, err := p.Status(); !errors.Is(err, ErrStatusUnsupported) {
+		t.Errorf("Status() error = %v, want ErrStatusUnsupported", err)
+	}
+}
+
+func TestStatus_Err(t *testing.T) {
+	tests := []struct {
+		name   string
+		status Status
+		want   string
+	}{
+		{"ready", Status{Online: true}, ""},
+		{"near end is not an error", Status{Online: true, PaperNearEnd: true}, ""},
+		{"paper out", Status{PaperOut: true}, "paper out"},
+		{"cover open", Status{CoverOpen: true}, "cover open"},
+		{"offline", Status{}, "offline"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.status.Err()
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != tt.want {
+				t.Errorf("Err() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -6,9 +6,14 @@
 // # Core Features
 //
 //   - Dynamic column width calculation with configurable spacing
+//   - Percentage, flexible ("*") and content-sized ("auto") column widths
 //   - Automatic word wrapping for long text content
 //   - Multiple column alignment options (left, center, right)
 //   - Bold header styling with ESC/POS command injection
+//   - Per-column and per-row styles (bold, underline, size, font)
+//   - Cells spanning several columns and horizontal rules
+//   - Totals row with decimal-exact sums of the marked columns
+//   - Number and currency columns (numfmt) with decimal-point alignment
 //   - Hardware-aware validation against paper width limits
 //
 // # Overflow Protection
@@ -56,6 +61,33 @@
 //	var buf strings.Builder
 //	engine.Render(&buf, data)
 //
+// # Relative Widths
+//
+// A column width can also be a percentage of the line, a share of the space
+// the other columns leave, or the width of its longest cell. ResolveWidths
+// turns them into characters for the printer, so one definition fits both
+// 58mm and 80mm paper:
+//
+//	{"name": "Qty", "width": "auto"}
+//	{"name": "Item", "width": "*"}
+//	{"name": "Price", "width": "25%"}
+//
+//	data.ResolveWidths(profile.Columns("A"), opts.ColumnSpacing, "A")
+//
+// # Styles, Spans and Totals
+//
+// Column widths always count characters of Options.Font; a wider style fits
+// fewer characters in the same column. A row format gives a row its own
+// style or cells that span columns, and Totals adds a last row with the sums
+// of the columns marked Total:
+//
+//	definition.Columns[1].Total = true
+//	data.Formats = map[int]tables.RowFormat{
+//	    2: {Spans: []int{2}, Style: &tables.Style{Size: "2x1"}},
+//	}
+//	data.Totals = &tables.Totals{Label: "TOTAL"}
+//	opts.TotalsRule = "="
+//
 // # Auto-Reduction Example
 //
 // When columns exceed paper width, the ReduceToFit function automatically
@@ -74,6 +106,10 @@
 //
 //   - table_types.go: Core types (Column, Definition, Row, Data) and validation
 //   - table_engine.go: TabEngine for rendering tables to io.Writer
+//   - table_style.go: Cell styles and their ESC/POS commands
+//   - table_totals.go: Totals row and amount parsing
+//   - table_width.go: Relative column widths and their resolution
+//   - table_numbers.go: Formatted number columns and decimal alignment
 //   - table_text.go: Text utilities (WrapText, PadString)
 //   - table_reduce.go: Auto-reduction algorithm for overflow protection
 package tables
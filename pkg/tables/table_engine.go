@@ -8,6 +8,7 @@ import (
 
 	"github.com/adcondev/poster/pkg/commands/print"
 	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/constants"
 )
 
 // Paper width constants (in characters)
@@ -16,20 +17,23 @@ const (
 	Width80mm203dpi = 48 // Conservative for 80mm
 )
 
-// Style represents text styling options
-type Style struct {
-	Bold       bool
-	DoubleSize bool
-	Underline  bool
-}
-
 // Options configures the table engine
 type Options struct {
-	PaperWidth    int   // Total width in characters
-	ShowHeaders   bool  // Whether to show column headers
-	HeaderStyle   Style // Style for headers
-	WordWrap      bool  // Enable automatic word wrapping
-	ColumnSpacing int   // Spaces between columns (default: 1)
+	PaperWidth    int    // Total width in characters
+	ShowHeaders   bool   // Whether to show column headers
+	HeaderStyle   Style  // Style for headers
+	WordWrap      bool   // Enable automatic word wrapping
+	ColumnSpacing int    // Spaces between columns (default: 1)
+	Font          string // Font the column widths are counted in, "A" (default) or "B"
+
+	// Rules are lines of the given character across the table, "" for none
+	HeaderRule string // After the header
+	RowRule    string // Between rows
+	TotalsRule string // Before the totals row
+
+	// Visual converts each cell line to display order before padding, e.g.
+	// bidi reordering of Hebrew and Arabic text. Nil keeps the text as is.
+	Visual func(string) string
 }
 
 // DefaultOptions returns sensible defaults for 80mm printers
@@ -49,6 +53,18 @@ type TabEngine struct {
 	options    *Options
 }
 
+// slot is the place of a cell on a line: one column, or several spanned
+type slot struct {
+	width int // In characters of the base font, spanned gaps included
+	align constants.Alignment
+	style Style
+
+	// Decimal alignment: the separator and the widest fraction of the
+	// column, -1 for other cells
+	decimal  string
+	fraction int
+}
+
 // NewEngine creates a new table engine
 func NewEngine(def *Definition, opts *Options) *TabEngine {
 	if opts == nil {
@@ -78,30 +94,73 @@ func (te *TabEngine) Render(w io.Writer, data *Data) error {
 		def = &data.Definition
 	}
 
+	writeLine := func(line string) error {
+		_, err := w.Write([]byte(line + string(print.LF)))
+		return err
+	}
+	writeRow := func(row Row, slots []slot) error {
+		lines := [][]string{row}
+		if te.options.WordWrap {
+			lines = te.wrapRow(row, slots)
+		}
+		for _, cells := range lines {
+			if err := writeLine(te.formatRow(cells, slots)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Raw numbers are formatted and totalled before anything is written
+	rows, err := data.displayRows(def)
+	if err != nil {
+		return err
+	}
+	var totals Row
+	var totalSpans []int
+	if data.Totals != nil {
+		if totals, totalSpans, err = totalsRow(def, data); err != nil {
+			return fmt.Errorf("totals: %w", err)
+		}
+	}
+	fractions := decimalFractions(def, rows, data.Formats, totals, totalSpans)
+
 	// Headers
 	if te.options.ShowHeaders || data.ShowHeaders {
 		headerLine := te.formatHeaderRow(te.makeHeaderRow(def), def)
-		if _, err := w.Write([]byte(headerLine + string(print.LF))); err != nil {
+		if err := writeLine(headerLine); err != nil {
 			return err
 		}
+		if te.options.HeaderRule != "" {
+			if err := writeLine(te.rule(def, te.options.HeaderRule)); err != nil {
+				return err
+			}
+		}
 	}
 
 	// Data rows (without blank lines between them)
-	for _, row := range data.Rows {
-		if te.options.WordWrap {
-			wrapped := te.wrapRow(row, def)
-			for _, wr := range wrapped {
-				line := te.formatRow(wr, def)
-				if _, err := w.Write([]byte(line + string(print.LF))); err != nil {
-					return err
-				}
+	for i, row := range rows {
+		if i > 0 && te.options.RowRule != "" {
+			if err := writeLine(te.rule(def, te.options.RowRule)); err != nil {
+				return err
 			}
-		} else {
-			line := te.formatRow(row, def)
-			if _, err := w.Write([]byte(line + string(print.LF))); err != nil {
+		}
+		format := data.Formats[i]
+		if err := writeRow(row, te.slots(def, format.Spans, format.Style, fractions)); err != nil {
+			return err
+		}
+	}
+
+	// Totals
+	if data.Totals != nil {
+		if te.options.TotalsRule != "" {
+			if err := writeLine(te.rule(def, te.options.TotalsRule)); err != nil {
 				return err
 			}
 		}
+		if err := writeRow(totals, te.slots(def, totalSpans, data.Totals.style(), fractions)); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -118,8 +177,12 @@ func (te *TabEngine) formatHeaderRow(cells []string, def *Definition) string {
 		result.WriteString(string(cmds.EnableBold())) // ESC E 1 (Bold ON)
 	}
 
-	// Format header cells
-	result.WriteString(te.formatRow(cells, def))
+	// Format header cells; only the column font applies to headers
+	slots := te.slots(def, nil, nil, nil)
+	for i := range slots {
+		slots[i].style = Style{Font: slots[i].style.Font}
+	}
+	result.WriteString(te.formatRow(cells, slots))
 
 	// Reset bold at the end if it was enabled
 	if te.options.HeaderStyle.Bold {
@@ -129,21 +192,78 @@ func (te *TabEngine) formatHeaderRow(cells []string, def *Definition) string {
 	return result.String()
 }
 
-// TODO: Consider row styles in the future
+// slots lays out the cells of a row: one per column without spans, or one
+// per span. A cell takes the alignment and style of its first column, with
+// the row style over it. Cells of one decimal-aligned column line up on the
+// fractions given; other decimal cells are right-aligned.
+func (te *TabEngine) slots(def *Definition, spans []int, rowStyle *Style, fractions []int) []slot {
+	if len(spans) == 0 {
+		spans = make([]int, len(def.Columns))
+		for i := range spans {
+			spans[i] = 1
+		}
+	}
+
+	slots := make([]slot, 0, len(spans))
+	col := 0
+	for _, span := range spans {
+		width := (span - 1) * te.options.ColumnSpacing
+		for _, c := range def.Columns[col : col+span] {
+			width += c.Width
+		}
+		first := def.Columns[col]
+		s := slot{
+			width:    width,
+			align:    first.Align,
+			style:    Style{}.merge(first.Style).merge(rowStyle),
+			fraction: -1,
+		}
+		if s.align == AlignDecimal {
+			s.align = constants.Right
+			if span == 1 && fractions != nil {
+				s.decimal, s.fraction = first.Spec.DecimalSeparator(), fractions[col]
+			}
+		}
+		slots = append(slots, s)
+		col += span
+	}
+	return slots
+}
 
-// formatRow formats a regular data row without styling
-func (te *TabEngine) formatRow(cells []string, def *Definition) string {
+// formatRow formats one line of a row. Styled cells are wrapped in their
+// ESC/POS commands and hold fewer characters when wider or in another font.
+func (te *TabEngine) formatRow(cells []string, slots []slot) string {
 	var result strings.Builder
 
 	for i, cell := range cells {
-		if i < len(def.Columns) {
-			padded := PadString(cell, def.Columns[i].Width, def.Columns[i].Align)
-			result.WriteString(padded)
-
-			// Add spacing between columns
-			if i < len(cells)-1 {
-				result.WriteString(strings.Repeat(" ", te.options.ColumnSpacing))
+		if i >= len(slots) {
+			break
+		}
+		s := slots[i]
+		chars, leftover := s.style.capacity(s.width, te.options.Font)
+		if te.options.Visual != nil {
+			// Truncate in logical order, then reorder
+			if runes := []rune(cell); len(runes) > chars {
+				cell = string(runes[:chars])
 			}
+			cell = te.options.Visual(cell)
+		}
+		if s.fraction >= 0 {
+			cell = alignDecimal(cell, s.decimal, s.fraction)
+		}
+		padded := s.style.on(te.options.Font) + PadString(cell, chars, s.align) + s.style.off(te.options.Font)
+
+		// Space the styled text leaves is filled in the base font
+		fill := strings.Repeat(" ", leftover)
+		if s.align == constants.Right {
+			result.WriteString(fill + padded)
+		} else {
+			result.WriteString(padded + fill)
+		}
+
+		// Add spacing between columns
+		if i < len(cells)-1 {
+			result.WriteString(strings.Repeat(" ", te.options.ColumnSpacing))
 		}
 	}
 
@@ -151,13 +271,14 @@ func (te *TabEngine) formatRow(cells []string, def *Definition) string {
 }
 
 // wrapRow handles word wrapping for a single row
-func (te *TabEngine) wrapRow(row Row, def *Definition) [][]string {
+func (te *TabEngine) wrapRow(row Row, slots []slot) [][]string {
 	wrappedCells := make([][]string, len(row))
 	maxLines := 0
 
 	for i, cell := range row {
-		if i < len(def.Columns) {
-			wrapped := WrapText(cell, def.Columns[i].Width)
+		if i < len(slots) {
+			chars, _ := slots[i].style.capacity(slots[i].width, te.options.Font)
+			wrapped := WrapText(cell, chars)
 			wrappedCells[i] = wrapped
 			if len(wrapped) > maxLines {
 				maxLines = len(wrapped)
@@ -169,7 +290,7 @@ func (te *TabEngine) wrapRow(row Row, def *Definition) [][]string {
 	for lineIdx := 0; lineIdx < maxLines; lineIdx++ {
 		result[lineIdx] = make([]string, len(row))
 		for colIdx := range row {
-			if colIdx < len(def.Columns) && lineIdx < len(wrappedCells[colIdx]) {
+			if colIdx < len(slots) && lineIdx < len(wrappedCells[colIdx]) {
 				result[lineIdx][colIdx] = wrappedCells[colIdx][lineIdx]
 			} else {
 				// Empty string for missing cells
@@ -181,6 +302,16 @@ func (te *TabEngine) wrapRow(row Row, def *Definition) [][]string {
 	return result
 }
 
+// rule returns a line of char across the columns of the table
+func (te *TabEngine) rule(def *Definition, char string) string {
+	width := (len(def.Columns) - 1) * te.options.ColumnSpacing
+	for _, col := range def.Columns {
+		width += col.Width
+	}
+	line := []rune(strings.Repeat(char, width))
+	return string(line[:width])
+}
+
 // makeHeaderRow creates header row from column definitions
 func (te *TabEngine) makeHeaderRow(def *Definition) []string {
 	headers := make([]string, len(def.Columns))
@@ -176,3 +176,101 @@ func TestRender(t *testing.T) {
 		})
 	}
 }
+
+func TestRender_StylesRulesAndTotals(t *testing.T) {
+	def := Definition{
+		Columns: []Column{
+			{Name: "Item", Width: 10, Align: constants.Left},
+			{Name: "Qty", Width: 3, Align: constants.Right, Total: true},
+			{Name: "Total", Width: 9, Align: constants.Right, Style: &Style{Bold: true}, Total: true},
+		},
+	}
+	data := &Data{
+		Definition: def,
+		Rows: []Row{
+			{"Coffee", "2", "$7.00"},
+			{"Sandwich", "1", "$1,008.50"},
+			{"Thanks for your visit"},
+		},
+		Formats: map[int]RowFormat{2: {Spans: []int{3}, Style: &Style{Size: "2x1"}}},
+		Totals:  &Totals{},
+	}
+	opts := DefaultOptions()
+	opts.ShowHeaders = false
+	opts.RowRule = "-"
+	opts.TotalsRule = "="
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEngine(&def, opts).Render(&buf, data))
+
+	want := "Coffee       2 \x1bE\x01    $7.00\x1bE\x00\n" +
+		"------------------------\n" +
+		"Sandwich     1 \x1bE\x01$1,008.50\x1bE\x00\n" +
+		"------------------------\n" +
+		// 24 columns at double width hold 12 characters
+		"\x1d!\x10Thanks for  \x1d!\x00\n" +
+		"\x1d!\x10your visit  \x1d!\x00\n" +
+		"========================\n" +
+		"\x1bE\x01TOTAL     \x1bE\x00 \x1bE\x01  3\x1bE\x00 \x1bE\x01$1,015.50\x1bE\x00\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestRender_TotalsLabelAfterTotalColumns(t *testing.T) {
+	// Column order of a mirrored right-to-left table
+	def := Definition{
+		Columns: []Column{
+			{Name: "Total", Width: 6, Align: constants.Left, Total: true},
+			{Name: "Qty", Width: 3, Align: constants.Left, Total: true},
+			{Name: "Item", Width: 10, Align: constants.Right},
+		},
+	}
+	data := &Data{
+		Definition: def,
+		Rows:       []Row{{"$7.00", "2", "Coffee"}, {"$3.50", "1", "Tea"}},
+		Totals:     &Totals{},
+	}
+	opts := DefaultOptions()
+	opts.ShowHeaders = false
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEngine(&def, opts).Render(&buf, data))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, "\x1bE\x01$10.50\x1bE\x00 \x1bE\x013  \x1bE\x00 \x1bE\x01     TOTAL\x1bE\x00", lines[len(lines)-1])
+}
+
+func TestRender_FontB(t *testing.T) {
+	def := Definition{
+		Columns: []Column{
+			{Name: "Item", Width: 6, Align: constants.Left, Style: &Style{Font: "B"}},
+			{Name: "Qty", Width: 3, Align: constants.Right},
+		},
+	}
+	opts := DefaultOptions()
+	opts.HeaderStyle = Style{}
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEngine(&def, opts).Render(&buf, &Data{Definition: def, Rows: []Row{{"Espresso", "1"}}}))
+
+	// Six Font A columns (72 dots) hold eight Font B characters
+	assert.Equal(t, "\x1bM\x01Item    \x1bM\x00 Qty\n\x1bM\x01Espresso\x1bM\x00   1\n", buf.String())
+}
+
+func TestData_ValidateSpansAndTotals(t *testing.T) {
+	def := Definition{Columns: []Column{{Name: "A", Width: 5}, {Name: "B", Width: 5}}}
+	tests := []struct {
+		name string
+		data Data
+		want string
+	}{
+		{"spans miss a cell", Data{Definition: def, Rows: []Row{{"a"}}, Formats: map[int]RowFormat{0: {Spans: []int{1, 1}}}}, "1 cells but 2 spans"},
+		{"spans too wide", Data{Definition: def, Rows: []Row{{"a"}}, Formats: map[int]RowFormat{0: {Spans: []int{3}}}}, "cover 3 columns"},
+		{"zero span", Data{Definition: def, Rows: []Row{{"a", "b"}}, Formats: map[int]RowFormat{0: {Spans: []int{0, 2}}}}, "invalid span 0"},
+		{"totals without total columns", Data{Definition: def, Totals: &Totals{}}, "at least one column with total"},
+	}
+	for _, tt := range tests {
+		err := tt.data.Validate()
+		require.Error(t, err, tt.name)
+		assert.Contains(t, err.Error(), tt.want, tt.name)
+	}
+}
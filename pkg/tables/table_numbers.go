@@ -0,0 +1,101 @@
+package tables
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// displayRows returns the rows with the raw numbers of formatted columns
+// written by their spec. Rows with spans are left as they are.
+func (dt *Data) displayRows(def *Definition) ([]Row, error) {
+	formatted := false
+	for _, col := range def.Columns {
+		formatted = formatted || !col.Spec.IsZero()
+	}
+	if !formatted {
+		return dt.Rows, nil
+	}
+
+	rows := make([]Row, len(dt.Rows))
+	for r, row := range dt.Rows {
+		if len(dt.Formats[r].Spans) > 0 {
+			rows[r] = row
+			continue
+		}
+		rows[r] = make(Row, len(row))
+		for i, cell := range row {
+			if i >= len(def.Columns) {
+				rows[r][i] = cell
+				continue
+			}
+			value, err := def.Columns[i].Spec.Format(cell)
+			if err != nil {
+				return nil, fmt.Errorf("row %d, column '%s': %w", r, def.Columns[i].Name, err)
+			}
+			rows[r][i] = value
+		}
+	}
+	return rows, nil
+}
+
+// decimalFractions returns, for each decimal-aligned column, the widest
+// fraction of its cells: the decimal separator and what follows it. Other
+// columns get -1.
+func decimalFractions(def *Definition, rows []Row, formats map[int]RowFormat, totals Row, totalSpans []int) []int {
+	fractions := make([]int, len(def.Columns))
+	for i, col := range def.Columns {
+		fractions[i] = -1
+		if col.Align != AlignDecimal {
+			continue
+		}
+		fractions[i] = 0
+		sep := col.Spec.DecimalSeparator()
+		for r, row := range rows {
+			if len(formats[r].Spans) == 0 && i < len(row) {
+				fractions[i] = max(fractions[i], fractionWidth(row[i], sep))
+			}
+		}
+		fractions[i] = max(fractions[i], fractionWidth(spannedCell(totals, totalSpans, i), sep))
+	}
+	return fractions
+}
+
+// spannedCell returns the cell that starts at column index and spans only
+// that column, "" when there is none
+func spannedCell(row Row, spans []int, index int) string {
+	col := 0
+	for k, span := range spans {
+		if col == index && span == 1 && k < len(row) {
+			return row[k]
+		}
+		col += span
+	}
+	return ""
+}
+
+// fractionWidth returns the characters from the decimal separator to the
+// end of cell, or the trailing non-digits (e.g. ")" or " €") of a cell
+// without separator
+func fractionWidth(cell, sep string) int {
+	cell = strings.TrimRight(cell, " ")
+	if i := strings.LastIndex(cell, sep); i > 0 && strings.IndexFunc(cell[:i], unicode.IsDigit) >= 0 {
+		return utf8.RuneCountInString(cell[i:])
+	}
+	end := strings.LastIndexFunc(cell, unicode.IsDigit)
+	if end < 0 {
+		return 0
+	}
+	return utf8.RuneCountInString(cell[end+1:])
+}
+
+// alignDecimal pads cell on the right so its decimal separator lines up with
+// the widest fraction of its column
+func alignDecimal(cell, sep string, fraction int) string {
+	cell = strings.TrimRight(cell, " ")
+	if cell == "" {
+		return cell
+	}
+	return cell + strings.Repeat(" ", max(fraction-fractionWidth(cell, sep), 0))
+}
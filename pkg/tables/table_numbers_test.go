@@ -0,0 +1,84 @@
+package tables
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/numfmt"
+)
+
+func TestRender_CurrencyColumns(t *testing.T) {
+	var def Definition
+	require.NoError(t, json.Unmarshal([]byte(`{"columns":[
+		{"name":"Item","width":8,"align":"left"},
+		{"name":"Amount","width":11,"align":"decimal","format":"currency","negative":"parentheses","total":true}
+	]}`), &def))
+
+	data := &Data{
+		Definition: def,
+		Rows: []Row{
+			{"Coffee", "35.5"},
+			{"Refund", "-1200"},
+			{"Tip", "7"},
+		},
+		Totals: &Totals{},
+	}
+	opts := DefaultOptions()
+	opts.ShowHeaders = false
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEngine(&def, opts).Render(&buf, data))
+
+	// Dots line up; the closing parenthesis hangs past them
+	want := "Coffee       $35.50 \n" +
+		"Refund   ($1,200.00)\n" +
+		"Tip           $7.00 \n" +
+		"\x1bE\x01TOTAL   \x1bE\x00 \x1bE\x01($1,157.50)\x1bE\x00\n"
+	assert.Equal(t, want, buf.String())
+}
+
+func TestRender_DecimalAlignPlainColumn(t *testing.T) {
+	def := Definition{Columns: []Column{
+		{Name: "Qty", Width: 8, Align: AlignDecimal},
+	}}
+	opts := DefaultOptions()
+
+	var buf bytes.Buffer
+	require.NoError(t, NewEngine(&def, opts).Render(&buf, &Data{
+		Definition: def,
+		Rows:       []Row{{"1.5"}, {"12"}, {"0.125"}},
+	}))
+
+	assert.Equal(t, "\x1bE\x01     Qty\x1bE\x00\n   1.5  \n  12    \n   0.125\n", buf.String())
+}
+
+func TestRender_FormatErrors(t *testing.T) {
+	def := Definition{Columns: []Column{
+		{Name: "Amount", Width: 10, Align: constants.Right, Spec: numfmt.Spec{Kind: numfmt.Currency}},
+	}}
+
+	err := NewEngine(&def, nil).Render(&bytes.Buffer{}, &Data{Definition: def, Rows: []Row{{"$5.00"}}})
+	assert.ErrorContains(t, err, `row 0, column 'Amount': invalid number "$5.00"`)
+
+	def.Columns[0].Spec.Locale = "xx"
+	err = NewEngine(&def, nil).Render(&bytes.Buffer{}, &Data{Definition: def, Rows: []Row{{"5"}}})
+	assert.ErrorContains(t, err, `column 'Amount': unknown locale "xx"`)
+}
+
+func TestData_ResolveWidths_Formatted(t *testing.T) {
+	data := &Data{
+		Definition: Definition{Columns: []Column{
+			{Name: "Amount", Sizing: Sizing{Mode: WidthAuto}, Align: AlignDecimal, Spec: numfmt.Spec{Kind: numfmt.Number}},
+		}},
+		Rows: []Row{{"1234567"}, {"0.125"}},
+	}
+
+	// "1,234,567" and ".125" line up in 13 characters
+	require.NoError(t, data.ResolveWidths(48, 1, "A"))
+	assert.Equal(t, 13, data.Definition.Columns[0].Width)
+}
@@ -0,0 +1,150 @@
+package tables
+
+import (
+	"strings"
+
+	"github.com/adcondev/poster/pkg/composer"
+	"github.com/adcondev/poster/pkg/constants"
+)
+
+// Style represents text styling options
+type Style struct {
+	Bold       bool   `json:"bold,omitempty"`
+	DoubleSize bool   `json:"-"` // Same as Size "2x2"
+	Underline  bool   `json:"underline,omitempty"`
+	Size       string `json:"size,omitempty"` // "WxH", e.g. "2x1"
+	Font       string `json:"font,omitempty"` // "A" or "B"
+}
+
+// merge returns s with the fields set in over added; over wins for size and
+// font
+func (s Style) merge(over *Style) Style {
+	if over == nil {
+		return s
+	}
+	s.Bold = s.Bold || over.Bold
+	s.Underline = s.Underline || over.Underline
+	if over.DoubleSize || over.Size != "" {
+		s.DoubleSize, s.Size = over.DoubleSize, over.Size
+	}
+	if over.Font != "" {
+		s.Font = over.Font
+	}
+	return s
+}
+
+// multipliers returns the width and height multipliers of the style size,
+// 1x1 when unset or invalid
+func (s Style) multipliers() (width, height int) {
+	if s.Size == "" {
+		if s.DoubleSize {
+			return 2, 2
+		}
+		return 1, 1
+	}
+	ss := strings.ToLower(s.Size)
+	if len(ss) == 3 && ss[1] == 'x' {
+		w, h := int(ss[0]-'0'), int(ss[2]-'0')
+		if w >= constants.MinScale && w <= constants.MaxScale && h >= constants.MinScale && h <= constants.MaxScale {
+			return w, h
+		}
+	}
+	return 1, 1
+}
+
+// isFontB reports whether font names Font B
+func isFontB(font string) bool {
+	return strings.EqualFold(font, "B")
+}
+
+// fontDots returns the character width in dots of a font
+func fontDots(font string) int {
+	if isFontB(font) {
+		return constants.FontBWidth
+	}
+	return constants.FontAWidth
+}
+
+// plain reports whether the style leaves text as the base font prints it
+func (s Style) plain(baseFont string) bool {
+	w, h := s.multipliers()
+	return !s.Bold && !s.Underline && w == 1 && h == 1 && (s.Font == "" || isFontB(s.Font) == isFontB(baseFont))
+}
+
+// capacity returns how many characters of the style fit in width characters
+// of the base font, and the base font spaces left over
+func (s Style) capacity(width int, baseFont string) (chars, leftover int) {
+	font := baseFont
+	if s.Font != "" {
+		font = s.Font
+	}
+	w, _ := s.multipliers()
+	dots := width * fontDots(baseFont)
+	chars = dots / (fontDots(font) * w)
+	leftover = (dots - chars*fontDots(font)*w) / fontDots(baseFont)
+	return chars, leftover
+}
+
+// needs returns the base font characters that chars characters of the style
+// take up, the inverse of capacity
+func (s Style) needs(chars int, baseFont string) int {
+	font := baseFont
+	if s.Font != "" {
+		font = s.Font
+	}
+	w, _ := s.multipliers()
+	dots := chars * fontDots(font) * w
+	return (dots + fontDots(baseFont) - 1) / fontDots(baseFont)
+}
+
+// on returns the ESC/POS commands that switch from the base font to the style
+func (s Style) on(baseFont string) string {
+	if s.plain(baseFont) {
+		return ""
+	}
+	cmds := composer.NewEscpos()
+	var b strings.Builder
+	if s.Bold {
+		b.Write(cmds.EnableBold())
+	}
+	if s.Underline {
+		b.Write(cmds.OneDotUnderline())
+	}
+	if w, h := s.multipliers(); w != 1 || h != 1 {
+		b.Write(cmds.CustomSizeText(byte(w), byte(h)))
+	}
+	if s.Font != "" && isFontB(s.Font) != isFontB(baseFont) {
+		b.Write(fontCommand(s.Font))
+	}
+	return b.String()
+}
+
+// off returns the ESC/POS commands that switch back to the base font
+func (s Style) off(baseFont string) string {
+	if s.plain(baseFont) {
+		return ""
+	}
+	cmds := composer.NewEscpos()
+	var b strings.Builder
+	if s.Bold {
+		b.Write(cmds.DisableBold())
+	}
+	if s.Underline {
+		b.Write(cmds.DisableUnderline())
+	}
+	if w, h := s.multipliers(); w != 1 || h != 1 {
+		b.Write(cmds.SingleSizeText())
+	}
+	if s.Font != "" && isFontB(s.Font) != isFontB(baseFont) {
+		b.Write(fontCommand(baseFont))
+	}
+	return b.String()
+}
+
+// fontCommand returns ESC M for the named font
+func fontCommand(font string) []byte {
+	if isFontB(font) {
+		return composer.NewEscpos().SetFontB()
+	}
+	return composer.NewEscpos().SetFontA()
+}
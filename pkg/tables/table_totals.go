@@ -0,0 +1,166 @@
+package tables
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultTotalsLabel is the label of a totals row without one
+const DefaultTotalsLabel = "TOTAL"
+
+// amount is a number read from a cell, with the text around it
+type amount struct {
+	value    *big.Rat
+	decimals int
+	grouped  bool   // Thousands separated with ','
+	prefix   string // e.g. "$"
+	suffix   string // e.g. " MXN"
+}
+
+// parseAmount reads a cell like "$1,234.50", "-3" or "12.5 kg". The decimal
+// separator is '.' and ',' groups thousands.
+func parseAmount(cell string) (amount, error) {
+	start := strings.IndexAny(cell, "0123456789")
+	end := strings.LastIndexAny(cell, "0123456789")
+	if start < 0 {
+		return amount{}, fmt.Errorf("%q is not a number", cell)
+	}
+
+	a := amount{prefix: cell[:start], suffix: cell[end+1:]}
+	negative := strings.Contains(a.prefix, "-")
+	a.prefix = strings.TrimSpace(strings.Replace(a.prefix, "-", "", 1))
+
+	digits := cell[start : end+1]
+	a.grouped = strings.Contains(digits, ",")
+	digits = strings.ReplaceAll(digits, ",", "")
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		a.decimals = len(digits) - i - 1
+	}
+	value, ok := new(big.Rat).SetString(digits)
+	if !ok {
+		return amount{}, fmt.Errorf("%q is not a number", cell)
+	}
+	if negative {
+		value.Neg(value)
+	}
+	a.value = value
+	return a, nil
+}
+
+// sumCells adds the numbers of a column. The sum keeps the prefix and suffix
+// of the first number, the most decimals seen and thousands grouping when
+// any cell used it. Empty cells are skipped.
+func sumCells(cells []string) (string, error) {
+	var sum amount
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) == "" {
+			continue
+		}
+		a, err := parseAmount(cell)
+		if err != nil {
+			return "", err
+		}
+		if sum.value == nil {
+			sum = a
+			sum.value = new(big.Rat).Set(a.value)
+			continue
+		}
+		sum.value.Add(sum.value, a.value)
+		sum.decimals = max(sum.decimals, a.decimals)
+		sum.grouped = sum.grouped || a.grouped
+	}
+	if sum.value == nil {
+		return "", nil
+	}
+	return sum.format(), nil
+}
+
+// format writes the amount with its prefix, suffix, decimals and grouping
+func (a amount) format() string {
+	digits := new(big.Rat).Abs(a.value).FloatString(a.decimals)
+	if a.grouped {
+		whole, frac, found := strings.Cut(digits, ".")
+		digits = groupThousands(whole)
+		if found {
+			digits += "." + frac
+		}
+	}
+	sign := ""
+	if a.value.Sign() < 0 {
+		sign = "-"
+	}
+	return sign + a.prefix + digits + a.suffix
+}
+
+// groupThousands inserts ',' every three digits from the right
+func groupThousands(digits string) string {
+	var b strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// totalsRow builds the totals row of the table: the label spans the columns
+// before the first total column, followed by one cell per column. When the
+// total columns come first, as in mirrored right-to-left tables, the label
+// spans the columns after the last one instead.
+func totalsRow(def *Definition, data *Data) (Row, []int, error) {
+	first, last := -1, -1
+	for i, col := range def.Columns {
+		if col.Total {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	label := data.Totals.Label
+	if label == "" {
+		label = DefaultTotalsLabel
+	}
+
+	var row Row
+	var spans []int
+	end := len(def.Columns)
+	switch {
+	case first > 0:
+		row = append(row, label)
+		spans = append(spans, first)
+	case first == 0 && last < end-1:
+		end = last + 1
+	}
+	for i := max(first, 0); i < end; i++ {
+		cell := ""
+		if def.Columns[i].Total {
+			column := make([]string, 0, len(data.Rows))
+			for r, cells := range data.Rows {
+				// Rows with spans don't line up with the columns
+				if len(data.Formats[r].Spans) == 0 {
+					column = append(column, cells[i])
+				}
+			}
+			sum, err := sumCells(column)
+			if !def.Columns[i].Spec.IsZero() {
+				// Raw numbers are summed before they are formatted
+				sum, err = def.Columns[i].Spec.Sum(column)
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("column '%s': %w", def.Columns[i].Name, err)
+			}
+			cell = sum
+		}
+		row = append(row, cell)
+		spans = append(spans, 1)
+	}
+	if end < len(def.Columns) {
+		row = append(row, label)
+		spans = append(spans, len(def.Columns)-end)
+	}
+	return row, spans, nil
+}
@@ -0,0 +1,30 @@
+package tables
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSumCells(t *testing.T) {
+	tests := []struct {
+		cells []string
+		want  string
+	}{
+		{[]string{"1", "2", "3"}, "6"},
+		{[]string{"$0.10", "$0.20"}, "$0.30"},
+		{[]string{"$1,000.5", "", "$24.25"}, "$1,024.75"},
+		{[]string{"12.5 kg", "0.25 kg"}, "12.75 kg"},
+		{[]string{"$5.00", "-$7.50"}, "-$2.50"},
+		{[]string{"", " "}, ""},
+	}
+	for _, tt := range tests {
+		got, err := sumCells(tt.cells)
+		require.NoError(t, err, tt.cells)
+		assert.Equal(t, tt.want, got, tt.cells)
+	}
+
+	_, err := sumCells([]string{"1", "n/a"})
+	assert.ErrorContains(t, err, `"n/a" is not a number`)
+}
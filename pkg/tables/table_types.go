@@ -4,13 +4,26 @@ import (
 	"fmt"
 
 	"github.com/adcondev/poster/pkg/constants"
+	"github.com/adcondev/poster/pkg/numfmt"
 )
 
+// AlignDecimal right-aligns the cells of a column on their decimal separator
+const AlignDecimal constants.Alignment = "decimal"
+
 // Column defines a table column configuration
 type Column struct {
-	Name  string              `json:"name"`
-	Width int                 `json:"width"`
-	Align constants.Alignment `json:"align"`
+	Name     string              `json:"name"`
+	Width    int                 `json:"width"` // Characters; set by ResolveWidths for relative widths
+	Sizing   Sizing              `json:"-"`     // Relative width, from a "width" string in JSON
+	MinWidth int                 `json:"min_width,omitempty"`
+	MaxWidth int                 `json:"max_width,omitempty"`
+	Align    constants.Alignment `json:"align"`
+	Style    *Style              `json:"style,omitempty"` // Style of the column cells
+	Total    bool                `json:"total,omitempty"` // Summed in the totals row
+
+	// Raw numbers in the cells are written with the spec, e.g.
+	// "format": "currency"
+	numfmt.Spec
 }
 
 // Definition defines the structure of a table
@@ -22,11 +35,33 @@ type Definition struct {
 // Row represents a single row of data
 type Row []string
 
+// RowFormat overrides the style of a row and lets its cells span columns
+type RowFormat struct {
+	Style *Style `json:"style,omitempty"`
+	Spans []int  `json:"spans,omitempty"` // Columns covered by each cell
+}
+
+// Totals is a footer row with the sums of the columns marked Total
+type Totals struct {
+	Label string `json:"label,omitempty"` // Default: "TOTAL"
+	Style *Style `json:"style,omitempty"` // Default: bold
+}
+
+// style returns the style of the totals row
+func (t *Totals) style() *Style {
+	if t.Style == nil {
+		return &Style{Bold: true}
+	}
+	return t.Style
+}
+
 // Data holds the complete table data
 type Data struct {
-	Definition  Definition `json:"definition"`
-	ShowHeaders bool       `json:"show_headers,omitempty"`
-	Rows        []Row      `json:"rows"`
+	Definition  Definition        `json:"definition"`
+	ShowHeaders bool              `json:"show_headers,omitempty"`
+	Rows        []Row             `json:"rows"`
+	Formats     map[int]RowFormat `json:"formats,omitempty"` // By row index
+	Totals      *Totals           `json:"totals,omitempty"`
 }
 
 // ValidateWidths checks if the total column widths fit within the specified max characters.
@@ -74,13 +109,54 @@ func (dt *Data) Validate() error {
 		return fmt.Errorf("table must have at least one column")
 	}
 
+	for _, col := range dt.Definition.Columns {
+		if err := col.Spec.Validate(); err != nil {
+			return fmt.Errorf("column '%s': %w", col.Name, err)
+		}
+	}
+
 	// Validate each row has correct number of cells
 	expectedCells := len(dt.Definition.Columns)
 	for i, row := range dt.Rows {
+		if spans := dt.Formats[i].Spans; len(spans) > 0 {
+			if err := validateSpans(spans, len(row), expectedCells); err != nil {
+				return fmt.Errorf("row %d: %w", i, err)
+			}
+			continue
+		}
 		if len(row) != expectedCells {
 			return fmt.Errorf("row %d has %d cells, expected %d", i, len(row), expectedCells)
 		}
 	}
 
+	if dt.Totals != nil {
+		total := false
+		for _, col := range dt.Definition.Columns {
+			total = total || col.Total
+		}
+		if !total {
+			return fmt.Errorf("totals row requires at least one column with total")
+		}
+	}
+
+	return nil
+}
+
+// validateSpans checks that a row has one span per cell and that the spans
+// cover every column
+func validateSpans(spans []int, cells, columns int) error {
+	if len(spans) != cells {
+		return fmt.Errorf("%d cells but %d spans", cells, len(spans))
+	}
+	covered := 0
+	for _, span := range spans {
+		if span < 1 {
+			return fmt.Errorf("invalid span %d (must be > 0)", span)
+		}
+		covered += span
+	}
+	if covered != columns {
+		return fmt.Errorf("spans cover %d columns, expected %d", covered, columns)
+	}
 	return nil
 }
@@ -0,0 +1,237 @@
+package tables
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// WidthMode says how the width of a column is worked out
+type WidthMode string
+
+// Width modes
+const (
+	WidthFixed   WidthMode = ""        // Width characters
+	WidthPercent WidthMode = "percent" // Share of the line, e.g. "30%"
+	WidthFlex    WidthMode = "flex"    // Share of what the other columns leave, e.g. "*" or "2*"
+	WidthAuto    WidthMode = "auto"    // Longest cell or header, within MinWidth and MaxWidth
+)
+
+// Sizing is a column width relative to the line or to the content
+type Sizing struct {
+	Mode  WidthMode
+	Value int // Percentage for WidthPercent, weight for WidthFlex
+}
+
+// ParseSizing parses a column width: a number of characters, "N%", "*",
+// "N*" or "auto"
+func ParseSizing(s string) (Sizing, int, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	invalid := fmt.Errorf("unknown column width %q (use a number, \"N%%\", \"*\", \"N*\" or \"auto\")", s)
+
+	switch {
+	case s == "auto":
+		return Sizing{Mode: WidthAuto}, 0, nil
+	case s == "*":
+		return Sizing{Mode: WidthFlex, Value: 1}, 0, nil
+	case strings.HasSuffix(s, "*"):
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "*"))
+		if err != nil || n < 1 {
+			return Sizing{}, 0, invalid
+		}
+		return Sizing{Mode: WidthFlex, Value: n}, 0, nil
+	case strings.HasSuffix(s, "%"):
+		n, err := strconv.Atoi(strings.TrimSuffix(s, "%"))
+		if err != nil || n < 1 || n > 100 {
+			return Sizing{}, 0, invalid
+		}
+		return Sizing{Mode: WidthPercent, Value: n}, 0, nil
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return Sizing{}, 0, invalid
+	}
+	return Sizing{}, n, nil
+}
+
+// String returns the width as written in JSON
+func (s Sizing) String() string {
+	switch s.Mode {
+	case WidthPercent:
+		return fmt.Sprintf("%d%%", s.Value)
+	case WidthFlex:
+		if s.Value == 1 {
+			return "*"
+		}
+		return fmt.Sprintf("%d*", s.Value)
+	case WidthAuto:
+		return "auto"
+	default:
+		return ""
+	}
+}
+
+// UnmarshalJSON accepts the width of a column as a number of characters or
+// as a string for ParseSizing
+func (c *Column) UnmarshalJSON(data []byte) error {
+	type column Column
+	var raw struct {
+		*column
+		Width json.RawMessage `json:"width"`
+	}
+	raw.column = (*column)(c)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	c.Width, c.Sizing = 0, Sizing{}
+	if len(raw.Width) == 0 || string(raw.Width) == "null" {
+		return nil
+	}
+	if raw.Width[0] != '"' {
+		return json.Unmarshal(raw.Width, &c.Width)
+	}
+
+	var s string
+	if err := json.Unmarshal(raw.Width, &s); err != nil {
+		return err
+	}
+	sizing, width, err := ParseSizing(s)
+	if err != nil {
+		return fmt.Errorf("column '%s': %w", c.Name, err)
+	}
+	c.Sizing, c.Width = sizing, width
+	return nil
+}
+
+// MarshalJSON writes relative widths as strings
+func (c Column) MarshalJSON() ([]byte, error) {
+	type column Column
+	if c.Sizing.Mode == WidthFixed {
+		return json.Marshal(column(c))
+	}
+	return json.Marshal(struct {
+		column
+		Width string `json:"width"`
+	}{column(c), c.Sizing.String()})
+}
+
+// ResolveWidths sets the Width of the percentage, flexible and auto columns
+// for lines of maxChars characters of font. Percentages are of the line less
+// the gaps, auto columns fit their longest cell or header, and flexible
+// columns share what is left by weight. Fixed columns keep their width.
+func (dt *Data) ResolveWidths(maxChars, columnSpacing int, font string) error {
+	columns := dt.Definition.Columns
+	if len(columns) == 0 {
+		return fmt.Errorf("table must have at least one column")
+	}
+	if columnSpacing < 0 {
+		columnSpacing = 0
+	}
+	available := maxChars - (len(columns)-1)*columnSpacing
+
+	// Auto columns fit the cells as printed
+	rows, err := dt.displayRows(&dt.Definition)
+	if err != nil {
+		return err
+	}
+	var totals Row
+	var totalSpans []int
+	if dt.Totals != nil {
+		if totals, totalSpans, err = totalsRow(&dt.Definition, dt); err != nil {
+			return fmt.Errorf("totals: %w", err)
+		}
+	}
+	printed := &content{
+		rows:       rows,
+		totals:     totals,
+		totalSpans: totalSpans,
+		fractions:  decimalFractions(&dt.Definition, rows, dt.Formats, totals, totalSpans),
+	}
+
+	used, weights := 0, 0
+	for i := range columns {
+		col := &columns[i]
+		switch col.Sizing.Mode {
+		case WidthPercent:
+			col.Width = col.bound(available * col.Sizing.Value / 100)
+		case WidthAuto:
+			col.Width = col.bound(dt.contentWidth(printed, i, font))
+		case WidthFlex:
+			weights += col.Sizing.Value
+			continue
+		}
+		used += col.Width
+	}
+
+	// Flexible columns share the free characters; the remainder goes to the
+	// first ones
+	free := max(available-used, 0)
+	left := free
+	for i := range columns {
+		if columns[i].Sizing.Mode == WidthFlex {
+			columns[i].Width = free * columns[i].Sizing.Value / weights
+			left -= columns[i].Width
+		}
+	}
+	for i := range columns {
+		if columns[i].Sizing.Mode == WidthFlex {
+			if left > 0 {
+				columns[i].Width++
+				left--
+			}
+			columns[i].Width = columns[i].bound(columns[i].Width)
+		}
+	}
+	return nil
+}
+
+// bound keeps a resolved width within MinWidth and MaxWidth, and at least 1
+func (c *Column) bound(width int) int {
+	if c.MaxWidth > 0 {
+		width = min(width, c.MaxWidth)
+	}
+	return max(width, c.MinWidth, 1)
+}
+
+// content is the text of a table as printed
+type content struct {
+	rows       []Row
+	totals     Row
+	totalSpans []int
+	fractions  []int // By column, see decimalFractions
+}
+
+// contentWidth returns the base font characters the longest cell or header
+// of a column needs. Rows with spans and the totals label don't count.
+func (dt *Data) contentWidth(c *content, index int, font string) int {
+	col := dt.Definition.Columns[index]
+	style := Style{}.merge(col.Style)
+
+	// Decimal cells are as wide as their widest whole part and fraction
+	cellWidth := func(cell string) int {
+		width := utf8.RuneCountInString(cell)
+		if fraction := c.fractions[index]; fraction >= 0 && strings.TrimSpace(cell) != "" {
+			width += fraction - fractionWidth(cell, col.Spec.DecimalSeparator())
+		}
+		return width
+	}
+
+	width := Style{Font: style.Font}.needs(utf8.RuneCountInString(col.Name), font)
+	for r, row := range c.rows {
+		format := dt.Formats[r]
+		if len(format.Spans) > 0 || index >= len(row) {
+			continue
+		}
+		for _, line := range strings.Split(row[index], "\n") {
+			width = max(width, style.merge(format.Style).needs(cellWidth(line), font))
+		}
+	}
+	if cell := spannedCell(c.totals, c.totalSpans, index); cell != "" {
+		width = max(width, style.merge(dt.Totals.style()).needs(cellWidth(cell), font))
+	}
+	return width
+}
@@ -0,0 +1,116 @@
+package tables
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSizing(t *testing.T) {
+	tests := []struct {
+		in     string
+		sizing Sizing
+		width  int
+	}{
+		{"12", Sizing{}, 12},
+		{"30%", Sizing{Mode: WidthPercent, Value: 30}, 0},
+		{"*", Sizing{Mode: WidthFlex, Value: 1}, 0},
+		{"3*", Sizing{Mode: WidthFlex, Value: 3}, 0},
+		{"AUTO", Sizing{Mode: WidthAuto}, 0},
+	}
+	for _, tt := range tests {
+		sizing, width, err := ParseSizing(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.sizing, sizing, tt.in)
+		assert.Equal(t, tt.width, width, tt.in)
+	}
+
+	for _, in := range []string{"", "0%", "101%", "0*", "x*", "wide"} {
+		_, _, err := ParseSizing(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestColumn_JSON(t *testing.T) {
+	var def Definition
+	require.NoError(t, json.Unmarshal([]byte(`{"columns":[
+		{"name":"Qty","width":4},
+		{"name":"Item","width":"*"},
+		{"name":"Price","width":"auto","max_width":12}
+	]}`), &def))
+
+	assert.Equal(t, 4, def.Columns[0].Width)
+	assert.Equal(t, Sizing{Mode: WidthFlex, Value: 1}, def.Columns[1].Sizing)
+	assert.Equal(t, Sizing{Mode: WidthAuto}, def.Columns[2].Sizing)
+	assert.Equal(t, 12, def.Columns[2].MaxWidth)
+
+	out, err := json.Marshal(def.Columns[1])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name":"Item","width":"*","align":""}`, string(out))
+
+	err = json.Unmarshal([]byte(`{"columns":[{"name":"Item","width":"half"}]}`), &def)
+	assert.ErrorContains(t, err, `column 'Item': unknown column width "half"`)
+}
+
+func TestData_ResolveWidths(t *testing.T) {
+	data := &Data{
+		Definition: Definition{Columns: []Column{
+			{Name: "Qty", Sizing: Sizing{Mode: WidthAuto}},
+			{Name: "Item", Sizing: Sizing{Mode: WidthFlex, Value: 1}},
+			{Name: "Code", Sizing: Sizing{Mode: WidthPercent, Value: 25}},
+			{Name: "Price", Sizing: Sizing{Mode: WidthAuto}, Total: true},
+		}},
+		Rows: []Row{
+			{"2", "Coffee", "A1", "$7.00"},
+			{"10", "Sandwich", "B2", "$80.00"},
+			{"A long note across the table"},
+		},
+		Formats: map[int]RowFormat{2: {Spans: []int{4}}},
+		Totals:  &Totals{},
+	}
+
+	// 80 mm: 48 characters less 3 gaps
+	require.NoError(t, data.ResolveWidths(48, 1, "A"))
+	assert.Equal(t, []int{3, 25, 11, 6}, widths(data.Definition.Columns))
+
+	// 58 mm: 32 characters; only the flexible and percentage columns shrink
+	require.NoError(t, data.ResolveWidths(32, 1, "A"))
+	assert.Equal(t, []int{3, 13, 7, 6}, widths(data.Definition.Columns))
+}
+
+func TestData_ResolveWidths_FlexWeightsAndBounds(t *testing.T) {
+	data := &Data{Definition: Definition{Columns: []Column{
+		{Name: "A", Width: 5},
+		{Name: "B", Sizing: Sizing{Mode: WidthFlex, Value: 2}},
+		{Name: "C", Sizing: Sizing{Mode: WidthFlex, Value: 1}},
+		{Name: "D", Sizing: Sizing{Mode: WidthFlex, Value: 1}, MaxWidth: 4},
+	}}}
+
+	// 30 characters less 3 gaps and the fixed column leave 22 to share
+	require.NoError(t, data.ResolveWidths(30, 1, "A"))
+	assert.Equal(t, []int{5, 12, 5, 4}, widths(data.Definition.Columns))
+}
+
+func TestData_ResolveWidths_StyledAuto(t *testing.T) {
+	data := &Data{
+		Definition: Definition{Columns: []Column{
+			{Name: "Total", Sizing: Sizing{Mode: WidthAuto}, Style: &Style{Size: "2x1"}},
+			{Name: "Note", Sizing: Sizing{Mode: WidthAuto}, Style: &Style{Font: "B"}},
+		}},
+		Rows: []Row{{"$9.50", "Twelve chars"}},
+	}
+
+	// Double width needs twice the characters; Font B needs three quarters
+	require.NoError(t, data.ResolveWidths(48, 1, "A"))
+	assert.Equal(t, []int{10, 9}, widths(data.Definition.Columns))
+}
+
+func widths(columns []Column) []int {
+	out := make([]int, len(columns))
+	for i, col := range columns {
+		out[i] = col.Width
+	}
+	return out
+}